@@ -0,0 +1,280 @@
+package generic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"text/template"
+
+	"github.com/buger/jsonparser"
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/macros"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// adapter passes the request through to an OpenRTB bidder, as described by the generic section of its bidder info.
+type adapter struct {
+	endpoint template.Template
+	info     adapters.GenericInfo
+}
+
+// Builder builds a new instance of the generic adapter for the given bidder with the given config, which sends
+// a single request without any bidder specific header.
+func Builder(bidderName openrtb_ext.BidderName, config config.Adapter) (adapters.Bidder, error) {
+	return NewBuilder(adapters.GenericInfo{})(bidderName, config)
+}
+
+// NewBuilder returns the builder of the bidders described by the given generic section of their bidder info.
+func NewBuilder(info adapters.GenericInfo) adapters.Builder {
+	return func(bidderName openrtb_ext.BidderName, config config.Adapter) (adapters.Bidder, error) {
+		endpoint, err := template.New("endpointTemplate").Parse(config.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse endpoint url template: %v", err)
+		}
+		for macro := range info.EndpointMacros {
+			if !setEndpointMacro(&macros.EndpointTemplateParams{}, macro, "") {
+				return nil, fmt.Errorf("unknown endpoint macro %s", macro)
+			}
+		}
+
+		return &adapter{
+			endpoint: *endpoint,
+			info:     info,
+		}, nil
+	}
+}
+
+func (a *adapter) MakeRequests(request *openrtb.BidRequest, reqInfo *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	if len(request.Imp) == 0 {
+		return nil, []error{&errortypes.BadInput{Message: "Missing Imp Object"}}
+	}
+
+	if !a.info.ImpSplit {
+		requestData, err := a.makeRequest(*request, request.Imp[0])
+		if err != nil {
+			return nil, []error{err}
+		}
+		return []*adapters.RequestData{requestData}, nil
+	}
+
+	requests := make([]*adapters.RequestData, 0, len(request.Imp))
+	var errs []error
+	for _, imp := range request.Imp {
+		impRequest := *request
+		impRequest.Imp = []openrtb.Imp{imp}
+		requestData, err := a.makeRequest(impRequest, imp)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		requests = append(requests, requestData)
+	}
+	return requests, errs
+}
+
+// makeRequest builds the request sent to the bidder, with the endpoint macros taken from the bidder params of the imp.
+func (a *adapter) makeRequest(request openrtb.BidRequest, imp openrtb.Imp) (*adapters.RequestData, error) {
+	uri, err := a.buildEndpointURL(imp)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.info.Currency != "" {
+		request.Cur = []string{a.info.Currency}
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return &adapters.RequestData{
+		Method:  http.MethodPost,
+		Uri:     uri,
+		Body:    body,
+		Headers: a.getHeaders(&request),
+	}, nil
+}
+
+func (a *adapter) buildEndpointURL(imp openrtb.Imp) (string, error) {
+	params := macros.EndpointTemplateParams{}
+	if len(a.info.EndpointMacros) > 0 {
+		var bidderExt adapters.ExtImpBidder
+		if err := json.Unmarshal(imp.Ext, &bidderExt); err != nil {
+			return "", &errortypes.BadInput{
+				Message: fmt.Sprintf("imp %s: bidder extension not provided or can't be unmarshalled", imp.ID),
+			}
+		}
+		for macro, param := range a.info.EndpointMacros {
+			value, dataType, _, err := jsonparser.Get(bidderExt.Bidder, param)
+			if err != nil || (dataType != jsonparser.String && dataType != jsonparser.Number) {
+				return "", &errortypes.BadInput{
+					Message: fmt.Sprintf("imp %s is missing the bidder param %s", imp.ID, param),
+				}
+			}
+			paramValue := string(value)
+			if dataType == jsonparser.String {
+				if paramValue, err = jsonparser.ParseString(value); err != nil {
+					return "", &errortypes.BadInput{
+						Message: fmt.Sprintf("imp %s has a malformed bidder param %s", imp.ID, param),
+					}
+				}
+			}
+			setEndpointMacro(&params, macro, paramValue)
+		}
+	}
+	return macros.ResolveMacros(a.endpoint, params)
+}
+
+// setEndpointMacro sets the field of the endpoint params named by the macro, and returns false if there's none.
+func setEndpointMacro(params *macros.EndpointTemplateParams, macro string, value string) bool {
+	switch macro {
+	case "Host":
+		params.Host = value
+	case "PublisherID":
+		params.PublisherID = value
+	case "ZoneID":
+		params.ZoneID = value
+	case "SourceId":
+		params.SourceId = value
+	case "AccountID":
+		params.AccountID = value
+	case "AdUnit":
+		params.AdUnit = value
+	default:
+		return false
+	}
+	return true
+}
+
+func (a *adapter) getHeaders(request *openrtb.BidRequest) http.Header {
+	headers := http.Header{}
+	headers.Add("Content-Type", "application/json;charset=utf-8")
+	headers.Add("Accept", "application/json")
+	for name, value := range a.info.Headers {
+		headers.Set(name, value)
+	}
+
+	if a.info.DeviceHeaders && request.Device != nil {
+		if len(request.Device.UA) > 0 {
+			headers.Add("User-Agent", request.Device.UA)
+		}
+		if len(request.Device.IP) > 0 {
+			headers.Add("X-Forwarded-For", request.Device.IP)
+		}
+		if len(request.Device.Language) > 0 {
+			headers.Add("Accept-Language", request.Device.Language)
+		}
+		if request.Device.DNT != nil {
+			headers.Add("Dnt", strconv.Itoa(int(*request.Device.DNT)))
+		}
+	}
+	return headers
+}
+
+func (a *adapter) MakeBids(request *openrtb.BidRequest, requestData *adapters.RequestData, responseData *adapters.ResponseData) (*adapters.BidderResponse, []error) {
+	if responseData.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+
+	if responseData.StatusCode == http.StatusBadRequest {
+		return nil, []error{&errortypes.BadInput{
+			Message: fmt.Sprintf("Unexpected status code: %d. Run with request.debug = 1 for more info", responseData.StatusCode),
+		}}
+	}
+
+	if responseData.StatusCode != http.StatusOK {
+		return nil, []error{&errortypes.BadServerResponse{
+			Message: fmt.Sprintf("Unexpected status code: %d. Run with request.debug = 1 for more info", responseData.StatusCode),
+		}}
+	}
+
+	var response openrtb.BidResponse
+	if err := json.Unmarshal(responseData.Body, &response); err != nil {
+		return nil, []error{&errortypes.BadServerResponse{
+			Message: fmt.Sprintf("Bad server response: %v", err),
+		}}
+	}
+
+	// The bids of the bidders which support OpenRTB 2.6 may set their type, which the 2.5 structs don't hold.
+	var mTypes bidResponseMTypes
+	if err := json.Unmarshal(responseData.Body, &mTypes); err != nil {
+		return nil, []error{&errortypes.BadServerResponse{
+			Message: fmt.Sprintf("Bad server response: %v", err),
+		}}
+	}
+
+	bidResponse := adapters.NewBidderResponseWithBidsCapacity(len(request.Imp))
+	if response.Cur != "" {
+		bidResponse.Currency = response.Cur
+	} else if a.info.Currency != "" {
+		bidResponse.Currency = a.info.Currency
+	}
+
+	var errs []error
+	for seatIndex, seatBid := range response.SeatBid {
+		for i := range seatBid.Bid {
+			bid := &seatBid.Bid[i]
+			bidType, err := getMediaTypeForBid(mTypes.SeatBid[seatIndex].Bid[i].MType, bid.ImpID, request.Imp)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			bidResponse.Bids = append(bidResponse.Bids, &adapters.TypedBid{
+				Bid:     bid,
+				BidType: bidType,
+			})
+		}
+	}
+	return bidResponse, errs
+}
+
+// bidResponseMTypes holds the OpenRTB 2.6 mtype of the bids of the response.
+type bidResponseMTypes struct {
+	SeatBid []struct {
+		Bid []struct {
+			MType int `json:"mtype"`
+		} `json:"bid"`
+	} `json:"seatbid"`
+}
+
+// getMediaTypeForBid returns the type set by the mtype of the bid, or the type of the bids on its imp if it has none.
+func getMediaTypeForBid(mType int, impID string, imps []openrtb.Imp) (openrtb_ext.BidType, error) {
+	switch mType {
+	case 1:
+		return openrtb_ext.BidTypeBanner, nil
+	case 2:
+		return openrtb_ext.BidTypeVideo, nil
+	case 3:
+		return openrtb_ext.BidTypeAudio, nil
+	case 4:
+		return openrtb_ext.BidTypeNative, nil
+	}
+	return getMediaTypeForImp(impID, imps)
+}
+
+// getMediaTypeForImp returns the type of the bids on the imp, which is the first of its media types in the order
+// banner, video, audio and native.
+func getMediaTypeForImp(impID string, imps []openrtb.Imp) (openrtb_ext.BidType, error) {
+	for _, imp := range imps {
+		if imp.ID != impID {
+			continue
+		}
+		switch {
+		case imp.Banner != nil:
+			return openrtb_ext.BidTypeBanner, nil
+		case imp.Video != nil:
+			return openrtb_ext.BidTypeVideo, nil
+		case imp.Audio != nil:
+			return openrtb_ext.BidTypeAudio, nil
+		case imp.Native != nil:
+			return openrtb_ext.BidTypeNative, nil
+		}
+	}
+	return "", &errortypes.BadServerResponse{
+		Message: fmt.Sprintf("Failed to find a supported media type for the imp %s", impID),
+	}
+}
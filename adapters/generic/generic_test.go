@@ -0,0 +1,116 @@
+package generic
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/adapters/adapterstest"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+var testInfo = adapters.GenericInfo{
+	EndpointMacros: map[string]string{"Host": "host", "AccountID": "key"},
+	Headers:        map[string]string{"X-Openrtb-Version": "2.5"},
+	DeviceHeaders:  true,
+	ImpSplit:       true,
+	Currency:       "EUR",
+}
+
+func TestJsonSamples(t *testing.T) {
+	bidder, buildErr := NewBuilder(testInfo)(openrtb_ext.BidderGeneric, config.Adapter{
+		Endpoint: "http://{{.Host}}/bid?key={{.AccountID}}"})
+
+	if buildErr != nil {
+		t.Fatalf("Builder returned unexpected error %v", buildErr)
+	}
+
+	adapterstest.RunJSONBidderTest(t, "generictest", bidder)
+}
+
+func TestEndpointTemplateMalformed(t *testing.T) {
+	_, buildErr := Builder(openrtb_ext.BidderGeneric, config.Adapter{
+		Endpoint: "{{Malformed}}"})
+
+	assert.Error(t, buildErr)
+}
+
+func TestUnknownEndpointMacro(t *testing.T) {
+	_, buildErr := NewBuilder(adapters.GenericInfo{EndpointMacros: map[string]string{"Unknown": "key"}})(openrtb_ext.BidderGeneric, config.Adapter{
+		Endpoint: "http://example.com/bid"})
+
+	assert.EqualError(t, buildErr, "unknown endpoint macro Unknown")
+}
+
+func TestMakeRequestsSingleRequest(t *testing.T) {
+	bidder, buildErr := Builder(openrtb_ext.BidderGeneric, config.Adapter{
+		Endpoint: "http://example.com/bid"})
+	if buildErr != nil {
+		t.Fatalf("Builder returned unexpected error %v", buildErr)
+	}
+
+	request := &openrtb.BidRequest{
+		ID:     "req1",
+		Device: &openrtb.Device{UA: "test-user-agent"},
+		Imp:    []openrtb.Imp{{ID: "imp1"}, {ID: "imp2"}},
+	}
+	requests, errs := bidder.MakeRequests(request, &adapters.ExtraRequestInfo{})
+
+	assert.Empty(t, errs)
+	if assert.Len(t, requests, 1) {
+		assert.Equal(t, "http://example.com/bid", requests[0].Uri)
+		assert.JSONEq(t, `{"id":"req1","device":{"ua":"test-user-agent"},"imp":[{"id":"imp1"},{"id":"imp2"}]}`, string(requests[0].Body))
+		assert.Equal(t, http.Header{
+			"Content-Type": []string{"application/json;charset=utf-8"},
+			"Accept":       []string{"application/json"},
+		}, requests[0].Headers, "The device headers should only be set if configured")
+	}
+}
+
+func TestMakeBidsCurrency(t *testing.T) {
+	testCases := []struct {
+		description      string
+		info             adapters.GenericInfo
+		responseBody     string
+		expectedCurrency string
+	}{
+		{
+			description:      "Response Currency",
+			info:             adapters.GenericInfo{Currency: "EUR"},
+			responseBody:     `{"id":"req1","cur":"GBP"}`,
+			expectedCurrency: "GBP",
+		},
+		{
+			description:      "Configured Currency",
+			info:             adapters.GenericInfo{Currency: "EUR"},
+			responseBody:     `{"id":"req1"}`,
+			expectedCurrency: "EUR",
+		},
+		{
+			description:      "Default Currency",
+			info:             adapters.GenericInfo{},
+			responseBody:     `{"id":"req1"}`,
+			expectedCurrency: "USD",
+		},
+	}
+
+	for _, test := range testCases {
+		bidder, buildErr := NewBuilder(test.info)(openrtb_ext.BidderGeneric, config.Adapter{Endpoint: "http://example.com/bid"})
+		if buildErr != nil {
+			t.Fatalf("%s: Builder returned unexpected error %v", test.description, buildErr)
+		}
+
+		bidResponse, errs := bidder.MakeBids(&openrtb.BidRequest{ID: "req1"}, &adapters.RequestData{}, &adapters.ResponseData{
+			StatusCode: http.StatusOK,
+			Body:       []byte(test.responseBody),
+		})
+
+		assert.Empty(t, errs, test.description)
+		if assert.NotNil(t, bidResponse, test.description) {
+			assert.Equal(t, test.expectedCurrency, bidResponse.Currency, test.description)
+		}
+	}
+}
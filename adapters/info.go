@@ -3,11 +3,13 @@ package adapters
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"strings"
 
 	"github.com/golang/glog"
 	"github.com/mxmCherry/openrtb"
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	yaml "gopkg.in/yaml.v2"
@@ -23,6 +25,10 @@ import (
 //      to nil before the request is forwarded to the delegate.
 //   3. Any Imps which have no MediaTypes left will be removed.
 //   4. If there are no valid Imps left, the delegate won't be called at all.
+//   5. If the bidder only accepts some currencies, the cur of the requests and the bid floors
+//      of the imps are converted to them.
+//   6. If the bidder limits the number of imps per request, the imps are split across
+//      several calls to the delegate.
 func EnforceBidderInfo(bidder Bidder, info BidderInfo) Bidder {
 	return &InfoAwareBidder{
 		Bidder: bidder,
@@ -69,8 +75,109 @@ func (i *InfoAwareBidder) MakeRequests(request *openrtb.BidRequest, reqInfo *Ext
 		request.Imp = filteredImps
 		errs = append(errs, newErrs...)
 	}
-	reqs, delegateErrs := i.Bidder.MakeRequests(request, reqInfo)
-	return reqs, append(errs, delegateErrs...)
+	if len(i.info.currencies) > 0 {
+		var currencyErrs []error
+		request, currencyErrs = i.convertCurrencies(request, reqInfo)
+		errs = append(errs, currencyErrs...)
+		if len(request.Imp) == 0 {
+			return nil, errs
+		}
+	}
+
+	reqs, delegateErrs := i.makeRequests(request, reqInfo)
+	errs = append(errs, delegateErrs...)
+
+	// The requests are built from the OpenRTB 2.5 structs, so the bidders which support 2.6 get its fields moved
+	// out of the exts.
+	if i.info.ortb26 {
+		for _, req := range reqs {
+			if req == nil {
+				continue
+			}
+			body, err := openrtb_ext.ConvertUpTo26(req.Body)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("the request could not be converted to OpenRTB 2.6: %v", err))
+				continue
+			}
+			req.Body = body
+		}
+	}
+	return reqs, errs
+}
+
+// convertCurrencies returns a copy of the request whose cur only has the currencies accepted by the bidder, and whose
+// imps have their bid floors in the first of them. The imps whose bid floor can't be converted are removed.
+func (i *InfoAwareBidder) convertCurrencies(request *openrtb.BidRequest, reqInfo *ExtraRequestInfo) (*openrtb.BidRequest, []error) {
+	converted := *request
+	converted.Cur = nil
+	for _, cur := range request.Cur {
+		if i.acceptsCurrency(cur) {
+			converted.Cur = append(converted.Cur, cur)
+		}
+	}
+	if len(converted.Cur) == 0 && (len(request.Cur) > 0 || !i.acceptsCurrency(defaultCurrency)) {
+		converted.Cur = append([]string(nil), i.info.currencies...)
+	}
+
+	floorCur := defaultCurrency
+	if len(converted.Cur) > 0 {
+		floorCur = converted.Cur[0]
+	}
+
+	var errs []error
+	converted.Imp = make([]openrtb.Imp, 0, len(request.Imp))
+	for index, imp := range request.Imp {
+		impFloorCur := imp.BidFloorCur
+		if impFloorCur == "" {
+			impFloorCur = defaultCurrency
+		}
+		if imp.BidFloor > 0 && !i.acceptsCurrency(impFloorCur) {
+			if reqInfo == nil || reqInfo.CurrencyConversions == nil {
+				errs = append(errs, BadInput(fmt.Sprintf("request.imp[%d] has a bid floor in %s, which this bidder doesn't accept. The imp will be ignored", index, impFloorCur)))
+				continue
+			}
+			rate, err := reqInfo.CurrencyConversions.GetRate(impFloorCur, floorCur)
+			if err != nil {
+				errs = append(errs, BadInput(fmt.Sprintf("request.imp[%d] bid floor could not be converted to %s, which this bidder requires. The imp will be ignored: %v", index, floorCur, err)))
+				continue
+			}
+			imp.BidFloor = imp.BidFloor * rate
+			imp.BidFloorCur = floorCur
+		}
+		converted.Imp = append(converted.Imp, imp)
+	}
+	return &converted, errs
+}
+
+func (i *InfoAwareBidder) acceptsCurrency(currency string) bool {
+	for _, accepted := range i.info.currencies {
+		if strings.EqualFold(accepted, currency) {
+			return true
+		}
+	}
+	return false
+}
+
+// makeRequests calls the delegate with at most the maximum number of imps per request of the bidder.
+func (i *InfoAwareBidder) makeRequests(request *openrtb.BidRequest, reqInfo *ExtraRequestInfo) ([]*RequestData, []error) {
+	if i.info.maxImps == 0 || len(request.Imp) <= i.info.maxImps {
+		return i.Bidder.MakeRequests(request, reqInfo)
+	}
+
+	var reqs []*RequestData
+	var errs []error
+	for start := 0; start < len(request.Imp); start += i.info.maxImps {
+		end := start + i.info.maxImps
+		if end > len(request.Imp) {
+			end = len(request.Imp)
+		}
+		splitRequest := *request
+		splitRequest.Imp = request.Imp[start:end]
+		splitReqs, splitErrs := i.Bidder.MakeRequests(&splitRequest, reqInfo)
+		reqs = append(reqs, splitReqs...)
+		errs = append(errs, splitErrs...)
+	}
+	return reqs, errs
 }
 
 // pruneImps trims invalid media types from each imp, and returns true if any of the
@@ -144,14 +251,23 @@ func ParseBidderInfos(cfg map[string]config.Adapter, infoDir string, bidders []o
 	bidderInfos := make(map[string]BidderInfo, len(bidders))
 	for _, bidderName := range bidders {
 		bidderString := string(bidderName)
-		fileData, err := ioutil.ReadFile(infoDir + "/" + bidderString + ".yaml")
+		// The aliases defined in the host configuration share the info file of their parent, unless they have their own.
+		infoFileName := bidderString
+		if aliasOf := cfg[strings.ToLower(bidderString)].AliasOf; aliasOf != "" {
+			if _, err := os.Stat(infoDir + "/" + bidderString + ".yaml"); os.IsNotExist(err) {
+				if parent, found := openrtb_ext.NormalizeBidderName(aliasOf); found {
+					infoFileName = string(parent)
+				}
+			}
+		}
+		fileData, err := ioutil.ReadFile(infoDir + "/" + infoFileName + ".yaml")
 		if err != nil {
-			glog.Fatalf("error reading from file %s: %v", infoDir+"/"+bidderString+".yaml", err)
+			glog.Fatalf("error reading from file %s: %v", infoDir+"/"+infoFileName+".yaml", err)
 		}
 
 		var parsedInfo BidderInfo
 		if err := yaml.Unmarshal(fileData, &parsedInfo); err != nil {
-			glog.Fatalf("error parsing yaml in file %s: %v", infoDir+"/"+bidderString+".yaml", err)
+			glog.Fatalf("error parsing yaml in file %s: %v", infoDir+"/"+infoFileName+".yaml", err)
 		}
 
 		if isEnabledBidder(cfg, bidderString) {
@@ -206,6 +322,51 @@ type BidderInfo struct {
 	Capabilities            *CapabilitiesInfo `yaml:"capabilities" json:"capabilities"`
 	AliasOf                 string            `json:"aliasOf,omitempty"`
 	ModifyingVastXmlAllowed bool              `yaml:"modifyingVastXmlAllowed" json:"-" xml:"-"`
+	// Generic describes how the generic OpenRTB adapter talks to the bidder. It's only read by the bidders built
+	// with that adapter, which need no code of their own.
+	Generic *GenericInfo `yaml:"generic" json:"-"`
+	// OpenRTB describes the version of OpenRTB which the bidder supports.
+	OpenRTB *OpenRTBInfo `yaml:"openrtb" json:"openrtb,omitempty"`
+	// Currencies are the only currencies which the bidder accepts in the cur of its requests and the bid floors of
+	// their imps. Every currency is accepted if it's empty.
+	Currencies []string `yaml:"currencies" json:"currencies,omitempty"`
+	// MaxImps is the maximum number of imps in a request to the bidder. There's no maximum if it's zero.
+	MaxImps int `yaml:"maxImps" json:"maxImps,omitempty"`
+	// EndpointCompression is the compression of the request bodies which the bidder's endpoint accepts: gzip,
+	// or none if it's empty.
+	EndpointCompression string `yaml:"endpointCompression" json:"endpointCompression,omitempty"`
+}
+
+// EndpointCompressionGZIP is the endpoint compression of the bidders which accept gzipped request bodies.
+const EndpointCompressionGZIP = "gzip"
+
+// defaultCurrency is the currency of the requests without a cur, and of the bid floors without a bidfloorcur.
+const defaultCurrency = "USD"
+
+// OpenRTBVersion26 is the version of the bidders which read the fields OpenRTB 2.6 moved out of the exts.
+const OpenRTBVersion26 = "2.6"
+
+// OpenRTBInfo describes the version of OpenRTB which a bidder supports.
+type OpenRTBInfo struct {
+	// Version is 2.6 for the bidders which read the fields OpenRTB 2.6 defines, such as regs.gdpr or user.eids, in
+	// their 2.6 placement. The other bidders get them in the exts where OpenRTB 2.5 placed them.
+	Version string `yaml:"version" json:"version"`
+}
+
+// GenericInfo describes the requests which the generic OpenRTB adapter sends to a bidder, and its responses.
+type GenericInfo struct {
+	// EndpointMacros maps the fields of macros.EndpointTemplateParams, such as Host or AccountID, to the bidder params
+	// in imp.ext.bidder which set them in the endpoint.
+	EndpointMacros map[string]string `yaml:"endpointMacros"`
+	// Headers are set on every request sent to the bidder.
+	Headers map[string]string `yaml:"headers"`
+	// DeviceHeaders forwards the user agent, IP, language and do not track signal of the device as HTTP headers.
+	DeviceHeaders bool `yaml:"deviceHeaders"`
+	// ImpSplit sends a request for each imp, instead of a single request with every imp.
+	ImpSplit bool `yaml:"impSplit"`
+	// Currency is the only currency of the requests sent to the bidder, and the currency of its bids when its
+	// response doesn't set one.
+	Currency string `yaml:"currency"`
 }
 
 type MaintainerInfo struct {
@@ -232,8 +393,11 @@ func containsMediaType(haystack []openrtb_ext.BidType, needle openrtb_ext.BidTyp
 
 // Structs to handle parsed bidder info, so we aren't reparsing every request
 type parsedBidderInfo struct {
-	app  parsedSupports
-	site parsedSupports
+	app        parsedSupports
+	site       parsedSupports
+	ortb26     bool
+	currencies []string
+	maxImps    int
 }
 
 type parsedSupports struct {
@@ -254,9 +418,19 @@ func parseBidderInfo(info BidderInfo) parsedBidderInfo {
 		parsedInfo.site.enabled = true
 		parsedInfo.site.banner, parsedInfo.site.video, parsedInfo.site.audio, parsedInfo.site.native = parseAllowedTypes(info.Capabilities.Site.MediaTypes)
 	}
+	parsedInfo.ortb26 = info.OpenRTB != nil && info.OpenRTB.Version == OpenRTBVersion26
+	parsedInfo.currencies = info.Currencies
+	parsedInfo.maxImps = info.MaxImps
 	return parsedInfo
 }
 
 type ExtraRequestInfo struct {
 	PbsEntryPoint metrics.RequestType
+	// BidderTmax is the time in milliseconds the bidder was given to respond. It is also set as the tmax of
+	// the bidder's request. Zero means that no deadline was computed for this bidder.
+	BidderTmax int64
+	// CurrencyConversions are the conversion rates of the auction.
+	CurrencyConversions currency.Conversions
+	// AccountID is the ID of the account resolved for the request.
+	AccountID string
 }
@@ -1,13 +1,18 @@
 package adapters_test
 
 import (
+	"encoding/json"
 	"errors"
+	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mxmCherry/openrtb"
 	"github.com/prebid/prebid-server/adapters"
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/stretchr/testify/assert"
@@ -178,6 +183,197 @@ func TestImpFiltering(t *testing.T) {
 	}
 }
 
+func TestOpenRTBVersion(t *testing.T) {
+	request := &openrtb.BidRequest{
+		ID:   "req1",
+		Imp:  []openrtb.Imp{{ID: "imp-1", Banner: &openrtb.Banner{}}},
+		Site: &openrtb.Site{},
+		Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gdpr":1}`)},
+		User: &openrtb.User{Ext: json.RawMessage(`{"consent":"some-consent-string"}`)},
+	}
+	site := &adapters.CapabilitiesInfo{Site: &adapters.PlatformInfo{MediaTypes: []openrtb_ext.BidType{openrtb_ext.BidTypeBanner}}}
+
+	testCases := []struct {
+		description  string
+		openRTB      *adapters.OpenRTBInfo
+		expectedBody string
+	}{
+		{
+			description:  "Not Set",
+			openRTB:      nil,
+			expectedBody: `{"id":"req1","imp":[{"id":"imp-1","banner":{}}],"site":{},"regs":{"ext":{"gdpr":1}},"user":{"ext":{"consent":"some-consent-string"}}}`,
+		},
+		{
+			description:  "2.5",
+			openRTB:      &adapters.OpenRTBInfo{Version: "2.5"},
+			expectedBody: `{"id":"req1","imp":[{"id":"imp-1","banner":{}}],"site":{},"regs":{"ext":{"gdpr":1}},"user":{"ext":{"consent":"some-consent-string"}}}`,
+		},
+		{
+			description:  "2.6",
+			openRTB:      &adapters.OpenRTBInfo{Version: "2.6"},
+			expectedBody: `{"id":"req1","imp":[{"id":"imp-1","banner":{}}],"site":{},"regs":{"gdpr":1},"user":{"consent":"some-consent-string"}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		constrained := adapters.EnforceBidderInfo(&marshalingBidder{}, adapters.BidderInfo{Capabilities: site, OpenRTB: test.openRTB})
+		reqs, errs := constrained.MakeRequests(request, &adapters.ExtraRequestInfo{})
+
+		assert.Empty(t, errs, test.description)
+		if assert.Len(t, reqs, 1, test.description) {
+			assert.JSONEq(t, test.expectedBody, string(reqs[0].Body), test.description)
+		}
+	}
+}
+
+func TestCurrencies(t *testing.T) {
+	site := &adapters.CapabilitiesInfo{Site: &adapters.PlatformInfo{MediaTypes: []openrtb_ext.BidType{openrtb_ext.BidTypeBanner}}}
+	conversions := currency.NewRates(time.Now(), map[string]map[string]float64{"USD": {"EUR": 0.5}})
+
+	testCases := []struct {
+		description     string
+		currencies      []string
+		request         *openrtb.BidRequest
+		conversions     currency.Conversions
+		expectedRequest string
+		expectedErrors  []error
+	}{
+		{
+			description:     "Every Currency Accepted",
+			currencies:      nil,
+			request:         &openrtb.BidRequest{ID: "req1", Site: &openrtb.Site{}, Cur: []string{"GBP"}, Imp: []openrtb.Imp{{ID: "imp-1", Banner: &openrtb.Banner{}, BidFloor: 1, BidFloorCur: "GBP"}}},
+			conversions:     conversions,
+			expectedRequest: `{"id":"req1","site":{},"cur":["GBP"],"imp":[{"id":"imp-1","banner":{},"bidfloor":1,"bidfloorcur":"GBP"}]}`,
+		},
+		{
+			description:     "Default Currency Accepted",
+			currencies:      []string{"USD", "EUR"},
+			request:         &openrtb.BidRequest{ID: "req1", Site: &openrtb.Site{}, Imp: []openrtb.Imp{{ID: "imp-1", Banner: &openrtb.Banner{}, BidFloor: 1}}},
+			conversions:     conversions,
+			expectedRequest: `{"id":"req1","site":{},"imp":[{"id":"imp-1","banner":{},"bidfloor":1}]}`,
+		},
+		{
+			description:     "Default Currency Not Accepted",
+			currencies:      []string{"EUR"},
+			request:         &openrtb.BidRequest{ID: "req1", Site: &openrtb.Site{}, Imp: []openrtb.Imp{{ID: "imp-1", Banner: &openrtb.Banner{}, BidFloor: 1}}},
+			conversions:     conversions,
+			expectedRequest: `{"id":"req1","site":{},"cur":["EUR"],"imp":[{"id":"imp-1","banner":{},"bidfloor":0.5,"bidfloorcur":"EUR"}]}`,
+		},
+		{
+			description:     "Accepted Currencies Kept",
+			currencies:      []string{"EUR", "GBP"},
+			request:         &openrtb.BidRequest{ID: "req1", Site: &openrtb.Site{}, Cur: []string{"USD", "GBP"}, Imp: []openrtb.Imp{{ID: "imp-1", Banner: &openrtb.Banner{}, BidFloor: 1, BidFloorCur: "EUR"}}},
+			conversions:     conversions,
+			expectedRequest: `{"id":"req1","site":{},"cur":["GBP"],"imp":[{"id":"imp-1","banner":{},"bidfloor":1,"bidfloorcur":"EUR"}]}`,
+		},
+		{
+			description: "Bid Floor Not Converted",
+			currencies:  []string{"EUR"},
+			request: &openrtb.BidRequest{ID: "req1", Site: &openrtb.Site{}, Imp: []openrtb.Imp{
+				{ID: "imp-1", Banner: &openrtb.Banner{}, BidFloor: 1, BidFloorCur: "JPY"},
+				{ID: "imp-2", Banner: &openrtb.Banner{}},
+			}},
+			conversions:     conversions,
+			expectedRequest: `{"id":"req1","site":{},"cur":["EUR"],"imp":[{"id":"imp-2","banner":{}}]}`,
+			expectedErrors: []error{
+				&errortypes.BadInput{Message: "request.imp[0] bid floor could not be converted to EUR, which this bidder requires. The imp will be ignored: Currency conversion rate not found: 'JPY' => 'EUR'"},
+			},
+		},
+		{
+			description:    "No Conversions",
+			currencies:     []string{"EUR"},
+			request:        &openrtb.BidRequest{ID: "req1", Site: &openrtb.Site{}, Imp: []openrtb.Imp{{ID: "imp-1", Banner: &openrtb.Banner{}, BidFloor: 1}}},
+			conversions:    nil,
+			expectedErrors: []error{&errortypes.BadInput{Message: "request.imp[0] has a bid floor in USD, which this bidder doesn't accept. The imp will be ignored"}},
+		},
+	}
+
+	for _, test := range testCases {
+		original, _ := json.Marshal(test.request)
+		constrained := adapters.EnforceBidderInfo(&marshalingBidder{}, adapters.BidderInfo{Capabilities: site, Currencies: test.currencies})
+
+		reqs, errs := constrained.MakeRequests(test.request, &adapters.ExtraRequestInfo{CurrencyConversions: test.conversions})
+
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+		if test.expectedRequest == "" {
+			assert.Empty(t, reqs, test.description)
+		} else if assert.Len(t, reqs, 1, test.description) {
+			assert.JSONEq(t, test.expectedRequest, string(reqs[0].Body), test.description)
+		}
+		unchanged, _ := json.Marshal(test.request)
+		assert.JSONEq(t, string(original), string(unchanged), "%s: the request shouldn't change", test.description)
+	}
+}
+
+func TestMaxImps(t *testing.T) {
+	site := &adapters.CapabilitiesInfo{Site: &adapters.PlatformInfo{MediaTypes: []openrtb_ext.BidType{openrtb_ext.BidTypeBanner}}}
+	request := &openrtb.BidRequest{ID: "req1", Site: &openrtb.Site{}, Imp: []openrtb.Imp{
+		{ID: "imp-1", Banner: &openrtb.Banner{}},
+		{ID: "imp-2", Banner: &openrtb.Banner{}},
+		{ID: "imp-3", Banner: &openrtb.Banner{}},
+	}}
+
+	testCases := []struct {
+		description  string
+		maxImps      int
+		expectedImps [][]string
+	}{
+		{
+			description:  "No Maximum",
+			maxImps:      0,
+			expectedImps: [][]string{{"imp-1", "imp-2", "imp-3"}},
+		},
+		{
+			description:  "Under Maximum",
+			maxImps:      3,
+			expectedImps: [][]string{{"imp-1", "imp-2", "imp-3"}},
+		},
+		{
+			description:  "Over Maximum",
+			maxImps:      2,
+			expectedImps: [][]string{{"imp-1", "imp-2"}, {"imp-3"}},
+		},
+		{
+			description:  "One Imp Per Request",
+			maxImps:      1,
+			expectedImps: [][]string{{"imp-1"}, {"imp-2"}, {"imp-3"}},
+		},
+	}
+
+	for _, test := range testCases {
+		constrained := adapters.EnforceBidderInfo(&marshalingBidder{}, adapters.BidderInfo{Capabilities: site, MaxImps: test.maxImps})
+
+		reqs, errs := constrained.MakeRequests(request, &adapters.ExtraRequestInfo{})
+
+		assert.Empty(t, errs, test.description)
+		var imps [][]string
+		for _, req := range reqs {
+			var sent openrtb.BidRequest
+			assert.NoError(t, json.Unmarshal(req.Body, &sent), test.description)
+			var impIDs []string
+			for _, imp := range sent.Imp {
+				impIDs = append(impIDs, imp.ID)
+			}
+			imps = append(imps, impIDs)
+		}
+		assert.Equal(t, test.expectedImps, imps, test.description)
+		assert.Len(t, request.Imp, 3, "%s: the request shouldn't change", test.description)
+	}
+}
+
+// marshalingBidder sends the request it's given as it is.
+type marshalingBidder struct {
+	mockBidder
+}
+
+func (m *marshalingBidder) MakeRequests(request *openrtb.BidRequest, reqInfo *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, []error{err}
+	}
+	return []*adapters.RequestData{{Body: body}}, nil
+}
+
 type mockBidder struct {
 	gotRequest *openrtb.BidRequest
 }
@@ -225,3 +421,59 @@ func TestParsing(t *testing.T) {
 	assert.Equal(t, false, infos.SupportsWebMediaType(mockBidderName, openrtb_ext.BidTypeAudio))
 	assert.Equal(t, true, infos.SupportsWebMediaType(mockBidderName, openrtb_ext.BidTypeNative))
 }
+
+func TestParsingAlias(t *testing.T) {
+	aliasName := openrtb_ext.BidderName("mybrand")
+	cfg := map[string]config.Adapter{
+		"mybrand": {AliasOf: "appnexus", Endpoint: "http://mybrand.com/openrtb2"},
+	}
+
+	infos := adapters.ParseBidderInfos(cfg, "../static/bidder-info", []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus, aliasName})
+
+	assert.True(t, infos.IsActive(aliasName))
+	assert.False(t, infos.IsActive(openrtb_ext.BidderAppnexus), "The parent of an alias isn't enabled by it")
+	assert.Equal(t, infos[string(openrtb_ext.BidderAppnexus)].Capabilities, infos[string(aliasName)].Capabilities, "An alias should share the info of its parent")
+	assert.Equal(t, infos[string(openrtb_ext.BidderAppnexus)].Maintainer, infos[string(aliasName)].Maintainer, "An alias should share the info of its parent")
+}
+
+func TestParsingGenericAlias(t *testing.T) {
+	infoDir, err := ioutil.TempDir("", "bidder-info")
+	if err != nil {
+		t.Fatalf("Failed to create the bidder info directory. %v", err)
+	}
+	defer os.RemoveAll(infoDir)
+
+	infoYAML := `maintainer:
+  email: "info@mybrand.com"
+capabilities:
+  site:
+    mediaTypes:
+      - banner
+generic:
+  endpointMacros:
+    Host: host
+  headers:
+    X-Openrtb-Version: "2.5"
+  impSplit: true
+  currency: EUR
+`
+	if err := ioutil.WriteFile(infoDir+"/mybrand.yaml", []byte(infoYAML), 0644); err != nil {
+		t.Fatalf("Failed to write the bidder info. %v", err)
+	}
+
+	aliasName := openrtb_ext.BidderName("mybrand")
+	cfg := map[string]config.Adapter{
+		"mybrand": {AliasOf: "generic", Endpoint: "http://{{.Host}}/openrtb2"},
+	}
+
+	infos := adapters.ParseBidderInfos(cfg, infoDir, []openrtb_ext.BidderName{aliasName})
+
+	assert.True(t, infos.IsActive(aliasName))
+	assert.Equal(t, "info@mybrand.com", infos[string(aliasName)].Maintainer.Email, "An alias with its own info file shouldn't use the one of its parent")
+	assert.Equal(t, &adapters.GenericInfo{
+		EndpointMacros: map[string]string{"Host": "host"},
+		Headers:        map[string]string{"X-Openrtb-Version": "2.5"},
+		ImpSplit:       true,
+		Currency:       "EUR",
+	}, infos[string(aliasName)].Generic)
+}
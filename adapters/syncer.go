@@ -47,6 +47,8 @@ func (s *Syncer) GetUsersyncInfo(privacyPolicies privacy.Policies) (*usersync.Us
 		GDPR:        privacyPolicies.GDPR.Signal,
 		GDPRConsent: privacyPolicies.GDPR.Consent,
 		USPrivacy:   privacyPolicies.CCPA.Consent,
+		GPP:         privacyPolicies.GPP.Consent,
+		GPPSID:      privacyPolicies.GPP.SectionIDsString(),
 	})
 	if err != nil {
 		return nil, err
@@ -66,3 +68,8 @@ func (s *Syncer) FamilyName() string {
 func (s *Syncer) GDPRVendorID() uint16 {
 	return s.gdprVendorID
 }
+
+// SyncType returns whether the usersync URL is loaded as a redirect or in an iframe.
+func (s *Syncer) SyncType() SyncType {
+	return s.syncType
+}
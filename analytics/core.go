@@ -28,12 +28,13 @@ type PBSAnalyticsModule interface {
 
 //Loggable object of a transaction at /openrtb2/auction endpoint
 type AuctionObject struct {
-	Status    int
-	Errors    []error
-	Request   *openrtb.BidRequest
-	Response  *openrtb.BidResponse
-	Account   *config.Account
-	StartTime time.Time
+	Status     int
+	Errors     []error
+	Request    *openrtb.BidRequest
+	Response   *openrtb.BidResponse
+	Account    *config.Account
+	StartTime  time.Time
+	SeatNonBid []openrtb_ext.SeatNonBid
 }
 
 //Loggable object of a transaction at /openrtb2/amp endpoint
@@ -45,6 +46,7 @@ type AmpObject struct {
 	AmpTargetingValues map[string]string
 	Origin             string
 	StartTime          time.Time
+	SeatNonBid         []openrtb_ext.SeatNonBid
 }
 
 //Loggable object of a transaction at /openrtb2/video endpoint
@@ -56,6 +58,7 @@ type VideoObject struct {
 	VideoRequest  *openrtb_ext.BidRequestVideo
 	VideoResponse *openrtb_ext.BidResponseVideo
 	StartTime     time.Time
+	SeatNonBid    []openrtb_ext.SeatNonBid
 }
 
 //Loggable object of a transaction at /setuid
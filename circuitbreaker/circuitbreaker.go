@@ -0,0 +1,203 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// Breaker stops the calls to a bidder endpoint while it keeps returning errors or timing out.
+//
+// The circuit starts closed, and every request is allowed. Once enough requests were made within a window,
+// the circuit opens if either the error rate or the timeout rate reaches its threshold. No request is allowed
+// while the circuit is open. When config.CircuitBreaker.OpenMillis have elapsed the circuit becomes half-open,
+// and a single trial request is allowed. The circuit closes if the trial succeeds, or opens again if it fails.
+//
+// A nil Breaker allows every request.
+type Breaker struct {
+	config        config.CircuitBreaker
+	onStateChange func(metrics.CircuitBreakerState)
+	now           func() time.Time
+
+	mutex         sync.Mutex
+	state         metrics.CircuitBreakerState
+	windowStart   time.Time
+	openedAt      time.Time
+	trialInFlight bool
+	requests      int
+	errors        int
+	timeouts      int
+}
+
+// New returns a closed Breaker, or nil if the circuit breaker isn't enabled.
+// The onStateChange callback is called, while holding the breaker lock, every time the circuit changes state.
+func New(cfg config.CircuitBreaker, onStateChange func(metrics.CircuitBreakerState)) *Breaker {
+	if !cfg.Enabled {
+		return nil
+	}
+	if onStateChange == nil {
+		onStateChange = func(metrics.CircuitBreakerState) {}
+	}
+	return &Breaker{
+		config:        cfg,
+		onStateChange: onStateChange,
+		now:           time.Now,
+		state:         metrics.CircuitBreakerClosed,
+	}
+}
+
+// Allow returns true if a request can be made to the bidder. Every allowed request must be followed by
+// a call to RecordSuccess, RecordError, RecordTimeout or RecordCanceled.
+func (b *Breaker) Allow() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case metrics.CircuitBreakerOpen:
+		if b.now().Sub(b.openedAt) < b.openDuration() {
+			return false
+		}
+		b.setState(metrics.CircuitBreakerHalfOpen)
+		b.trialInFlight = true
+		return true
+	case metrics.CircuitBreakerHalfOpen:
+		if b.trialInFlight {
+			return false
+		}
+		b.trialInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+// RecordSuccess records a request which got a response from the bidder.
+func (b *Breaker) RecordSuccess() {
+	b.record(false, false)
+}
+
+// RecordError records a request which failed because of a server or transport error.
+func (b *Breaker) RecordError() {
+	b.record(true, false)
+}
+
+// RecordTimeout records a request which didn't get a response in time.
+func (b *Breaker) RecordTimeout() {
+	b.record(false, true)
+}
+
+// RecordCanceled records a request which Prebid Server itself canceled, such as at the soft deadline of the auction,
+// or which it failed to build. It says nothing about the bidder endpoint, so it isn't counted. A trial request of the half-open circuit is
+// released, so that another one can be made.
+func (b *Breaker) RecordCanceled() {
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.trialInFlight = false
+}
+
+// State returns the current state of the circuit. A nil Breaker is always closed.
+func (b *Breaker) State() metrics.CircuitBreakerState {
+	if b == nil {
+		return metrics.CircuitBreakerClosed
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.state
+}
+
+func (b *Breaker) record(failed bool, timedOut bool) {
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := b.now()
+	switch b.state {
+	case metrics.CircuitBreakerHalfOpen:
+		b.trialInFlight = false
+		if failed || timedOut {
+			b.open(now)
+		} else {
+			b.setState(metrics.CircuitBreakerClosed)
+			b.resetWindow(now)
+		}
+	case metrics.CircuitBreakerClosed:
+		if now.Sub(b.windowStart) >= time.Duration(b.config.WindowMillis)*time.Millisecond {
+			b.resetWindow(now)
+		}
+		b.requests++
+		if failed {
+			b.errors++
+		}
+		if timedOut {
+			b.timeouts++
+		}
+		if b.requests >= b.config.MinRequests && b.tripped() {
+			b.open(now)
+		}
+	}
+	// Requests which were allowed before the circuit opened are ignored once it is open.
+}
+
+func (b *Breaker) tripped() bool {
+	requests := float64(b.requests)
+	return float64(b.errors)/requests >= b.config.ErrorRateThreshold || float64(b.timeouts)/requests >= b.config.TimeoutRateThreshold
+}
+
+func (b *Breaker) open(now time.Time) {
+	b.openedAt = now
+	b.setState(metrics.CircuitBreakerOpen)
+}
+
+func (b *Breaker) resetWindow(now time.Time) {
+	b.windowStart = now
+	b.requests = 0
+	b.errors = 0
+	b.timeouts = 0
+}
+
+func (b *Breaker) setState(state metrics.CircuitBreakerState) {
+	b.state = state
+	b.onStateChange(state)
+}
+
+func (b *Breaker) openDuration() time.Duration {
+	return time.Duration(b.config.OpenMillis) * time.Millisecond
+}
+
+// Breakers holds the circuit breaker of each bidder which has one enabled.
+type Breakers map[openrtb_ext.BidderName]*Breaker
+
+// NewBreakers builds the circuit breakers configured for the adapters, and reports their state transitions to the metrics engine.
+func NewBreakers(adapters map[string]config.Adapter, me metrics.MetricsEngine) Breakers {
+	breakers := make(Breakers)
+	for name, adapter := range adapters {
+		if adapter.Disabled || !adapter.CircuitBreaker.Enabled {
+			continue
+		}
+		bidderName, ok := openrtb_ext.NormalizeBidderName(name)
+		if !ok {
+			continue
+		}
+		breakers[bidderName] = New(adapter.CircuitBreaker, func(state metrics.CircuitBreakerState) {
+			me.RecordAdapterCircuitBreakerState(bidderName, state)
+		})
+	}
+	return breakers
+}
@@ -0,0 +1,200 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+var testConfig = config.CircuitBreaker{
+	Enabled:              true,
+	ErrorRateThreshold:   0.5,
+	TimeoutRateThreshold: 0.8,
+	MinRequests:          4,
+	WindowMillis:         1000,
+	OpenMillis:           500,
+}
+
+type testClock struct {
+	current time.Time
+}
+
+func (c *testClock) now() time.Time {
+	return c.current
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.current = c.current.Add(d)
+}
+
+func newBreakerForTest(cfg config.CircuitBreaker) (*Breaker, *testClock, *[]metrics.CircuitBreakerState) {
+	transitions := []metrics.CircuitBreakerState{}
+	breaker := New(cfg, func(state metrics.CircuitBreakerState) {
+		transitions = append(transitions, state)
+	})
+	clock := &testClock{current: time.Unix(1600000000, 0)}
+	breaker.now = clock.now
+	return breaker, clock, &transitions
+}
+
+func TestNewDisabled(t *testing.T) {
+	breaker := New(config.CircuitBreaker{Enabled: false}, nil)
+
+	assert.Nil(t, breaker)
+	assert.True(t, breaker.Allow(), "A nil breaker allows every request")
+	breaker.RecordError()
+	assert.Equal(t, metrics.CircuitBreakerClosed, breaker.State(), "A nil breaker is always closed")
+}
+
+func TestBreakerOpensOnErrorRate(t *testing.T) {
+	breaker, _, transitions := newBreakerForTest(testConfig)
+
+	breaker.RecordError()
+	breaker.RecordError()
+	breaker.RecordError()
+	assert.Equal(t, metrics.CircuitBreakerClosed, breaker.State(), "Circuit shouldn't open before min requests")
+
+	breaker.RecordSuccess()
+	assert.Equal(t, metrics.CircuitBreakerOpen, breaker.State())
+	assert.False(t, breaker.Allow())
+	assert.Equal(t, []metrics.CircuitBreakerState{metrics.CircuitBreakerOpen}, *transitions)
+}
+
+func TestBreakerOpensOnTimeoutRate(t *testing.T) {
+	breaker, _, _ := newBreakerForTest(testConfig)
+
+	breaker.RecordTimeout()
+	breaker.RecordTimeout()
+	breaker.RecordTimeout()
+	breaker.RecordSuccess()
+	assert.Equal(t, metrics.CircuitBreakerClosed, breaker.State(), "Timeout rate 0.75 is below the threshold")
+
+	breaker.RecordTimeout()
+	assert.Equal(t, metrics.CircuitBreakerOpen, breaker.State(), "Timeout rate 0.8 reaches the threshold")
+}
+
+func TestBreakerWindowReset(t *testing.T) {
+	breaker, clock, _ := newBreakerForTest(testConfig)
+
+	breaker.RecordError()
+	breaker.RecordError()
+	breaker.RecordSuccess()
+	clock.advance(time.Second)
+	breaker.RecordError()
+	breaker.RecordSuccess()
+	breaker.RecordSuccess()
+	breaker.RecordSuccess()
+
+	assert.Equal(t, metrics.CircuitBreakerClosed, breaker.State(), "Errors from the previous window should be forgotten")
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	testCases := []struct {
+		description         string
+		trialFailed         bool
+		expectedState       metrics.CircuitBreakerState
+		expectedTransitions []metrics.CircuitBreakerState
+	}{
+		{
+			description:   "Trial Succeeds",
+			trialFailed:   false,
+			expectedState: metrics.CircuitBreakerClosed,
+			expectedTransitions: []metrics.CircuitBreakerState{
+				metrics.CircuitBreakerOpen,
+				metrics.CircuitBreakerHalfOpen,
+				metrics.CircuitBreakerClosed,
+			},
+		},
+		{
+			description:   "Trial Fails",
+			trialFailed:   true,
+			expectedState: metrics.CircuitBreakerOpen,
+			expectedTransitions: []metrics.CircuitBreakerState{
+				metrics.CircuitBreakerOpen,
+				metrics.CircuitBreakerHalfOpen,
+				metrics.CircuitBreakerOpen,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		breaker, clock, transitions := newBreakerForTest(testConfig)
+		for i := 0; i < testConfig.MinRequests; i++ {
+			breaker.RecordError()
+		}
+
+		clock.advance(499 * time.Millisecond)
+		assert.False(t, breaker.Allow(), test.description+": circuit should still be open")
+
+		clock.advance(time.Millisecond)
+		assert.True(t, breaker.Allow(), test.description+": trial request should be allowed")
+		assert.Equal(t, metrics.CircuitBreakerHalfOpen, breaker.State(), test.description)
+		assert.False(t, breaker.Allow(), test.description+": only one trial request should be allowed")
+
+		if test.trialFailed {
+			breaker.RecordTimeout()
+		} else {
+			breaker.RecordSuccess()
+		}
+
+		assert.Equal(t, test.expectedState, breaker.State(), test.description)
+		assert.Equal(t, test.expectedTransitions, *transitions, test.description)
+	}
+}
+
+func TestBreakerIgnoresCanceledRequests(t *testing.T) {
+	breaker, clock, transitions := newBreakerForTest(testConfig)
+
+	for i := 0; i < testConfig.MinRequests; i++ {
+		breaker.RecordCanceled()
+	}
+	assert.Equal(t, metrics.CircuitBreakerClosed, breaker.State(), "Canceled requests shouldn't open the circuit")
+
+	for i := 0; i < testConfig.MinRequests; i++ {
+		breaker.RecordError()
+	}
+	clock.advance(500 * time.Millisecond)
+	assert.True(t, breaker.Allow(), "trial request should be allowed")
+	breaker.RecordCanceled()
+
+	assert.Equal(t, metrics.CircuitBreakerHalfOpen, breaker.State(), "A canceled trial shouldn't change the state")
+	assert.True(t, breaker.Allow(), "A canceled trial should allow another trial request")
+	assert.Equal(t, []metrics.CircuitBreakerState{metrics.CircuitBreakerOpen, metrics.CircuitBreakerHalfOpen}, *transitions)
+}
+
+func TestBreakerIgnoresResultsWhileOpen(t *testing.T) {
+	breaker, _, transitions := newBreakerForTest(testConfig)
+	for i := 0; i < testConfig.MinRequests; i++ {
+		breaker.RecordError()
+	}
+
+	breaker.RecordSuccess()
+	breaker.RecordError()
+
+	assert.Equal(t, metrics.CircuitBreakerOpen, breaker.State())
+	assert.Equal(t, []metrics.CircuitBreakerState{metrics.CircuitBreakerOpen}, *transitions)
+}
+
+func TestNewBreakers(t *testing.T) {
+	me := &metrics.MetricsEngineMock{}
+	me.On("RecordAdapterCircuitBreakerState", openrtb_ext.BidderAppnexus, metrics.CircuitBreakerOpen).Return()
+
+	breakers := NewBreakers(map[string]config.Adapter{
+		"appnexus": {CircuitBreaker: testConfig},
+		"rubicon":  {CircuitBreaker: config.CircuitBreaker{Enabled: false}},
+		"openx":    {Disabled: true, CircuitBreaker: testConfig},
+		"unknown":  {CircuitBreaker: testConfig},
+	}, me)
+
+	assert.Len(t, breakers, 1)
+	if assert.NotNil(t, breakers[openrtb_ext.BidderAppnexus]) {
+		for i := 0; i < testConfig.MinRequests; i++ {
+			breakers[openrtb_ext.BidderAppnexus].RecordError()
+		}
+		me.AssertCalled(t, "RecordAdapterCircuitBreakerState", openrtb_ext.BidderAppnexus, metrics.CircuitBreakerOpen)
+	}
+}
@@ -1,5 +1,12 @@
 package config
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
 // IntegrationType enumerates the values of integrations Prebid Server can configure for an account
 type IntegrationType string
 
@@ -13,12 +20,25 @@ const (
 
 // Account represents a publisher account configuration
 type Account struct {
-	ID            string      `mapstructure:"id" json:"id"`
-	Disabled      bool        `mapstructure:"disabled" json:"disabled"`
-	CacheTTL      DefaultTTLs `mapstructure:"cache_ttl" json:"cache_ttl"`
-	EventsEnabled bool        `mapstructure:"events_enabled" json:"events_enabled"`
-	CCPA          AccountCCPA `mapstructure:"ccpa" json:"ccpa"`
-	GDPR          AccountGDPR `mapstructure:"gdpr" json:"gdpr"`
+	ID            string             `mapstructure:"id" json:"id"`
+	Disabled      bool               `mapstructure:"disabled" json:"disabled"`
+	CacheTTL      DefaultTTLs        `mapstructure:"cache_ttl" json:"cache_ttl"`
+	EventsEnabled bool               `mapstructure:"events_enabled" json:"events_enabled"`
+	CCPA          AccountCCPA        `mapstructure:"ccpa" json:"ccpa"`
+	GDPR          AccountGDPR        `mapstructure:"gdpr" json:"gdpr"`
+	PriceFloors   AccountPriceFloors `mapstructure:"price_floors" json:"price_floors"`
+	Validations   Validations        `mapstructure:"validations" json:"validations"`
+	Hooks         AccountHooks       `mapstructure:"hooks" json:"hooks"`
+	Auction       AccountAuction     `mapstructure:"auction" json:"auction"`
+	// USStatePrivacy toggles the enforcement of the privacy laws of the US states read from the GPP string.
+	USStatePrivacy AccountUSStatePrivacy `mapstructure:"us_state_privacy" json:"us_state_privacy"`
+	// Privacy holds the rules which allow or deny the activities of the components of Prebid Server on the user data.
+	Privacy AccountPrivacy `mapstructure:"privacy" json:"privacy"`
+	// DealTiers holds the deal tier of each bidder for the imps which don't define their own in imp.ext.
+	DealTiers openrtb_ext.DealTierBidderMap `mapstructure:"deal_tiers" json:"deal_tiers,omitempty"`
+	// BidderAliases maps the aliases of the account to their core bidders. They're added to request.ext.prebid.aliases,
+	// where the aliases of the request take precedence.
+	BidderAliases map[string]string `mapstructure:"bidder_aliases" json:"bidder_aliases,omitempty"`
 }
 
 // AccountCCPA represents account-specific CCPA configuration
@@ -36,10 +56,69 @@ func (a *AccountCCPA) EnabledForIntegrationType(integrationType IntegrationType)
 	return a.Enabled
 }
 
+// AccountUSStatePrivacy represents the account-specific enforcement of the privacy laws of the US states
+type AccountUSStatePrivacy struct {
+	Virginia    AccountUSState `mapstructure:"va" json:"va"`
+	Colorado    AccountUSState `mapstructure:"co" json:"co"`
+	Connecticut AccountUSState `mapstructure:"ct" json:"ct"`
+	Utah        AccountUSState `mapstructure:"ut" json:"ut"`
+}
+
+// State returns the configuration of the state with the given code, or nil if the state has none.
+func (a *AccountUSStatePrivacy) State(state string) *AccountUSState {
+	switch state {
+	case "va":
+		return &a.Virginia
+	case "co":
+		return &a.Colorado
+	case "ct":
+		return &a.Connecticut
+	case "ut":
+		return &a.Utah
+	}
+	return nil
+}
+
+// AccountUSState represents the account-specific enforcement of the privacy law of a US state
+type AccountUSState struct {
+	Enabled            *bool              `mapstructure:"enabled" json:"enabled,omitempty"`
+	IntegrationEnabled AccountIntegration `mapstructure:"integration_enabled" json:"integration_enabled"`
+}
+
+// EnabledForIntegrationType indicates whether the privacy law of the state is enforced at the account level for the
+// specified integration type by using the integration type setting if defined or the general setting if defined;
+// otherwise it returns nil
+func (a *AccountUSState) EnabledForIntegrationType(integrationType IntegrationType) *bool {
+	if integrationEnabled := a.IntegrationEnabled.GetByIntegrationType(integrationType); integrationEnabled != nil {
+		return integrationEnabled
+	}
+	return a.Enabled
+}
+
 // AccountGDPR represents account-specific GDPR configuration
 type AccountGDPR struct {
 	Enabled            *bool              `mapstructure:"enabled" json:"enabled,omitempty"`
 	IntegrationEnabled AccountIntegration `mapstructure:"integration_enabled" json:"integration_enabled"`
+	// The purposes override the enforcement of the TCF2 purposes set by the host in gdpr.tcf2
+	Purpose1  AccountGDPRPurpose `mapstructure:"purpose1" json:"purpose1"`
+	Purpose2  AccountGDPRPurpose `mapstructure:"purpose2" json:"purpose2"`
+	Purpose3  AccountGDPRPurpose `mapstructure:"purpose3" json:"purpose3"`
+	Purpose4  AccountGDPRPurpose `mapstructure:"purpose4" json:"purpose4"`
+	Purpose5  AccountGDPRPurpose `mapstructure:"purpose5" json:"purpose5"`
+	Purpose6  AccountGDPRPurpose `mapstructure:"purpose6" json:"purpose6"`
+	Purpose7  AccountGDPRPurpose `mapstructure:"purpose7" json:"purpose7"`
+	Purpose8  AccountGDPRPurpose `mapstructure:"purpose8" json:"purpose8"`
+	Purpose9  AccountGDPRPurpose `mapstructure:"purpose9" json:"purpose9"`
+	Purpose10 AccountGDPRPurpose `mapstructure:"purpose10" json:"purpose10"`
+}
+
+// AccountGDPRPurpose represents account-specific enforcement of a TCF2 purpose. The fields which aren't set keep
+// the host config.
+type AccountGDPRPurpose struct {
+	EnforcePurpose string `mapstructure:"enforce_purpose" json:"enforce_purpose,omitempty"`
+	EnforceVendors *bool  `mapstructure:"enforce_vendors" json:"enforce_vendors,omitempty"`
+	// VendorExceptions replace the host vendor exceptions of the purpose when they're set, even if they're empty
+	VendorExceptions []openrtb_ext.BidderName `mapstructure:"vendor_exceptions" json:"vendor_exceptions,omitempty"`
 }
 
 // EnabledForIntegrationType indicates whether GDPR is turned on at the account level for the specified integration type
@@ -52,7 +131,112 @@ func (a *AccountGDPR) EnabledForIntegrationType(integrationType IntegrationType)
 	return a.Enabled
 }
 
-// AccountIntegration indicates whether a particular privacy policy (GDPR, CCPA) is enabled for each integration type
+// PurposeConfig returns the host config of the TCF2 purpose with the overrides of the account applied
+func (a *AccountGDPR) PurposeConfig(host *TCF2, purpose int) PurposeDetail {
+	purposeConfig := host.PurposeConfig(purpose)
+	override := a.purpose(purpose)
+	if override == nil {
+		return purposeConfig
+	}
+
+	if override.EnforcePurpose != "" {
+		purposeConfig.Enabled = override.EnforcePurpose != TCF2NoEnforcement
+		purposeConfig.EnforcePurpose = override.EnforcePurpose
+	}
+	if override.EnforceVendors != nil {
+		purposeConfig.EnforceVendors = *override.EnforceVendors
+	}
+	if override.VendorExceptions != nil {
+		purposeConfig.VendorExceptions = override.VendorExceptions
+	}
+	return purposeConfig
+}
+
+func (a *AccountGDPR) purpose(purpose int) *AccountGDPRPurpose {
+	switch purpose {
+	case 1:
+		return &a.Purpose1
+	case 2:
+		return &a.Purpose2
+	case 3:
+		return &a.Purpose3
+	case 4:
+		return &a.Purpose4
+	case 5:
+		return &a.Purpose5
+	case 6:
+		return &a.Purpose6
+	case 7:
+		return &a.Purpose7
+	case 8:
+		return &a.Purpose8
+	case 9:
+		return &a.Purpose9
+	case 10:
+		return &a.Purpose10
+	}
+	return nil
+}
+
+func (a *AccountGDPR) validate(errs []error) []error {
+	for purpose := 1; purpose <= TCF2PurposeCount; purpose++ {
+		if enforcement := a.purpose(purpose).EnforcePurpose; !isTCF2Enforcement(enforcement) {
+			errs = append(errs, fmt.Errorf("account_defaults.gdpr.purpose%d.enforce_purpose must be full, basic or no. Got %s", purpose, enforcement))
+		}
+	}
+	return errs
+}
+
+// AccountPriceFloors represents account-specific price floor configuration
+type AccountPriceFloors struct {
+	// Enabled turns on floor resolution and enforcement for the account. A request may still opt out
+	// with ext.prebid.floors.enabled=false, but it cannot opt in when the account is disabled.
+	Enabled bool `mapstructure:"enabled" json:"enabled"`
+	// EnforceDealFloors enforces floors on deal bids unless the request overrides ext.prebid.floors.enforcement.floordeals
+	EnforceDealFloors bool `mapstructure:"enforce_deal_floors" json:"enforce_deal_floors"`
+	// Rules is the floor rule set used when the request (including any stored request) does not define one
+	Rules *openrtb_ext.PriceFloorRules `mapstructure:"rules" json:"rules,omitempty"`
+}
+
+// Clearing modes of the auctions run for an account
+const (
+	// ClearingModeFirstPrice clears the winning bids at their own price.
+	ClearingModeFirstPrice = "first_price"
+	// ClearingModeSecondPrice clears the winning bids at the second highest price plus the price increment.
+	ClearingModeSecondPrice = "second_price"
+	// ClearingModeSoftFloor clears the winning bids above the soft floor like a second price auction, but never below
+	// the soft floor. The bids below the soft floor clear at their own price.
+	ClearingModeSoftFloor = "soft_floor"
+)
+
+// AccountAuction represents account-specific auction configuration
+type AccountAuction struct {
+	// ClearingMode decides the price the winning bids clear the auction at. An empty or unknown mode is the same as ClearingModeFirstPrice.
+	ClearingMode string `mapstructure:"clearing_mode" json:"clearing_mode,omitempty"`
+	// PriceIncrement is added to the second highest price by the second price and soft floor auctions
+	PriceIncrement float64 `mapstructure:"price_increment" json:"price_increment,omitempty"`
+	// SoftFloor is the soft floor of the soft floor auction, in the currency of the bid response
+	SoftFloor float64 `mapstructure:"soft_floor" json:"soft_floor,omitempty"`
+}
+
+func (cfg *AccountAuction) validate(errs []error) []error {
+	if cfg.ClearingMode != "" && !isClearingMode(cfg.ClearingMode) {
+		errs = append(errs, fmt.Errorf("account_defaults.auction.clearing_mode must be one of first_price, second_price or soft_floor. Got %s", cfg.ClearingMode))
+	}
+	if cfg.PriceIncrement < 0 {
+		errs = append(errs, fmt.Errorf("account_defaults.auction.price_increment must be >= 0. Got %f", cfg.PriceIncrement))
+	}
+	if cfg.SoftFloor < 0 {
+		errs = append(errs, fmt.Errorf("account_defaults.auction.soft_floor must be >= 0. Got %f", cfg.SoftFloor))
+	}
+	return errs
+}
+
+func isClearingMode(mode string) bool {
+	return mode == ClearingModeFirstPrice || mode == ClearingModeSecondPrice || mode == ClearingModeSoftFloor
+}
+
+// AccountIntegration indicates whether a particular privacy policy (GDPR, CCPA, US states) is enabled for each integration type
 type AccountIntegration struct {
 	AMP   *bool `mapstructure:"amp" json:"amp,omitempty"`
 	App   *bool `mapstructure:"app" json:"app,omitempty"`
@@ -77,3 +261,91 @@ func (a *AccountIntegration) GetByIntegrationType(integrationType IntegrationTyp
 
 	return integrationEnabled
 }
+
+// Component types which the activity rules may name
+const (
+	ComponentTypeBidder       = "bidder"
+	ComponentTypeAnalytics    = "analytics"
+	ComponentTypeRealTimeData = "rtd"
+	ComponentTypeGeneral      = "general"
+)
+
+// AccountPrivacy represents the account-specific controls of the activities on the user data
+type AccountPrivacy struct {
+	AllowActivities AccountActivities `mapstructure:"allow_activities" json:"allow_activities"`
+}
+
+// AccountActivities holds the rules of each activity which a component of Prebid Server may take on the user data
+type AccountActivities struct {
+	// SyncUser allows the bidders to sync their user IDs, through /cookie_sync and /setuid
+	SyncUser AccountActivity `mapstructure:"sync_user" json:"sync_user"`
+	// FetchBids allows the auction to call the bidders
+	FetchBids AccountActivity `mapstructure:"fetch_bids" json:"fetch_bids"`
+	// TransmitUserFPD allows the user IDs, eids, demographics, first party data and device IDs to be sent to the bidders
+	TransmitUserFPD AccountActivity `mapstructure:"transmit_ufpd" json:"transmit_ufpd"`
+	// TransmitPreciseGeo allows the precise geo and IP addresses to be sent to the bidders
+	TransmitPreciseGeo AccountActivity `mapstructure:"transmit_precise_geo" json:"transmit_precise_geo"`
+}
+
+// AccountActivity holds the rules of an activity. The first rule whose condition matches decides whether the
+// activity is allowed, or the default when there's none, which allows it unless set.
+type AccountActivity struct {
+	Default *bool                 `mapstructure:"default" json:"default,omitempty"`
+	Rules   []AccountActivityRule `mapstructure:"rules" json:"rules,omitempty"`
+}
+
+// AccountActivityRule allows or denies an activity when its condition matches.
+type AccountActivityRule struct {
+	Condition AccountActivityCondition `mapstructure:"condition" json:"condition"`
+	Allow     bool                     `mapstructure:"allow" json:"allow"`
+}
+
+// AccountActivityCondition matches the components and requests which an activity rule applies to. It matches when
+// each of its lists is empty or holds a value of the component or request.
+type AccountActivityCondition struct {
+	// ComponentName lists the names of the components, such as the bidders.
+	ComponentName []string `mapstructure:"component_name" json:"component_name,omitempty"`
+	// ComponentType lists the types of the components: bidder, analytics, rtd or general.
+	ComponentType []string `mapstructure:"component_type" json:"component_type,omitempty"`
+	// GPPSID lists the sections of the GPP string, of which one must apply to the request.
+	GPPSID []int8 `mapstructure:"gpp_sid" json:"gpp_sid,omitempty"`
+	// Geo lists the countries of the user, as ISO-3166-1-alpha-3 codes, optionally followed by a dot and the
+	// region, such as USA.VA.
+	Geo []string `mapstructure:"geo" json:"geo,omitempty"`
+}
+
+func (a *AccountPrivacy) validate(errs []error) []error {
+	activities := []struct {
+		name     string
+		activity AccountActivity
+	}{
+		{name: "sync_user", activity: a.AllowActivities.SyncUser},
+		{name: "fetch_bids", activity: a.AllowActivities.FetchBids},
+		{name: "transmit_ufpd", activity: a.AllowActivities.TransmitUserFPD},
+		{name: "transmit_precise_geo", activity: a.AllowActivities.TransmitPreciseGeo},
+	}
+
+	for _, activity := range activities {
+		for i, rule := range activity.activity.Rules {
+			for _, componentType := range rule.Condition.ComponentType {
+				if !isComponentType(componentType) {
+					errs = append(errs, fmt.Errorf("account_defaults.privacy.allow_activities.%s.rules[%d].condition.component_type must be bidder, analytics, rtd or general. Got %s", activity.name, i, componentType))
+				}
+			}
+			for _, geo := range rule.Condition.Geo {
+				if parts := strings.Split(geo, "."); len(parts) > 2 || parts[0] == "" {
+					errs = append(errs, fmt.Errorf("account_defaults.privacy.allow_activities.%s.rules[%d].condition.geo must be a country, optionally followed by a dot and a region. Got %s", activity.name, i, geo))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func isComponentType(componentType string) bool {
+	switch componentType {
+	case ComponentTypeBidder, ComponentTypeAnalytics, ComponentTypeRealTimeData, ComponentTypeGeneral:
+		return true
+	}
+	return false
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"testing"
 
+	"github.com/prebid/prebid-server/openrtb_ext"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -142,6 +144,85 @@ func TestAccountCCPAEnabledForIntegrationType(t *testing.T) {
 	}
 }
 
+func TestAccountUSStateEnabledForIntegrationType(t *testing.T) {
+	trueValue, falseValue := true, false
+
+	tests := []struct {
+		description         string
+		giveIntegrationType IntegrationType
+		giveEnabled         *bool
+		giveWebEnabled      *bool
+		wantEnabled         *bool
+	}{
+		{
+			description:         "Web integration enabled, general setting disabled",
+			giveIntegrationType: IntegrationTypeWeb,
+			giveEnabled:         &falseValue,
+			giveWebEnabled:      &trueValue,
+			wantEnabled:         &trueValue,
+		},
+		{
+			description:         "Web integration disabled, general setting enabled",
+			giveIntegrationType: IntegrationTypeWeb,
+			giveEnabled:         &trueValue,
+			giveWebEnabled:      &falseValue,
+			wantEnabled:         &falseValue,
+		},
+		{
+			description:         "Web integration unspecified, general setting enabled",
+			giveIntegrationType: IntegrationTypeWeb,
+			giveEnabled:         &trueValue,
+			giveWebEnabled:      nil,
+			wantEnabled:         &trueValue,
+		},
+		{
+			description:         "Web integration unspecified, general setting unspecified",
+			giveIntegrationType: IntegrationTypeWeb,
+			giveEnabled:         nil,
+			giveWebEnabled:      nil,
+			wantEnabled:         nil,
+		},
+	}
+
+	for _, tt := range tests {
+		account := Account{
+			USStatePrivacy: AccountUSStatePrivacy{
+				Colorado: AccountUSState{
+					Enabled: tt.giveEnabled,
+					IntegrationEnabled: AccountIntegration{
+						Web: tt.giveWebEnabled,
+					},
+				},
+			},
+		}
+
+		enabled := account.USStatePrivacy.State("co").EnabledForIntegrationType(tt.giveIntegrationType)
+
+		if tt.wantEnabled == nil {
+			assert.Nil(t, enabled, tt.description)
+		} else {
+			assert.NotNil(t, enabled, tt.description)
+			assert.Equal(t, *tt.wantEnabled, *enabled, tt.description)
+		}
+	}
+}
+
+func TestAccountUSStatePrivacyState(t *testing.T) {
+	trueValue := true
+	account := AccountUSStatePrivacy{
+		Virginia:    AccountUSState{IntegrationEnabled: AccountIntegration{Web: &trueValue}},
+		Colorado:    AccountUSState{IntegrationEnabled: AccountIntegration{App: &trueValue}},
+		Connecticut: AccountUSState{IntegrationEnabled: AccountIntegration{AMP: &trueValue}},
+		Utah:        AccountUSState{IntegrationEnabled: AccountIntegration{Video: &trueValue}},
+	}
+
+	assert.Same(t, &account.Virginia, account.State("va"), "va")
+	assert.Same(t, &account.Colorado, account.State("co"), "co")
+	assert.Same(t, &account.Connecticut, account.State("ct"), "ct")
+	assert.Same(t, &account.Utah, account.State("ut"), "ut")
+	assert.Nil(t, account.State("ca"), "ca")
+}
+
 func TestAccountIntegrationGetByIntegrationType(t *testing.T) {
 	trueValue, falseValue := true, false
 
@@ -241,3 +322,207 @@ func TestAccountIntegrationGetByIntegrationType(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateAccountAuction(t *testing.T) {
+	testCases := []struct {
+		description  string
+		auction      AccountAuction
+		expectedErrs []string
+	}{
+		{
+			description: "Unset",
+			auction:     AccountAuction{},
+		},
+		{
+			description: "Valid",
+			auction:     AccountAuction{ClearingMode: ClearingModeSoftFloor, PriceIncrement: 0.01, SoftFloor: 1.5},
+		},
+		{
+			description: "Invalid",
+			auction:     AccountAuction{ClearingMode: "vickrey", PriceIncrement: -0.01, SoftFloor: -1},
+			expectedErrs: []string{
+				"account_defaults.auction.clearing_mode must be one of first_price, second_price or soft_floor. Got vickrey",
+				"account_defaults.auction.price_increment must be >= 0. Got -0.010000",
+				"account_defaults.auction.soft_floor must be >= 0. Got -1.000000",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.auction.validate(nil)
+
+		errMessages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			errMessages = append(errMessages, err.Error())
+		}
+		assert.ElementsMatch(t, test.expectedErrs, errMessages, test.description)
+	}
+}
+
+func TestAccountGDPRPurposeConfig(t *testing.T) {
+	enforceVendors := true
+	noEnforceVendors := false
+	host := TCF2{
+		Purpose1: PurposeDetail{Enabled: true, EnforcePurpose: TCF2FullEnforcement, EnforceVendors: true},
+		Purpose2: PurposeDetail{Enabled: false, EnforcePurpose: TCF2FullEnforcement, EnforceVendors: false, VendorExceptions: []openrtb_ext.BidderName{"appnexus"}},
+	}
+
+	testCases := []struct {
+		description    string
+		account        AccountGDPR
+		purpose        int
+		expectedConfig PurposeDetail
+	}{
+		{
+			description:    "No Overrides",
+			account:        AccountGDPR{},
+			purpose:        1,
+			expectedConfig: host.Purpose1,
+		},
+		{
+			description: "Enforcement Type Overridden",
+			account:     AccountGDPR{Purpose1: AccountGDPRPurpose{EnforcePurpose: TCF2BasicEnforcement}},
+			purpose:     1,
+			expectedConfig: PurposeDetail{
+				Enabled:        true,
+				EnforcePurpose: TCF2BasicEnforcement,
+				EnforceVendors: true,
+			},
+		},
+		{
+			description: "Purpose Disabled By The Account",
+			account:     AccountGDPR{Purpose1: AccountGDPRPurpose{EnforcePurpose: TCF2NoEnforcement, EnforceVendors: &noEnforceVendors}},
+			purpose:     1,
+			expectedConfig: PurposeDetail{
+				Enabled:        false,
+				EnforcePurpose: TCF2NoEnforcement,
+				EnforceVendors: false,
+			},
+		},
+		{
+			description: "Purpose Enabled By The Account",
+			account:     AccountGDPR{Purpose2: AccountGDPRPurpose{EnforcePurpose: TCF2FullEnforcement, EnforceVendors: &enforceVendors}},
+			purpose:     2,
+			expectedConfig: PurposeDetail{
+				Enabled:          true,
+				EnforcePurpose:   TCF2FullEnforcement,
+				EnforceVendors:   true,
+				VendorExceptions: []openrtb_ext.BidderName{"appnexus"},
+			},
+		},
+		{
+			description: "Vendor Exceptions Cleared By The Account",
+			account:     AccountGDPR{Purpose2: AccountGDPRPurpose{VendorExceptions: []openrtb_ext.BidderName{}}},
+			purpose:     2,
+			expectedConfig: PurposeDetail{
+				Enabled:          false,
+				EnforcePurpose:   TCF2FullEnforcement,
+				VendorExceptions: []openrtb_ext.BidderName{},
+			},
+		},
+		{
+			description:    "Other Purpose Overridden",
+			account:        AccountGDPR{Purpose3: AccountGDPRPurpose{EnforcePurpose: TCF2NoEnforcement}},
+			purpose:        1,
+			expectedConfig: host.Purpose1,
+		},
+		{
+			description:    "Unknown Purpose",
+			account:        AccountGDPR{},
+			purpose:        11,
+			expectedConfig: PurposeDetail{},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedConfig, test.account.PurposeConfig(&host, test.purpose), test.description)
+	}
+}
+
+func TestValidateAccountGDPR(t *testing.T) {
+	testCases := []struct {
+		description  string
+		gdpr         AccountGDPR
+		expectedErrs []string
+	}{
+		{
+			description: "Unset",
+			gdpr:        AccountGDPR{},
+		},
+		{
+			description: "Valid",
+			gdpr: AccountGDPR{
+				Purpose1:  AccountGDPRPurpose{EnforcePurpose: TCF2BasicEnforcement},
+				Purpose10: AccountGDPRPurpose{EnforcePurpose: TCF2NoEnforcement},
+			},
+		},
+		{
+			description:  "Invalid",
+			gdpr:         AccountGDPR{Purpose4: AccountGDPRPurpose{EnforcePurpose: "strict"}},
+			expectedErrs: []string{"account_defaults.gdpr.purpose4.enforce_purpose must be full, basic or no. Got strict"},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.gdpr.validate(nil)
+
+		errMessages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			errMessages = append(errMessages, err.Error())
+		}
+		assert.ElementsMatch(t, test.expectedErrs, errMessages, test.description)
+	}
+}
+
+func TestValidateAccountPrivacy(t *testing.T) {
+	testCases := []struct {
+		description  string
+		privacy      AccountPrivacy
+		expectedErrs []string
+	}{
+		{
+			description: "Unset",
+			privacy:     AccountPrivacy{},
+		},
+		{
+			description: "Valid",
+			privacy: AccountPrivacy{AllowActivities: AccountActivities{
+				SyncUser: AccountActivity{Rules: []AccountActivityRule{
+					{Condition: AccountActivityCondition{ComponentType: []string{"bidder", "rtd"}, Geo: []string{"USA", "USA.VA"}}},
+				}},
+			}},
+		},
+		{
+			description: "Invalid Component Type",
+			privacy: AccountPrivacy{AllowActivities: AccountActivities{
+				FetchBids: AccountActivity{Rules: []AccountActivityRule{
+					{Condition: AccountActivityCondition{ComponentType: []string{"bidder"}}},
+					{Condition: AccountActivityCondition{ComponentType: []string{"adapter"}}},
+				}},
+			}},
+			expectedErrs: []string{"account_defaults.privacy.allow_activities.fetch_bids.rules[1].condition.component_type must be bidder, analytics, rtd or general. Got adapter"},
+		},
+		{
+			description: "Invalid Geos",
+			privacy: AccountPrivacy{AllowActivities: AccountActivities{
+				TransmitPreciseGeo: AccountActivity{Rules: []AccountActivityRule{
+					{Condition: AccountActivityCondition{Geo: []string{".VA", "USA.VA.RIC"}}},
+				}},
+			}},
+			expectedErrs: []string{
+				"account_defaults.privacy.allow_activities.transmit_precise_geo.rules[0].condition.geo must be a country, optionally followed by a dot and a region. Got .VA",
+				"account_defaults.privacy.allow_activities.transmit_precise_geo.rules[0].condition.geo must be a country, optionally followed by a dot and a region. Got USA.VA.RIC",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.privacy.validate(nil)
+
+		errMessages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			errMessages = append(errMessages, err.Error())
+		}
+		assert.ElementsMatch(t, test.expectedErrs, errMessages, test.description)
+	}
+}
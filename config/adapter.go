@@ -6,6 +6,7 @@ import (
 
 	validator "github.com/asaskevich/govalidator"
 	"github.com/prebid/prebid-server/macros"
+	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
 type Adapter struct {
@@ -23,6 +24,8 @@ type Adapter struct {
 	//   {{.GDPR}}      -- This will be replaced with the "gdpr" property sent to /cookie_sync
 	//   {{.Consent}}   -- This will be replaced with the "consent" property sent to /cookie_sync
 	//   {{.USPrivacy}} -- This will be replaced with the "us_privacy" property sent to /cookie_sync
+	//   {{.GPP}}       -- This will be replaced with the "gpp" property sent to /cookie_sync
+	//   {{.GPPSID}}    -- This will be replaced with the "gpp_sid" property sent to /cookie_sync
 	//
 	// For more info on templates, see: https://golang.org/pkg/text/template/
 	UserSyncURL      string `mapstructure:"usersync_url"`
@@ -35,6 +38,80 @@ type Adapter struct {
 	// needed for Facebook
 	PlatformID string `mapstructure:"platform_id"`
 	AppSecret  string `mapstructure:"app_secret"`
+
+	CircuitBreaker CircuitBreaker `mapstructure:"circuit_breaker"`
+	BidCache       BidCache       `mapstructure:"bid_cache"`
+
+	// AliasOf makes this bidder an alias of a core bidder, built with the adapter of that bidder but with its own
+	// endpoint, usersync URL and GDPR vendor ID, so that white-label bidders don't need a code change.
+	AliasOf string `mapstructure:"alias_of"`
+	// GVLVendorID is the ID of an alias in the IAB Global Vendor List, which is checked by the GDPR enforcement.
+	GVLVendorID uint16 `mapstructure:"gvl_vendor_id"`
+
+	// EndpointCompression overrides the compression of the request bodies sent to the bidder, set by the
+	// endpointCompression of its bidder info. It's either gzip or none.
+	EndpointCompression string `mapstructure:"endpoint_compression"`
+}
+
+// CircuitBreaker configures when PBS stops calling a bidder endpoint which keeps failing.
+//
+// The error and timeout rates are computed over windows of WindowMillis, once at least MinRequests were made.
+// When either rate reaches its threshold the circuit opens and the bidder isn't called for OpenMillis.
+// A single trial request is then let through, which closes the circuit if it succeeds or opens it again if it fails.
+type CircuitBreaker struct {
+	Enabled              bool    `mapstructure:"enabled"`
+	ErrorRateThreshold   float64 `mapstructure:"error_rate_threshold"`
+	TimeoutRateThreshold float64 `mapstructure:"timeout_rate_threshold"`
+	MinRequests          int     `mapstructure:"min_requests"`
+	WindowMillis         int     `mapstructure:"window_ms"`
+	OpenMillis           int     `mapstructure:"open_ms"`
+}
+
+func (cfg *CircuitBreaker) validate(adapterName string, errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.ErrorRateThreshold <= 0 || cfg.ErrorRateThreshold > 1 {
+		errs = append(errs, fmt.Errorf("adapters.%s.circuit_breaker.error_rate_threshold must be in the range (0, 1]. Got %f", adapterName, cfg.ErrorRateThreshold))
+	}
+	if cfg.TimeoutRateThreshold <= 0 || cfg.TimeoutRateThreshold > 1 {
+		errs = append(errs, fmt.Errorf("adapters.%s.circuit_breaker.timeout_rate_threshold must be in the range (0, 1]. Got %f", adapterName, cfg.TimeoutRateThreshold))
+	}
+	if cfg.MinRequests < 1 {
+		errs = append(errs, fmt.Errorf("adapters.%s.circuit_breaker.min_requests must be positive. Got %d", adapterName, cfg.MinRequests))
+	}
+	if cfg.WindowMillis < 1 {
+		errs = append(errs, fmt.Errorf("adapters.%s.circuit_breaker.window_ms must be positive. Got %d", adapterName, cfg.WindowMillis))
+	}
+	if cfg.OpenMillis < 1 {
+		errs = append(errs, fmt.Errorf("adapters.%s.circuit_breaker.open_ms must be positive. Got %d", adapterName, cfg.OpenMillis))
+	}
+	return errs
+}
+
+// BidCache configures the reuse of the bids of a bidder for identical requests made within a short window,
+// such as the refreshes of an app on the same device.
+//
+// A cached bid is reused until it expires or wins an auction. It expires after TTLSeconds, or after bid.exp
+// seconds if the bidder set a shorter one.
+type BidCache struct {
+	Enabled    bool `mapstructure:"enabled"`
+	TTLSeconds int  `mapstructure:"ttl_seconds"`
+	// MaxEntries is the number of distinct requests the bids are kept for. The bids of new requests aren't cached once it's reached.
+	MaxEntries int `mapstructure:"max_entries"`
+}
+
+func (cfg *BidCache) validate(adapterName string, errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.TTLSeconds < 1 {
+		errs = append(errs, fmt.Errorf("adapters.%s.bid_cache.ttl_seconds must be positive. Got %d", adapterName, cfg.TTLSeconds))
+	}
+	if cfg.MaxEntries < 1 {
+		errs = append(errs, fmt.Errorf("adapters.%s.bid_cache.max_entries must be positive. Got %d", adapterName, cfg.MaxEntries))
+	}
+	return errs
 }
 
 type AdapterXAPI struct {
@@ -43,7 +120,7 @@ type AdapterXAPI struct {
 	Tracker  string `mapstructure:"tracker"`
 }
 
-// validateAdapters validates adapter's endpoint and user sync URL
+// validateAdapters validates adapter's endpoint, user sync URL, circuit breaker, bid cache and endpoint compression
 func validateAdapters(adapterMap map[string]Adapter, errs []error) []error {
 	for adapterName, adapter := range adapterMap {
 		if !adapter.Disabled {
@@ -52,7 +129,31 @@ func validateAdapters(adapterMap map[string]Adapter, errs []error) []error {
 
 			// Verify that valid user_sync URLs are specified in the config
 			errs = validateAdapterUserSyncURL(adapter.UserSyncURL, adapterName, errs)
+
+			errs = adapter.CircuitBreaker.validate(adapterName, errs)
+			errs = adapter.BidCache.validate(adapterName, errs)
+
+			if adapter.EndpointCompression != "" && adapter.EndpointCompression != "gzip" && adapter.EndpointCompression != "none" {
+				errs = append(errs, fmt.Errorf("adapters.%s.endpoint_compression must be gzip or none. Got %s", adapterName, adapter.EndpointCompression))
+			}
 		}
+		if adapter.AliasOf != "" {
+			errs = validateAdapterAlias(adapterName, adapter.AliasOf, errs)
+		}
+	}
+	return errs
+}
+
+// validateAdapterAlias makes sure that an alias doesn't take the name of a core bidder, and that it refers to one
+func validateAdapterAlias(adapterName string, aliasOf string, errs []error) []error {
+	if _, isCoreBidder := openrtb_ext.NormalizeBidderName(adapterName); isCoreBidder {
+		if _, isAlias := openrtb_ext.AliasBidderParent(openrtb_ext.BidderName(adapterName)); !isAlias {
+			return append(errs, fmt.Errorf("adapters.%s.alias_of can't be set on a core bidder", adapterName))
+		}
+	}
+	parent, isCoreBidder := openrtb_ext.NormalizeBidderName(aliasOf)
+	if _, isAlias := openrtb_ext.AliasBidderParent(parent); !isCoreBidder || isAlias {
+		return append(errs, fmt.Errorf("adapters.%s.alias_of must refer to a core bidder. Got %s", adapterName, aliasOf))
 	}
 	return errs
 }
@@ -64,6 +165,8 @@ const (
 	dummyGDPR        string = "0"
 	dummyGDPRConsent string = "someGDPRConsentString"
 	dummyCCPA        string = "1NYN"
+	dummyGPP         string = "DBABM~someTCFConsentString"
+	dummyGPPSID      string = "2"
 )
 
 // validateAdapterEndpoint makes sure that an adapter has a valid endpoint
@@ -115,6 +218,8 @@ func validateAdapterUserSyncURL(userSyncURL string, adapterName string, errs []e
 			GDPR:        dummyGDPR,
 			GDPRConsent: dummyGDPRConsent,
 			USPrivacy:   dummyCCPA,
+			GPP:         dummyGPP,
+			GPPSID:      dummyGPPSID,
 		}
 		resolvedUserSyncURL, err := macros.ResolveMacros(*userSyncTemplate, dummyMacroValues)
 		if err != nil {
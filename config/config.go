@@ -28,6 +28,11 @@ type Configuration struct {
 	// If empty, it will return a 204 with no content.
 	StatusResponse    string          `mapstructure:"status_response"`
 	AuctionTimeouts   AuctionTimeouts `mapstructure:"auction_timeouts_ms"`
+	TmaxAdjustments   TmaxAdjustments `mapstructure:"tmax_adjustments"`
+	SoftDeadline      SoftDeadline    `mapstructure:"auction_soft_deadline"`
+	TrafficShaping    TrafficShaping  `mapstructure:"traffic_shaping"`
+	Validations       Validations     `mapstructure:"validations"`
+	Hooks             Hooks           `mapstructure:"hooks"`
 	CacheURL          Cache           `mapstructure:"cache"`
 	ExtCacheURL       ExternalCache   `mapstructure:"external_cache"`
 	RecaptchaSecret   string          `mapstructure:"recaptcha_secret"`
@@ -52,6 +57,7 @@ type Configuration struct {
 	GDPR                 GDPR               `mapstructure:"gdpr"`
 	CCPA                 CCPA               `mapstructure:"ccpa"`
 	LMT                  LMT                `mapstructure:"lmt"`
+	USStatePrivacy       USStatePrivacy     `mapstructure:"us_state_privacy"`
 	CurrencyConverter    CurrencyConverter  `mapstructure:"currency_converter"`
 	DefReqConfig         DefReqConfig       `mapstructure:"default_request"`
 
@@ -94,6 +100,11 @@ type HTTPClient struct {
 func (cfg *Configuration) validate() []error {
 	var errs []error
 	errs = cfg.AuctionTimeouts.validate(errs)
+	errs = cfg.TmaxAdjustments.validate(errs)
+	errs = cfg.SoftDeadline.validate(errs)
+	errs = cfg.TrafficShaping.validate(errs)
+	errs = cfg.Validations.validate(errs)
+	errs = cfg.Hooks.validate(errs)
 	errs = cfg.StoredRequests.validate(errs)
 	errs = cfg.StoredRequestsAMP.validate(errs)
 	errs = cfg.Accounts.validate(errs)
@@ -108,6 +119,9 @@ func (cfg *Configuration) validate() []error {
 	errs = validateAdapters(cfg.Adapters, errs)
 	errs = cfg.Debug.validate(errs)
 	errs = cfg.ExtCacheURL.validate(errs)
+	errs = cfg.AccountDefaults.Auction.validate(errs)
+	errs = cfg.AccountDefaults.GDPR.validate(errs)
+	errs = cfg.AccountDefaults.Privacy.validate(errs)
 	if cfg.AccountDefaults.Disabled {
 		glog.Warning(`With account_defaults.disabled=true, host-defined accounts must exist and have "disabled":false. All other requests will be rejected.`)
 	}
@@ -128,6 +142,135 @@ func (cfg *AuctionTimeouts) validate(errs []error) []error {
 	return errs
 }
 
+// SoftDeadline finalizes the auction with the bids received by a percentage of the auction timeout,
+// instead of waiting on the slowest bidders until the timeout itself.
+type SoftDeadline struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Percent is the share of the auction timeout the bidders have to respond. The others are cancelled.
+	Percent uint64 `mapstructure:"percent"`
+}
+
+func (cfg *SoftDeadline) validate(errs []error) []error {
+	if cfg.Enabled && (cfg.Percent < 1 || cfg.Percent > 99) {
+		errs = append(errs, fmt.Errorf("auction_soft_deadline.percent must be between 1 and 99. Got %d", cfg.Percent))
+	}
+	return errs
+}
+
+// TmaxAdjustments controls how the auction deadline is turned into the tmax sent to each bidder.
+type TmaxAdjustments struct {
+	Enabled bool `mapstructure:"enabled"`
+	// SafetyMarginMillis is reserved for the work done after the bidders respond, on top of the expected cache time.
+	SafetyMarginMillis uint64 `mapstructure:"safety_margin_ms"`
+	// NetworkLatencyPercentile picks the percentile of each bidder's recorded connection wait times which
+	// is subtracted from its tmax to account for the network. Use 0 to ignore the recorded latencies.
+	NetworkLatencyPercentile float64 `mapstructure:"network_latency_percentile"`
+	// MinBidderTmaxMillis is the lowest tmax ever sent to a bidder.
+	MinBidderTmaxMillis uint64 `mapstructure:"min_bidder_tmax_ms"`
+}
+
+func (cfg *TmaxAdjustments) validate(errs []error) []error {
+	if cfg.NetworkLatencyPercentile < 0 || cfg.NetworkLatencyPercentile >= 1 {
+		errs = append(errs, fmt.Errorf("tmax_adjustments.network_latency_percentile must be at least 0 and less than 1. Got %f", cfg.NetworkLatencyPercentile))
+	}
+	return errs
+}
+
+// TrafficShaping configures the skipping of bidders which almost never bid for an account and media type,
+// or which almost always time out.
+type TrafficShaping struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MinSamples is the number of requests a bidder must have received before it can be skipped.
+	MinSamples int `mapstructure:"min_samples"`
+	// MinBidRate is the share of requests a bidder must bid on to never be skipped.
+	MinBidRate float64 `mapstructure:"min_bid_rate"`
+	// MaxTimeoutRate is the share of requests a bidder may time out on before it can be skipped.
+	MaxTimeoutRate float64 `mapstructure:"max_timeout_rate"`
+	// SkipRate is the probability of skipping a bidder which is being shaped. The requests which still
+	// go through keep its statistics up to date, so that bidders which start bidding are noticed.
+	SkipRate float64 `mapstructure:"skip_rate"`
+	// WindowSize is the number of requests after which the statistics of a bidder are halved,
+	// so that recent traffic weighs more than old traffic. It must be at least twice MinSamples.
+	WindowSize int `mapstructure:"window_size"`
+	// MaxEntries bounds the number of bidder, account and media type statistics kept in memory. Once it's
+	// reached, a random entry is evicted for each new one.
+	MaxEntries int `mapstructure:"max_entries"`
+}
+
+func (cfg *TrafficShaping) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.MinSamples < 1 {
+		errs = append(errs, fmt.Errorf("traffic_shaping.min_samples must be positive. Got %d", cfg.MinSamples))
+	}
+	if cfg.WindowSize < 2*cfg.MinSamples {
+		errs = append(errs, fmt.Errorf("traffic_shaping.window_size must be at least twice traffic_shaping.min_samples. window_size=%d, min_samples=%d", cfg.WindowSize, cfg.MinSamples))
+	}
+	if cfg.MaxEntries < 1 {
+		errs = append(errs, fmt.Errorf("traffic_shaping.max_entries must be positive. Got %d", cfg.MaxEntries))
+	}
+	rates := []struct {
+		name  string
+		value float64
+	}{
+		{"min_bid_rate", cfg.MinBidRate},
+		{"max_timeout_rate", cfg.MaxTimeoutRate},
+		{"skip_rate", cfg.SkipRate},
+	}
+	for _, rate := range rates {
+		if rate.value < 0 || rate.value > 1 {
+			errs = append(errs, fmt.Errorf("traffic_shaping.%s must be in the range [0, 1]. Got %f", rate.name, rate.value))
+		}
+	}
+	return errs
+}
+
+// Bid validation modes
+const (
+	// ValidationOff skips the validation.
+	ValidationOff = "off"
+	// ValidationWarn keeps the invalid bids, but returns a warning for each of them.
+	ValidationWarn = "warn"
+	// ValidationEnforce removes the invalid bids from the auction, and returns an error for each of them.
+	ValidationEnforce = "enforce"
+)
+
+// Validations configures the optional checks run on the bids returned by the bidders.
+// Accounts may override each of them. An empty mode is the same as ValidationOff.
+type Validations struct {
+	// BannerCreativeSize checks that the w and h of banner bids match one of the sizes of the imp.
+	BannerCreativeSize string `mapstructure:"banner_creative_size" json:"banner_creative_size"`
+	// SecureMarkup checks that the bids for secure imps don't load creatives over plain http.
+	SecureMarkup string `mapstructure:"secure_markup" json:"secure_markup"`
+}
+
+func (cfg *Validations) validate(errs []error) []error {
+	if cfg.BannerCreativeSize != "" && !isValidationMode(cfg.BannerCreativeSize) {
+		errs = append(errs, fmt.Errorf("validations.banner_creative_size must be one of off, warn or enforce. Got %s", cfg.BannerCreativeSize))
+	}
+	if cfg.SecureMarkup != "" && !isValidationMode(cfg.SecureMarkup) {
+		errs = append(errs, fmt.Errorf("validations.secure_markup must be one of off, warn or enforce. Got %s", cfg.SecureMarkup))
+	}
+	return errs
+}
+
+// WithAccountOverrides returns the validations to run for an account. The modes set by the account
+// replace the host ones, and the unset or unknown ones are ignored.
+func (cfg Validations) WithAccountOverrides(account Validations) Validations {
+	if isValidationMode(account.BannerCreativeSize) {
+		cfg.BannerCreativeSize = account.BannerCreativeSize
+	}
+	if isValidationMode(account.SecureMarkup) {
+		cfg.SecureMarkup = account.SecureMarkup
+	}
+	return cfg
+}
+
+func isValidationMode(mode string) bool {
+	return mode == ValidationOff || mode == ValidationWarn || mode == ValidationEnforce
+}
+
 func (data *ExternalCache) validate(errs []error) []error {
 	if data.Host == "" && data.Path == "" {
 		// Both host and path can be blank. No further validation needed
@@ -183,9 +326,10 @@ func (cfg *AuctionTimeouts) LimitAuctionTimeout(requested time.Duration) time.Du
 
 // Privacy is a grouping of privacy related configs to assist in dependency injection.
 type Privacy struct {
-	CCPA CCPA
-	GDPR GDPR
-	LMT  LMT
+	CCPA           CCPA
+	GDPR           GDPR
+	LMT            LMT
+	USStatePrivacy USStatePrivacy
 }
 
 type GDPR struct {
@@ -213,6 +357,7 @@ func (cfg *GDPR) validate(errs []error) []error {
 	if cfg.HostVendorID == 0 {
 		glog.Warning("gdpr.host_vendor_id was not specified. Host company GDPR checks will be skipped.")
 	}
+	errs = cfg.TCF2.validate(errs)
 	if cfg.AMPException == true {
 		errs = append(errs, fmt.Errorf("gdpr.amp_exception has been discontinued and must be removed from your config. If you need to disable GDPR for AMP, you may do so per-account (gdpr.integration_enabled.amp) or at the host level for the default account (account_defaults.gdpr.integration_enabled.amp)"))
 	}
@@ -243,14 +388,124 @@ type TCF2 struct {
 	Enabled             bool                 `mapstructure:"enabled"`
 	Purpose1            PurposeDetail        `mapstructure:"purpose1"`
 	Purpose2            PurposeDetail        `mapstructure:"purpose2"`
+	Purpose3            PurposeDetail        `mapstructure:"purpose3"`
+	Purpose4            PurposeDetail        `mapstructure:"purpose4"`
+	Purpose5            PurposeDetail        `mapstructure:"purpose5"`
+	Purpose6            PurposeDetail        `mapstructure:"purpose6"`
 	Purpose7            PurposeDetail        `mapstructure:"purpose7"`
+	Purpose8            PurposeDetail        `mapstructure:"purpose8"`
+	Purpose9            PurposeDetail        `mapstructure:"purpose9"`
+	Purpose10           PurposeDetail        `mapstructure:"purpose10"`
 	SpecialPurpose1     PurposeDetail        `mapstructure:"special_purpose1"`
 	PurposeOneTreatment PurposeOneTreatement `mapstructure:"purpose_one_treatement"`
+	// NonGVLVendorsBasicEnforcement gives the bidders which don't have a GVL ID, or aren't on the GVL, the basic
+	// enforcement of every purpose. They're denied otherwise, unless they're basic enforcement vendors of the purpose.
+	NonGVLVendorsBasicEnforcement bool `mapstructure:"non_gvl_vendors_basic_enforcement"`
+}
+
+// TCF2PurposeCount is the number of purposes defined by TCF2.
+const TCF2PurposeCount = 10
+
+// PurposeConfig returns the config of the TCF2 purpose, which is disabled if there's no such purpose.
+func (t *TCF2) PurposeConfig(purpose int) PurposeDetail {
+	switch purpose {
+	case 1:
+		return t.Purpose1
+	case 2:
+		return t.Purpose2
+	case 3:
+		return t.Purpose3
+	case 4:
+		return t.Purpose4
+	case 5:
+		return t.Purpose5
+	case 6:
+		return t.Purpose6
+	case 7:
+		return t.Purpose7
+	case 8:
+		return t.Purpose8
+	case 9:
+		return t.Purpose9
+	case 10:
+		return t.Purpose10
+	}
+	return PurposeDetail{}
 }
 
-// Making a purpose struct so purpose specific details can be added later.
+func (t *TCF2) validate(errs []error) []error {
+	for purpose := 1; purpose <= TCF2PurposeCount; purpose++ {
+		purposeConfig := t.PurposeConfig(purpose)
+		if !isTCF2Enforcement(purposeConfig.EnforcePurpose) {
+			errs = append(errs, fmt.Errorf("gdpr.tcf2.purpose%d.enforce_purpose must be full, basic or no. Got %s", purpose, purposeConfig.EnforcePurpose))
+		}
+	}
+	if !isTCF2Enforcement(t.SpecialPurpose1.EnforcePurpose) {
+		errs = append(errs, fmt.Errorf("gdpr.tcf2.special_purpose1.enforce_purpose must be full, basic or no. Got %s", t.SpecialPurpose1.EnforcePurpose))
+	}
+	return errs
+}
+
+// Enforcement types of the TCF2 purposes
+const (
+	// TCF2FullEnforcement checks the legal basis given by the consent string against the declarations of the vendor in the GVL.
+	TCF2FullEnforcement = "full"
+	// TCF2BasicEnforcement only checks the legal basis given by the consent string, without the GVL.
+	TCF2BasicEnforcement = "basic"
+	// TCF2NoEnforcement allows the purpose for every vendor. It isn't a legal basis for sending the user IDs, though.
+	TCF2NoEnforcement = "no"
+)
+
+// isTCF2Enforcement accepts an empty enforcement, which is the same as TCF2FullEnforcement.
+func isTCF2Enforcement(enforcement string) bool {
+	return enforcement == "" || enforcement == TCF2FullEnforcement || enforcement == TCF2BasicEnforcement || enforcement == TCF2NoEnforcement
+}
+
+// PurposeDetail defines how a TCF2 purpose is enforced.
 type PurposeDetail struct {
+	// Enabled enforces the purpose. The user IDs are still only sent to the vendors with a legal basis for one of the
+	// purposes 2 to 10, whether they're enabled, or enforced, or not.
 	Enabled bool `mapstructure:"enabled"`
+	// EnforcePurpose is the enforcement type of the purpose, once it's enabled. An empty type is the same as TCF2FullEnforcement.
+	EnforcePurpose string `mapstructure:"enforce_purpose"`
+	// EnforceVendors checks that the vendor has the consent, or the legitimate interest, of the user in the consent string.
+	EnforceVendors bool `mapstructure:"enforce_vendors"`
+	// VendorExceptions are the bidders which the purpose is allowed for, whatever the consent string says.
+	VendorExceptions []openrtb_ext.BidderName `mapstructure:"vendor_exceptions"`
+	// BasicEnforcementVendors are the bidders which are given the basic enforcement of the purpose, without the vendor
+	// signals of the consent string, whether they're on the GVL or not.
+	BasicEnforcementVendors []openrtb_ext.BidderName `mapstructure:"basic_enforcement_vendors"`
+}
+
+// Enforcement returns the enforcement type of the purpose, which is TCF2NoEnforcement if it isn't enabled.
+func (d *PurposeDetail) Enforcement() string {
+	if !d.Enabled {
+		return TCF2NoEnforcement
+	}
+	if d.EnforcePurpose == "" {
+		return TCF2FullEnforcement
+	}
+	return d.EnforcePurpose
+}
+
+// IsVendorException returns whether the purpose is allowed for the bidder whatever the consent string says.
+func (d *PurposeDetail) IsVendorException(bidder openrtb_ext.BidderName) bool {
+	for _, exception := range d.VendorExceptions {
+		if exception == bidder {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBasicEnforcementVendor returns whether the bidder is given the basic enforcement of the purpose.
+func (d *PurposeDetail) IsBasicEnforcementVendor(bidder openrtb_ext.BidderName) bool {
+	for _, vendor := range d.BasicEnforcementVendors {
+		if vendor == bidder {
+			return true
+		}
+	}
+	return false
 }
 
 type PurposeOneTreatement struct {
@@ -266,6 +521,12 @@ type LMT struct {
 	Enforce bool `mapstructure:"enforce"`
 }
 
+// USStatePrivacy enforces the opt outs of the sale of the user data and of targeted advertising sent in the sections
+// of the GPP string of the US states with their own privacy law. The accounts may override it per state.
+type USStatePrivacy struct {
+	Enforce bool `mapstructure:"enforce"`
+}
+
 type Analytics struct {
 	File     FileLogs `mapstructure:"file"`
 	Pubstack Pubstack `mapstructure:"pubstack"`
@@ -668,6 +929,22 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("status_response", "")
 	v.SetDefault("auction_timeouts_ms.default", 0)
 	v.SetDefault("auction_timeouts_ms.max", 0)
+	v.SetDefault("tmax_adjustments.enabled", false)
+	v.SetDefault("tmax_adjustments.safety_margin_ms", 0)
+	v.SetDefault("tmax_adjustments.network_latency_percentile", 0.9)
+	v.SetDefault("tmax_adjustments.min_bidder_tmax_ms", 0)
+	v.SetDefault("auction_soft_deadline.enabled", false)
+	v.SetDefault("auction_soft_deadline.percent", 80)
+	v.SetDefault("traffic_shaping.enabled", false)
+	v.SetDefault("traffic_shaping.min_samples", 1000)
+	v.SetDefault("traffic_shaping.min_bid_rate", 0.01)
+	v.SetDefault("traffic_shaping.max_timeout_rate", 0.9)
+	v.SetDefault("traffic_shaping.skip_rate", 0.9)
+	v.SetDefault("traffic_shaping.window_size", 10000)
+	v.SetDefault("traffic_shaping.max_entries", 100000)
+	v.SetDefault("validations.banner_creative_size", ValidationOff)
+	v.SetDefault("validations.secure_markup", ValidationOff)
+	v.SetDefault("hooks.enabled", false)
 	v.SetDefault("cache.scheme", "")
 	v.SetDefault("cache.host", "")
 	v.SetDefault("cache.query", "")
@@ -830,6 +1107,7 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("adapters.eplanning.endpoint", "http://rtb.e-planning.net/pbs/1")
 	v.SetDefault("adapters.gamma.endpoint", "https://hb.gammaplatform.com/adx/request/")
 	v.SetDefault("adapters.gamoshi.endpoint", "https://rtb.gamoshi.io")
+	v.SetDefault("adapters.generic.disabled", true)
 	v.SetDefault("adapters.grid.endpoint", "https://grid.bidswitch.net/sp_bid?sp=prebid")
 	v.SetDefault("adapters.gumgum.endpoint", "https://g2.gumgum.com/providers/prbds2s/bid")
 	v.SetDefault("adapters.improvedigital.endpoint", "http://ad.360yield.com/pbs")
@@ -906,12 +1184,63 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("gdpr.tcf1.fallback_gvl_path", "./static/tcf1/fallback_gvl.json")
 	v.SetDefault("gdpr.tcf2.enabled", true)
 	v.SetDefault("gdpr.tcf2.purpose1.enabled", true)
+	v.SetDefault("gdpr.tcf2.purpose1.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose1.enforce_vendors", true)
+	v.SetDefault("gdpr.tcf2.purpose1.vendor_exceptions", []string{})
+	v.SetDefault("gdpr.tcf2.purpose1.basic_enforcement_vendors", []string{})
 	v.SetDefault("gdpr.tcf2.purpose2.enabled", true)
+	v.SetDefault("gdpr.tcf2.purpose2.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose2.enforce_vendors", true)
+	v.SetDefault("gdpr.tcf2.purpose2.vendor_exceptions", []string{})
+	v.SetDefault("gdpr.tcf2.purpose2.basic_enforcement_vendors", []string{})
+	v.SetDefault("gdpr.tcf2.purpose3.enabled", true)
+	v.SetDefault("gdpr.tcf2.purpose3.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose3.enforce_vendors", true)
+	v.SetDefault("gdpr.tcf2.purpose3.vendor_exceptions", []string{})
+	v.SetDefault("gdpr.tcf2.purpose3.basic_enforcement_vendors", []string{})
 	v.SetDefault("gdpr.tcf2.purpose4.enabled", true)
+	v.SetDefault("gdpr.tcf2.purpose4.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose4.enforce_vendors", true)
+	v.SetDefault("gdpr.tcf2.purpose4.vendor_exceptions", []string{})
+	v.SetDefault("gdpr.tcf2.purpose4.basic_enforcement_vendors", []string{})
+	v.SetDefault("gdpr.tcf2.purpose5.enabled", true)
+	v.SetDefault("gdpr.tcf2.purpose5.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose5.enforce_vendors", true)
+	v.SetDefault("gdpr.tcf2.purpose5.vendor_exceptions", []string{})
+	v.SetDefault("gdpr.tcf2.purpose5.basic_enforcement_vendors", []string{})
+	v.SetDefault("gdpr.tcf2.purpose6.enabled", true)
+	v.SetDefault("gdpr.tcf2.purpose6.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose6.enforce_vendors", true)
+	v.SetDefault("gdpr.tcf2.purpose6.vendor_exceptions", []string{})
+	v.SetDefault("gdpr.tcf2.purpose6.basic_enforcement_vendors", []string{})
 	v.SetDefault("gdpr.tcf2.purpose7.enabled", true)
+	v.SetDefault("gdpr.tcf2.purpose7.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose7.enforce_vendors", true)
+	v.SetDefault("gdpr.tcf2.purpose7.vendor_exceptions", []string{})
+	v.SetDefault("gdpr.tcf2.purpose7.basic_enforcement_vendors", []string{})
+	v.SetDefault("gdpr.tcf2.purpose8.enabled", true)
+	v.SetDefault("gdpr.tcf2.purpose8.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose8.enforce_vendors", true)
+	v.SetDefault("gdpr.tcf2.purpose8.vendor_exceptions", []string{})
+	v.SetDefault("gdpr.tcf2.purpose8.basic_enforcement_vendors", []string{})
+	v.SetDefault("gdpr.tcf2.purpose9.enabled", true)
+	v.SetDefault("gdpr.tcf2.purpose9.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose9.enforce_vendors", true)
+	v.SetDefault("gdpr.tcf2.purpose9.vendor_exceptions", []string{})
+	v.SetDefault("gdpr.tcf2.purpose9.basic_enforcement_vendors", []string{})
+	v.SetDefault("gdpr.tcf2.purpose10.enabled", true)
+	v.SetDefault("gdpr.tcf2.purpose10.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose10.enforce_vendors", true)
+	v.SetDefault("gdpr.tcf2.purpose10.vendor_exceptions", []string{})
+	v.SetDefault("gdpr.tcf2.purpose10.basic_enforcement_vendors", []string{})
 	v.SetDefault("gdpr.tcf2.special_purpose1.enabled", true)
+	v.SetDefault("gdpr.tcf2.special_purpose1.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.special_purpose1.enforce_vendors", true)
+	v.SetDefault("gdpr.tcf2.special_purpose1.vendor_exceptions", []string{})
+	v.SetDefault("gdpr.tcf2.special_purpose1.basic_enforcement_vendors", []string{})
 	v.SetDefault("gdpr.tcf2.purpose_one_treatement.enabled", true)
 	v.SetDefault("gdpr.tcf2.purpose_one_treatement.access_allowed", true)
+	v.SetDefault("gdpr.tcf2.non_gvl_vendors_basic_enforcement", false)
 	v.SetDefault("gdpr.amp_exception", false)
 	v.SetDefault("gdpr.eea_countries", []string{"ALA", "AUT", "BEL", "BGR", "HRV", "CYP", "CZE", "DNK", "EST",
 		"FIN", "FRA", "GUF", "DEU", "GIB", "GRC", "GLP", "GGY", "HUN", "ISL", "IRL", "IMN", "ITA", "JEY", "LVA",
@@ -919,6 +1248,7 @@ func SetupViper(v *viper.Viper, filename string) {
 		"SVK", "SVN", "ESP", "SWE", "GBR"})
 	v.SetDefault("ccpa.enforce", false)
 	v.SetDefault("lmt.enforce", true)
+	v.SetDefault("us_state_privacy.enforce", false)
 	v.SetDefault("currency_converter.fetch_url", "https://cdn.jsdelivr.net/gh/prebid/currency-file@1/latest.json")
 	v.SetDefault("currency_converter.fetch_interval_seconds", 1800) // fetch currency rates every 30 minutes
 	v.SetDefault("currency_converter.stale_rates_seconds", 0)
@@ -929,6 +1259,8 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("blacklisted_accts", []string{""})
 	v.SetDefault("account_required", false)
 	v.SetDefault("account_defaults.disabled", false)
+	v.SetDefault("account_defaults.price_floors.enabled", false)
+	v.SetDefault("account_defaults.price_floors.enforce_deal_floors", false)
 	v.SetDefault("certificates_file", "")
 	v.SetDefault("auto_gen_source_tid", true)
 
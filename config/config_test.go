@@ -3,6 +3,7 @@ package config
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"net"
 	"os"
 	"strings"
@@ -124,6 +125,12 @@ func TestDefaults(t *testing.T) {
 	cmpInts(t, "port", cfg.Port, 8000)
 	cmpInts(t, "admin_port", cfg.AdminPort, 6060)
 	cmpInts(t, "auction_timeouts_ms.max", int(cfg.AuctionTimeouts.Max), 0)
+	cmpBools(t, "tmax_adjustments.enabled", cfg.TmaxAdjustments.Enabled, false)
+	assert.Equal(t, 0.9, cfg.TmaxAdjustments.NetworkLatencyPercentile, "tmax_adjustments.network_latency_percentile")
+	cmpBools(t, "auction_soft_deadline.enabled", cfg.SoftDeadline.Enabled, false)
+	cmpInts(t, "auction_soft_deadline.percent", int(cfg.SoftDeadline.Percent), 80)
+	cmpStrings(t, "validations.banner_creative_size", cfg.Validations.BannerCreativeSize, ValidationOff)
+	cmpStrings(t, "validations.secure_markup", cfg.Validations.SecureMarkup, ValidationOff)
 	cmpInts(t, "max_request_size", int(cfg.MaxRequestSize), 1024*256)
 	cmpInts(t, "host_cookie.ttl_days", int(cfg.HostCookie.TTL), 90)
 	cmpInts(t, "host_cookie.max_cookie_size_bytes", cfg.HostCookie.MaxCookieSizeBytes, 0)
@@ -139,6 +146,16 @@ func TestDefaults(t *testing.T) {
 	cmpBools(t, "stored_requests.filesystem.enabled", false, cfg.StoredRequests.Files.Enabled)
 	cmpStrings(t, "stored_requests.filesystem.directorypath", "./stored_requests/data/by_id", cfg.StoredRequests.Files.Path)
 	cmpBools(t, "auto_gen_source_tid", cfg.AutoGenSourceTID, true)
+	cmpBools(t, "us_state_privacy.enforce", cfg.USStatePrivacy.Enforce, false)
+	for purpose := 1; purpose <= TCF2PurposeCount; purpose++ {
+		purposeConfig := cfg.GDPR.TCF2.PurposeConfig(purpose)
+		cmpBools(t, fmt.Sprintf("gdpr.tcf2.purpose%d.enabled", purpose), purposeConfig.Enabled, true)
+		cmpStrings(t, fmt.Sprintf("gdpr.tcf2.purpose%d.enforce_purpose", purpose), purposeConfig.EnforcePurpose, TCF2FullEnforcement)
+		cmpBools(t, fmt.Sprintf("gdpr.tcf2.purpose%d.enforce_vendors", purpose), purposeConfig.EnforceVendors, true)
+		assert.Empty(t, purposeConfig.VendorExceptions, "gdpr.tcf2.purpose%d.vendor_exceptions", purpose)
+		assert.Empty(t, purposeConfig.BasicEnforcementVendors, "gdpr.tcf2.purpose%d.basic_enforcement_vendors", purpose)
+	}
+	cmpBools(t, "gdpr.tcf2.non_gvl_vendors_basic_enforcement", cfg.GDPR.TCF2.NonGVLVendorsBasicEnforcement, false)
 }
 
 var fullConfig = []byte(`
@@ -146,10 +163,22 @@ gdpr:
   host_vendor_id: 15
   usersync_if_ambiguous: true
   non_standard_publishers: ["siteID","fake-site-id","appID","agltb3B1Yi1pbmNyDAsSA0FwcBiJkfIUDA"]
+  tcf2:
+    purpose2:
+      enforce_purpose: basic
+      enforce_vendors: false
+    purpose4:
+      vendor_exceptions: ["appnexus", "rubicon"]
+      basic_enforcement_vendors: ["openx"]
+    purpose7:
+      enabled: false
+    non_gvl_vendors_basic_enforcement: true
 ccpa:
   enforce: true
 lmt:
   enforce: true
+us_state_privacy:
+  enforce: true
 host_cookie:
   cookie_name: userid
   family: prebid
@@ -223,6 +252,11 @@ adapters:
     endpoint: http://test-bid.ybp.yahoo.com/bid/appnexuspbs
   adkerneladn:
      usersync_url: https://tag.adkernel.com/syncr?gdpr={{.GDPR}}&gdpr_consent={{.GDPRConsent}}&r=
+  mybrand:
+    alias_of: appnexus
+    endpoint: http://mybrand.com/openrtb2
+    usersync_url: http://mybrand.com/sync?gdpr={{.GDPR}}
+    gvl_vendor_id: 123
 blacklisted_apps: ["spamAppID","sketchy-app-id"]
 account_required: true
 auto_gen_source_tid: false
@@ -347,6 +381,13 @@ func TestFullConfig(t *testing.T) {
 	cmpInts(t, "http_client_cache.idle_connection_timeout_seconds", cfg.CacheClient.IdleConnTimeout, 3)
 	cmpInts(t, "gdpr.host_vendor_id", cfg.GDPR.HostVendorID, 15)
 	cmpBools(t, "gdpr.usersync_if_ambiguous", cfg.GDPR.UsersyncIfAmbiguous, true)
+	cmpStrings(t, "gdpr.tcf2.purpose2.enforce_purpose", cfg.GDPR.TCF2.Purpose2.EnforcePurpose, TCF2BasicEnforcement)
+	cmpBools(t, "gdpr.tcf2.purpose2.enforce_vendors", cfg.GDPR.TCF2.Purpose2.EnforceVendors, false)
+	assert.Equal(t, []openrtb_ext.BidderName{"appnexus", "rubicon"}, cfg.GDPR.TCF2.Purpose4.VendorExceptions, "gdpr.tcf2.purpose4.vendor_exceptions")
+	cmpBools(t, "gdpr.tcf2.purpose7.enabled", cfg.GDPR.TCF2.Purpose7.Enabled, false)
+	cmpStrings(t, "gdpr.tcf2.purpose7.enforcement", cfg.GDPR.TCF2.Purpose7.Enforcement(), TCF2NoEnforcement)
+	assert.Equal(t, []openrtb_ext.BidderName{"openx"}, cfg.GDPR.TCF2.Purpose4.BasicEnforcementVendors, "gdpr.tcf2.purpose4.basic_enforcement_vendors")
+	cmpBools(t, "gdpr.tcf2.non_gvl_vendors_basic_enforcement", cfg.GDPR.TCF2.NonGVLVendorsBasicEnforcement, true)
 
 	//Assert the NonStandardPublishers was correctly unmarshalled
 	cmpStrings(t, "gdpr.non_standard_publishers", cfg.GDPR.NonStandardPublishers[0], "siteID")
@@ -365,6 +406,7 @@ func TestFullConfig(t *testing.T) {
 
 	cmpBools(t, "ccpa.enforce", cfg.CCPA.Enforce, true)
 	cmpBools(t, "lmt.enforce", cfg.LMT.Enforce, true)
+	cmpBools(t, "us_state_privacy.enforce", cfg.USStatePrivacy.Enforce, true)
 
 	//Assert the NonStandardPublishers was correctly unmarshalled
 	cmpStrings(t, "blacklisted_apps", cfg.BlacklistedApps[0], "spamAppID")
@@ -405,6 +447,10 @@ func TestFullConfig(t *testing.T) {
 	cmpStrings(t, "adapters.brightroll.endpoint", cfg.Adapters[string(openrtb_ext.BidderBrightroll)].Endpoint, "http://test-bid.ybp.yahoo.com/bid/appnexuspbs")
 	cmpStrings(t, "adapters.brightroll.usersync_url", cfg.Adapters[string(openrtb_ext.BidderBrightroll)].UserSyncURL, "http://test-bh.ybp.yahoo.com/sync/appnexuspbs?gdpr={{.GDPR}}&euconsent={{.GDPRConsent}}&us_privacy={{.USPrivacy}}&url=%s")
 	cmpStrings(t, "adapters.adkerneladn.usersync_url", cfg.Adapters[strings.ToLower(string(openrtb_ext.BidderAdkernelAdn))].UserSyncURL, "https://tag.adkernel.com/syncr?gdpr={{.GDPR}}&gdpr_consent={{.GDPRConsent}}&r=")
+	cmpStrings(t, "adapters.mybrand.alias_of", cfg.Adapters["mybrand"].AliasOf, "appnexus")
+	cmpStrings(t, "adapters.mybrand.endpoint", cfg.Adapters["mybrand"].Endpoint, "http://mybrand.com/openrtb2")
+	cmpStrings(t, "adapters.mybrand.usersync_url", cfg.Adapters["mybrand"].UserSyncURL, "http://mybrand.com/sync?gdpr={{.GDPR}}")
+	cmpInts(t, "adapters.mybrand.gvl_vendor_id", int(cfg.Adapters["mybrand"].GVLVendorID), 123)
 	cmpStrings(t, "adapters.rhythmone.endpoint", cfg.Adapters[string(openrtb_ext.BidderRhythmone)].Endpoint, "http://tag.1rx.io/rmp")
 	cmpStrings(t, "adapters.rhythmone.usersync_url", cfg.Adapters[string(openrtb_ext.BidderRhythmone)].UserSyncURL, "https://sync.1rx.io/usersync2/rmphb?gdpr={{.GDPR}}&gdpr_consent={{.GDPRConsent}}&us_privacy={{.USPrivacy}}&redir=http%3A%2F%2Fprebid-server.prebid.org%2F%2Fsetuid%3Fbidder%3Drhythmone%26gdpr%3D{{.GDPR}}%26gdpr_consent%3D{{.GDPRConsent}}%26uid%3D%5BRX_UUID%5D")
 	cmpBools(t, "account_required", cfg.AccountRequired, true)
@@ -527,12 +573,390 @@ func TestNegativeRequestSize(t *testing.T) {
 	assertOneError(t, cfg.validate(), "cfg.max_request_size must be >= 0. Got -1")
 }
 
+func TestInvalidNetworkLatencyPercentile(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.TmaxAdjustments.NetworkLatencyPercentile = 1
+	assertOneError(t, cfg.validate(), "tmax_adjustments.network_latency_percentile must be at least 0 and less than 1. Got 1.000000")
+}
+
+func TestValidateSoftDeadline(t *testing.T) {
+	testCases := []struct {
+		description  string
+		softDeadline SoftDeadline
+		expectedErrs []error
+	}{
+		{
+			description:  "Disabled",
+			softDeadline: SoftDeadline{Enabled: false, Percent: 0},
+		},
+		{
+			description:  "Valid",
+			softDeadline: SoftDeadline{Enabled: true, Percent: 80},
+		},
+		{
+			description:  "Zero Percent",
+			softDeadline: SoftDeadline{Enabled: true, Percent: 0},
+			expectedErrs: []error{errors.New("auction_soft_deadline.percent must be between 1 and 99. Got 0")},
+		},
+		{
+			description:  "Whole Timeout",
+			softDeadline: SoftDeadline{Enabled: true, Percent: 100},
+			expectedErrs: []error{errors.New("auction_soft_deadline.percent must be between 1 and 99. Got 100")},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedErrs, test.softDeadline.validate(nil), test.description)
+	}
+}
+
+func TestValidateTrafficShaping(t *testing.T) {
+	testCases := []struct {
+		description    string
+		trafficShaping TrafficShaping
+		expectedErrs   []string
+	}{
+		{
+			description:    "Disabled",
+			trafficShaping: TrafficShaping{Enabled: false, SkipRate: 2},
+		},
+		{
+			description:    "Valid",
+			trafficShaping: TrafficShaping{Enabled: true, MinSamples: 10, WindowSize: 100, MinBidRate: 0.1, MaxTimeoutRate: 0.5, SkipRate: 0.9, MaxEntries: 1000},
+		},
+		{
+			description:    "Invalid Samples",
+			trafficShaping: TrafficShaping{Enabled: true, MinSamples: 0, WindowSize: -1, MaxEntries: 0},
+			expectedErrs: []string{
+				"traffic_shaping.min_samples must be positive. Got 0",
+				"traffic_shaping.window_size must be at least twice traffic_shaping.min_samples. window_size=-1, min_samples=0",
+				"traffic_shaping.max_entries must be positive. Got 0",
+			},
+		},
+		{
+			description:    "Invalid Rates",
+			trafficShaping: TrafficShaping{Enabled: true, MinSamples: 10, WindowSize: 100, MinBidRate: -0.1, MaxTimeoutRate: 1.5, SkipRate: 1, MaxEntries: 1000},
+			expectedErrs: []string{
+				"traffic_shaping.min_bid_rate must be in the range [0, 1]. Got -0.100000",
+				"traffic_shaping.max_timeout_rate must be in the range [0, 1]. Got 1.500000",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.trafficShaping.validate(nil)
+
+		errMessages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			errMessages = append(errMessages, err.Error())
+		}
+		assert.ElementsMatch(t, test.expectedErrs, errMessages, test.description)
+	}
+}
+
+func TestValidateValidations(t *testing.T) {
+	testCases := []struct {
+		description  string
+		validations  Validations
+		expectedErrs []string
+	}{
+		{
+			description: "Unset",
+			validations: Validations{},
+		},
+		{
+			description: "Valid",
+			validations: Validations{BannerCreativeSize: ValidationWarn, SecureMarkup: ValidationEnforce},
+		},
+		{
+			description: "Invalid",
+			validations: Validations{BannerCreativeSize: "skip", SecureMarkup: "reject"},
+			expectedErrs: []string{
+				"validations.banner_creative_size must be one of off, warn or enforce. Got skip",
+				"validations.secure_markup must be one of off, warn or enforce. Got reject",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.validations.validate(nil)
+
+		errMessages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			errMessages = append(errMessages, err.Error())
+		}
+		assert.ElementsMatch(t, test.expectedErrs, errMessages, test.description)
+	}
+}
+
+func TestValidationsWithAccountOverrides(t *testing.T) {
+	host := Validations{BannerCreativeSize: ValidationWarn, SecureMarkup: ValidationEnforce}
+
+	testCases := []struct {
+		description string
+		account     Validations
+		expected    Validations
+	}{
+		{
+			description: "No Overrides",
+			account:     Validations{},
+			expected:    host,
+		},
+		{
+			description: "Both Overridden",
+			account:     Validations{BannerCreativeSize: ValidationEnforce, SecureMarkup: ValidationOff},
+			expected:    Validations{BannerCreativeSize: ValidationEnforce, SecureMarkup: ValidationOff},
+		},
+		{
+			description: "Unknown Mode Ignored",
+			account:     Validations{BannerCreativeSize: "skip", SecureMarkup: ValidationWarn},
+			expected:    Validations{BannerCreativeSize: ValidationWarn, SecureMarkup: ValidationWarn},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, host.WithAccountOverrides(test.account), test.description)
+	}
+}
+
+func TestValidateCircuitBreaker(t *testing.T) {
+	testCases := []struct {
+		description    string
+		circuitBreaker CircuitBreaker
+		expectedErrs   []string
+	}{
+		{
+			description:    "Disabled",
+			circuitBreaker: CircuitBreaker{Enabled: false, ErrorRateThreshold: 2},
+		},
+		{
+			description:    "Valid",
+			circuitBreaker: CircuitBreaker{Enabled: true, ErrorRateThreshold: 0.5, TimeoutRateThreshold: 1, MinRequests: 10, WindowMillis: 10000, OpenMillis: 5000},
+		},
+		{
+			description:    "Invalid Rates",
+			circuitBreaker: CircuitBreaker{Enabled: true, ErrorRateThreshold: 0, TimeoutRateThreshold: 1.5, MinRequests: 10, WindowMillis: 10000, OpenMillis: 5000},
+			expectedErrs: []string{
+				"adapters.appnexus.circuit_breaker.error_rate_threshold must be in the range (0, 1]. Got 0.000000",
+				"adapters.appnexus.circuit_breaker.timeout_rate_threshold must be in the range (0, 1]. Got 1.500000",
+			},
+		},
+		{
+			description:    "Invalid Durations",
+			circuitBreaker: CircuitBreaker{Enabled: true, ErrorRateThreshold: 0.5, TimeoutRateThreshold: 0.5, MinRequests: 0, WindowMillis: 0, OpenMillis: -1},
+			expectedErrs: []string{
+				"adapters.appnexus.circuit_breaker.min_requests must be positive. Got 0",
+				"adapters.appnexus.circuit_breaker.window_ms must be positive. Got 0",
+				"adapters.appnexus.circuit_breaker.open_ms must be positive. Got -1",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.circuitBreaker.validate("appnexus", nil)
+
+		errMessages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			errMessages = append(errMessages, err.Error())
+		}
+		assert.ElementsMatch(t, test.expectedErrs, errMessages, test.description)
+	}
+}
+
+func TestValidateBidCache(t *testing.T) {
+	testCases := []struct {
+		description  string
+		bidCache     BidCache
+		expectedErrs []string
+	}{
+		{
+			description: "Disabled",
+			bidCache:    BidCache{Enabled: false, TTLSeconds: -1},
+		},
+		{
+			description: "Valid",
+			bidCache:    BidCache{Enabled: true, TTLSeconds: 30, MaxEntries: 10000},
+		},
+		{
+			description: "Invalid",
+			bidCache:    BidCache{Enabled: true, TTLSeconds: 0, MaxEntries: -1},
+			expectedErrs: []string{
+				"adapters.appnexus.bid_cache.ttl_seconds must be positive. Got 0",
+				"adapters.appnexus.bid_cache.max_entries must be positive. Got -1",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.bidCache.validate("appnexus", nil)
+
+		errMessages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			errMessages = append(errMessages, err.Error())
+		}
+		assert.ElementsMatch(t, test.expectedErrs, errMessages, test.description)
+	}
+}
+
+func TestValidateEndpointCompression(t *testing.T) {
+	testCases := []struct {
+		description  string
+		compression  string
+		expectedErrs []string
+	}{
+		{
+			description: "Not Set",
+			compression: "",
+		},
+		{
+			description: "GZIP",
+			compression: "gzip",
+		},
+		{
+			description: "None",
+			compression: "none",
+		},
+		{
+			description:  "Unknown",
+			compression:  "deflate",
+			expectedErrs: []string{"adapters.appnexus.endpoint_compression must be gzip or none. Got deflate"},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := validateAdapters(map[string]Adapter{
+			"appnexus": {Endpoint: "http://ib.adnxs.com/openrtb2", EndpointCompression: test.compression},
+		}, nil)
+
+		errMessages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			errMessages = append(errMessages, err.Error())
+		}
+		assert.ElementsMatch(t, test.expectedErrs, errMessages, test.description)
+	}
+}
+
+func TestValidateAdapterAlias(t *testing.T) {
+	testCases := []struct {
+		description  string
+		adapterName  string
+		aliasOf      string
+		expectedErrs []string
+	}{
+		{
+			description: "Alias Of A Core Bidder",
+			adapterName: "mybrand",
+			aliasOf:     "appnexus",
+		},
+		{
+			description: "Alias Of A Core Bidder With Different Case",
+			adapterName: "mybrand",
+			aliasOf:     "AudienceNetwork",
+		},
+		{
+			description:  "Alias Of An Unknown Bidder",
+			adapterName:  "mybrand",
+			aliasOf:      "unknown",
+			expectedErrs: []string{"adapters.mybrand.alias_of must refer to a core bidder. Got unknown"},
+		},
+		{
+			description:  "Core Bidder Set As An Alias",
+			adapterName:  "rubicon",
+			aliasOf:      "appnexus",
+			expectedErrs: []string{"adapters.rubicon.alias_of can't be set on a core bidder"},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := validateAdapterAlias(test.adapterName, test.aliasOf, nil)
+
+		errMessages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			errMessages = append(errMessages, err.Error())
+		}
+		assert.ElementsMatch(t, test.expectedErrs, errMessages, test.description)
+	}
+}
+
 func TestNegativeVendorID(t *testing.T) {
 	cfg := newDefaultConfig(t)
 	cfg.GDPR.HostVendorID = -1
 	assertOneError(t, cfg.validate(), "gdpr.host_vendor_id must be in the range [0, 65535]. Got -1")
 }
 
+func TestValidateTCF2Enforcement(t *testing.T) {
+	testCases := []struct {
+		description  string
+		tcf2         TCF2
+		expectedErrs []string
+	}{
+		{
+			description: "Not Set",
+			tcf2:        TCF2{},
+		},
+		{
+			description: "Valid",
+			tcf2: TCF2{
+				Purpose1:        PurposeDetail{EnforcePurpose: TCF2FullEnforcement},
+				Purpose2:        PurposeDetail{EnforcePurpose: TCF2BasicEnforcement},
+				Purpose10:       PurposeDetail{EnforcePurpose: TCF2NoEnforcement},
+				SpecialPurpose1: PurposeDetail{EnforcePurpose: TCF2BasicEnforcement},
+			},
+		},
+		{
+			description: "Invalid",
+			tcf2: TCF2{
+				Purpose3:        PurposeDetail{EnforcePurpose: "strict"},
+				Purpose10:       PurposeDetail{EnforcePurpose: "Full"},
+				SpecialPurpose1: PurposeDetail{EnforcePurpose: "none"},
+			},
+			expectedErrs: []string{
+				"gdpr.tcf2.purpose3.enforce_purpose must be full, basic or no. Got strict",
+				"gdpr.tcf2.purpose10.enforce_purpose must be full, basic or no. Got Full",
+				"gdpr.tcf2.special_purpose1.enforce_purpose must be full, basic or no. Got none",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.tcf2.validate(nil)
+
+		errMessages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			errMessages = append(errMessages, err.Error())
+		}
+		assert.ElementsMatch(t, test.expectedErrs, errMessages, test.description)
+	}
+}
+
+func TestPurposeDetailEnforcement(t *testing.T) {
+	testCases := []struct {
+		description         string
+		purposeConfig       PurposeDetail
+		expectedEnforcement string
+	}{
+		{
+			description:         "Disabled",
+			purposeConfig:       PurposeDetail{Enabled: false, EnforcePurpose: TCF2FullEnforcement},
+			expectedEnforcement: TCF2NoEnforcement,
+		},
+		{
+			description:         "Enabled Without Enforcement Type",
+			purposeConfig:       PurposeDetail{Enabled: true},
+			expectedEnforcement: TCF2FullEnforcement,
+		},
+		{
+			description:         "Enabled With Enforcement Type",
+			purposeConfig:       PurposeDetail{Enabled: true, EnforcePurpose: TCF2BasicEnforcement},
+			expectedEnforcement: TCF2BasicEnforcement,
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedEnforcement, test.purposeConfig.Enforcement(), test.description)
+	}
+}
+
 func TestNegativePrometheusTimeout(t *testing.T) {
 	cfg := newDefaultConfig(t)
 	cfg.Metrics.Prometheus.Port = 8001
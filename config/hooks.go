@@ -0,0 +1,69 @@
+package config
+
+import "fmt"
+
+// Hooks configures the modules which plug logic into the stages of an auction.
+//
+// The host execution plan runs for every account. The execution plan of the account runs after it, and
+// account_defaults.hooks.execution_plan can be used to define one for the accounts which don't.
+type Hooks struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Modules holds the host configuration of each module, keyed by vendor and then by module name.
+	// The module code used in the execution plans is "{vendor}.{module}".
+	Modules map[string]map[string]interface{} `mapstructure:"modules"`
+	// HostExecutionPlan is the plan run for every account.
+	HostExecutionPlan HookExecutionPlan `mapstructure:"host_execution_plan"`
+}
+
+// AccountHooks configures the modules run for an account
+type AccountHooks struct {
+	// Modules holds the account configuration of each module, keyed by vendor and then by module name.
+	// It is passed to the hooks of the module along with the host configuration the module was built with.
+	Modules map[string]map[string]interface{} `mapstructure:"modules" json:"modules,omitempty"`
+	// ExecutionPlan is the plan run for the account after the host one.
+	ExecutionPlan HookExecutionPlan `mapstructure:"execution_plan" json:"execution_plan"`
+}
+
+// HookExecutionPlan lists the hooks to run for each endpoint, such as "/openrtb2/auction", and stage, such as "bidder_request".
+type HookExecutionPlan struct {
+	Endpoints map[string]EndpointExecutionPlan `mapstructure:"endpoints" json:"endpoints,omitempty"`
+}
+
+// EndpointExecutionPlan lists the hooks to run for each stage of an endpoint
+type EndpointExecutionPlan struct {
+	Stages map[string]StageExecutionPlan `mapstructure:"stages" json:"stages,omitempty"`
+}
+
+// StageExecutionPlan lists the groups of hooks to run at a stage. The groups run one after another.
+type StageExecutionPlan struct {
+	Groups []HookExecutionGroup `mapstructure:"groups" json:"groups,omitempty"`
+}
+
+// HookExecutionGroup is a set of hooks which run in parallel. The hooks which haven't returned once the timeout
+// has elapsed are ignored. Their results are then applied in the order of the sequence.
+type HookExecutionGroup struct {
+	TimeoutMillis int      `mapstructure:"timeout" json:"timeout"`
+	HookSequence  []HookID `mapstructure:"hook_sequence" json:"hook_sequence"`
+}
+
+// HookID identifies the hook of a module. The implementation code is reported in the hook outcomes.
+type HookID struct {
+	ModuleCode   string `mapstructure:"module_code" json:"module_code"`
+	HookImplCode string `mapstructure:"hook_impl_code" json:"hook_impl_code"`
+}
+
+func (cfg *Hooks) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	for endpoint, endpointPlan := range cfg.HostExecutionPlan.Endpoints {
+		for stage, stagePlan := range endpointPlan.Stages {
+			for i, group := range stagePlan.Groups {
+				if group.TimeoutMillis <= 0 {
+					errs = append(errs, fmt.Errorf("hooks.host_execution_plan.endpoints.%s.stages.%s.groups[%d].timeout must be positive. Got %d", endpoint, stage, i, group.TimeoutMillis))
+				}
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateHooks(t *testing.T) {
+	plan := HookExecutionPlan{
+		Endpoints: map[string]EndpointExecutionPlan{
+			"/openrtb2/auction": {
+				Stages: map[string]StageExecutionPlan{
+					"entrypoint": {
+						Groups: []HookExecutionGroup{
+							{TimeoutMillis: 5, HookSequence: []HookID{{ModuleCode: "vendor.module", HookImplCode: "code"}}},
+							{TimeoutMillis: 0, HookSequence: []HookID{{ModuleCode: "vendor.module", HookImplCode: "code"}}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		description  string
+		hooks        Hooks
+		expectedErrs []string
+	}{
+		{
+			description: "Disabled",
+			hooks:       Hooks{Enabled: false, HostExecutionPlan: plan},
+		},
+		{
+			description: "Enabled without a host plan",
+			hooks:       Hooks{Enabled: true},
+		},
+		{
+			description:  "Enabled with a group without timeout",
+			hooks:        Hooks{Enabled: true, HostExecutionPlan: plan},
+			expectedErrs: []string{"hooks.host_execution_plan.endpoints./openrtb2/auction.stages.entrypoint.groups[1].timeout must be positive. Got 0"},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.hooks.validate(nil)
+
+		errMessages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			errMessages = append(errMessages, err.Error())
+		}
+		assert.ElementsMatch(t, test.expectedErrs, errMessages, test.description)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/buger/jsonparser"
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
+	accountService "github.com/prebid/prebid-server/account"
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/gdpr"
@@ -21,6 +22,8 @@ import (
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/privacy/ccpa"
 	gdprPrivacy "github.com/prebid/prebid-server/privacy/gdpr"
+	gppPrivacy "github.com/prebid/prebid-server/privacy/gpp"
+	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/usersync"
 )
 
@@ -30,7 +33,8 @@ func NewCookieSyncEndpoint(
 	syncPermissions gdpr.Permissions,
 	metrics metrics.MetricsEngine,
 	pbsAnalytics analytics.PBSAnalyticsModule,
-	bidderMap map[string]openrtb_ext.BidderName) httprouter.Handle {
+	bidderMap map[string]openrtb_ext.BidderName,
+	accountsFetcher stored_requests.AccountFetcher) httprouter.Handle {
 
 	bidderLookup := make(map[string]struct{})
 	for k := range bidderMap {
@@ -46,6 +50,8 @@ func NewCookieSyncEndpoint(
 		pbsAnalytics:    pbsAnalytics,
 		enforceCCPA:     cfg.CCPA.Enforce,
 		bidderLookup:    bidderLookup,
+		config:          cfg,
+		accountsFetcher: accountsFetcher,
 	}
 	return deps.Endpoint
 }
@@ -59,6 +65,8 @@ type cookieSyncDeps struct {
 	pbsAnalytics    analytics.PBSAnalyticsModule
 	enforceCCPA     bool
 	bidderLookup    map[string]struct{}
+	config          *config.Configuration
+	accountsFetcher stored_requests.AccountFetcher
 }
 
 func (deps *cookieSyncDeps) Endpoint(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -104,6 +112,14 @@ func (deps *cookieSyncDeps) Endpoint(w http.ResponseWriter, r *http.Request, _ h
 		return
 	}
 
+	activityControl, errs := getActivityControl(deps.config, deps.accountsFetcher, parsedReq.Account)
+	if len(errs) > 0 {
+		co.Status = http.StatusBadRequest
+		co.Errors = append(co.Errors, errs...)
+		http.Error(w, co.Errors[len(co.Errors)-1].Error(), co.Status)
+		return
+	}
+
 	if len(biddersJSON) == 0 {
 		parsedReq.Bidders = make([]string, 0, len(deps.syncers))
 		for bidder := range deps.syncers {
@@ -135,6 +151,7 @@ func (deps *cookieSyncDeps) Endpoint(w http.ResponseWriter, r *http.Request, _ h
 		CCPA: ccpa.Policy{
 			Consent: parsedReq.USPrivacy,
 		},
+		GPP: parsedReq.gppPolicy(),
 	}
 
 	parsedReq.filterForGDPR(deps.syncPermissions)
@@ -143,6 +160,8 @@ func (deps *cookieSyncDeps) Endpoint(w http.ResponseWriter, r *http.Request, _ h
 		parsedReq.filterForCCPA(deps.bidderLookup)
 	}
 
+	parsedReq.filterForActivityControl(activityControl)
+
 	// surviving bidders are not privacy blocked
 	for _, b := range parsedReq.Bidders {
 		adapterSyncs[openrtb_ext.BidderName(b)] = false
@@ -186,6 +205,19 @@ func parseRequest(parsedReq *cookieSyncRequest, bodyBytes []byte, usersyncIfAmbi
 		return fmt.Errorf("JSON parsing failed: %s", err.Error())
 	}
 
+	sectionIDs, err := gppPrivacy.ParseSectionIDs(parsedReq.GPPSID)
+	if err != nil {
+		return err
+	}
+	parsedReq.gppSectionIDs = sectionIDs
+
+	// The TCF consent may be sent within the GPP string instead of the gdpr_consent property.
+	if parsedReq.Consent == "" {
+		if gppPolicy, err := parsedReq.gppPolicy().Parse(nil); err == nil {
+			parsedReq.Consent = gppPolicy.TCFEUv2Consent()
+		}
+	}
+
 	if parsedReq.GDPR != nil && *parsedReq.GDPR == 1 && parsedReq.Consent == "" {
 		return errors.New("gdpr_consent is required if gdpr=1")
 	}
@@ -201,6 +233,20 @@ func parseRequest(parsedReq *cookieSyncRequest, bodyBytes []byte, usersyncIfAmbi
 	return nil
 }
 
+// getActivityControl returns the activity control of the account of the sync request. The account defaults apply to
+// the requests which don't name an account.
+func getActivityControl(cfg *config.Configuration, fetcher stored_requests.AccountFetcher, accountID string) (privacy.ActivityControl, []error) {
+	if accountID == "" {
+		return privacy.NewActivityControl(&cfg.AccountDefaults.Privacy), nil
+	}
+
+	account, errs := accountService.GetAccount(context.Background(), cfg, fetcher, accountID)
+	if len(errs) > 0 {
+		return privacy.ActivityControl{}, errs
+	}
+	return privacy.NewActivityControl(&account.Privacy), nil
+}
+
 func gdprToString(gdpr *int) string {
 	if gdpr == nil {
 		return ""
@@ -230,7 +276,16 @@ type cookieSyncRequest struct {
 	GDPR      *int     `json:"gdpr"`
 	Consent   string   `json:"gdpr_consent"`
 	USPrivacy string   `json:"us_privacy"`
+	GPP       string   `json:"gpp"`
+	GPPSID    string   `json:"gpp_sid"`
 	Limit     int      `json:"limit"`
+	Account   string   `json:"account"`
+
+	gppSectionIDs []int8
+}
+
+func (req *cookieSyncRequest) gppPolicy() gppPrivacy.Policy {
+	return gppPrivacy.Policy{Consent: req.GPP, SectionIDs: req.gppSectionIDs}
 }
 
 func (req *cookieSyncRequest) filterExistingSyncs(valid map[openrtb_ext.BidderName]usersync.Usersyncer, cookie *usersync.PBSCookie, needSyncupForSameSite bool) {
@@ -263,14 +318,28 @@ func (req *cookieSyncRequest) filterForGDPR(permissions gdpr.Permissions) {
 
 func (req *cookieSyncRequest) filterForCCPA(bidderMap map[string]struct{}) {
 	ccpaPolicy := &ccpa.Policy{Consent: req.USPrivacy}
-	ccpaParsedPolicy, err := ccpaPolicy.Parse(bidderMap)
+	ccpaParsedPolicy, ccpaErr := ccpaPolicy.Parse(bidderMap)
+	// The US sections of the GPP string opt the user out of the syncs just like the US Privacy string.
+	gppParsedPolicy, gppErr := req.gppPolicy().Parse(bidderMap)
 
-	if err == nil {
-		for i := 0; i < len(req.Bidders); i++ {
-			if ccpaParsedPolicy.ShouldEnforce(req.Bidders[i]) {
-				req.Bidders = append(req.Bidders[:i], req.Bidders[i+1:]...)
-				i--
-			}
+	for i := 0; i < len(req.Bidders); i++ {
+		if (ccpaErr == nil && ccpaParsedPolicy.ShouldEnforce(req.Bidders[i])) ||
+			(gppErr == nil && gppParsedPolicy.ShouldEnforce(req.Bidders[i])) {
+			req.Bidders = append(req.Bidders[:i], req.Bidders[i+1:]...)
+			i--
+		}
+	}
+}
+
+// filterForActivityControl removes the bidders which the account doesn't allow to sync the user.
+func (req *cookieSyncRequest) filterForActivityControl(activityControl privacy.ActivityControl) {
+	activityRequest := privacy.ActivityRequest{GPPSectionIDs: req.gppSectionIDs}
+
+	for i := 0; i < len(req.Bidders); i++ {
+		component := privacy.Component{Type: config.ComponentTypeBidder, Name: req.Bidders[i]}
+		if !activityControl.Allow(privacy.ActivitySyncUser, component, activityRequest) {
+			req.Bidders = append(req.Bidders[:i], req.Bidders[i+1:]...)
+			i--
 		}
 	}
 }
@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -20,6 +21,7 @@ import (
 	"github.com/prebid/prebid-server/gdpr"
 	metricsConf "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/usersync"
 	"github.com/stretchr/testify/assert"
 )
@@ -120,6 +122,157 @@ func TestCCPA(t *testing.T) {
 	}
 }
 
+func TestGPP(t *testing.T) {
+	testCases := []struct {
+		description     string
+		requestBody     string
+		enforceCCPA     bool
+		expectedCode    int
+		expectedSyncs   []string
+		expectedSyncURL string
+	}{
+		{
+			description:   "US National Opted Out",
+			requestBody:   `{"bidders":["appnexus"], "gpp":"DBABL~BAAaAA", "gpp_sid":"7"}`,
+			enforceCCPA:   true,
+			expectedCode:  http.StatusOK,
+			expectedSyncs: []string{},
+		},
+		{
+			description:   "US National Opted Out - Feature Flag Off",
+			requestBody:   `{"bidders":["appnexus"], "gpp":"DBABL~BAAaAA", "gpp_sid":"7"}`,
+			enforceCCPA:   false,
+			expectedCode:  http.StatusOK,
+			expectedSyncs: []string{"appnexus"},
+		},
+		{
+			description:   "US National Not Opted Out",
+			requestBody:   `{"bidders":["appnexus"], "gpp":"DBABL~BAAqAA", "gpp_sid":"7"}`,
+			enforceCCPA:   true,
+			expectedCode:  http.StatusOK,
+			expectedSyncs: []string{"appnexus"},
+		},
+		{
+			description:   "US National Opted Out - Section Not Applicable",
+			requestBody:   `{"bidders":["appnexus"], "gpp":"DBABL~BAAaAA", "gpp_sid":"2"}`,
+			enforceCCPA:   true,
+			expectedCode:  http.StatusOK,
+			expectedSyncs: []string{"appnexus"},
+		},
+		{
+			description:   "Invalid GPP String Ignored",
+			requestBody:   `{"bidders":["appnexus"], "gpp":"malformed", "gpp_sid":"7"}`,
+			enforceCCPA:   true,
+			expectedCode:  http.StatusOK,
+			expectedSyncs: []string{"appnexus"},
+		},
+		{
+			description:     "TCF EU v2 Section Used As Consent",
+			requestBody:     `{"bidders":["audienceNetwork"], "gdpr":1, "gpp":"DBABM~BOONm0NOONm0NABABAENAa-AAAARh7______b9_3__7_9uz_Kv_K7Vf7nnG072lPVA9LTOQ6gEaY", "gpp_sid":"2"}`,
+			enforceCCPA:     true,
+			expectedCode:    http.StatusOK,
+			expectedSyncs:   []string{"audienceNetwork"},
+			expectedSyncURL: "https://www.facebook.com/audiencenetwork/idsync/?partner=partnerId&callback=localhost%2Fsetuid%3Fbidder%3DaudienceNetwork%26gdpr%3D1%26gdpr_consent%3DBOONm0NOONm0NABABAENAa-AAAARh7______b9_3__7_9uz_Kv_K7Vf7nnG072lPVA9LTOQ6gEaY%26uid%3D%24UID",
+		},
+		{
+			description:  "Invalid Section IDs",
+			requestBody:  `{"bidders":["appnexus"], "gpp":"DBABL~BAAaAA", "gpp_sid":"a"}`,
+			enforceCCPA:  true,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range testCases {
+		gdpr := config.GDPR{UsersyncIfAmbiguous: true}
+		ccpa := config.CCPA{Enforce: test.enforceCCPA}
+		rr := doConfigurablePost(test.requestBody, nil, true, syncersForTest(), gdpr, ccpa)
+		assert.Equal(t, test.expectedCode, rr.Code, test.description+":httpResponseCode")
+		if test.expectedCode != http.StatusOK {
+			continue
+		}
+		assert.ElementsMatch(t, test.expectedSyncs, parseSyncs(t, rr.Body.Bytes()), test.description+":syncs")
+		if test.expectedSyncURL != "" {
+			syncURL, _ := jsonparser.GetString(rr.Body.Bytes(), "bidder_status", "[0]", "usersync", "url")
+			assert.Equal(t, test.expectedSyncURL, syncURL, test.description+":syncURL")
+		}
+	}
+}
+
+func TestCookieSyncActivityControl(t *testing.T) {
+	denyAppnexus := `{"privacy":{"allow_activities":{"sync_user":{"rules":[{"condition":{"component_name":["appnexus"]},"allow":false}]}}}}`
+	denyUSNat := `{"privacy":{"allow_activities":{"sync_user":{"rules":[{"condition":{"component_type":["bidder"],"gpp_sid":[7]},"allow":false}]}}}}`
+	falseValue := false
+
+	testCases := []struct {
+		description     string
+		requestBody     string
+		accountDefaults config.Account
+		expectedCode    int
+		expectedSyncs   []string
+	}{
+		{
+			description:   "No Account",
+			requestBody:   `{"bidders":["appnexus", "pubmatic"]}`,
+			expectedCode:  http.StatusOK,
+			expectedSyncs: []string{"appnexus", "pubmatic"},
+		},
+		{
+			description:   "Account Denies A Bidder",
+			requestBody:   `{"bidders":["appnexus", "pubmatic"], "account":"deny_appnexus"}`,
+			expectedCode:  http.StatusOK,
+			expectedSyncs: []string{"pubmatic"},
+		},
+		{
+			description:   "Account Denies The Bidders For A GPP Section",
+			requestBody:   `{"bidders":["appnexus", "pubmatic"], "account":"deny_usnat", "gpp":"DBABL~BAAqAA", "gpp_sid":"7"}`,
+			expectedCode:  http.StatusOK,
+			expectedSyncs: []string{},
+		},
+		{
+			description:   "Account Denies The Bidders For Another GPP Section",
+			requestBody:   `{"bidders":["appnexus", "pubmatic"], "account":"deny_usnat", "gpp":"DBABM~COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA", "gpp_sid":"2", "gdpr":0}`,
+			expectedCode:  http.StatusOK,
+			expectedSyncs: []string{"appnexus", "pubmatic"},
+		},
+		{
+			description: "Account Defaults Deny Without An Account",
+			requestBody: `{"bidders":["appnexus", "pubmatic"]}`,
+			accountDefaults: config.Account{Privacy: config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				SyncUser: config.AccountActivity{Default: &falseValue},
+			}}},
+			expectedCode:  http.StatusOK,
+			expectedSyncs: []string{},
+		},
+		{
+			description:     "Disabled Account",
+			requestBody:     `{"bidders":["appnexus", "pubmatic"], "account":"disabled"}`,
+			accountDefaults: config.Account{},
+			expectedCode:    http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range testCases {
+		cfg := &config.Configuration{GDPR: config.GDPR{UsersyncIfAmbiguous: true}, AccountDefaults: test.accountDefaults}
+		cfg.MarshalAccountDefaults()
+		fetcher := &mockAccountFetcher{accounts: map[string]json.RawMessage{
+			"deny_appnexus": json.RawMessage(denyAppnexus),
+			"deny_usnat":    json.RawMessage(denyUSNat),
+			"disabled":      json.RawMessage(`{"disabled":true}`),
+		}}
+		endpoint := NewCookieSyncEndpoint(syncersForTest(), cfg, mockPermissions(true, syncersForTest()), &metricsConf.DummyMetricsEngine{}, analyticsConf.NewPBSAnalytics(&config.Analytics{}), openrtb_ext.BuildBidderMap(), fetcher)
+
+		req, _ := http.NewRequest("POST", "/cookie_sync", strings.NewReader(test.requestBody))
+		rr := httptest.NewRecorder()
+		endpoint(rr, req, nil)
+
+		assert.Equal(t, test.expectedCode, rr.Code, test.description+":httpResponseCode")
+		if test.expectedCode != http.StatusOK {
+			continue
+		}
+		assert.ElementsMatch(t, test.expectedSyncs, parseSyncs(t, rr.Body.Bytes()), test.description+":syncs")
+	}
+}
+
 func TestCookieSyncHasCookies(t *testing.T) {
 	rr := doPost(`{"bidders":["appnexus", "audienceNetwork", "random"]}`, map[string]string{
 		"adnxs":           "1234",
@@ -196,7 +349,7 @@ func doConfigurablePost(body string, existingSyncs map[string]string, gdprHostCo
 }
 
 func testableEndpoint(perms gdpr.Permissions, cfgGDPR config.GDPR, cfgCCPA config.CCPA) httprouter.Handle {
-	return NewCookieSyncEndpoint(syncersForTest(), &config.Configuration{GDPR: cfgGDPR, CCPA: cfgCCPA}, perms, &metricsConf.DummyMetricsEngine{}, analyticsConf.NewPBSAnalytics(&config.Analytics{}), openrtb_ext.BuildBidderMap())
+	return NewCookieSyncEndpoint(syncersForTest(), &config.Configuration{GDPR: cfgGDPR, CCPA: cfgCCPA}, perms, &metricsConf.DummyMetricsEngine{}, analyticsConf.NewPBSAnalytics(&config.Analytics{}), openrtb_ext.BuildBidderMap(), &mockAccountFetcher{})
 }
 
 func syncersForTest() map[openrtb_ext.BidderName]usersync.Usersyncer {
@@ -254,6 +407,17 @@ func (g *gdprPerms) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.Bi
 	return ok, nil
 }
 
-func (g *gdprPerms) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, consent string) (bool, bool, bool, error) {
+func (g *gdprPerms) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, account *config.AccountGDPR, consent string) (bool, bool, bool, error) {
 	return true, true, true, nil
 }
+
+type mockAccountFetcher struct {
+	accounts map[string]json.RawMessage
+}
+
+func (f *mockAccountFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
+	if account, ok := f.accounts[accountID]; ok {
+		return account, nil
+	}
+	return nil, []error{stored_requests.NotFoundError{ID: accountID, DataType: "Account"}}
+}
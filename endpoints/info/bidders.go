@@ -8,6 +8,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/circuitbreaker"
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
@@ -37,7 +38,9 @@ func NewBiddersEndpoint(aliases map[string]string) httprouter.Handle {
 }
 
 // NewBidderDetailsEndpoint implements /info/bidders/*
-func NewBidderDetailsEndpoint(infos adapters.BidderInfos, aliases map[string]string) httprouter.Handle {
+//
+// Bidders with a circuit breaker also report its current state, which is why it's added to the responses at request time.
+func NewBidderDetailsEndpoint(infos adapters.BidderInfos, aliases map[string]string, breakers circuitbreaker.Breakers) httprouter.Handle {
 	// Validate if there exist and alias with name "all". If it does error out because
 	// that will break the /info/bidders/all endpoint.
 	if _, ok := aliases["all"]; ok {
@@ -80,12 +83,32 @@ func NewBidderDetailsEndpoint(infos adapters.BidderInfos, aliases map[string]str
 	// Add the json response containing all bidders info for the /info/bidders/all endpoint
 	responses["all"] = allBidderResponse
 
+	// Aliases share the circuit breaker of their core bidder
+	bidderBreakers := make(map[string]*circuitbreaker.Breaker, len(breakers))
+	for bidderName := range allBidderInfo {
+		coreBidder := bidderName
+		if aliasOf, ok := aliases[bidderName]; ok {
+			coreBidder = aliasOf
+		}
+		if breaker, ok := breakers[openrtb_ext.BidderName(coreBidder)]; ok {
+			bidderBreakers[bidderName] = breaker
+		}
+	}
+
 	// Return an endpoint which writes the responses from memory.
 	return func(w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
 		forBidder := ps.ByName("bidderName")
 
 		// If the requested path was /info/bidders/{bidderName} then return the info about that bidder
 		if response, ok := responses[forBidder]; ok {
+			if forBidder == "all" {
+				for bidderName, breaker := range bidderBreakers {
+					response = addCircuitBreakerState(response, breaker, bidderName)
+				}
+			} else if breaker, ok := bidderBreakers[forBidder]; ok {
+				response = addCircuitBreakerState(response, breaker)
+			}
+
 			w.Header().Set("Content-Type", "application/json")
 			if _, err := w.Write(response); err != nil {
 				glog.Errorf("error writing response to /info/bidders/%s: %v", forBidder, err)
@@ -111,6 +134,19 @@ func createAliasInfo(responses map[string]json.RawMessage, alias string, core st
 	return jsonInfo
 }
 
+// addCircuitBreakerState returns a copy of the response with the state of the circuit breaker added to the bidder info found at keys.
+func addCircuitBreakerState(response json.RawMessage, breaker *circuitbreaker.Breaker, keys ...string) json.RawMessage {
+	jsonData := make(json.RawMessage, len(response))
+	copy(jsonData, response)
+
+	jsonInfo, err := jsonparser.Set(jsonData, []byte(`{"state":"`+string(breaker.State())+`"}`), append(keys, "circuitBreaker")...)
+	if err != nil {
+		glog.Errorf("Failed to add the circuit breaker state to the bidder info: %v", err)
+		return response
+	}
+	return jsonInfo
+}
+
 type infoFile struct {
 	Maintainer   *maintainerInfo   `yaml:"maintainer" json:"maintainer"`
 	Capabilities *capabilitiesInfo `yaml:"capabilities" json:"capabilities"`
@@ -11,10 +11,12 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/buger/jsonparser"
 	"github.com/julienschmidt/httprouter"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/circuitbreaker"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/endpoints/info"
 	"github.com/prebid/prebid-server/openrtb_ext"
@@ -89,7 +91,7 @@ func TestGetSpecificBidders(t *testing.T) {
 		}
 		cfg := blankAdapterConfigWithStatus(openrtb_ext.CoreBidderNames(), bidderDisabled)
 		bidderInfos := adapters.ParseBidderInfos(cfg, "../../static/bidder-info", openrtb_ext.CoreBidderNames())
-		endpoint := info.NewBidderDetailsEndpoint(bidderInfos, map[string]string{})
+		endpoint := info.NewBidderDetailsEndpoint(bidderInfos, map[string]string{}, nil)
 
 		for _, bidderName := range openrtb_ext.CoreBidderNames() {
 			req, err := http.NewRequest("GET", "http://prebid-server.com/info/bidders/"+string(bidderName), strings.NewReader(""))
@@ -140,7 +142,7 @@ func testGetBidderAccuracy(t *testing.T, alias string) {
 		bidder = alias
 	}
 
-	endpoint := info.NewBidderDetailsEndpoint(bidderInfos, aliases)
+	endpoint := info.NewBidderDetailsEndpoint(bidderInfos, aliases, nil)
 	req, err := http.NewRequest("GET", "http://prebid-server.com/info/bidders/"+bidder, strings.NewReader(""))
 	assert.NoError(t, err, "Failed to create a GET /info/bidders request: %v", err)
 	params := []httprouter.Param{{
@@ -173,7 +175,7 @@ func testGetBidderAccuracy(t *testing.T, alias string) {
 
 func TestGetUnknownBidder(t *testing.T) {
 	bidderInfos := adapters.BidderInfos(make(map[string]adapters.BidderInfo))
-	endpoint := info.NewBidderDetailsEndpoint(bidderInfos, map[string]string{})
+	endpoint := info.NewBidderDetailsEndpoint(bidderInfos, map[string]string{}, nil)
 	req, err := http.NewRequest("GET", "http://prebid-server.com/info/bidders/someUnknownBidder", strings.NewReader(""))
 	if err != nil {
 		assert.FailNow(t, "Failed to create a GET /info/bidders/someUnknownBidder request: %v", err)
@@ -191,7 +193,7 @@ func TestGetUnknownBidder(t *testing.T) {
 func TestGetAllBidders(t *testing.T) {
 	cfg := blankAdapterConfig(openrtb_ext.CoreBidderNames())
 	bidderInfos := adapters.ParseBidderInfos(cfg, "../../static/bidder-info", openrtb_ext.CoreBidderNames())
-	endpoint := info.NewBidderDetailsEndpoint(bidderInfos, map[string]string{})
+	endpoint := info.NewBidderDetailsEndpoint(bidderInfos, map[string]string{}, nil)
 	req, err := http.NewRequest("GET", "http://prebid-server.com/info/bidders/all", strings.NewReader(""))
 	if err != nil {
 		assert.FailNow(t, "Failed to create a GET /info/bidders/someUnknownBidder request: %v", err)
@@ -215,6 +217,66 @@ func TestGetAllBidders(t *testing.T) {
 	assert.Len(t, resBidderInfos, len(bidderInfos), "GET /info/bidders/all should respond with all bidders info")
 }
 
+func TestGetBidderCircuitBreakerState(t *testing.T) {
+	cfg := blankAdapterConfig(openrtb_ext.CoreBidderNames())
+	bidderInfos := adapters.ParseBidderInfos(cfg, "../../static/bidder-info", openrtb_ext.CoreBidderNames())
+	breaker := circuitbreaker.New(config.CircuitBreaker{
+		Enabled:              true,
+		ErrorRateThreshold:   1,
+		TimeoutRateThreshold: 1,
+		MinRequests:          1,
+		WindowMillis:         1000,
+		OpenMillis:           60000,
+	}, nil)
+	breaker.RecordError()
+	breakers := circuitbreaker.Breakers{openrtb_ext.BidderAppnexus: breaker}
+	endpoint := info.NewBidderDetailsEndpoint(bidderInfos, map[string]string{"alias": "appnexus"}, breakers)
+
+	testCases := []struct {
+		description   string
+		bidder        string
+		expectedState string
+		expectedPath  []string
+	}{
+		{
+			description:   "Bidder With Circuit Breaker",
+			bidder:        "appnexus",
+			expectedState: "open",
+			expectedPath:  []string{"circuitBreaker", "state"},
+		},
+		{
+			description:   "Alias Of Bidder With Circuit Breaker",
+			bidder:        "alias",
+			expectedState: "open",
+			expectedPath:  []string{"circuitBreaker", "state"},
+		},
+		{
+			description:   "Bidder Without Circuit Breaker",
+			bidder:        "rubicon",
+			expectedState: "",
+			expectedPath:  []string{"circuitBreaker", "state"},
+		},
+		{
+			description:   "All Bidders",
+			bidder:        "all",
+			expectedState: "open",
+			expectedPath:  []string{"appnexus", "circuitBreaker", "state"},
+		},
+	}
+
+	for _, test := range testCases {
+		req, err := http.NewRequest("GET", "http://prebid-server.com/info/bidders/"+test.bidder, strings.NewReader(""))
+		assert.NoError(t, err, test.description)
+		r := httptest.NewRecorder()
+
+		endpoint(r, req, []httprouter.Param{{Key: "bidderName", Value: test.bidder}})
+
+		assert.Equal(t, http.StatusOK, r.Code, test.description)
+		state, _ := jsonparser.GetString(r.Body.Bytes(), test.expectedPath...)
+		assert.Equal(t, test.expectedState, state, test.description)
+	}
+}
+
 // TestInfoFiles makes sure that static/bidder-info contains a .yaml file for every BidderName.
 func TestInfoFiles(t *testing.T) {
 	fileInfos, err := ioutil.ReadDir("../../static/bidder-info")
@@ -26,6 +26,8 @@ import (
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/firstpartydata"
+	"github.com/prebid/prebid-server/hooks"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
@@ -133,7 +135,7 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		deps.analytics.LogAuctionObject(&ao)
 	}()
 
-	req, errL := deps.parseRequest(r)
+	req, account, acctIDErrs, errL := deps.parseRequest(r)
 
 	if errortypes.ContainsFatalError(errL) && writeError(errL, w, &labels) {
 		return
@@ -163,14 +165,15 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		labels.PubID = getAccountID(req.Site.Publisher)
 	}
 
-	// Look up account now that we have resolved the pubID value
-	account, acctIDErrs := accountService.GetAccount(ctx, deps.cfg, deps.accounts, labels.PubID)
 	if len(acctIDErrs) > 0 {
 		errL = append(errL, acctIDErrs...)
 		writeError(errL, w, &labels)
 		return
 	}
 
+	hookExecutor := hooks.ExecutorFromContext(r.Context())
+	hookExecutor.SetAccount(account)
+
 	auctionRequest := exchange.AuctionRequest{
 		BidRequest:   req,
 		Account:      *account,
@@ -178,12 +181,14 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		RequestType:  labels.RType,
 		StartTime:    start,
 		LegacyLabels: labels,
+		HookExecutor: hookExecutor,
 	}
 
 	response, err := deps.ex.HoldAuction(ctx, auctionRequest, nil)
 	ao.Request = req
 	ao.Response = response
 	ao.Account = account
+	ao.SeatNonBid = getSeatNonBids(response)
 	if err != nil {
 		labels.RequestStatus = metrics.RequestStatusErr
 		w.WriteHeader(http.StatusInternalServerError)
@@ -220,7 +225,10 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 // possible, it will return errors with messages that suggest improvements.
 //
 // If the errors list has at least one element, then no guarantees are made about the returned request.
-func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb.BidRequest, errs []error) {
+//
+// The account of the request is looked up along the way. The errors of the lookup are returned apart from the
+// others, as acctIDErrs, in which case the account is nil.
+func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb.BidRequest, account *config.Account, acctIDErrs []error, errs []error) {
 	req = &openrtb.BidRequest{}
 	errs = nil
 
@@ -251,6 +259,12 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb.
 		return
 	}
 
+	// The OpenRTB 2.6 fields are held in their 2.5 placement, which the rest of the auction reads.
+	if requestJson, err = openrtb_ext.ConvertDownTo25(requestJson); err != nil {
+		errs = []error{err}
+		return
+	}
+
 	if err := json.Unmarshal(requestJson, req); err != nil {
 		errs = []error{err}
 		return
@@ -264,6 +278,9 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb.
 		return
 	}
 
+	account, acctIDErrs = deps.lookupAccount(req)
+	setAccountAliases(req, account)
+
 	errL := deps.validateRequest(req)
 	if len(errL) > 0 {
 		errs = append(errs, errL...)
@@ -315,10 +332,14 @@ func (deps *endpointDeps) validateRequest(req *openrtb.BidRequest) []error {
 	}
 
 	var aliases map[string]string
+	var eidPermissions []openrtb_ext.ExtRequestPrebidDataEidPermission
 	if bidExt, err := deps.parseBidExt(req.Ext); err != nil {
 		return []error{err}
 	} else if bidExt != nil {
 		aliases = bidExt.Prebid.Aliases
+		if bidExt.Prebid.Data != nil {
+			eidPermissions = bidExt.Prebid.Data.EidPermissions
+		}
 
 		if err := deps.validateAliases(aliases); err != nil {
 			return []error{err}
@@ -331,6 +352,10 @@ func (deps *endpointDeps) validateRequest(req *openrtb.BidRequest) []error {
 		if err := validateSChains(bidExt); err != nil {
 			return []error{err}
 		}
+
+		if err := validateFirstPartyData(req, bidExt); err != nil {
+			return []error{err}
+		}
 	}
 
 	if (req.Site == nil && req.App == nil) || (req.Site != nil && req.App != nil) {
@@ -345,7 +370,7 @@ func (deps *endpointDeps) validateRequest(req *openrtb.BidRequest) []error {
 		return append(errL, err)
 	}
 
-	if err := deps.validateUser(req.User, aliases); err != nil {
+	if err := deps.validateUser(req.User, aliases, eidPermissions); err != nil {
 		return append(errL, err)
 	}
 
@@ -419,6 +444,11 @@ func validateSChains(req *openrtb_ext.ExtRequest) error {
 	return err
 }
 
+func validateFirstPartyData(req *openrtb.BidRequest, requestExt *openrtb_ext.ExtRequest) error {
+	_, err := firstpartydata.NewResolver(req, requestExt)
+	return err
+}
+
 func (deps *endpointDeps) validateImp(imp *openrtb.Imp, aliases map[string]string, index int) []error {
 	if imp.ID == "" {
 		return []error{fmt.Errorf("request.imp[%d] missing required field: \"id\"", index)}
@@ -912,7 +942,11 @@ func (deps *endpointDeps) validateApp(app *openrtb.App) error {
 	return nil
 }
 
-func (deps *endpointDeps) validateUser(user *openrtb.User, aliases map[string]string) error {
+func (deps *endpointDeps) validateUser(user *openrtb.User, aliases map[string]string, eidPermissions []openrtb_ext.ExtRequestPrebidDataEidPermission) error {
+	if err := deps.validateEidPermissions(eidPermissions, aliases); err != nil {
+		return err
+	}
+
 	// DigiTrust support
 	if user != nil && user.Ext != nil {
 		// Creating ExtUser object to check if DigiTrust is valid
@@ -974,6 +1008,34 @@ func (deps *endpointDeps) validateUser(user *openrtb.User, aliases map[string]st
 	return nil
 }
 
+func (deps *endpointDeps) validateEidPermissions(eidPermissions []openrtb_ext.ExtRequestPrebidDataEidPermission, aliases map[string]string) error {
+	uniqueSources := make(map[string]struct{}, len(eidPermissions))
+	for i, eidPermission := range eidPermissions {
+		if eidPermission.Source == "" {
+			return fmt.Errorf("request.ext.prebid.data.eidpermissions[%d] missing required field: \"source\"", i)
+		}
+		if _, ok := uniqueSources[eidPermission.Source]; ok {
+			return fmt.Errorf("request.ext.prebid.data.eidpermissions[%d] duplicates the rule of source %s", i, eidPermission.Source)
+		}
+		uniqueSources[eidPermission.Source] = struct{}{}
+
+		if len(eidPermission.Bidders) == 0 {
+			return fmt.Errorf("request.ext.prebid.data.eidpermissions[%d].bidders must contain at least one element", i)
+		}
+		for _, bidder := range eidPermission.Bidders {
+			if bidder == "*" {
+				continue
+			}
+			if _, isBidder := deps.bidderMap[bidder]; !isBidder {
+				if _, isAlias := aliases[bidder]; !isAlias {
+					return fmt.Errorf("request.ext.prebid.data.eidpermissions[%d].bidders contains %s, which is not a known bidder or alias", i, bidder)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func validateRegs(regs *openrtb.Regs) error {
 	if regs != nil && len(regs.Ext) > 0 {
 		var regsExt openrtb_ext.ExtRegs
@@ -999,6 +1061,57 @@ func sanitizeRequest(r *openrtb.BidRequest, ipValidator iputil.IPValidator) {
 	}
 }
 
+// lookupAccount looks up the account of the request's publisher. It's done before the request is validated, so that
+// the bidder aliases of the account are known to the validation.
+func (deps *endpointDeps) lookupAccount(req *openrtb.BidRequest) (*config.Account, []error) {
+	var publisher *openrtb.Publisher
+	if req.App != nil {
+		publisher = req.App.Publisher
+	} else if req.Site != nil {
+		publisher = req.Site.Publisher
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
+	defer cancel()
+
+	return accountService.GetAccount(ctx, deps.cfg, deps.accounts, getAccountID(publisher))
+}
+
+// setAccountAliases adds the bidder aliases of the request's account to request.ext.prebid.aliases, so that they're
+// validated and resolved like the aliases of the request, which take precedence over them.
+func setAccountAliases(req *openrtb.BidRequest, account *config.Account) {
+	if account == nil || len(account.BidderAliases) == 0 {
+		return
+	}
+
+	aliases := make(map[string]string, len(account.BidderAliases))
+	for alias, bidder := range account.BidderAliases {
+		aliases[alias] = bidder
+	}
+	// A malformed request.ext is left as is, for validateRequest to report.
+	if requestAliasesJSON, _, _, err := jsonparser.Get(req.Ext, "prebid", "aliases"); err == nil {
+		var requestAliases map[string]string
+		if err := json.Unmarshal(requestAliasesJSON, &requestAliases); err != nil {
+			return
+		}
+		for alias, bidder := range requestAliases {
+			aliases[alias] = bidder
+		}
+	}
+	aliasesJSON, err := json.Marshal(aliases)
+	if err != nil {
+		return
+	}
+
+	ext := append(json.RawMessage(nil), req.Ext...)
+	if len(ext) == 0 {
+		ext = json.RawMessage(`{}`)
+	}
+	if ext, err = jsonparser.Set(ext, aliasesJSON, "prebid", "aliases"); err == nil {
+		req.Ext = ext
+	}
+}
+
 // setFieldsImplicitly uses _implicit_ information from the httpReq to set values on bidReq.
 // This function does not consume the request body, which was set explicitly, but infers certain
 // OpenRTB properties from the headers and other implicit info.
@@ -1319,3 +1432,20 @@ func getAccountID(pub *openrtb.Publisher) string {
 	}
 	return metrics.PublisherUnknown
 }
+
+// getSeatNonBids returns the bids the exchange reported in bidresponse.ext.prebid.seatnonbid, so that the analytics
+// modules get them without parsing the response.
+func getSeatNonBids(response *openrtb.BidResponse) []openrtb_ext.SeatNonBid {
+	if response == nil {
+		return nil
+	}
+	value, dataType, _, err := jsonparser.Get(response.Ext, "prebid", "seatnonbid")
+	if err != nil || dataType != jsonparser.Array {
+		return nil
+	}
+	var seatNonBids []openrtb_ext.SeatNonBid
+	if err := json.Unmarshal(value, &seatNonBids); err != nil {
+		return nil
+	}
+	return seatNonBids
+}
@@ -2243,7 +2243,8 @@ func (cf mockStoredReqFetcher) FetchRequests(ctx context.Context, requestIDs []s
 }
 
 var mockAccountData = map[string]json.RawMessage{
-	"valid_acct": json.RawMessage(`{"disabled":false}`),
+	"valid_acct":   json.RawMessage(`{"disabled":false}`),
+	"aliases_acct": json.RawMessage(`{"bidder_aliases":{"mybrand":"appnexus","otherbrand":"rubicon"}}`),
 }
 
 type mockAccountFetcher struct {
@@ -2302,3 +2303,190 @@ type hardcodedResponseIPValidator struct {
 func (v hardcodedResponseIPValidator) IsValid(net.IP, iputil.IPVersion) bool {
 	return v.response
 }
+
+func TestGetSeatNonBids(t *testing.T) {
+	testCases := []struct {
+		description string
+		response    *openrtb.BidResponse
+		expected    []openrtb_ext.SeatNonBid
+	}{
+		{
+			description: "No Response",
+		},
+		{
+			description: "No Ext",
+			response:    &openrtb.BidResponse{ID: "some-id"},
+		},
+		{
+			description: "No SeatNonBid",
+			response:    &openrtb.BidResponse{Ext: json.RawMessage(`{"prebid":{"auctiontimestamp":1}}`)},
+		},
+		{
+			description: "Malformed SeatNonBid",
+			response:    &openrtb.BidResponse{Ext: json.RawMessage(`{"prebid":{"seatnonbid":[{"seat":1}]}}`)},
+		},
+		{
+			description: "SeatNonBid",
+			response:    &openrtb.BidResponse{Ext: json.RawMessage(`{"prebid":{"seatnonbid":[{"seat":"appnexus","nonbid":[{"id":"bid1","impid":"imp1","price":0.5,"statuscode":100}]}]}}`)},
+			expected: []openrtb_ext.SeatNonBid{
+				{Seat: "appnexus", NonBid: []openrtb_ext.NonBid{{ID: "bid1", ImpID: "imp1", Price: 0.5, StatusCode: 100}}},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, getSeatNonBids(test.response), test.description)
+	}
+}
+
+func TestValidateEidPermissions(t *testing.T) {
+	deps := &endpointDeps{
+		&nobidExchange{},
+		newParamsValidator(t),
+		&mockStoredReqFetcher{},
+		empty_fetcher.EmptyFetcher{},
+		empty_fetcher.EmptyFetcher{},
+		&config.Configuration{},
+		newTestMetrics(),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		map[string]string{},
+		false,
+		[]byte{},
+		openrtb_ext.BuildBidderMap(),
+		nil,
+		nil,
+		hardcodedResponseIPValidator{response: true},
+	}
+
+	testCases := []struct {
+		description    string
+		eidPermissions []openrtb_ext.ExtRequestPrebidDataEidPermission
+		aliases        map[string]string
+		expectedError  error
+	}{
+		{
+			description: "No Rules",
+		},
+		{
+			description: "Valid Rules",
+			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
+				{Source: "source1", Bidders: []string{"appnexus", "alias"}},
+				{Source: "source2", Bidders: []string{"*"}},
+			},
+			aliases: map[string]string{"alias": "appnexus"},
+		},
+		{
+			description: "Missing Source",
+			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
+				{Source: "source1", Bidders: []string{"appnexus"}},
+				{Bidders: []string{"appnexus"}},
+			},
+			expectedError: errors.New(`request.ext.prebid.data.eidpermissions[1] missing required field: "source"`),
+		},
+		{
+			description: "Duplicate Source",
+			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
+				{Source: "source1", Bidders: []string{"appnexus"}},
+				{Source: "source1", Bidders: []string{"rubicon"}},
+			},
+			expectedError: errors.New("request.ext.prebid.data.eidpermissions[1] duplicates the rule of source source1"),
+		},
+		{
+			description: "No Bidders",
+			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
+				{Source: "source1", Bidders: []string{}},
+			},
+			expectedError: errors.New("request.ext.prebid.data.eidpermissions[0].bidders must contain at least one element"),
+		},
+		{
+			description: "Unknown Bidder",
+			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
+				{Source: "source1", Bidders: []string{"appnexus", "unknown"}},
+			},
+			expectedError: errors.New("request.ext.prebid.data.eidpermissions[0].bidders contains unknown, which is not a known bidder or alias"),
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedError, deps.validateUser(nil, test.aliases, test.eidPermissions), test.description)
+	}
+}
+
+func TestSetAccountAliases(t *testing.T) {
+	cfg := &config.Configuration{}
+	assert.NoError(t, cfg.MarshalAccountDefaults())
+
+	deps := &endpointDeps{
+		&nobidExchange{},
+		newParamsValidator(t),
+		&mockStoredReqFetcher{},
+		empty_fetcher.EmptyFetcher{},
+		&mockAccountFetcher{},
+		cfg,
+		newTestMetrics(),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		map[string]string{},
+		false,
+		[]byte{},
+		openrtb_ext.BuildBidderMap(),
+		nil,
+		nil,
+		hardcodedResponseIPValidator{response: true},
+	}
+
+	testCases := []struct {
+		description string
+		request     *openrtb.BidRequest
+		expectedExt string
+	}{
+		{
+			description: "Account Without Aliases",
+			request: &openrtb.BidRequest{
+				Site: &openrtb.Site{Publisher: &openrtb.Publisher{ID: "valid_acct"}},
+				Ext:  json.RawMessage(`{"prebid":{"aliases":{"requestbrand":"appnexus"}}}`),
+			},
+			expectedExt: `{"prebid":{"aliases":{"requestbrand":"appnexus"}}}`,
+		},
+		{
+			description: "Unknown Account",
+			request: &openrtb.BidRequest{
+				Site: &openrtb.Site{Publisher: &openrtb.Publisher{ID: "unknown_acct"}},
+			},
+			expectedExt: ``,
+		},
+		{
+			description: "Request Without Ext",
+			request: &openrtb.BidRequest{
+				App: &openrtb.App{Publisher: &openrtb.Publisher{ID: "aliases_acct"}},
+			},
+			expectedExt: `{"prebid":{"aliases":{"mybrand":"appnexus","otherbrand":"rubicon"}}}`,
+		},
+		{
+			description: "Request Aliases Take Precedence",
+			request: &openrtb.BidRequest{
+				Site: &openrtb.Site{Publisher: &openrtb.Publisher{ID: "aliases_acct"}},
+				Ext:  json.RawMessage(`{"prebid":{"debug":true,"aliases":{"mybrand":"openx","requestbrand":"appnexus"}}}`),
+			},
+			expectedExt: `{"prebid":{"debug":true,"aliases":{"mybrand":"openx","otherbrand":"rubicon","requestbrand":"appnexus"}}}`,
+		},
+		{
+			description: "Malformed Request Aliases",
+			request: &openrtb.BidRequest{
+				Site: &openrtb.Site{Publisher: &openrtb.Publisher{ID: "aliases_acct"}},
+				Ext:  json.RawMessage(`{"prebid":{"aliases":["appnexus"]}}`),
+			},
+			expectedExt: `{"prebid":{"aliases":["appnexus"]}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		account, _ := deps.lookupAccount(test.request)
+		setAccountAliases(test.request, account)
+
+		if test.expectedExt == "" {
+			assert.Empty(t, test.request.Ext, test.description)
+		} else {
+			assert.JSONEq(t, test.expectedExt, string(test.request.Ext), test.description)
+		}
+	}
+}
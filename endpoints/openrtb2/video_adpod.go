@@ -0,0 +1,230 @@
+package openrtb2
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// maxPodFillSteps bounds the search for the best set of bids of a pod. Once it is reached, the best set found so far is kept.
+// The bids are tried from the highest price down, so the first set found is the greedy one.
+const maxPodFillSteps = 10000
+
+// bidPosition locates a bid in the bid response
+type bidPosition struct {
+	seatIndex int
+	bidIndex  int
+}
+
+// podCandidate is a cached bid which may fill a pod
+type podCandidate struct {
+	position bidPosition
+	price    float64
+	duration int
+	domains  []string
+	category string
+}
+
+// fillAdPods keeps, in each pod, the cached bids which maximize the revenue of the pod without exceeding its duration.
+// If the pod config excludes them, two ads of the same advertiser domain or primary category never fill the same pod.
+// The bids of the pods which aren't in the pod config are left untouched.
+func fillAdPods(bidResponse *openrtb.BidResponse, bidRequest *openrtb.BidRequest, podConfig openrtb_ext.PodConfig) {
+	impDurations := make(map[string]int, len(bidRequest.Imp))
+	for _, imp := range bidRequest.Imp {
+		if imp.Video != nil {
+			impDurations[imp.ID] = int(imp.Video.MaxDuration)
+		}
+	}
+
+	candidates := make(map[int64][]podCandidate)
+	for seatIndex, seatBid := range bidResponse.SeatBid {
+		for bidIndex, bid := range seatBid.Bid {
+			podId, err := strconv.ParseInt(strings.Split(bid.ImpID, "_")[0], 0, 64)
+			if err != nil {
+				continue
+			}
+			candidate, ok := newPodCandidate(bid, seatBid.Seat, impDurations)
+			if !ok {
+				continue
+			}
+			candidate.position = bidPosition{seatIndex: seatIndex, bidIndex: bidIndex}
+			candidates[podId] = append(candidates[podId], candidate)
+		}
+	}
+
+	rejected := make(map[bidPosition]bool)
+	for _, pod := range podConfig.Pods {
+		podCandidates := candidates[int64(pod.PodId)]
+		for _, candidate := range podCandidates {
+			rejected[candidate.position] = true
+		}
+		if podConfig.RequireExactDuration {
+			podCandidates = filterExactDurations(podCandidates, podConfig.DurationRangeSec)
+		}
+		for _, candidate := range fillPod(podCandidates, pod.AdPodDurationSec, podConfig.Exclusion) {
+			delete(rejected, candidate.position)
+		}
+	}
+	if len(rejected) == 0 {
+		return
+	}
+
+	for seatIndex := range bidResponse.SeatBid {
+		seatBid := &bidResponse.SeatBid[seatIndex]
+		bids := make([]openrtb.Bid, 0, len(seatBid.Bid))
+		for bidIndex, bid := range seatBid.Bid {
+			if !rejected[bidPosition{seatIndex: seatIndex, bidIndex: bidIndex}] {
+				bids = append(bids, bid)
+			}
+		}
+		seatBid.Bid = bids
+	}
+}
+
+// newPodCandidate returns false if the bid wasn't cached, as it can't be part of a pod.
+// The duration of the imp is used for the bids which don't report theirs.
+func newPodCandidate(bid openrtb.Bid, seat string, impDurations map[string]int) (podCandidate, bool) {
+	var bidExt openrtb_ext.ExtBid
+	if err := json.Unmarshal(bid.Ext, &bidExt); err != nil || bidExt.Prebid == nil {
+		return podCandidate{}, false
+	}
+	if bidExt.Prebid.Targeting[formatTargetingKey(openrtb_ext.HbVastCacheKey, seat)] == "" {
+		return podCandidate{}, false
+	}
+
+	candidate := podCandidate{
+		price:    bid.Price,
+		duration: impDurations[bid.ImpID],
+		domains:  bid.ADomain,
+	}
+	if bidExt.Prebid.Video != nil {
+		if bidExt.Prebid.Video.Duration > 0 {
+			candidate.duration = bidExt.Prebid.Video.Duration
+		}
+		candidate.category = bidExt.Prebid.Video.PrimaryCategory
+	}
+	if candidate.category == "" && len(bid.Cat) > 0 {
+		candidate.category = bid.Cat[0]
+	}
+	return candidate, true
+}
+
+func filterExactDurations(candidates []podCandidate, durations []int) []podCandidate {
+	filtered := make([]podCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		for _, duration := range durations {
+			if candidate.duration == duration {
+				filtered = append(filtered, candidate)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// podFiller searches the set of candidates with the highest revenue which fits in the pod, by branch and bound.
+// Between two sets with the same revenue, the one with the most ads wins.
+type podFiller struct {
+	candidates []podCandidate
+	// remainingRevenue[i] is the revenue of all the candidates from i on, which bounds what the search can still add.
+	remainingRevenue []float64
+	exclusion        openrtb_ext.PodExclusion
+	steps            int
+
+	current        []int
+	usedDomains    map[string]int
+	usedCategories map[string]int
+
+	best        []int
+	bestRevenue float64
+}
+
+func fillPod(candidates []podCandidate, podDuration int, exclusion *openrtb_ext.PodExclusion) []podCandidate {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sorted := make([]podCandidate, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].price > sorted[j].price
+	})
+
+	filler := &podFiller{
+		candidates:       sorted,
+		remainingRevenue: make([]float64, len(sorted)+1),
+		usedDomains:      make(map[string]int),
+		usedCategories:   make(map[string]int),
+	}
+	if exclusion != nil {
+		filler.exclusion = *exclusion
+	}
+	for i := len(sorted) - 1; i >= 0; i-- {
+		filler.remainingRevenue[i] = filler.remainingRevenue[i+1] + sorted[i].price
+	}
+	filler.search(0, podDuration, 0)
+
+	kept := make([]podCandidate, 0, len(filler.best))
+	for _, i := range filler.best {
+		kept = append(kept, filler.candidates[i])
+	}
+	return kept
+}
+
+func (f *podFiller) search(next int, remainingDuration int, revenue float64) {
+	if revenue > f.bestRevenue || (revenue == f.bestRevenue && len(f.current) > len(f.best)) {
+		f.bestRevenue = revenue
+		f.best = append(f.best[:0], f.current...)
+	}
+	if next == len(f.candidates) || f.steps >= maxPodFillSteps {
+		return
+	}
+	bound := revenue + f.remainingRevenue[next]
+	if bound < f.bestRevenue || (bound == f.bestRevenue && len(f.current)+len(f.candidates)-next <= len(f.best)) {
+		return
+	}
+	f.steps++
+
+	candidate := f.candidates[next]
+	if candidate.duration <= remainingDuration && !f.excluded(candidate) {
+		f.add(next)
+		f.search(next+1, remainingDuration-candidate.duration, revenue+candidate.price)
+		f.remove(next)
+	}
+	f.search(next+1, remainingDuration, revenue)
+}
+
+func (f *podFiller) excluded(candidate podCandidate) bool {
+	if f.exclusion.AdvertiserDomain {
+		for _, domain := range candidate.domains {
+			if f.usedDomains[domain] > 0 {
+				return true
+			}
+		}
+	}
+	return f.exclusion.IABCategory && candidate.category != "" && f.usedCategories[candidate.category] > 0
+}
+
+func (f *podFiller) add(i int) {
+	f.current = append(f.current, i)
+	for _, domain := range f.candidates[i].domains {
+		f.usedDomains[domain]++
+	}
+	if f.candidates[i].category != "" {
+		f.usedCategories[f.candidates[i].category]++
+	}
+}
+
+func (f *podFiller) remove(i int) {
+	f.current = f.current[:len(f.current)-1]
+	for _, domain := range f.candidates[i].domains {
+		f.usedDomains[domain]--
+	}
+	if f.candidates[i].category != "" {
+		f.usedCategories[f.candidates[i].category]--
+	}
+}
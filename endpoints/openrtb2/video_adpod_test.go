@@ -0,0 +1,175 @@
+package openrtb2
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFillPod(t *testing.T) {
+	testCases := []struct {
+		description string
+		candidates  []podCandidate
+		podDuration int
+		exclusion   *openrtb_ext.PodExclusion
+		expectedIDs []int
+	}{
+		{
+			description: "No candidates",
+			podDuration: 60,
+		},
+		{
+			description: "All candidates fit",
+			candidates: []podCandidate{
+				{position: bidPosition{bidIndex: 0}, price: 1, duration: 15},
+				{position: bidPosition{bidIndex: 1}, price: 2, duration: 30},
+			},
+			podDuration: 60,
+			expectedIDs: []int{1, 0},
+		},
+		{
+			description: "Two cheaper ads beat the greedy pick",
+			candidates: []podCandidate{
+				{position: bidPosition{bidIndex: 0}, price: 10, duration: 45},
+				{position: bidPosition{bidIndex: 1}, price: 6, duration: 30},
+				{position: bidPosition{bidIndex: 2}, price: 6, duration: 30},
+			},
+			podDuration: 60,
+			expectedIDs: []int{1, 2},
+		},
+		{
+			description: "Candidate longer than the pod",
+			candidates: []podCandidate{
+				{position: bidPosition{bidIndex: 0}, price: 10, duration: 90},
+				{position: bidPosition{bidIndex: 1}, price: 1, duration: 30},
+			},
+			podDuration: 60,
+			expectedIDs: []int{1},
+		},
+		{
+			description: "Same advertiser domain excluded",
+			candidates: []podCandidate{
+				{position: bidPosition{bidIndex: 0}, price: 10, duration: 15, domains: []string{"brand.com"}},
+				{position: bidPosition{bidIndex: 1}, price: 8, duration: 15, domains: []string{"other.com", "brand.com"}},
+				{position: bidPosition{bidIndex: 2}, price: 5, duration: 15, domains: []string{"other.com"}},
+			},
+			podDuration: 60,
+			exclusion:   &openrtb_ext.PodExclusion{AdvertiserDomain: true},
+			expectedIDs: []int{0, 2},
+		},
+		{
+			description: "Same advertiser domain allowed without exclusion",
+			candidates: []podCandidate{
+				{position: bidPosition{bidIndex: 0}, price: 10, duration: 15, domains: []string{"brand.com"}},
+				{position: bidPosition{bidIndex: 1}, price: 8, duration: 15, domains: []string{"brand.com"}},
+			},
+			podDuration: 60,
+			expectedIDs: []int{0, 1},
+		},
+		{
+			description: "Same category excluded",
+			candidates: []podCandidate{
+				{position: bidPosition{bidIndex: 0}, price: 10, duration: 30, category: "IAB1"},
+				{position: bidPosition{bidIndex: 1}, price: 9, duration: 30, category: "IAB1"},
+				{position: bidPosition{bidIndex: 2}, price: 1, duration: 30, category: "IAB2"},
+				{position: bidPosition{bidIndex: 3}, price: 1, duration: 30},
+			},
+			podDuration: 90,
+			exclusion:   &openrtb_ext.PodExclusion{IABCategory: true},
+			expectedIDs: []int{0, 2, 3},
+		},
+		{
+			description: "Same revenue prefers more ads",
+			candidates: []podCandidate{
+				{position: bidPosition{bidIndex: 0}, price: 0, duration: 30},
+				{position: bidPosition{bidIndex: 1}, price: 0, duration: 30},
+			},
+			podDuration: 60,
+			expectedIDs: []int{0, 1},
+		},
+	}
+
+	for _, test := range testCases {
+		ids := []int{}
+		for _, candidate := range fillPod(test.candidates, test.podDuration, test.exclusion) {
+			ids = append(ids, candidate.position.bidIndex)
+		}
+		if test.expectedIDs == nil {
+			test.expectedIDs = []int{}
+		}
+		assert.Equal(t, test.expectedIDs, ids, test.description)
+	}
+}
+
+func TestFillPodSearchLimit(t *testing.T) {
+	// Every set of 10 candidates out of 40 is worth the same, so the search can't prune, and stops at the greedy set.
+	candidates := make([]podCandidate, 40)
+	for i := range candidates {
+		candidates[i] = podCandidate{position: bidPosition{bidIndex: i}, price: 1, duration: 30}
+	}
+
+	kept := fillPod(candidates, 300, nil)
+
+	assert.Len(t, kept, 10)
+}
+
+func TestFillAdPods(t *testing.T) {
+	podBid := func(id, impID string, price float64, duration int, domain string, cached bool) openrtb.Bid {
+		targeting := `"hb_pb_appnexus":"1.00"`
+		if cached {
+			targeting += `,"hb_uuid_appnexus":"some-uuid"`
+		}
+		return openrtb.Bid{
+			ID:      id,
+			ImpID:   impID,
+			Price:   price,
+			ADomain: []string{domain},
+			Ext:     json.RawMessage(fmt.Sprintf(`{"prebid":{"targeting":{%s},"type":"video","video":{"duration":%d,"primary_category":""}}}`, targeting, duration)),
+		}
+	}
+
+	bidRequest := &openrtb.BidRequest{
+		Imp: []openrtb.Imp{
+			{ID: "1_0", Video: &openrtb.Video{MaxDuration: 30}},
+			{ID: "1_1", Video: &openrtb.Video{MaxDuration: 30}},
+			{ID: "2_0", Video: &openrtb.Video{MaxDuration: 15}},
+		},
+	}
+	podConfig := openrtb_ext.PodConfig{
+		DurationRangeSec:     []int{15, 30},
+		RequireExactDuration: true,
+		Exclusion:            &openrtb_ext.PodExclusion{AdvertiserDomain: true},
+		Pods: []openrtb_ext.Pod{
+			{PodId: 1, AdPodDurationSec: 60},
+			{PodId: 2, AdPodDurationSec: 15},
+		},
+	}
+	bidResponse := &openrtb.BidResponse{
+		SeatBid: []openrtb.SeatBid{
+			{
+				Seat: "appnexus",
+				Bid: []openrtb.Bid{
+					podBid("kept", "1_0", 5, 30, "brand.com", true),
+					podBid("same-domain", "1_1", 4, 30, "brand.com", true),
+					podBid("inexact-duration", "1_1", 3, 20, "other.com", true),
+					podBid("uncached", "1_1", 9, 30, "uncached.com", false),
+					podBid("second-in-pod", "1_1", 1, 0, "third.com", true),
+					podBid("other-pod", "2_0", 2, 15, "brand.com", true),
+					podBid("unknown-pod", "3_0", 2, 300, "brand.com", true),
+				},
+			},
+		},
+	}
+
+	fillAdPods(bidResponse, bidRequest, podConfig)
+
+	ids := []string{}
+	for _, bid := range bidResponse.SeatBid[0].Bid {
+		ids = append(ids, bid.ID)
+	}
+	assert.Equal(t, []string{"kept", "uncached", "second-in-pod", "other-pod", "unknown-pod"}, ids)
+}
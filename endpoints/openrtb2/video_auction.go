@@ -23,7 +23,6 @@ import (
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
 	"github.com/mxmCherry/openrtb"
-	accountService "github.com/prebid/prebid-server/account"
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/exchange"
@@ -103,7 +102,8 @@ func NewVideoEndpoint(
 	d. Append impressions for this pod to the overall list of impressions in the OpenRTB bid request.
 9. Call validateRequest() function from auction.go to validate the generated request.
 10. Call HoldAuction() function to run the auction for the OpenRTB bid request that was built in the previous step.
-11. Build proper response format.
+11. Keep in each pod the bids which maximize its revenue within its duration, without breaking the competitive exclusion rules of the pod config.
+12. Build proper response format.
 */
 func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	start := time.Now()
@@ -188,6 +188,13 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 			return
 		}
 	}
+	// The OpenRTB 2.6 fields are held in their 2.5 placement, which the rest of the auction reads.
+	resolvedRequest, err = openrtb_ext.ConvertDownTo25(resolvedRequest)
+	if err != nil {
+		handleError(&labels, w, []error{err}, &vo, &debugLog)
+		return
+	}
+
 	//unmarshal and validate combined result
 	videoBidReq, errL, podErrors := deps.parseVideoRequest(resolvedRequest, r.Header)
 	if len(errL) > 0 {
@@ -239,6 +246,9 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	// Populate any "missing" OpenRTB fields with info from other sources, (e.g. HTTP request headers).
 	deps.setFieldsImplicitly(r, bidReq) // move after merge
 
+	account, acctIDErrs := deps.lookupAccount(bidReq)
+	setAccountAliases(bidReq, account)
+
 	errL = deps.validateRequest(bidReq)
 	if errortypes.ContainsFatalError(errL) {
 		handleError(&labels, w, errL, &vo, &debugLog)
@@ -267,8 +277,6 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		labels.PubID = getAccountID(bidReq.Site.Publisher)
 	}
 
-	// Look up account now that we have resolved the pubID value
-	account, acctIDErrs := accountService.GetAccount(ctx, deps.cfg, deps.accounts, labels.PubID)
 	if len(acctIDErrs) > 0 {
 		handleError(&labels, w, acctIDErrs, &vo, &debugLog)
 		return
@@ -286,12 +294,15 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	response, err := deps.ex.HoldAuction(ctx, auctionRequest, &debugLog)
 	vo.Request = bidReq
 	vo.Response = response
+	vo.SeatNonBid = getSeatNonBids(response)
 	if err != nil {
 		errL := []error{err}
 		handleError(&labels, w, errL, &vo, &debugLog)
 		return
 	}
 
+	fillAdPods(response, bidReq, videoBidReq.PodConfig)
+
 	//build simplified response
 	bidResp, err := buildVideoResponse(response, podErrors)
 	if err != nil {
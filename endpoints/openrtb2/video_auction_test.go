@@ -12,6 +12,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/buger/jsonparser"
 	"github.com/mxmCherry/openrtb"
 	"github.com/prebid/prebid-server/analytics"
 	analyticsConf "github.com/prebid/prebid-server/analytics/config"
@@ -1145,6 +1146,40 @@ func TestCCPA(t *testing.T) {
 	}
 }
 
+func TestVideoEndpointOpenRTB26Fields(t *testing.T) {
+	reqData, err := ioutil.ReadFile("sample-requests/video/video_valid_sample.json")
+	if err != nil {
+		t.Fatalf("Failed to fetch a valid request: %v", err)
+	}
+	reqBody := getRequestPayload(t, reqData)
+	reqBody, err = jsonparser.Set(reqBody, []byte(`"1YNN"`), "regs", "us_privacy")
+	if err != nil {
+		t.Fatalf("Failed to set regs.us_privacy: %v", err)
+	}
+	reqBody, err = jsonparser.Set(reqBody, []byte(`"consent-string"`), "user", "consent")
+	if err != nil {
+		t.Fatalf("Failed to set user.consent: %v", err)
+	}
+
+	ex := &mockExchangeVideo{}
+	req := httptest.NewRequest("POST", "/openrtb2/video", bytes.NewReader(reqBody))
+	recorder := httptest.NewRecorder()
+	mockDeps(t, ex).VideoAuctionEndpoint(recorder, req, nil)
+
+	if ex.lastRequest == nil {
+		t.Fatalf("The request never made it into the exchange: %s", recorder.Body.String())
+	}
+
+	extRegs := &openrtb_ext.ExtRegs{}
+	if assert.NoError(t, json.Unmarshal(ex.lastRequest.Regs.Ext, extRegs)) {
+		assert.Equal(t, "1YNN", extRegs.USPrivacy, "regs.us_privacy should be moved to regs.ext")
+	}
+	extUser := &openrtb_ext.ExtUser{}
+	if assert.NoError(t, json.Unmarshal(ex.lastRequest.User.Ext, extUser)) {
+		assert.Equal(t, "consent-string", extUser.Consent, "user.consent should be moved to user.ext")
+	}
+}
+
 func TestVideoEndpointAppendBidderNames(t *testing.T) {
 	ex := &mockExchangeAppendBidderNames{}
 	reqData, err := ioutil.ReadFile("sample-requests/video/video_valid_sample_appendbiddernames.json")
@@ -15,6 +15,9 @@ import (
 	"github.com/prebid/prebid-server/gdpr"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/privacy"
+	gppPrivacy "github.com/prebid/prebid-server/privacy/gpp"
+	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/usersync"
 )
 
@@ -26,12 +29,18 @@ const (
 	chromeiOSStrLen = len(chromeiOSStr)
 )
 
-func NewSetUIDEndpoint(cfg config.HostCookie, syncers map[openrtb_ext.BidderName]usersync.Usersyncer, perms gdpr.Permissions, pbsanalytics analytics.PBSAnalyticsModule, metricsEngine metrics.MetricsEngine) httprouter.Handle {
-	cookieTTL := time.Duration(cfg.TTL) * 24 * time.Hour
+func NewSetUIDEndpoint(cfg *config.Configuration, syncers map[openrtb_ext.BidderName]usersync.Usersyncer, perms gdpr.Permissions, pbsanalytics analytics.PBSAnalyticsModule, metricsEngine metrics.MetricsEngine, accountsFetcher stored_requests.AccountFetcher) httprouter.Handle {
+	hostCookie := cfg.HostCookie
+	cookieTTL := time.Duration(hostCookie.TTL) * 24 * time.Hour
 
 	validFamilyNameMap := make(map[string]struct{})
-	for _, s := range syncers {
+	familyBidders := make(map[string]openrtb_ext.BidderName)
+	for bidder, s := range syncers {
 		validFamilyNameMap[s.FamilyName()] = struct{}{}
+		// The bidder named after the family is preferred when the family is shared.
+		if _, ok := familyBidders[s.FamilyName()]; !ok || string(bidder) == s.FamilyName() {
+			familyBidders[s.FamilyName()] = bidder
+		}
 	}
 
 	return httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -42,7 +51,7 @@ func NewSetUIDEndpoint(cfg config.HostCookie, syncers map[openrtb_ext.BidderName
 
 		defer pbsanalytics.LogSetUIDObject(&so)
 
-		pc := usersync.ParsePBSCookieFromRequest(r, &cfg)
+		pc := usersync.ParsePBSCookieFromRequest(r, &hostCookie)
 		if !pc.AllowSyncs() {
 			w.WriteHeader(http.StatusUnauthorized)
 			metricsEngine.RecordUserIDSet(metrics.UserLabels{
@@ -66,7 +75,18 @@ func NewSetUIDEndpoint(cfg config.HostCookie, syncers map[openrtb_ext.BidderName
 		}
 		so.Bidder = familyName
 
-		if shouldReturn, status, body := preventSyncsGDPR(query.Get("gdpr"), query.Get("gdpr_consent"), perms); shouldReturn {
+		if shouldReturn, status, body := preventSyncsGDPR(query.Get("gdpr"), query.Get("gdpr_consent"), familyBidders[familyName], perms); shouldReturn {
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+			metricsEngine.RecordUserIDSet(metrics.UserLabels{
+				Action: metrics.RequestActionGDPR,
+				Bidder: openrtb_ext.BidderName(familyName),
+			})
+			so.Status = status
+			return
+		}
+
+		if shouldReturn, status, body := preventSyncsActivityControl(query, familyBidders[familyName], cfg, accountsFetcher); shouldReturn {
 			w.WriteHeader(status)
 			w.Write([]byte(body))
 			metricsEngine.RecordUserIDSet(metrics.UserLabels{
@@ -96,7 +116,7 @@ func NewSetUIDEndpoint(cfg config.HostCookie, syncers map[openrtb_ext.BidderName
 		}
 
 		setSiteCookie := siteCookieCheck(r.UserAgent())
-		pc.SetCookieOnResponse(w, setSiteCookie, &cfg, cookieTTL)
+		pc.SetCookieOnResponse(w, setSiteCookie, &hostCookie, cookieTTL)
 	})
 }
 
@@ -143,7 +163,7 @@ func checkChromeBrowserVersion(ua string, index int, chromeStrLength int) bool {
 	return result
 }
 
-func preventSyncsGDPR(gdprEnabled string, gdprConsent string, perms gdpr.Permissions) (bool, int, string) {
+func preventSyncsGDPR(gdprEnabled string, gdprConsent string, bidder openrtb_ext.BidderName, perms gdpr.Permissions) (bool, int, string) {
 	switch gdprEnabled {
 	case "0":
 		return false, 0, ""
@@ -165,6 +185,8 @@ func preventSyncsGDPR(gdprEnabled string, gdprConsent string, perms gdpr.Permiss
 			}
 		} else if !allowed {
 			return true, http.StatusOK, "The gdpr_consent string prevents cookies from being saved"
+		} else if allowed, err := perms.BidderSyncAllowed(context.Background(), bidder, gdprConsent); err != nil || !allowed {
+			return true, http.StatusOK, "The gdpr_consent string prevents the bidder from syncing"
 		} else {
 			return false, 0, ""
 		}
@@ -172,3 +194,23 @@ func preventSyncsGDPR(gdprEnabled string, gdprConsent string, perms gdpr.Permiss
 		return true, http.StatusBadRequest, "the gdpr query param must be either 0 or 1. You gave " + gdprEnabled
 	}
 }
+
+// preventSyncsActivityControl returns true, with the status and body of the response, when the account named by the
+// account query param doesn't allow the bidder to sync the user.
+func preventSyncsActivityControl(query url.Values, bidder openrtb_ext.BidderName, cfg *config.Configuration, fetcher stored_requests.AccountFetcher) (bool, int, string) {
+	sectionIDs, err := gppPrivacy.ParseSectionIDs(query.Get("gpp_sid"))
+	if err != nil {
+		return true, http.StatusBadRequest, err.Error()
+	}
+
+	activityControl, errs := getActivityControl(cfg, fetcher, query.Get("account"))
+	if len(errs) > 0 {
+		return true, http.StatusBadRequest, errs[0].Error()
+	}
+
+	component := privacy.Component{Type: config.ComponentTypeBidder, Name: string(bidder)}
+	if !activityControl.Allow(privacy.ActivitySyncUser, component, privacy.ActivityRequest{GPPSectionIDs: sectionIDs}) {
+		return true, http.StatusOK, "The account prevents the bidder from syncing"
+	}
+	return false, 0, ""
+}
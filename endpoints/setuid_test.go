@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -278,6 +279,125 @@ func TestSetUIDEndpointMetrics(t *testing.T) {
 	}
 }
 
+func TestSetUIDEndpointBidderGDPR(t *testing.T) {
+	testCases := []struct {
+		description          string
+		uri                  string
+		prohibitedBidders    []openrtb_ext.BidderName
+		expectedSyncs        map[string]string
+		expectedRespMessage  string
+		expectedResponseCode int
+	}{
+		{
+			description:          "Bidder Allowed",
+			uri:                  "/setuid?bidder=pubmatic&uid=123&gdpr=1&gdpr_consent=BONciguONcjGKADACHENAOLS1rAHDAFAAEAASABQAMwAeACEAFw",
+			prohibitedBidders:    []openrtb_ext.BidderName{"appnexus"},
+			expectedSyncs:        map[string]string{"pubmatic": "123"},
+			expectedResponseCode: http.StatusOK,
+		},
+		{
+			description:          "Bidder Prohibited",
+			uri:                  "/setuid?bidder=pubmatic&uid=123&gdpr=1&gdpr_consent=BONciguONcjGKADACHENAOLS1rAHDAFAAEAASABQAMwAeACEAFw",
+			prohibitedBidders:    []openrtb_ext.BidderName{"pubmatic"},
+			expectedRespMessage:  "The gdpr_consent string prevents the bidder from syncing",
+			expectedResponseCode: http.StatusOK,
+		},
+		{
+			description:          "Bidder Prohibited Without GDPR",
+			uri:                  "/setuid?bidder=pubmatic&uid=123&gdpr=0",
+			prohibitedBidders:    []openrtb_ext.BidderName{"pubmatic"},
+			expectedSyncs:        map[string]string{"pubmatic": "123"},
+			expectedResponseCode: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		perms := &mockPermsSetUID{allowHost: true, prohibitedBidders: test.prohibitedBidders}
+		syncers := map[openrtb_ext.BidderName]usersync.Usersyncer{"pubmatic": newFakeSyncer("pubmatic")}
+		cfg := config.Configuration{}
+		endpoint := NewSetUIDEndpoint(&cfg, syncers, perms, analyticsConf.NewPBSAnalytics(&cfg.Analytics), &metricsConf.DummyMetricsEngine{}, &mockAccountFetcher{})
+
+		response := httptest.NewRecorder()
+		endpoint(response, makeRequest(test.uri, nil), nil)
+
+		assert.Equal(t, test.expectedResponseCode, response.Code, test.description)
+		if test.expectedSyncs != nil {
+			assertHasSyncs(t, test.description, response, test.expectedSyncs)
+		} else {
+			assert.Equal(t, "", response.Header().Get("Set-Cookie"), test.description)
+		}
+		if test.expectedRespMessage != "" {
+			assert.Equal(t, test.expectedRespMessage, response.Body.String(), test.description)
+		}
+	}
+}
+
+func TestSetUIDEndpointActivityControl(t *testing.T) {
+	denyPubmatic := `{"privacy":{"allow_activities":{"sync_user":{"rules":[{"condition":{"component_name":["pubmatic"],"gpp_sid":[7]},"allow":false}]}}}}`
+
+	testCases := []struct {
+		description          string
+		uri                  string
+		expectedSyncs        map[string]string
+		expectedRespMessage  string
+		expectedResponseCode int
+	}{
+		{
+			description:          "No Account",
+			uri:                  "/setuid?bidder=pubmatic&uid=123&gdpr=0&gpp_sid=7",
+			expectedSyncs:        map[string]string{"pubmatic": "123"},
+			expectedResponseCode: http.StatusOK,
+		},
+		{
+			description:          "Account Allows The Bidder For Another GPP Section",
+			uri:                  "/setuid?bidder=pubmatic&uid=123&gdpr=0&account=deny_pubmatic&gpp_sid=2",
+			expectedSyncs:        map[string]string{"pubmatic": "123"},
+			expectedResponseCode: http.StatusOK,
+		},
+		{
+			description:          "Account Denies The Bidder",
+			uri:                  "/setuid?bidder=pubmatic&uid=123&gdpr=0&account=deny_pubmatic&gpp_sid=7",
+			expectedRespMessage:  "The account prevents the bidder from syncing",
+			expectedResponseCode: http.StatusOK,
+		},
+		{
+			description:          "Invalid GPP Section IDs",
+			uri:                  "/setuid?bidder=pubmatic&uid=123&gdpr=0&account=deny_pubmatic&gpp_sid=a",
+			expectedResponseCode: http.StatusBadRequest,
+		},
+		{
+			description:          "Disabled Account",
+			uri:                  "/setuid?bidder=pubmatic&uid=123&gdpr=0&account=disabled",
+			expectedResponseCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range testCases {
+		perms := &mockPermsSetUID{allowHost: true}
+		syncers := map[openrtb_ext.BidderName]usersync.Usersyncer{"pubmatic": newFakeSyncer("pubmatic")}
+		cfg := config.Configuration{}
+		cfg.MarshalAccountDefaults()
+		fetcher := &mockAccountFetcher{accounts: map[string]json.RawMessage{
+			"deny_pubmatic": json.RawMessage(denyPubmatic),
+			"disabled":      json.RawMessage(`{"disabled":true}`),
+		}}
+		endpoint := NewSetUIDEndpoint(&cfg, syncers, perms, analyticsConf.NewPBSAnalytics(&cfg.Analytics), &metricsConf.DummyMetricsEngine{}, fetcher)
+
+		response := httptest.NewRecorder()
+		endpoint(response, makeRequest(test.uri, nil), nil)
+
+		assert.Equal(t, test.expectedResponseCode, response.Code, test.description)
+		if test.expectedSyncs != nil {
+			assertHasSyncs(t, test.description, response, test.expectedSyncs)
+		} else {
+			assert.Equal(t, "", response.Header().Get("Set-Cookie"), test.description)
+		}
+		if test.expectedRespMessage != "" {
+			assert.Equal(t, test.expectedRespMessage, response.Body.String(), test.description)
+		}
+	}
+}
+
 func TestOptedOut(t *testing.T) {
 	request := httptest.NewRequest("GET", "/setuid?bidder=pubmatic&uid=123", nil)
 	cookie := usersync.NewPBSCookie()
@@ -397,7 +517,7 @@ func doRequest(req *http.Request, metrics metrics.MetricsEngine, validFamilyName
 		syncers[openrtb_ext.BidderName(name)] = newFakeSyncer(name)
 	}
 
-	endpoint := NewSetUIDEndpoint(cfg.HostCookie, syncers, perms, analytics, metrics)
+	endpoint := NewSetUIDEndpoint(&cfg, syncers, perms, analytics, metrics, &mockAccountFetcher{})
 	response := httptest.NewRecorder()
 	endpoint(response, req, nil)
 	return response
@@ -420,9 +540,10 @@ func parseCookieString(t *testing.T, response *httptest.ResponseRecorder) *users
 }
 
 type mockPermsSetUID struct {
-	allowHost bool
-	errorHost bool
-	allowPI   bool
+	allowHost         bool
+	errorHost         bool
+	allowPI           bool
+	prohibitedBidders []openrtb_ext.BidderName
 }
 
 func (g *mockPermsSetUID) HostCookiesAllowed(ctx context.Context, consent string) (bool, error) {
@@ -434,10 +555,15 @@ func (g *mockPermsSetUID) HostCookiesAllowed(ctx context.Context, consent string
 }
 
 func (g *mockPermsSetUID) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, consent string) (bool, error) {
-	return false, nil
+	for _, prohibitedBidder := range g.prohibitedBidders {
+		if bidder == prohibitedBidder {
+			return false, nil
+		}
+	}
+	return true, nil
 }
 
-func (g *mockPermsSetUID) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, consent string) (bool, bool, bool, error) {
+func (g *mockPermsSetUID) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, account *config.AccountGDPR, consent string) (bool, bool, bool, error) {
 	return g.allowPI, g.allowPI, g.allowPI, nil
 }
 
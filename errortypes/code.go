@@ -17,6 +17,21 @@ const (
 const (
 	UnknownWarningCode               = 10999
 	InvalidPrivacyConsentWarningCode = iota + 10000
+	BidderThrottledWarningCode
+)
+
+// Defines numeric codes for the reasons a bid was dropped or lost the auction, as reported in bidresponse.ext.prebid.seatnonbid.
+// They are the OpenRTB loss reason codes, so that they stay stable and mean the same to every bidder.
+const (
+	InternalErrorNonBidCode          = 1
+	InvalidBidResponseNonBidCode     = 3
+	BelowFloorNonBidCode             = 100
+	LostToHigherBidNonBidCode        = 102
+	LostToDealBidNonBidCode          = 103
+	CreativeFilteredNonBidCode       = 200
+	CreativeSizeNotAllowedNonBidCode = 203
+	CreativeNotSecureNonBidCode      = 207
+	CategoryExclusionNonBidCode      = 209
 )
 
 // Coder provides an error or warning code with severity.
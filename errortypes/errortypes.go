@@ -198,3 +198,21 @@ func (err *InvalidPrivacyConsent) Code() int {
 func (err *InvalidPrivacyConsent) Severity() Severity {
 	return SeverityWarning
 }
+
+// BidderThrottled is a warning for when traffic shaping skipped a bidder, or would have skipped it if
+// the request hadn't asked to bypass traffic shaping.
+type BidderThrottled struct {
+	Message string
+}
+
+func (err *BidderThrottled) Error() string {
+	return err.Message
+}
+
+func (err *BidderThrottled) Code() int {
+	return BidderThrottledWarningCode
+}
+
+func (err *BidderThrottled) Severity() Severity {
+	return SeverityWarning
+}
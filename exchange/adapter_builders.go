@@ -41,6 +41,7 @@ import (
 	"github.com/prebid/prebid-server/adapters/eplanning"
 	"github.com/prebid/prebid-server/adapters/gamma"
 	"github.com/prebid/prebid-server/adapters/gamoshi"
+	"github.com/prebid/prebid-server/adapters/generic"
 	"github.com/prebid/prebid-server/adapters/grid"
 	"github.com/prebid/prebid-server/adapters/gumgum"
 	"github.com/prebid/prebid-server/adapters/improvedigital"
@@ -139,6 +140,7 @@ func newAdapterBuilders() map[openrtb_ext.BidderName]adapters.Builder {
 		openrtb_ext.BidderEPlanning:        eplanning.Builder,
 		openrtb_ext.BidderGamma:            gamma.Builder,
 		openrtb_ext.BidderGamoshi:          gamoshi.Builder,
+		openrtb_ext.BidderGeneric:          generic.Builder,
 		openrtb_ext.BidderGrid:             grid.Builder,
 		openrtb_ext.BidderGumGum:           gumgum.Builder,
 		openrtb_ext.BidderImprovedigital:   improvedigital.Builder,
@@ -3,20 +3,23 @@ package exchange
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/prebid/prebid-server/metrics"
 
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/adapters/generic"
 	"github.com/prebid/prebid-server/adapters/lifestreet"
 	"github.com/prebid/prebid-server/adapters/pulsepoint"
+	"github.com/prebid/prebid-server/circuitbreaker"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
-func BuildAdapters(client *http.Client, cfg *config.Configuration, infos adapters.BidderInfos, me metrics.MetricsEngine) (map[openrtb_ext.BidderName]adaptedBidder, []error) {
+func BuildAdapters(client *http.Client, cfg *config.Configuration, infos adapters.BidderInfos, me metrics.MetricsEngine, breakers circuitbreaker.Breakers) (map[openrtb_ext.BidderName]adaptedBidder, []error) {
 	exchangeBidders := buildExchangeBiddersLegacy(cfg.Adapters, infos)
 
-	exchangeBiddersModern, errs := buildExchangeBidders(cfg, infos, client, me)
+	exchangeBiddersModern, errs := buildExchangeBidders(cfg, infos, client, me, breakers)
 	if len(errs) > 0 {
 		return nil, errs
 	}
@@ -26,12 +29,12 @@ func BuildAdapters(client *http.Client, cfg *config.Configuration, infos adapter
 		exchangeBidders[bidderName] = bidder
 	}
 
-	wrapWithMiddleware(exchangeBidders)
+	wrapWithMiddleware(exchangeBidders, cfg.Adapters, cfg.TrafficShaping)
 
 	return exchangeBidders, nil
 }
 
-func buildExchangeBidders(cfg *config.Configuration, infos adapters.BidderInfos, client *http.Client, me metrics.MetricsEngine) (map[openrtb_ext.BidderName]adaptedBidder, []error) {
+func buildExchangeBidders(cfg *config.Configuration, infos adapters.BidderInfos, client *http.Client, me metrics.MetricsEngine, breakers circuitbreaker.Breakers) (map[openrtb_ext.BidderName]adaptedBidder, []error) {
 	bidders, errs := buildBidders(cfg.Adapters, infos, newAdapterBuilders())
 	if len(errs) > 0 {
 		return nil, errs
@@ -39,23 +42,44 @@ func buildExchangeBidders(cfg *config.Configuration, infos adapters.BidderInfos,
 
 	exchangeBidders := make(map[openrtb_ext.BidderName]adaptedBidder, len(bidders))
 	for bidderName, bidder := range bidders {
-		exchangeBidders[bidderName] = adaptBidder(bidder, client, cfg, me, bidderName)
+		adapted := adaptBidder(bidder, client, cfg, me, bidderName)
+		adapted.(*bidderAdapter).breaker = breakers[bidderName]
+		adapted.(*bidderAdapter).config.EndpointCompression = endpointCompression(cfg.Adapters, infos, bidderName)
+		exchangeBidders[bidderName] = adapted
 	}
 
 	return exchangeBidders, nil
 
 }
 
+// endpointCompression returns the compression of the request bodies sent to the bidder, which the host configuration
+// can set to override its bidder info.
+func endpointCompression(adapterConfig map[string]config.Adapter, infos adapters.BidderInfos, bidderName openrtb_ext.BidderName) string {
+	if compression := adapterConfig[strings.ToLower(string(bidderName))].EndpointCompression; compression != "" {
+		return compression
+	}
+	return infos[string(bidderName)].EndpointCompression
+}
+
 func buildBidders(adapterConfig map[string]config.Adapter, infos adapters.BidderInfos, builders map[openrtb_ext.BidderName]adapters.Builder) (map[openrtb_ext.BidderName]adapters.Bidder, []error) {
 	bidders := make(map[openrtb_ext.BidderName]adapters.Bidder)
 	var errs []error
 
 	for bidder, cfg := range adapterConfig {
-		bidderName, bidderNameFound := openrtb_ext.NormalizeBidderName(bidder)
+		// The aliases defined in the host configuration are built with the builder of their parent.
+		coreBidder := bidder
+		if cfg.AliasOf != "" {
+			coreBidder = cfg.AliasOf
+		}
+		coreBidderName, bidderNameFound := openrtb_ext.NormalizeBidderName(coreBidder)
 		if !bidderNameFound {
-			errs = append(errs, fmt.Errorf("%v: unknown bidder", bidder))
+			errs = append(errs, fmt.Errorf("%v: unknown bidder", coreBidder))
 			continue
 		}
+		bidderName := coreBidderName
+		if cfg.AliasOf != "" {
+			bidderName = openrtb_ext.BidderName(strings.ToLower(bidder))
+		}
 
 		// Ignore Legacy Bidders
 		if bidderName == openrtb_ext.BidderLifestreet || bidderName == openrtb_ext.BidderPulsepoint {
@@ -68,7 +92,12 @@ func buildBidders(adapterConfig map[string]config.Adapter, infos adapters.Bidder
 			continue
 		}
 
-		builder, builderFound := builders[bidderName]
+		builder, builderFound := builders[coreBidderName]
+		// The bidders described by the generic section of their info are built by the generic OpenRTB adapter,
+		// so that they don't need any code of their own.
+		if info.Generic != nil {
+			builder, builderFound = generic.NewBuilder(*info.Generic), true
+		}
 		if !builderFound {
 			errs = append(errs, fmt.Errorf("%v: builder not registered", bidder))
 			continue
@@ -108,9 +137,16 @@ func buildExchangeBiddersLegacy(adapterConfig map[string]config.Adapter, infos a
 	return bidders
 }
 
-func wrapWithMiddleware(bidders map[openrtb_ext.BidderName]adaptedBidder) {
+func wrapWithMiddleware(bidders map[openrtb_ext.BidderName]adaptedBidder, adapterConfig map[string]config.Adapter, trafficShaping config.TrafficShaping) {
 	for name, bidder := range bidders {
 		bidders[name] = addValidatedBidderMiddleware(bidder)
+		if trafficShaping.Enabled {
+			bidders[name] = addTrafficShapingMiddleware(bidders[name], trafficShaping)
+		}
+		// The bid cache must stay the outermost middleware, as the exchange tells it which bids won.
+		if bidCache := adapterConfig[strings.ToLower(string(name))].BidCache; bidCache.Enabled {
+			bidders[name] = addBidCacheMiddleware(bidders[name], bidCache)
+		}
 	}
 }
 
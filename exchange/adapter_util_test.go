@@ -37,7 +37,7 @@ func TestBuildAdaptersSuccess(t *testing.T) {
 	}
 	metricEngine := &metrics.DummyMetricsEngine{}
 
-	bidders, errs := BuildAdapters(client, cfg, infos, metricEngine)
+	bidders, errs := BuildAdapters(client, cfg, infos, metricEngine, nil)
 
 	appnexusBidder, _ := appnexus.Builder(openrtb_ext.BidderAppnexus, config.Adapter{})
 	appnexusBidderWithInfo := adapters.EnforceBidderInfo(appnexusBidder, infoActive)
@@ -62,7 +62,7 @@ func TestBuildAdaptersErrors(t *testing.T) {
 	infos := map[string]adapters.BidderInfo{}
 	metricEngine := &metrics.DummyMetricsEngine{}
 
-	bidders, errs := BuildAdapters(client, cfg, infos, metricEngine)
+	bidders, errs := BuildAdapters(client, cfg, infos, metricEngine, nil)
 
 	expectedErrors := []error{
 		errors.New("unknown: unknown bidder"),
@@ -127,7 +127,7 @@ func TestBuildExchangeBidders(t *testing.T) {
 
 	for _, test := range testCases {
 		cfg := &config.Configuration{Adapters: test.adapterConfig}
-		bidders, errs := buildExchangeBidders(cfg, test.bidderInfos, client, metricEngine)
+		bidders, errs := buildExchangeBidders(cfg, test.bidderInfos, client, metricEngine, nil)
 		assert.Equal(t, test.expectedBidders, bidders, test.description+":bidders")
 		assert.ElementsMatch(t, test.expectedErrors, errs, test.description+":errors")
 	}
@@ -248,6 +248,36 @@ func TestBuildBidders(t *testing.T) {
 				openrtb_ext.BidderAppnexus: adapters.EnforceBidderInfo(appnexusBidder, infoActive),
 			},
 		},
+		{
+			description:   "Success - Alias",
+			adapterConfig: map[string]config.Adapter{"appnexus": {}, "mybrand": {AliasOf: "appnexus"}},
+			bidderInfos:   map[string]adapters.BidderInfo{"appnexus": infoDisabled, "mybrand": infoActive},
+			builders:      map[openrtb_ext.BidderName]adapters.Builder{openrtb_ext.BidderAppnexus: appnexusBuilder},
+			expectedBidders: map[openrtb_ext.BidderName]adapters.Bidder{
+				openrtb_ext.BidderName("mybrand"): adapters.EnforceBidderInfo(appnexusBidder, infoActive),
+			},
+		},
+		{
+			description:   "Generic - Built From Bidder Info",
+			adapterConfig: map[string]config.Adapter{"mybrand": {AliasOf: "generic", Endpoint: "http://example.com/bid"}},
+			bidderInfos: map[string]adapters.BidderInfo{"mybrand": {
+				Status:  adapters.StatusActive,
+				Generic: &adapters.GenericInfo{EndpointMacros: map[string]string{"Unknown": "key"}},
+			}},
+			builders: map[openrtb_ext.BidderName]adapters.Builder{openrtb_ext.BidderGeneric: appnexusBuilder},
+			expectedErrors: []error{
+				errors.New("mybrand: unknown endpoint macro Unknown"),
+			},
+		},
+		{
+			description:   "Invalid - Alias Of Unknown Bidder",
+			adapterConfig: map[string]config.Adapter{"mybrand": {AliasOf: "unknown"}},
+			bidderInfos:   map[string]adapters.BidderInfo{"mybrand": infoActive},
+			builders:      map[openrtb_ext.BidderName]adapters.Builder{openrtb_ext.BidderAppnexus: appnexusBuilder},
+			expectedErrors: []error{
+				errors.New("unknown: unknown bidder"),
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -314,7 +344,7 @@ func TestWrapWithMiddleware(t *testing.T) {
 		openrtb_ext.BidderAppnexus: appNexusBidder,
 	}
 
-	wrapWithMiddleware(bidders)
+	wrapWithMiddleware(bidders, nil, config.TrafficShaping{})
 
 	expected := map[openrtb_ext.BidderName]adaptedBidder{
 		openrtb_ext.BidderAppnexus: &validatedBidder{appNexusBidder},
@@ -323,6 +353,42 @@ func TestWrapWithMiddleware(t *testing.T) {
 	assert.Equal(t, expected, bidders)
 }
 
+func TestWrapWithTrafficShapingMiddleware(t *testing.T) {
+	appNexusBidder := fakeAdaptedBidder{}
+
+	bidders := map[openrtb_ext.BidderName]adaptedBidder{
+		openrtb_ext.BidderAppnexus: appNexusBidder,
+	}
+	trafficShaping := config.TrafficShaping{Enabled: true, MinSamples: 10, WindowSize: 100}
+
+	wrapWithMiddleware(bidders, nil, trafficShaping)
+
+	shaped, ok := bidders[openrtb_ext.BidderAppnexus].(*shapedBidder)
+	if assert.True(t, ok, "Traffic shaping should wrap the validated bidder") {
+		assert.Equal(t, &validatedBidder{appNexusBidder}, shaped.bidder)
+		assert.Equal(t, trafficShaping, shaped.config)
+	}
+}
+
+func TestWrapWithBidCacheMiddleware(t *testing.T) {
+	appNexusBidder := fakeAdaptedBidder{}
+
+	bidders := map[openrtb_ext.BidderName]adaptedBidder{
+		openrtb_ext.BidderAppnexus: appNexusBidder,
+	}
+	bidCache := config.BidCache{Enabled: true, TTLSeconds: 30, MaxEntries: 100}
+	adapterConfig := map[string]config.Adapter{"appnexus": {BidCache: bidCache}}
+	trafficShaping := config.TrafficShaping{Enabled: true, MinSamples: 10, WindowSize: 100}
+
+	wrapWithMiddleware(bidders, adapterConfig, trafficShaping)
+
+	cached, ok := bidders[openrtb_ext.BidderAppnexus].(*cachedBidder)
+	if assert.True(t, ok, "The bid cache should wrap the other middleware") {
+		assert.IsType(t, &shapedBidder{}, cached.bidder)
+		assert.Equal(t, bidCache, cached.config)
+	}
+}
+
 func TestGetActiveBidders(t *testing.T) {
 	testCases := []struct {
 		description string
@@ -429,3 +495,39 @@ type fakeBuilder struct {
 func (b fakeBuilder) Builder(name openrtb_ext.BidderName, cfg config.Adapter) (adapters.Bidder, error) {
 	return b.bidder, b.err
 }
+
+func TestEndpointCompressionOverride(t *testing.T) {
+	testCases := []struct {
+		description         string
+		adapterConfig       map[string]config.Adapter
+		infoCompression     string
+		expectedCompression string
+	}{
+		{
+			description:         "Bidder Info",
+			adapterConfig:       map[string]config.Adapter{"appnexus": {}},
+			infoCompression:     "gzip",
+			expectedCompression: "gzip",
+		},
+		{
+			description:         "Host Opt In",
+			adapterConfig:       map[string]config.Adapter{"appnexus": {EndpointCompression: "gzip"}},
+			infoCompression:     "",
+			expectedCompression: "gzip",
+		},
+		{
+			description:         "Host Opt Out",
+			adapterConfig:       map[string]config.Adapter{"appnexus": {EndpointCompression: "none"}},
+			infoCompression:     "gzip",
+			expectedCompression: "none",
+		},
+	}
+
+	for _, test := range testCases {
+		infos := adapters.BidderInfos{"appnexus": {EndpointCompression: test.infoCompression}}
+
+		compression := endpointCompression(test.adapterConfig, infos, openrtb_ext.BidderAppnexus)
+
+		assert.Equal(t, test.expectedCompression, compression, test.description)
+	}
+}
@@ -102,7 +102,7 @@ func newAuction(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, numImps int
 			for _, bid := range seatBid.bids {
 				cpm := bid.bid.Price
 				wbid, ok := winningBids[bid.bid.ImpID]
-				if !ok || isNewWinningBid(bid.bid, wbid.bid, preferDeals) {
+				if !ok || isNewWinningBid(bid, wbid, preferDeals) {
 					winningBids[bid.bid.ImpID] = bid
 				}
 				if bidMap, ok := winningBidsByBidder[bid.bid.ImpID]; ok {
@@ -125,16 +125,20 @@ func newAuction(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, numImps int
 }
 
 // isNewWinningBid calculates if the new bid (nbid) will win against the current winning bid (wbid) given preferDeals.
-func isNewWinningBid(bid, wbid *openrtb.Bid, preferDeals bool) bool {
+// When deals are preferred, a deal beats any bid without one, and a deal of a higher priority beats a deal of a lower priority.
+func isNewWinningBid(bid, wbid *pbsOrtbBid, preferDeals bool) bool {
 	if preferDeals {
-		if len(wbid.DealID) > 0 && len(bid.DealID) == 0 {
+		if len(wbid.bid.DealID) > 0 && len(bid.bid.DealID) == 0 {
 			return false
 		}
-		if len(wbid.DealID) == 0 && len(bid.DealID) > 0 {
+		if len(wbid.bid.DealID) == 0 && len(bid.bid.DealID) > 0 {
 			return true
 		}
+		if len(bid.bid.DealID) > 0 && bid.dealPriority != wbid.dealPriority {
+			return bid.dealPriority > wbid.dealPriority
+		}
 	}
-	return bid.Price > wbid.Price
+	return bid.bid.Price > wbid.bid.Price
 }
 
 func (a *auction) setRoundedPrices(priceGranularity openrtb_ext.PriceGranularity) {
@@ -347,7 +351,10 @@ type auction struct {
 	// winningBids is a map from imp.id to the highest overall CPM bid in that imp.
 	winningBids map[string]*pbsOrtbBid
 	// winningBidsByBidder stores the highest bid on each imp by each bidder.
+	// With multibid, the runner-up bids of a bidder are stored under their targeting bidder code.
 	winningBidsByBidder map[string]map[openrtb_ext.BidderName]*pbsOrtbBid
+	// multiBidSeats maps the targeting bidder codes of multibid runner-up bids to the bidder which made them.
+	multiBidSeats map[openrtb_ext.BidderName]openrtb_ext.BidderName
 	// roundedPrices stores the price strings rounded for each bid according to the price granularity.
 	roundedPrices map[*pbsOrtbBid]string
 	// cacheIds stores the UUIDs from Prebid Cache for fetching the full bid JSON.
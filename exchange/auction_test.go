@@ -313,6 +313,14 @@ func TestNewAuction(t *testing.T) {
 			DealID: "BigDeal",
 		},
 	}
+	bid1p050d5 := pbsOrtbBid{
+		bid: &openrtb.Bid{
+			ImpID:  "imp1",
+			Price:  0.50,
+			DealID: "PriorityDeal",
+		},
+		dealPriority: 5,
+	}
 	bid2p123 := pbsOrtbBid{
 		bid: &openrtb.Bid{
 			ImpID: "imp2",
@@ -489,6 +497,54 @@ func TestNewAuction(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "Auction with deals of different priorities, prefer deals",
+			seatBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {
+					bids: []*pbsOrtbBid{&bid1p166d},
+				},
+				"rubicon": {
+					bids: []*pbsOrtbBid{&bid1p050d5},
+				},
+			},
+			numImps:     1,
+			preferDeals: true,
+			expectedAuction: auction{
+				winningBids: map[string]*pbsOrtbBid{
+					"imp1": &bid1p050d5,
+				},
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+					"imp1": {
+						"appnexus": &bid1p166d,
+						"rubicon":  &bid1p050d5,
+					},
+				},
+			},
+		},
+		{
+			description: "Auction with deals of different priorities, no preference",
+			seatBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {
+					bids: []*pbsOrtbBid{&bid1p166d},
+				},
+				"rubicon": {
+					bids: []*pbsOrtbBid{&bid1p050d5},
+				},
+			},
+			numImps:     1,
+			preferDeals: false,
+			expectedAuction: auction{
+				winningBids: map[string]*pbsOrtbBid{
+					"imp1": &bid1p166d,
+				},
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+					"imp1": {
+						"appnexus": &bid1p166d,
+						"rubicon":  &bid1p050d5,
+					},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -2,14 +2,17 @@ package exchange
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptrace"
+	"strings"
 	"time"
 
 	"github.com/golang/glog"
@@ -20,6 +23,7 @@ import (
 	nativeRequests "github.com/mxmCherry/openrtb/native/request"
 	nativeResponse "github.com/mxmCherry/openrtb/native/response"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/circuitbreaker"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/metrics"
@@ -67,6 +71,8 @@ type pbsOrtbBid struct {
 	bidVideo          *openrtb_ext.ExtBidPrebidVideo
 	dealPriority      int
 	dealTierSatisfied bool
+	clearingPrice     float64
+	reused            bool
 }
 
 // pbsOrtbSeatBid is a SeatBid returned by an adaptedBidder.
@@ -85,6 +91,9 @@ type pbsOrtbSeatBid struct {
 	// if len(bids) > 0, this will become response.seatbid[i].ext.{bidder} on the final OpenRTB response.
 	// if len(bids) == 0, this will be ignored because the OpenRTB spec doesn't allow a SeatBid with 0 Bids.
 	ext json.RawMessage
+	// nonBids are the bids which were dropped, or which lost the auction.
+	// These will become response.ext.prebid.seatnonbid if the request asks for them.
+	nonBids []nonBid
 }
 
 // adaptBidder converts an adapters.Bidder into an exchange.adaptedBidder.
@@ -110,11 +119,14 @@ type bidderAdapter struct {
 	Client     *http.Client
 	me         metrics.MetricsEngine
 	config     bidderAdapterConfig
+	breaker    *circuitbreaker.Breaker
 }
 
 type bidderAdapterConfig struct {
 	Debug              config.Debug
 	DisableConnMetrics bool
+	// EndpointCompression is the compression of the request bodies accepted by the bidder, from its bidder info.
+	EndpointCompression string
 }
 
 func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo) (*pbsOrtbSeatBid, []error) {
@@ -217,6 +229,9 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb.Bi
 				} else {
 					// If no conversions found, do not handle the bid
 					errs = append(errs, err)
+					for i := 0; i < len(bidResponse.Bids); i++ {
+						seatBid.addNonBid(bidResponse.Bids[i].Bid, errortypes.InternalErrorNonBidCode)
+					}
 				}
 			}
 		} else {
@@ -330,14 +345,49 @@ func (bidder *bidderAdapter) doRequest(ctx context.Context, req *adapters.Reques
 }
 
 func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.RequestData, logger util.LogMsg) *httpCallInfo {
-	httpReq, err := http.NewRequest(req.Method, req.Uri, bytes.NewBuffer(req.Body))
+	if !bidder.breaker.Allow() {
+		return &httpCallInfo{
+			request: req,
+			err: &errortypes.BidderTemporarilyDisabled{
+				Message: fmt.Sprintf("Bidder %s was not called because its circuit breaker is open", bidder.BidderName),
+			},
+		}
+	}
+
+	body, headers := req.Body, req.Headers
+	if strings.EqualFold(bidder.config.EndpointCompression, adapters.EndpointCompressionGZIP) {
+		// The request is left as it is, as it's reported in the debug output along with its uncompressed body.
+		headers = cloneHeaders(req.Headers)
+		if len(req.Body) > 0 {
+			compressed, err := compressGZIP(req.Body)
+			if err != nil {
+				bidder.breaker.RecordCanceled()
+				return &httpCallInfo{
+					request: req,
+					err:     err,
+				}
+			}
+			body = compressed
+			headers.Set("Content-Encoding", "gzip")
+			if bytesSaved := len(req.Body) - len(compressed); bytesSaved > 0 {
+				bidder.me.RecordAdapterGZIPBytesSaved(bidder.BidderName, metrics.CompressionRequest, int64(bytesSaved))
+			}
+		}
+		// The gzipped responses are then decompressed below rather than by the HTTP client, so that the bytes saved are known.
+		if headers.Get("Accept-Encoding") == "" {
+			headers.Set("Accept-Encoding", "gzip")
+		}
+	}
+
+	httpReq, err := http.NewRequest(req.Method, req.Uri, bytes.NewBuffer(body))
 	if err != nil {
+		bidder.breaker.RecordCanceled()
 		return &httpCallInfo{
 			request: req,
 			err:     err,
 		}
 	}
-	httpReq.Header = req.Headers
+	httpReq.Header = headers
 
 	// If adapter connection metrics are not disabled, add the client trace
 	// to get complete connection info into our metrics
@@ -346,6 +396,7 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 	}
 	httpResp, err := ctxhttp.Do(ctx, bidder.Client, httpReq)
 	if err != nil {
+		bidder.recordBreakerFailure(ctx)
 		if err == context.DeadlineExceeded {
 			err = &errortypes.Timeout{Message: err.Error()}
 			var corebidder adapters.Bidder = bidder.Bidder
@@ -371,6 +422,7 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 
 	respBody, err := ioutil.ReadAll(httpResp.Body)
 	if err != nil {
+		bidder.recordBreakerFailure(ctx)
 		return &httpCallInfo{
 			request: req,
 			err:     err,
@@ -378,6 +430,32 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 	}
 	defer httpResp.Body.Close()
 
+	// The HTTP client only decompresses the responses itself if the request didn't set its own Accept-Encoding.
+	if strings.EqualFold(httpResp.Header.Get("Content-Encoding"), "gzip") {
+		decompressed, err := decompressGZIP(respBody)
+		if err != nil {
+			bidder.breaker.RecordError()
+			return &httpCallInfo{
+				request: req,
+				err: &errortypes.BadServerResponse{
+					Message: fmt.Sprintf("The gzipped response could not be decompressed: %v", err),
+				},
+			}
+		}
+		if bytesSaved := len(decompressed) - len(respBody); bytesSaved > 0 {
+			bidder.me.RecordAdapterGZIPBytesSaved(bidder.BidderName, metrics.CompressionResponse, int64(bytesSaved))
+		}
+		respBody = decompressed
+		httpResp.Header.Del("Content-Encoding")
+	}
+
+	// 4xx responses are caused by the request we sent, so they don't mean that the bidder endpoint is failing
+	if httpResp.StatusCode >= 500 {
+		bidder.breaker.RecordError()
+	} else {
+		bidder.breaker.RecordSuccess()
+	}
+
 	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 400 {
 		err = &errortypes.BadServerResponse{
 			Message: fmt.Sprintf("Server responded with failure status: %d. Set request.test = 1 for debugging info.", httpResp.StatusCode),
@@ -395,6 +473,61 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 	}
 }
 
+func compressGZIP(body []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
+
+// maxDecompressedResponseSize caps the size of a gzipped bidder response once decompressed, so that a small response
+// can't expand without bound in memory.
+const maxDecompressedResponseSize = 20 * 1024 * 1024
+
+func decompressGZIP(body []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	// Read one more byte than allowed, to tell the responses which are too large apart.
+	decompressed, err := ioutil.ReadAll(io.LimitReader(reader, maxDecompressedResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(decompressed) > maxDecompressedResponseSize {
+		return nil, fmt.Errorf("the response exceeds %d bytes once decompressed", maxDecompressedResponseSize)
+	}
+	return decompressed, nil
+}
+
+func cloneHeaders(headers http.Header) http.Header {
+	cloned := make(http.Header, len(headers)+1)
+	for name, values := range headers {
+		cloned[name] = append([]string(nil), values...)
+	}
+	return cloned
+}
+
+// recordBreakerFailure records a failed call to the bidder endpoint in its circuit breaker. Only the calls which
+// failed on their own or ran out of time count against the bidder. The calls canceled by the exchange, at the soft
+// deadline of the auction, don't.
+func (bidder *bidderAdapter) recordBreakerFailure(ctx context.Context) {
+	switch ctx.Err() {
+	case nil:
+		bidder.breaker.RecordError()
+	case context.DeadlineExceeded:
+		bidder.breaker.RecordTimeout()
+	default:
+		bidder.breaker.RecordCanceled()
+	}
+}
+
 func (bidder *bidderAdapter) doTimeoutNotification(timeoutBidder adapters.TimeoutBidder, req *adapters.RequestData, logger util.LogMsg) {
 	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
 	defer cancel()
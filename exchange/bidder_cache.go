@@ -0,0 +1,222 @@
+package exchange
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// addBidCacheMiddleware returns a bidder which keeps the bids of the argument bidder, and returns them again instead of
+// calling it when an identical request is made before they expire. The exchange drops the bids which win an auction
+// from the cache, so that an ad is never served twice.
+//
+// The reused bids are marked in bidresponse.seatbid[i].bid[j].ext.prebid.reused. Test requests always call the bidder.
+func addBidCacheMiddleware(bidder adaptedBidder, cfg config.BidCache) adaptedBidder {
+	return &cachedBidder{
+		bidder:  bidder,
+		config:  cfg,
+		clock:   time.Now,
+		entries: make(map[string]*bidCacheEntry),
+	}
+}
+
+type cachedBidder struct {
+	bidder adaptedBidder
+	config config.BidCache
+	clock  func() time.Time
+
+	mutex   sync.Mutex
+	entries map[string]*bidCacheEntry
+}
+
+type bidCacheEntry struct {
+	currency string
+	bids     []cachedBid
+}
+
+type cachedBid struct {
+	bid     pbsOrtbBid
+	expires time.Time
+}
+
+func (c *cachedBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo) (*pbsOrtbSeatBid, []error) {
+	if request.Test == 1 {
+		return c.bidder.requestBid(ctx, request, name, bidAdjustment, conversions, reqInfo)
+	}
+
+	key, err := requestFingerprint(request, name, bidAdjustment)
+	if err != nil {
+		return c.bidder.requestBid(ctx, request, name, bidAdjustment, conversions, reqInfo)
+	}
+	if seatBid := c.reuse(key); seatBid != nil {
+		return seatBid, nil
+	}
+
+	seatBid, errs := c.bidder.requestBid(ctx, request, name, bidAdjustment, conversions, reqInfo)
+	if seatBid != nil && len(seatBid.bids) > 0 {
+		c.store(key, seatBid)
+	}
+	return seatBid, errs
+}
+
+// reuse returns a copy of the unexpired bids cached for the request, or nil if there are none.
+func (c *cachedBidder) reuse(key string) *pbsOrtbSeatBid {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	now := c.clock()
+	entry.bids = unexpiredBids(entry.bids, now)
+	if len(entry.bids) == 0 {
+		delete(c.entries, key)
+		return nil
+	}
+
+	seatBid := &pbsOrtbSeatBid{
+		bids:     make([]*pbsOrtbBid, 0, len(entry.bids)),
+		currency: entry.currency,
+	}
+	for _, cached := range entry.bids {
+		bid := copyBid(cached.bid)
+		bid.reused = true
+		seatBid.bids = append(seatBid.bids, bid)
+	}
+	return seatBid
+}
+
+func (c *cachedBidder) store(key string, seatBid *pbsOrtbSeatBid) {
+	now := c.clock()
+	ttl := time.Duration(c.config.TTLSeconds) * time.Second
+	entry := &bidCacheEntry{
+		currency: seatBid.currency,
+		bids:     make([]cachedBid, 0, len(seatBid.bids)),
+	}
+	for _, bid := range seatBid.bids {
+		bidTTL := ttl
+		if exp := time.Duration(bid.bid.Exp) * time.Second; exp > 0 && exp < bidTTL {
+			bidTTL = exp
+		}
+		entry.bids = append(entry.bids, cachedBid{bid: *copyBid(*bid), expires: now.Add(bidTTL)})
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.config.MaxEntries {
+		c.removeExpired(now)
+		if len(c.entries) >= c.config.MaxEntries {
+			return
+		}
+	}
+	c.entries[key] = entry
+}
+
+// markWon drops the bid from the cache, as an ad which won an auction must not be served again.
+func (c *cachedBidder) markWon(bid *openrtb.Bid) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for key, entry := range c.entries {
+		for i, cached := range entry.bids {
+			if cached.bid.bid.ID == bid.ID && cached.bid.bid.ImpID == bid.ImpID {
+				entry.bids = append(entry.bids[:i], entry.bids[i+1:]...)
+				break
+			}
+		}
+		if len(entry.bids) == 0 {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *cachedBidder) removeExpired(now time.Time) {
+	for key, entry := range c.entries {
+		entry.bids = unexpiredBids(entry.bids, now)
+		if len(entry.bids) == 0 {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func unexpiredBids(bids []cachedBid, now time.Time) []cachedBid {
+	unexpired := bids[:0]
+	for _, cached := range bids {
+		if now.Before(cached.expires) {
+			unexpired = append(unexpired, cached)
+		}
+	}
+	return unexpired
+}
+
+// copyBid copies the fields of the bid which the exchange changes during the auction.
+func copyBid(bid pbsOrtbBid) *pbsOrtbBid {
+	ortbBid := *bid.bid
+	bid.bid = &ortbBid
+	if bid.bidVideo != nil {
+		bidVideo := *bid.bidVideo
+		bid.bidVideo = &bidVideo
+	}
+	return &bid
+}
+
+// requestFingerprint identifies the requests which get the same bids: the ones sent under the same bidder name, with
+// the same imps, user, device, privacy signals and bid adjustment. The name tells an alias apart from the bidder it
+// aliases, since both share this cache. The IDs which change on every request, such as the request ID, are ignored.
+func requestFingerprint(request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64) (string, error) {
+	fingerprinted := *request
+	fingerprinted.ID = ""
+	fingerprinted.TMax = 0
+	if request.Source != nil {
+		source := *request.Source
+		source.TID = ""
+		fingerprinted.Source = &source
+	}
+
+	requestJSON, err := json.Marshal(struct {
+		Bidder        string              `json:"bidder"`
+		Request       *openrtb.BidRequest `json:"request"`
+		BidAdjustment float64             `json:"bidadjustment"`
+	}{string(name), &fingerprinted, bidAdjustment})
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(requestJSON)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// recordWonBids drops the winning bids of the auction from the caches of the bidders. Without targeting the
+// exchange doesn't pick the winners, so the highest bid of each imp is taken as the winner.
+func (e *exchange) recordWonBids(auc *auction, adapterBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, numImps int, aliases map[string]string) {
+	cachedBidders := make(map[openrtb_ext.BidderName]*cachedBidder)
+	for bidderName := range adapterBids {
+		if cached, ok := e.adapterMap[resolveBidder(bidderName.String(), aliases)].(*cachedBidder); ok {
+			cachedBidders[bidderName] = cached
+		}
+	}
+	if len(cachedBidders) == 0 {
+		return
+	}
+
+	if auc == nil {
+		auc = newAuction(adapterBids, numImps, false)
+	}
+	for impID, bidsByBidder := range auc.winningBidsByBidder {
+		for bidderName, bid := range bidsByBidder {
+			if cached, ok := cachedBidders[bidderName]; ok && bid == auc.winningBids[impID] {
+				cached.markWon(bid.bid)
+			}
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCachedBidderForTest(bidder adaptedBidder, now *time.Time) *cachedBidder {
+	cached := addBidCacheMiddleware(bidder, config.BidCache{Enabled: true, TTLSeconds: 30, MaxEntries: 2}).(*cachedBidder)
+	cached.clock = func() time.Time { return *now }
+	return cached
+}
+
+func cacheTestRequest(id string, user string) *openrtb.BidRequest {
+	return &openrtb.BidRequest{
+		ID:     id,
+		App:    &openrtb.App{ID: "app1"},
+		Device: &openrtb.Device{IFA: "device1"},
+		User:   &openrtb.User{ID: user},
+		Imp:    []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{}}},
+		Source: &openrtb.Source{TID: id},
+	}
+}
+
+func cacheTestSeatBid(exp int64) *pbsOrtbSeatBid {
+	return &pbsOrtbSeatBid{
+		bids:     []*pbsOrtbBid{{bid: &openrtb.Bid{ID: "bid1", ImpID: "imp1", Price: 2, Exp: exp}, bidType: openrtb_ext.BidTypeBanner}},
+		currency: "USD",
+	}
+}
+
+func requestCachedBid(cached *cachedBidder, request *openrtb.BidRequest) *pbsOrtbSeatBid {
+	seatBid, _ := cached.requestBid(context.Background(), request, "appnexus", 1, currency.NewConstantRates(), &adapters.ExtraRequestInfo{})
+	return seatBid
+}
+
+func TestBidCacheReusesBids(t *testing.T) {
+	now := time.Now()
+	bidder := &countingBidder{seatBid: cacheTestSeatBid(0)}
+	cached := newCachedBidderForTest(bidder, &now)
+
+	first := requestCachedBid(cached, cacheTestRequest("req1", "user1"))
+	assert.Equal(t, 1, bidder.calls, "The bidder should be called on the first request")
+	assert.False(t, first.bids[0].reused, "A new bid shouldn't be marked as reused")
+
+	// The exchange changes the price of the bids it gets during the auction.
+	first.bids[0].bid.Price = 1
+
+	second := requestCachedBid(cached, cacheTestRequest("req2", "user1"))
+	assert.Equal(t, 1, bidder.calls, "The bidder shouldn't be called for an identical request")
+	if assert.Len(t, second.bids, 1) {
+		assert.True(t, second.bids[0].reused, "The cached bid should be marked as reused")
+		assert.Equal(t, 2.0, second.bids[0].bid.Price, "The cached bid should keep the price of the bidder")
+		assert.Equal(t, "USD", second.currency)
+	}
+
+	requestCachedBid(cached, cacheTestRequest("req3", "user2"))
+	assert.Equal(t, 2, bidder.calls, "The bidder should be called for another user")
+}
+
+func TestBidCacheExpiry(t *testing.T) {
+	testCases := []struct {
+		description   string
+		exp           int64
+		elapsed       time.Duration
+		expectedCalls int
+	}{
+		{
+			description:   "Within TTL",
+			elapsed:       29 * time.Second,
+			expectedCalls: 1,
+		},
+		{
+			description:   "After TTL",
+			elapsed:       30 * time.Second,
+			expectedCalls: 2,
+		},
+		{
+			description:   "Within Bid Exp",
+			exp:           10,
+			elapsed:       9 * time.Second,
+			expectedCalls: 1,
+		},
+		{
+			description:   "After Bid Exp",
+			exp:           10,
+			elapsed:       10 * time.Second,
+			expectedCalls: 2,
+		},
+		{
+			description:   "Bid Exp Longer Than TTL",
+			exp:           300,
+			elapsed:       30 * time.Second,
+			expectedCalls: 2,
+		},
+	}
+
+	for _, test := range testCases {
+		now := time.Now()
+		bidder := &countingBidder{seatBid: cacheTestSeatBid(test.exp)}
+		cached := newCachedBidderForTest(bidder, &now)
+
+		requestCachedBid(cached, cacheTestRequest("req1", "user1"))
+		now = now.Add(test.elapsed)
+		requestCachedBid(cached, cacheTestRequest("req2", "user1"))
+
+		assert.Equal(t, test.expectedCalls, bidder.calls, test.description)
+	}
+}
+
+func TestBidCacheKeysByBidderName(t *testing.T) {
+	now := time.Now()
+	bidder := &countingBidder{seatBid: cacheTestSeatBid(0)}
+	cached := newCachedBidderForTest(bidder, &now)
+
+	requestCachedBid(cached, cacheTestRequest("req1", "user1"))
+	seatBid, _ := cached.requestBid(context.Background(), cacheTestRequest("req2", "user1"), "appnexusAlias", 1, currency.NewConstantRates(), &adapters.ExtraRequestInfo{})
+
+	assert.Equal(t, 2, bidder.calls, "An alias shouldn't reuse the bids of the bidder it aliases")
+	if assert.Len(t, seatBid.bids, 1) {
+		assert.False(t, seatBid.bids[0].reused, "The bid of the alias shouldn't be marked as reused")
+	}
+}
+
+func TestBidCacheSkipsTestRequests(t *testing.T) {
+	now := time.Now()
+	bidder := &countingBidder{seatBid: cacheTestSeatBid(0)}
+	cached := newCachedBidderForTest(bidder, &now)
+
+	request := cacheTestRequest("req1", "user1")
+	request.Test = 1
+	requestCachedBid(cached, request)
+	requestCachedBid(cached, request)
+
+	assert.Equal(t, 2, bidder.calls)
+}
+
+func TestBidCacheIgnoresNoBids(t *testing.T) {
+	now := time.Now()
+	bidder := &countingBidder{seatBid: &pbsOrtbSeatBid{currency: "USD"}}
+	cached := newCachedBidderForTest(bidder, &now)
+
+	requestCachedBid(cached, cacheTestRequest("req1", "user1"))
+	requestCachedBid(cached, cacheTestRequest("req2", "user1"))
+
+	assert.Equal(t, 2, bidder.calls)
+}
+
+func TestBidCacheMaxEntries(t *testing.T) {
+	now := time.Now()
+	bidder := &countingBidder{seatBid: cacheTestSeatBid(10)}
+	cached := newCachedBidderForTest(bidder, &now)
+
+	requestCachedBid(cached, cacheTestRequest("req1", "user1"))
+	requestCachedBid(cached, cacheTestRequest("req2", "user2"))
+	requestCachedBid(cached, cacheTestRequest("req3", "user3"))
+	assert.Len(t, cached.entries, 2, "The bids of a request shouldn't be cached once the cache is full")
+
+	now = now.Add(10 * time.Second)
+	requestCachedBid(cached, cacheTestRequest("req4", "user4"))
+	assert.Len(t, cached.entries, 1, "The expired entries should make room for new ones")
+}
+
+func TestBidCacheDropsWonBids(t *testing.T) {
+	now := time.Now()
+	bidder := &countingBidder{seatBid: cacheTestSeatBid(0)}
+	cached := newCachedBidderForTest(bidder, &now)
+
+	requestCachedBid(cached, cacheTestRequest("req1", "user1"))
+	cached.markWon(&openrtb.Bid{ID: "bid1", ImpID: "imp1"})
+	requestCachedBid(cached, cacheTestRequest("req2", "user1"))
+
+	assert.Equal(t, 2, bidder.calls, "A bid which won shouldn't be reused")
+}
+
+func TestRecordWonBids(t *testing.T) {
+	now := time.Now()
+	cached := newCachedBidderForTest(&countingBidder{}, &now)
+	cached.store("appnexus-key", &pbsOrtbSeatBid{
+		bids: []*pbsOrtbBid{
+			{bid: &openrtb.Bid{ID: "winner", ImpID: "imp1", Price: 3}},
+			{bid: &openrtb.Bid{ID: "loser", ImpID: "imp2", Price: 1}},
+		},
+	})
+	e := &exchange{adapterMap: map[openrtb_ext.BidderName]adaptedBidder{openrtb_ext.BidderAppnexus: cached}}
+
+	adapterBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		"alias": {bids: []*pbsOrtbBid{
+			{bid: &openrtb.Bid{ID: "winner", ImpID: "imp1", Price: 3}},
+			{bid: &openrtb.Bid{ID: "loser", ImpID: "imp2", Price: 1}},
+		}},
+		openrtb_ext.BidderRubicon: {bids: []*pbsOrtbBid{
+			{bid: &openrtb.Bid{ID: "rubicon", ImpID: "imp2", Price: 2}},
+		}},
+	}
+
+	e.recordWonBids(nil, adapterBids, 2, map[string]string{"alias": "appnexus"})
+
+	if assert.Len(t, cached.entries["appnexus-key"].bids, 1) {
+		assert.Equal(t, "loser", cached.entries["appnexus-key"].bids[0].bid.bid.ID, "Only the losing bid should stay in the cache")
+	}
+}
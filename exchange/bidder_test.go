@@ -2,6 +2,7 @@ package exchange
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -18,8 +19,10 @@ import (
 	"github.com/golang/glog"
 	"github.com/mxmCherry/openrtb"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/circuitbreaker"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/metrics"
 	metricsConfig "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
@@ -258,6 +261,295 @@ func TestConnectionClose(t *testing.T) {
 	}
 }
 
+// TestCircuitBreaker makes sure that bidderAdapter.doRequest stops calling a bidder endpoint which keeps failing.
+func TestCircuitBreaker(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	bidder := &bidderAdapter{
+		Bidder:     &mixedMultiBidder{},
+		BidderName: openrtb_ext.BidderAppnexus,
+		Client:     server.Client(),
+		me:         &metricsConfig.DummyMetricsEngine{},
+		breaker: circuitbreaker.New(config.CircuitBreaker{
+			Enabled:              true,
+			ErrorRateThreshold:   0.5,
+			TimeoutRateThreshold: 0.5,
+			MinRequests:          2,
+			WindowMillis:         60000,
+			OpenMillis:           60000,
+		}, nil),
+	}
+	request := &adapters.RequestData{
+		Method: "POST",
+		Uri:    server.URL,
+	}
+
+	for i := 0; i < 2; i++ {
+		callInfo := bidder.doRequest(context.Background(), request)
+		assert.IsType(t, &errortypes.BadServerResponse{}, callInfo.err, "Bidder should be called while the circuit is closed")
+	}
+
+	callInfo := bidder.doRequest(context.Background(), request)
+	assert.IsType(t, &errortypes.BidderTemporarilyDisabled{}, callInfo.err, "Bidder should not be called once the circuit is open")
+	assert.Nil(t, callInfo.response)
+	assert.Equal(t, 2, calls)
+}
+
+func TestCircuitBreakerOpenSkipsCompression(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterGZIPBytesSaved", openrtb_ext.BidderAppnexus, metrics.CompressionRequest, mock.Anything).Return()
+	bidder := &bidderAdapter{
+		Bidder:     &mixedMultiBidder{},
+		BidderName: openrtb_ext.BidderAppnexus,
+		Client:     server.Client(),
+		me:         metricsMock,
+		breaker: circuitbreaker.New(config.CircuitBreaker{
+			Enabled:              true,
+			ErrorRateThreshold:   0.5,
+			TimeoutRateThreshold: 0.5,
+			MinRequests:          2,
+			WindowMillis:         60000,
+			OpenMillis:           60000,
+		}, nil),
+		config: bidderAdapterConfig{EndpointCompression: "gzip", DisableConnMetrics: true},
+	}
+	request := &adapters.RequestData{
+		Method: "POST",
+		Uri:    server.URL,
+		Body:   []byte(`{"id":"req1","imp":[` + strings.Repeat(`{"id":"imp","banner":{"w":300,"h":250}},`, 50) + `{"id":"imp"}]}`),
+	}
+
+	for i := 0; i < 3; i++ {
+		bidder.doRequest(context.Background(), request)
+	}
+
+	metricsMock.AssertNumberOfCalls(t, "RecordAdapterGZIPBytesSaved", 2)
+}
+
+// TestCircuitBreakerCanceledCalls makes sure that only the calls which run out of time count as bidder timeouts,
+// and not the ones which the exchange cancels at the soft deadline.
+func TestCircuitBreakerCanceledCalls(t *testing.T) {
+	testCases := []struct {
+		description   string
+		makeContext   func() (context.Context, context.CancelFunc)
+		expectedState metrics.CircuitBreakerState
+	}{
+		{
+			description: "Deadline Exceeded",
+			makeContext: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 5*time.Millisecond)
+			},
+			expectedState: metrics.CircuitBreakerOpen,
+		},
+		{
+			description: "Canceled By The Exchange",
+			makeContext: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(5*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			expectedState: metrics.CircuitBreakerClosed,
+		},
+	}
+
+	for _, test := range testCases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+
+		breaker := circuitbreaker.New(config.CircuitBreaker{
+			Enabled:              true,
+			ErrorRateThreshold:   0.5,
+			TimeoutRateThreshold: 0.5,
+			MinRequests:          2,
+			WindowMillis:         60000,
+			OpenMillis:           60000,
+		}, nil)
+		bidder := &bidderAdapter{
+			Bidder:     &mixedMultiBidder{},
+			BidderName: openrtb_ext.BidderAppnexus,
+			Client:     server.Client(),
+			me:         &metricsConfig.DummyMetricsEngine{},
+			breaker:    breaker,
+			config:     bidderAdapterConfig{DisableConnMetrics: true},
+		}
+		request := &adapters.RequestData{
+			Method: "POST",
+			Uri:    server.URL,
+		}
+
+		for i := 0; i < 2; i++ {
+			ctx, cancel := test.makeContext()
+			bidder.doRequest(ctx, request)
+			cancel()
+		}
+
+		assert.Equal(t, test.expectedState, breaker.State(), test.description)
+		server.Close()
+	}
+}
+
+func TestEndpointCompression(t *testing.T) {
+	requestBody := `{"id":"req1","imp":[` + strings.Repeat(`{"id":"imp","banner":{"w":300,"h":250}},`, 50) + `{"id":"imp"}]}`
+	responseBody := `{"id":"req1","seatbid":[{"bid":[` + strings.Repeat(`{"id":"bid","impid":"imp","price":1},`, 50) + `{"id":"bid"}]}]}`
+
+	testCases := []struct {
+		description             string
+		compression             string
+		expectedEncoding        string
+		expectedAcceptEncoding  string
+		expectedRequestMetrics  bool
+		expectedResponseMetrics bool
+	}{
+		{
+			description:      "None",
+			compression:      "",
+			expectedEncoding: "",
+			// The HTTP client asks for gzipped responses and decompresses them itself.
+			expectedAcceptEncoding: "gzip",
+		},
+		{
+			description:             "GZIP",
+			compression:             "gzip",
+			expectedEncoding:        "gzip",
+			expectedAcceptEncoding:  "gzip",
+			expectedRequestMetrics:  true,
+			expectedResponseMetrics: true,
+		},
+	}
+
+	for _, test := range testCases {
+		var receivedEncoding, receivedAcceptEncoding string
+		var receivedBody []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			receivedEncoding = r.Header.Get("Content-Encoding")
+			receivedAcceptEncoding = r.Header.Get("Accept-Encoding")
+			body := r.Body
+			if receivedEncoding == "gzip" {
+				body, _ = gzip.NewReader(r.Body)
+			}
+			receivedBody, _ = ioutil.ReadAll(body)
+
+			w.Header().Set("Content-Encoding", "gzip")
+			writer := gzip.NewWriter(w)
+			writer.Write([]byte(responseBody))
+			writer.Close()
+		}))
+
+		metricsMock := &metrics.MetricsEngineMock{}
+		metricsMock.On("RecordAdapterGZIPBytesSaved", openrtb_ext.BidderAppnexus, mock.Anything, mock.Anything).Return()
+		bidder := &bidderAdapter{
+			Bidder:     &mixedMultiBidder{},
+			BidderName: openrtb_ext.BidderAppnexus,
+			Client:     server.Client(),
+			me:         metricsMock,
+			config:     bidderAdapterConfig{EndpointCompression: test.compression, DisableConnMetrics: true},
+		}
+		request := &adapters.RequestData{
+			Method:  "POST",
+			Uri:     server.URL,
+			Body:    []byte(requestBody),
+			Headers: http.Header{"Content-Type": []string{"application/json"}},
+		}
+
+		callInfo := bidder.doRequest(context.Background(), request)
+		server.Close()
+
+		assert.NoError(t, callInfo.err, test.description)
+		assert.Equal(t, test.expectedEncoding, receivedEncoding, test.description)
+		assert.Equal(t, test.expectedAcceptEncoding, receivedAcceptEncoding, test.description)
+		assert.Equal(t, requestBody, string(receivedBody), test.description)
+		assert.Equal(t, requestBody, string(callInfo.request.Body), "%s: the request should be reported uncompressed", test.description)
+		assert.Equal(t, http.Header{"Content-Type": []string{"application/json"}}, callInfo.request.Headers, "%s: the request headers shouldn't change", test.description)
+		if assert.NotNil(t, callInfo.response, test.description) {
+			assert.Equal(t, responseBody, string(callInfo.response.Body), "%s: the response should be decompressed", test.description)
+			assert.Empty(t, callInfo.response.Headers.Get("Content-Encoding"), test.description)
+		}
+		if test.expectedRequestMetrics {
+			metricsMock.AssertCalled(t, "RecordAdapterGZIPBytesSaved", openrtb_ext.BidderAppnexus, metrics.CompressionRequest, mock.Anything)
+		} else {
+			metricsMock.AssertNotCalled(t, "RecordAdapterGZIPBytesSaved", openrtb_ext.BidderAppnexus, metrics.CompressionRequest, mock.Anything)
+		}
+		if test.expectedResponseMetrics {
+			metricsMock.AssertCalled(t, "RecordAdapterGZIPBytesSaved", openrtb_ext.BidderAppnexus, metrics.CompressionResponse, mock.Anything)
+		} else {
+			metricsMock.AssertNotCalled(t, "RecordAdapterGZIPBytesSaved", openrtb_ext.BidderAppnexus, metrics.CompressionResponse, mock.Anything)
+		}
+	}
+}
+
+func TestDecompressGZIPMaxSize(t *testing.T) {
+	testCases := []struct {
+		description   string
+		size          int
+		expectedError bool
+	}{
+		{
+			description:   "At Max Size",
+			size:          maxDecompressedResponseSize,
+			expectedError: false,
+		},
+		{
+			description:   "Above Max Size",
+			size:          maxDecompressedResponseSize + 1,
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		compressed, err := compressGZIP(make([]byte, test.size))
+		if !assert.NoError(t, err, test.description) {
+			continue
+		}
+
+		decompressed, err := decompressGZIP(compressed)
+
+		if test.expectedError {
+			assert.Error(t, err, test.description)
+			assert.Nil(t, decompressed, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+			assert.Len(t, decompressed, test.size, test.description)
+		}
+	}
+}
+
+func TestMalformedGZIPResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write([]byte("not gzipped"))
+	}))
+	defer server.Close()
+
+	bidder := &bidderAdapter{
+		Bidder:     &mixedMultiBidder{},
+		BidderName: openrtb_ext.BidderAppnexus,
+		Client:     server.Client(),
+		me:         &metricsConfig.DummyMetricsEngine{},
+		config:     bidderAdapterConfig{EndpointCompression: "gzip"},
+	}
+	request := &adapters.RequestData{
+		Method: "POST",
+		Uri:    server.URL,
+		Body:   []byte(`{"id":"req1"}`),
+	}
+
+	callInfo := bidder.doRequest(context.Background(), request)
+
+	assert.IsType(t, &errortypes.BadServerResponse{}, callInfo.err)
+	assert.Nil(t, callInfo.response)
+}
+
 type bid struct {
 	currency string
 	price    float64
@@ -0,0 +1,190 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/buger/jsonparser"
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// addTrafficShapingMiddleware returns a bidder which learns how often the argument bidder bids and times out
+// for each account and media type. Bidders which almost never bid, or almost always time out, are then skipped
+// on most of the requests for that account and media type. At most config.TrafficShaping.MaxEntries statistics
+// are kept.
+//
+// Publishers can set bidrequest.ext.prebid.trafficshaping.debug to bypass traffic shaping. The bidders which would
+// have been skipped are then reported with a warning.
+func addTrafficShapingMiddleware(bidder adaptedBidder, cfg config.TrafficShaping) adaptedBidder {
+	return &shapedBidder{
+		bidder: bidder,
+		config: cfg,
+		stats:  make(map[trafficKey]*trafficCounts),
+		random: rand.Float64,
+	}
+}
+
+type shapedBidder struct {
+	bidder adaptedBidder
+	config config.TrafficShaping
+	random func() float64
+
+	mutex sync.Mutex
+	stats map[trafficKey]*trafficCounts
+}
+
+type trafficKey struct {
+	bidder    openrtb_ext.BidderName
+	account   string
+	mediaType openrtb_ext.BidType
+}
+
+// trafficCounts are decayed every config.TrafficShaping.WindowSize requests, which is why they aren't integers.
+type trafficCounts struct {
+	requests float64
+	bids     float64
+	timeouts float64
+}
+
+func (s *shapedBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo) (*pbsOrtbSeatBid, []error) {
+	account := reqInfo.AccountID
+	if account == "" {
+		account = metrics.PublisherUnknown
+	}
+	mediaTypes := requestMediaTypes(request)
+
+	var shapingErrs []error
+	if reason := s.shapingReason(name, account, mediaTypes); reason != "" {
+		if debug, _ := jsonparser.GetBoolean(request.Ext, "prebid", "trafficshaping", "debug"); debug {
+			shapingErrs = append(shapingErrs, &errortypes.BidderThrottled{
+				Message: fmt.Sprintf("Bidder %s would have been skipped by traffic shaping: %s", name, reason),
+			})
+		} else if s.random() < s.config.SkipRate {
+			return nil, nil
+		}
+	}
+
+	seatBid, errs := s.bidder.requestBid(ctx, request, name, bidAdjustment, conversions, reqInfo)
+	s.record(name, account, mediaTypes, seatBid, errs)
+
+	return seatBid, append(errs, shapingErrs...)
+}
+
+// shapingReason explains why the bidder should be skipped, or returns an empty string if it shouldn't.
+// The bidder is only skipped if it should be for every media type in the request.
+func (s *shapedBidder) shapingReason(bidder openrtb_ext.BidderName, account string, mediaTypes []openrtb_ext.BidType) string {
+	if len(mediaTypes) == 0 {
+		return ""
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var reason string
+	for _, mediaType := range mediaTypes {
+		counts, ok := s.stats[trafficKey{bidder, account, mediaType}]
+		if !ok || counts.requests < float64(s.config.MinSamples) {
+			return ""
+		}
+		bidRate, timeoutRate := counts.bids/counts.requests, counts.timeouts/counts.requests
+		switch {
+		case bidRate < s.config.MinBidRate:
+			reason = fmt.Sprintf("%s bid rate %.4f for account %s is below %.4f", mediaType, bidRate, account, s.config.MinBidRate)
+		case timeoutRate > s.config.MaxTimeoutRate:
+			reason = fmt.Sprintf("%s timeout rate %.4f for account %s is above %.4f", mediaType, timeoutRate, account, s.config.MaxTimeoutRate)
+		default:
+			return ""
+		}
+	}
+	return reason
+}
+
+func (s *shapedBidder) record(bidder openrtb_ext.BidderName, account string, mediaTypes []openrtb_ext.BidType, seatBid *pbsOrtbSeatBid, errs []error) {
+	bidTypes := make(map[openrtb_ext.BidType]bool)
+	if seatBid != nil {
+		for _, bid := range seatBid.bids {
+			bidTypes[bid.bidType] = true
+		}
+	}
+	timedOut := false
+	for _, err := range errs {
+		if errortypes.ReadCode(err) == errortypes.TimeoutErrorCode {
+			timedOut = true
+			break
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, mediaType := range mediaTypes {
+		key := trafficKey{bidder, account, mediaType}
+		counts, ok := s.stats[key]
+		if !ok {
+			s.evictIfFull()
+			counts = &trafficCounts{}
+			s.stats[key] = counts
+		}
+		counts.requests++
+		if bidTypes[mediaType] {
+			counts.bids++
+		}
+		if timedOut {
+			counts.timeouts++
+		}
+		if counts.requests >= float64(s.config.WindowSize) {
+			counts.requests /= 2
+			counts.bids /= 2
+			counts.timeouts /= 2
+		}
+	}
+}
+
+// evictIfFull makes room for a new entry when the statistics reached config.TrafficShaping.MaxEntries. Map iteration
+// is randomized, so an arbitrary entry is evicted. The caller must hold the mutex.
+func (s *shapedBidder) evictIfFull() {
+	if s.config.MaxEntries <= 0 || len(s.stats) < s.config.MaxEntries {
+		return
+	}
+	for key := range s.stats {
+		delete(s.stats, key)
+		return
+	}
+}
+
+// requestMediaTypes returns the sorted list of the media types found in the imps of the request.
+func requestMediaTypes(request *openrtb.BidRequest) []openrtb_ext.BidType {
+	found := make(map[openrtb_ext.BidType]bool)
+	for _, imp := range request.Imp {
+		if imp.Banner != nil {
+			found[openrtb_ext.BidTypeBanner] = true
+		}
+		if imp.Video != nil {
+			found[openrtb_ext.BidTypeVideo] = true
+		}
+		if imp.Audio != nil {
+			found[openrtb_ext.BidTypeAudio] = true
+		}
+		if imp.Native != nil {
+			found[openrtb_ext.BidTypeNative] = true
+		}
+	}
+
+	mediaTypes := make([]openrtb_ext.BidType, 0, len(found))
+	for mediaType := range found {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Slice(mediaTypes, func(i, j int) bool {
+		return mediaTypes[i] < mediaTypes[j]
+	})
+	return mediaTypes
+}
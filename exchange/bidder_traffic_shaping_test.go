@@ -0,0 +1,180 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingBidder struct {
+	calls   int
+	seatBid *pbsOrtbSeatBid
+	errs    []error
+}
+
+func (b *countingBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo) (*pbsOrtbSeatBid, []error) {
+	b.calls++
+	return b.seatBid, b.errs
+}
+
+func newShapedBidderForTest(bidder adaptedBidder, random float64) *shapedBidder {
+	shaped := addTrafficShapingMiddleware(bidder, config.TrafficShaping{
+		Enabled:        true,
+		MinSamples:     10,
+		MinBidRate:     0.1,
+		MaxTimeoutRate: 0.5,
+		SkipRate:       0.9,
+		WindowSize:     100,
+	}).(*shapedBidder)
+	shaped.random = func() float64 { return random }
+	return shaped
+}
+
+func bannerRequest(ext json.RawMessage) *openrtb.BidRequest {
+	return &openrtb.BidRequest{
+		Site: &openrtb.Site{Publisher: &openrtb.Publisher{ID: "publisher"}},
+		Imp:  []openrtb.Imp{{ID: "imp1", Banner: &openrtb.Banner{}}},
+		Ext:  ext,
+	}
+}
+
+func TestTrafficShapingSkipsBidderWithoutBids(t *testing.T) {
+	bidder := &countingBidder{}
+	shaped := newShapedBidderForTest(bidder, 0.5)
+
+	for i := 0; i < 10; i++ {
+		shaped.requestBid(context.Background(), bannerRequest(nil), "appnexus", 1, currency.NewConstantRates(), &adapters.ExtraRequestInfo{AccountID: "acct"})
+	}
+	assert.Equal(t, 10, bidder.calls, "Bidder should be called until enough samples are collected")
+
+	seatBid, errs := shaped.requestBid(context.Background(), bannerRequest(nil), "appnexus", 1, currency.NewConstantRates(), &adapters.ExtraRequestInfo{AccountID: "acct"})
+	assert.Equal(t, 10, bidder.calls, "Bidder should be skipped")
+	assert.Nil(t, seatBid)
+	assert.Empty(t, errs)
+
+	shaped.requestBid(context.Background(), bannerRequest(nil), "appnexus", 1, currency.NewConstantRates(), &adapters.ExtraRequestInfo{AccountID: "other"})
+	assert.Equal(t, 11, bidder.calls, "Bidder should not be skipped for another account")
+
+	shaped.random = func() float64 { return 0.95 }
+	shaped.requestBid(context.Background(), bannerRequest(nil), "appnexus", 1, currency.NewConstantRates(), &adapters.ExtraRequestInfo{AccountID: "acct"})
+	assert.Equal(t, 12, bidder.calls, "Bidder should still get the requests outside of the skip rate")
+}
+
+func TestTrafficShapingKeepsBiddingBidder(t *testing.T) {
+	bidder := &countingBidder{
+		seatBid: &pbsOrtbSeatBid{bids: []*pbsOrtbBid{{bid: &openrtb.Bid{ID: "bid1", ImpID: "imp1"}, bidType: openrtb_ext.BidTypeBanner}}},
+	}
+	shaped := newShapedBidderForTest(bidder, 0)
+
+	for i := 0; i < 20; i++ {
+		shaped.requestBid(context.Background(), bannerRequest(nil), "appnexus", 1, currency.NewConstantRates(), &adapters.ExtraRequestInfo{AccountID: "acct"})
+	}
+
+	assert.Equal(t, 20, bidder.calls)
+}
+
+func TestTrafficShapingSkipsBidderTimingOut(t *testing.T) {
+	bidder := &countingBidder{
+		seatBid: &pbsOrtbSeatBid{bids: []*pbsOrtbBid{{bid: &openrtb.Bid{ID: "bid1", ImpID: "imp1"}, bidType: openrtb_ext.BidTypeBanner}}},
+		errs:    []error{&errortypes.Timeout{Message: "timeout"}},
+	}
+	shaped := newShapedBidderForTest(bidder, 0)
+
+	for i := 0; i < 11; i++ {
+		shaped.requestBid(context.Background(), bannerRequest(nil), "appnexus", 1, currency.NewConstantRates(), &adapters.ExtraRequestInfo{AccountID: "acct"})
+	}
+
+	assert.Equal(t, 10, bidder.calls)
+}
+
+func TestTrafficShapingDebug(t *testing.T) {
+	bidder := &countingBidder{}
+	shaped := newShapedBidderForTest(bidder, 0)
+	debugExt := json.RawMessage(`{"prebid":{"trafficshaping":{"debug":true}}}`)
+
+	for i := 0; i < 10; i++ {
+		shaped.requestBid(context.Background(), bannerRequest(nil), "appnexus", 1, currency.NewConstantRates(), &adapters.ExtraRequestInfo{AccountID: "acct"})
+	}
+	_, errs := shaped.requestBid(context.Background(), bannerRequest(debugExt), "appnexus", 1, currency.NewConstantRates(), &adapters.ExtraRequestInfo{AccountID: "acct"})
+
+	assert.Equal(t, 11, bidder.calls, "Debug should bypass traffic shaping")
+	assert.Equal(t, []error{
+		&errortypes.BidderThrottled{Message: "Bidder appnexus would have been skipped by traffic shaping: banner bid rate 0.0000 for account acct is below 0.1000"},
+	}, errs)
+}
+
+func TestTrafficShapingMultipleMediaTypes(t *testing.T) {
+	bidder := &countingBidder{
+		seatBid: &pbsOrtbSeatBid{bids: []*pbsOrtbBid{{bid: &openrtb.Bid{ID: "bid1", ImpID: "imp2"}, bidType: openrtb_ext.BidTypeVideo}}},
+	}
+	shaped := newShapedBidderForTest(bidder, 0)
+	request := bannerRequest(nil)
+	request.Imp = append(request.Imp, openrtb.Imp{ID: "imp2", Video: &openrtb.Video{}})
+
+	for i := 0; i < 10; i++ {
+		shaped.requestBid(context.Background(), request, "appnexus", 1, currency.NewConstantRates(), &adapters.ExtraRequestInfo{AccountID: "acct"})
+	}
+	shaped.requestBid(context.Background(), request, "appnexus", 1, currency.NewConstantRates(), &adapters.ExtraRequestInfo{AccountID: "acct"})
+	assert.Equal(t, 11, bidder.calls, "Bidder should not be skipped while it bids on one of the media types")
+
+	shaped.requestBid(context.Background(), bannerRequest(nil), "appnexus", 1, currency.NewConstantRates(), &adapters.ExtraRequestInfo{AccountID: "acct"})
+	assert.Equal(t, 11, bidder.calls, "Bidder should be skipped for banner only requests")
+}
+
+func TestTrafficShapingWindowDecay(t *testing.T) {
+	shaped := newShapedBidderForTest(&countingBidder{}, 0)
+	key := trafficKey{"appnexus", "acct", openrtb_ext.BidTypeBanner}
+
+	for i := 0; i < 99; i++ {
+		shaped.record("appnexus", "acct", []openrtb_ext.BidType{openrtb_ext.BidTypeBanner}, nil, nil)
+	}
+	assert.Equal(t, &trafficCounts{requests: 99}, shaped.stats[key])
+
+	shaped.record("appnexus", "acct", []openrtb_ext.BidType{openrtb_ext.BidTypeBanner}, nil, []error{&errortypes.Timeout{}})
+	assert.Equal(t, &trafficCounts{requests: 50, timeouts: 0.5}, shaped.stats[key])
+}
+
+func TestRequestMediaTypes(t *testing.T) {
+	request := &openrtb.BidRequest{
+		Imp: []openrtb.Imp{
+			{ID: "imp1", Video: &openrtb.Video{}, Banner: &openrtb.Banner{}},
+			{ID: "imp2", Native: &openrtb.Native{}},
+			{ID: "imp3", Banner: &openrtb.Banner{}},
+		},
+	}
+
+	assert.Equal(t, []openrtb_ext.BidType{openrtb_ext.BidTypeBanner, openrtb_ext.BidTypeNative, openrtb_ext.BidTypeVideo}, requestMediaTypes(request))
+}
+
+func TestTrafficShapingUnknownAccount(t *testing.T) {
+	bidder := &countingBidder{}
+	shaped := newShapedBidderForTest(bidder, 0)
+
+	shaped.requestBid(context.Background(), bannerRequest(nil), "appnexus", 1, currency.NewConstantRates(), &adapters.ExtraRequestInfo{})
+
+	assert.Contains(t, shaped.stats, trafficKey{"appnexus", "unknown", openrtb_ext.BidTypeBanner})
+	assert.NotContains(t, shaped.stats, trafficKey{"appnexus", "publisher", openrtb_ext.BidTypeBanner}, "The publisher ID of the request shouldn't be used as the account")
+}
+
+func TestTrafficShapingMaxEntries(t *testing.T) {
+	shaped := newShapedBidderForTest(&countingBidder{}, 0)
+	shaped.config.MaxEntries = 3
+
+	for _, account := range []string{"acct1", "acct2", "acct3", "acct4", "acct5"} {
+		shaped.record("appnexus", account, []openrtb_ext.BidType{openrtb_ext.BidTypeBanner}, nil, nil)
+	}
+	assert.Len(t, shaped.stats, 3)
+	assert.Contains(t, shaped.stats, trafficKey{"appnexus", "acct5", openrtb_ext.BidTypeBanner}, "The newest entry should be kept")
+
+	shaped.record("appnexus", "acct5", []openrtb_ext.BidType{openrtb_ext.BidTypeBanner}, nil, nil)
+	assert.Len(t, shaped.stats, 3)
+	assert.Equal(t, &trafficCounts{requests: 2}, shaped.stats[trafficKey{"appnexus", "acct5", openrtb_ext.BidTypeBanner}], "Known entries shouldn't evict others")
+}
@@ -2,13 +2,20 @@ package exchange
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
+	"github.com/buger/jsonparser"
 	"github.com/mxmCherry/openrtb"
+	nativeResponse "github.com/mxmCherry/openrtb/native/response"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	goCurrency "golang.org/x/text/currency"
 )
@@ -45,6 +52,9 @@ func removeInvalidBids(request *openrtb.BidRequest, seatBid *pbsOrtbSeatBid) []e
 
 	// By design, default currency is USD.
 	if cerr := validateCurrency(request.Cur, seatBid.currency); cerr != nil {
+		for _, bid := range seatBid.bids {
+			seatBid.addNonBid(bid.bid, errortypes.InvalidBidResponseNonBidCode)
+		}
 		seatBid.bids = nil
 		return []error{cerr}
 	}
@@ -56,6 +66,9 @@ func removeInvalidBids(request *openrtb.BidRequest, seatBid *pbsOrtbSeatBid) []e
 			validBids = append(validBids, bid)
 		} else {
 			errs = append(errs, berr)
+			if bid != nil {
+				seatBid.addNonBid(bid.bid, errortypes.InvalidBidResponseNonBidCode)
+			}
 		}
 	}
 	seatBid.bids = validBids
@@ -119,3 +132,163 @@ func validateBid(bid *pbsOrtbBid) (bool, error) {
 
 	return true, nil
 }
+
+// applyBidValidations runs the optional bid validations, which the host and the account configure, on the bids of a bidder.
+// In ValidationEnforce mode the invalid bids are removed and an error is returned for each of them. In ValidationWarn mode
+// they are kept and a warning is returned instead. The metrics engine records every invalid bid, whatever the mode.
+func applyBidValidations(request *openrtb.BidRequest, bidderName openrtb_ext.BidderName, seatBid *pbsOrtbSeatBid, validations config.Validations, me metrics.MetricsEngine) []error {
+	if seatBid == nil || len(seatBid.bids) == 0 {
+		return nil
+	}
+	if !isValidationOn(validations.BannerCreativeSize) && !isValidationOn(validations.SecureMarkup) {
+		return nil
+	}
+
+	imps := make(map[string]*openrtb.Imp, len(request.Imp))
+	for i := range request.Imp {
+		imps[request.Imp[i].ID] = &request.Imp[i]
+	}
+
+	var errs []error
+	validBids := make([]*pbsOrtbBid, 0, len(seatBid.bids))
+	for _, bid := range seatBid.bids {
+		imp, ok := imps[bid.bid.ImpID]
+		if !ok {
+			validBids = append(validBids, bid)
+			continue
+		}
+
+		rejected := false
+		checks := []struct {
+			mode       string
+			validation metrics.BidValidation
+			validate   func(*openrtb.Imp, *pbsOrtbBid) string
+			nonBidCode int
+		}{
+			{validations.BannerCreativeSize, metrics.BidValidationBannerCreativeSize, validateBannerCreativeSize, errortypes.CreativeSizeNotAllowedNonBidCode},
+			{validations.SecureMarkup, metrics.BidValidationSecureMarkup, validateSecureMarkup, errortypes.CreativeNotSecureNonBidCode},
+		}
+		for _, check := range checks {
+			if !isValidationOn(check.mode) {
+				continue
+			}
+			reason := check.validate(imp, bid)
+			if reason == "" {
+				continue
+			}
+			me.RecordAdapterBidValidationError(bidderName, check.validation)
+			if check.mode == config.ValidationEnforce {
+				errs = append(errs, fmt.Errorf("Bid \"%s\" rejected: %s", bid.bid.ID, reason))
+				seatBid.addNonBid(bid.bid, check.nonBidCode)
+				rejected = true
+				break
+			}
+			errs = append(errs, &errortypes.Warning{Message: fmt.Sprintf("Bid \"%s\" %s", bid.bid.ID, reason)})
+		}
+		if !rejected {
+			validBids = append(validBids, bid)
+		}
+	}
+	seatBid.bids = validBids
+	return errs
+}
+
+func isValidationOn(mode string) bool {
+	return mode == config.ValidationWarn || mode == config.ValidationEnforce
+}
+
+// validateBannerCreativeSize checks that the size of a banner bid is one of the sizes of its imp.
+// Imps without any size accept every creative size.
+func validateBannerCreativeSize(imp *openrtb.Imp, bid *pbsOrtbBid) string {
+	if bid.bidType != openrtb_ext.BidTypeBanner || imp.Banner == nil {
+		return ""
+	}
+	if imp.Banner.W == nil && imp.Banner.H == nil && len(imp.Banner.Format) == 0 {
+		return ""
+	}
+
+	if imp.Banner.W != nil && imp.Banner.H != nil && *imp.Banner.W == bid.bid.W && *imp.Banner.H == bid.bid.H {
+		return ""
+	}
+	for _, format := range imp.Banner.Format {
+		if format.W == bid.bid.W && format.H == bid.bid.H {
+			return ""
+		}
+	}
+	return fmt.Sprintf("creative size %dx%d does not match any of the banner sizes of imp \"%s\"", bid.bid.W, bid.bid.H, imp.ID)
+}
+
+// insecureHTMLAsset matches the src and href attributes, and the CSS urls, which load their content over plain http.
+// The XML namespaces, such as the one of inline SVG, aren't loaded and so aren't matched.
+var insecureHTMLAsset = regexp.MustCompile(`(?i)(?:\b(?:src|href)\s*=\s*["']?|url\(\s*["']?)\s*http:`)
+
+// insecureVASTAsset matches the nodes of a VAST document which load a media file, a resource or a tracker over plain http.
+var insecureVASTAsset = regexp.MustCompile(`(?i)<(?:MediaFile|Tracking|Impression|ClickTracking|Error|StaticResource|IFrameResource|VASTAdTagURI)\b[^>]*>\s*(?:<!\[CDATA\[)?\s*http:`)
+
+// validateSecureMarkup checks that a bid for a secure imp doesn't load its creative assets over plain http.
+// The markup is read as HTML, VAST or a native response depending on the type of the bid.
+func validateSecureMarkup(imp *openrtb.Imp, bid *pbsOrtbBid) string {
+	if imp.Secure == nil || *imp.Secure != 1 {
+		return ""
+	}
+
+	if hasInsecureAsset(bid.bidType, bid.bid.AdM) {
+		return fmt.Sprintf("markup loads insecure http content in secure imp \"%s\"", imp.ID)
+	}
+	if isInsecureURL(bid.bid.NURL) {
+		return fmt.Sprintf("nurl is not secure in secure imp \"%s\"", imp.ID)
+	}
+	return ""
+}
+
+func hasInsecureAsset(bidType openrtb_ext.BidType, adm string) bool {
+	switch bidType {
+	case openrtb_ext.BidTypeVideo, openrtb_ext.BidTypeAudio:
+		return insecureVASTAsset.MatchString(adm)
+	case openrtb_ext.BidTypeNative:
+		return hasInsecureNativeAsset(adm)
+	default:
+		return insecureHTMLAsset.MatchString(adm)
+	}
+}
+
+// hasInsecureNativeAsset checks the images, trackers and video of a native response. Native 1.0 responses are
+// wrapped in a native object. Markup which isn't a native response can't be checked, and is left to the other
+// validations.
+func hasInsecureNativeAsset(adm string) bool {
+	markup := []byte(adm)
+	if wrapped, dataType, _, err := jsonparser.Get(markup, "native"); err == nil && dataType == jsonparser.Object {
+		markup = wrapped
+	}
+	var native nativeResponse.Response
+	if err := json.Unmarshal(markup, &native); err != nil {
+		return false
+	}
+
+	if isInsecureURL(native.AssetsURL) || isInsecureURL(native.DCOURL) || insecureHTMLAsset.MatchString(native.JSTracker) {
+		return true
+	}
+	for _, tracker := range native.ImpTrackers {
+		if isInsecureURL(tracker) {
+			return true
+		}
+	}
+	for _, tracker := range native.EventTrackers {
+		if isInsecureURL(tracker.URL) {
+			return true
+		}
+	}
+	for _, asset := range native.Assets {
+		if asset.Img != nil && isInsecureURL(asset.Img.URL) {
+			return true
+		}
+		if asset.Video != nil && insecureVASTAsset.MatchString(asset.Video.VASTTag) {
+			return true
+		}
+	}
+	return false
+}
+
+func isInsecureURL(url string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(url)), "http:")
+}
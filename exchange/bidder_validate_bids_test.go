@@ -2,13 +2,18 @@ package exchange
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/mxmCherry/openrtb"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestAllValidBids(t *testing.T) {
@@ -129,6 +134,7 @@ func TestMixedBids(t *testing.T) {
 	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{})
 	assert.Len(t, seatBid.bids, 2)
 	assert.Len(t, errs, 3)
+	assert.Len(t, seatBid.nonBids, 2, "The bids without an openrtb bid can't be reported")
 }
 
 func TestCurrencyBids(t *testing.T) {
@@ -260,3 +266,186 @@ type mockAdaptedBidder struct {
 func (b *mockAdaptedBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo) (*pbsOrtbSeatBid, []error) {
 	return b.bidResponse, b.errorResponse
 }
+
+func TestApplyBidValidations(t *testing.T) {
+	secure := int8(1)
+	request := &openrtb.BidRequest{
+		Imp: []openrtb.Imp{
+			{ID: "bannerImp", Banner: &openrtb.Banner{Format: []openrtb.Format{{W: 300, H: 250}, {W: 728, H: 90}}}},
+			{ID: "secureImp", Secure: &secure, Banner: &openrtb.Banner{W: openrtb.Uint64Ptr(320), H: openrtb.Uint64Ptr(50)}},
+			{ID: "anySizeImp", Banner: &openrtb.Banner{}},
+		},
+	}
+	validBid := &pbsOrtbBid{bid: &openrtb.Bid{ID: "valid", ImpID: "bannerImp", W: 728, H: 90, AdM: "<img src=\"http://cdn.com/ad.png\">"}, bidType: openrtb_ext.BidTypeBanner}
+	wrongSizeBid := &pbsOrtbBid{bid: &openrtb.Bid{ID: "wrongSize", ImpID: "bannerImp", W: 320, H: 50}, bidType: openrtb_ext.BidTypeBanner}
+	wrongSizeVideoBid := &pbsOrtbBid{bid: &openrtb.Bid{ID: "video", ImpID: "bannerImp", W: 320, H: 50}, bidType: openrtb_ext.BidTypeVideo}
+	anySizeBid := &pbsOrtbBid{bid: &openrtb.Bid{ID: "anySize", ImpID: "anySizeImp", W: 1, H: 1}, bidType: openrtb_ext.BidTypeBanner}
+	secureBid := &pbsOrtbBid{bid: &openrtb.Bid{ID: "secure", ImpID: "secureImp", W: 320, H: 50, AdM: "<img src=\"https://cdn.com/ad.png\">"}, bidType: openrtb_ext.BidTypeBanner}
+	insecureAdmBid := &pbsOrtbBid{bid: &openrtb.Bid{ID: "insecureAdm", ImpID: "secureImp", W: 320, H: 50, AdM: "<img src=\"HTTP://cdn.com/ad.png\">"}, bidType: openrtb_ext.BidTypeBanner}
+	insecureNurlBid := &pbsOrtbBid{bid: &openrtb.Bid{ID: "insecureNurl", ImpID: "secureImp", W: 320, H: 50, NURL: "http://cdn.com/win"}, bidType: openrtb_ext.BidTypeBanner}
+	allBids := []*pbsOrtbBid{validBid, wrongSizeBid, wrongSizeVideoBid, anySizeBid, secureBid, insecureAdmBid, insecureNurlBid}
+
+	testCases := []struct {
+		description     string
+		validations     config.Validations
+		expectedBids    []*pbsOrtbBid
+		expectedErrs    []error
+		expectedMetrics map[metrics.BidValidation]int
+		expectedNonBids []nonBid
+	}{
+		{
+			description:     "Validations Off",
+			validations:     config.Validations{BannerCreativeSize: config.ValidationOff},
+			expectedBids:    allBids,
+			expectedMetrics: map[metrics.BidValidation]int{},
+		},
+		{
+			description:  "Creative Size Warn",
+			validations:  config.Validations{BannerCreativeSize: config.ValidationWarn},
+			expectedBids: allBids,
+			expectedErrs: []error{
+				&errortypes.Warning{Message: "Bid \"wrongSize\" creative size 320x50 does not match any of the banner sizes of imp \"bannerImp\""},
+			},
+			expectedMetrics: map[metrics.BidValidation]int{metrics.BidValidationBannerCreativeSize: 1},
+		},
+		{
+			description:  "Creative Size Enforce",
+			validations:  config.Validations{BannerCreativeSize: config.ValidationEnforce},
+			expectedBids: []*pbsOrtbBid{validBid, wrongSizeVideoBid, anySizeBid, secureBid, insecureAdmBid, insecureNurlBid},
+			expectedErrs: []error{
+				errors.New("Bid \"wrongSize\" rejected: creative size 320x50 does not match any of the banner sizes of imp \"bannerImp\""),
+			},
+			expectedMetrics: map[metrics.BidValidation]int{metrics.BidValidationBannerCreativeSize: 1},
+			expectedNonBids: []nonBid{{bid: wrongSizeBid.bid, statusCode: errortypes.CreativeSizeNotAllowedNonBidCode}},
+		},
+		{
+			description:  "Secure Markup Enforce",
+			validations:  config.Validations{SecureMarkup: config.ValidationEnforce},
+			expectedBids: []*pbsOrtbBid{validBid, wrongSizeBid, wrongSizeVideoBid, anySizeBid, secureBid},
+			expectedErrs: []error{
+				errors.New("Bid \"insecureAdm\" rejected: markup loads insecure http content in secure imp \"secureImp\""),
+				errors.New("Bid \"insecureNurl\" rejected: nurl is not secure in secure imp \"secureImp\""),
+			},
+			expectedMetrics: map[metrics.BidValidation]int{metrics.BidValidationSecureMarkup: 2},
+			expectedNonBids: []nonBid{
+				{bid: insecureAdmBid.bid, statusCode: errortypes.CreativeNotSecureNonBidCode},
+				{bid: insecureNurlBid.bid, statusCode: errortypes.CreativeNotSecureNonBidCode},
+			},
+		},
+		{
+			description:  "Secure Markup Warn And Creative Size Enforce",
+			validations:  config.Validations{BannerCreativeSize: config.ValidationEnforce, SecureMarkup: config.ValidationWarn},
+			expectedBids: []*pbsOrtbBid{validBid, wrongSizeVideoBid, anySizeBid, secureBid, insecureAdmBid, insecureNurlBid},
+			expectedErrs: []error{
+				errors.New("Bid \"wrongSize\" rejected: creative size 320x50 does not match any of the banner sizes of imp \"bannerImp\""),
+				&errortypes.Warning{Message: "Bid \"insecureAdm\" markup loads insecure http content in secure imp \"secureImp\""},
+				&errortypes.Warning{Message: "Bid \"insecureNurl\" nurl is not secure in secure imp \"secureImp\""},
+			},
+			expectedMetrics: map[metrics.BidValidation]int{metrics.BidValidationBannerCreativeSize: 1, metrics.BidValidationSecureMarkup: 2},
+			expectedNonBids: []nonBid{{bid: wrongSizeBid.bid, statusCode: errortypes.CreativeSizeNotAllowedNonBidCode}},
+		},
+	}
+
+	for _, test := range testCases {
+		me := &metrics.MetricsEngineMock{}
+		me.On("RecordAdapterBidValidationError", openrtb_ext.BidderAppnexus, mock.Anything).Return()
+		seatBid := &pbsOrtbSeatBid{bids: append([]*pbsOrtbBid{}, allBids...)}
+
+		errs := applyBidValidations(request, openrtb_ext.BidderAppnexus, seatBid, test.validations, me)
+
+		recordedMetrics := map[metrics.BidValidation]int{}
+		for _, call := range me.Calls {
+			recordedMetrics[call.Arguments.Get(1).(metrics.BidValidation)]++
+		}
+
+		assert.Equal(t, test.expectedBids, seatBid.bids, test.description)
+		assert.Equal(t, test.expectedErrs, errs, test.description)
+		assert.Equal(t, test.expectedMetrics, recordedMetrics, test.description)
+		assert.Equal(t, test.expectedNonBids, seatBid.nonBids, test.description)
+	}
+}
+
+func TestValidateSecureMarkup(t *testing.T) {
+	secure := int8(1)
+	secureImp := &openrtb.Imp{ID: "secureImp", Secure: &secure}
+	vastHeader := `<?xml version="1.0" encoding="UTF-8"?><VAST version="3.0" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:noNamespaceSchemaLocation="vast.xsd">`
+
+	testCases := []struct {
+		description    string
+		imp            *openrtb.Imp
+		bid            *pbsOrtbBid
+		expectedReason string
+	}{
+		{
+			description: "Insecure Imp",
+			imp:         &openrtb.Imp{ID: "imp"},
+			bid:         &pbsOrtbBid{bid: &openrtb.Bid{AdM: `<img src="http://cdn.com/ad.png">`}, bidType: openrtb_ext.BidTypeBanner},
+		},
+		{
+			description: "Banner With Inline SVG",
+			imp:         secureImp,
+			bid:         &pbsOrtbBid{bid: &openrtb.Bid{AdM: `<svg xmlns="http://www.w3.org/2000/svg"><image href="https://cdn.com/ad.png"/></svg>`}, bidType: openrtb_ext.BidTypeBanner},
+		},
+		{
+			description:    "Banner With Insecure Image",
+			imp:            secureImp,
+			bid:            &pbsOrtbBid{bid: &openrtb.Bid{AdM: `<img src = 'http://cdn.com/ad.png'>`}, bidType: openrtb_ext.BidTypeBanner},
+			expectedReason: "markup loads insecure http content in secure imp \"secureImp\"",
+		},
+		{
+			description:    "Banner With Insecure CSS Background",
+			imp:            secureImp,
+			bid:            &pbsOrtbBid{bid: &openrtb.Bid{AdM: `<div style="background: url(http://cdn.com/ad.png)"></div>`}, bidType: openrtb_ext.BidTypeBanner},
+			expectedReason: "markup loads insecure http content in secure imp \"secureImp\"",
+		},
+		{
+			description: "Secure VAST",
+			imp:         secureImp,
+			bid: &pbsOrtbBid{bid: &openrtb.Bid{AdM: vastHeader + `<Ad><InLine><Impression><![CDATA[https://cdn.com/imp]]></Impression><Creatives><Creative><Linear>` +
+				`<TrackingEvents><Tracking event="start">https://cdn.com/start</Tracking></TrackingEvents>` +
+				`<MediaFiles><MediaFile delivery="progressive" type="video/mp4"><![CDATA[https://cdn.com/ad.mp4]]></MediaFile></MediaFiles>` +
+				`</Linear></Creative></Creatives></InLine></Ad></VAST>`}, bidType: openrtb_ext.BidTypeVideo},
+		},
+		{
+			description: "VAST With Insecure Media File",
+			imp:         secureImp,
+			bid: &pbsOrtbBid{bid: &openrtb.Bid{AdM: vastHeader + `<Ad><InLine><Creatives><Creative><Linear>` +
+				`<MediaFiles><MediaFile delivery="progressive" type="video/mp4"><![CDATA[ http://cdn.com/ad.mp4 ]]></MediaFile></MediaFiles>` +
+				`</Linear></Creative></Creatives></InLine></Ad></VAST>`}, bidType: openrtb_ext.BidTypeVideo},
+			expectedReason: "markup loads insecure http content in secure imp \"secureImp\"",
+		},
+		{
+			description:    "VAST With Insecure Tracking",
+			imp:            secureImp,
+			bid:            &pbsOrtbBid{bid: &openrtb.Bid{AdM: vastHeader + `<Ad><InLine><Tracking event="start">http://cdn.com/start</Tracking></InLine></Ad></VAST>`}, bidType: openrtb_ext.BidTypeVideo},
+			expectedReason: "markup loads insecure http content in secure imp \"secureImp\"",
+		},
+		{
+			description: "Secure Native",
+			imp:         secureImp,
+			bid:         &pbsOrtbBid{bid: &openrtb.Bid{AdM: `{"assets":[{"id":1,"img":{"url":"https://cdn.com/ad.png"}}],"link":{"url":"http://landing.com"},"imptrackers":["https://cdn.com/imp"]}`}, bidType: openrtb_ext.BidTypeNative},
+		},
+		{
+			description:    "Native With Insecure Image",
+			imp:            secureImp,
+			bid:            &pbsOrtbBid{bid: &openrtb.Bid{AdM: `{"native":{"assets":[{"id":1,"img":{"url":"http://cdn.com/ad.png"}}],"link":{"url":"https://landing.com"}}}`}, bidType: openrtb_ext.BidTypeNative},
+			expectedReason: "markup loads insecure http content in secure imp \"secureImp\"",
+		},
+		{
+			description:    "Native With Insecure Event Tracker",
+			imp:            secureImp,
+			bid:            &pbsOrtbBid{bid: &openrtb.Bid{AdM: `{"assets":[],"link":{"url":"https://landing.com"},"eventtrackers":[{"event":1,"method":1,"url":"http://cdn.com/imp"}]}`}, bidType: openrtb_ext.BidTypeNative},
+			expectedReason: "markup loads insecure http content in secure imp \"secureImp\"",
+		},
+		{
+			description:    "Insecure Nurl",
+			imp:            secureImp,
+			bid:            &pbsOrtbBid{bid: &openrtb.Bid{NURL: "http://cdn.com/vast.xml"}, bidType: openrtb_ext.BidTypeVideo},
+			expectedReason: "nurl is not secure in secure imp \"secureImp\"",
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedReason, validateSecureMarkup(test.imp, test.bid), test.description)
+	}
+}
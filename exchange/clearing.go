@@ -0,0 +1,79 @@
+package exchange
+
+import (
+	"math"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// applyClearingPrices sets the price of the winning bid of each imp to the price it clears the auction of the account at,
+// and records that price in bid.ext.prebid.clearingprice. The first price auction leaves the bids untouched.
+//
+// The prices are compared after bid adjustment and currency conversion, so all the seats share the currency of the response.
+// A winning bid never clears above its own price, and the deals always clear at their own price.
+func applyClearingPrices(cfg config.AccountAuction, seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, preferDeals bool) {
+	if cfg.ClearingMode != config.ClearingModeSecondPrice && cfg.ClearingMode != config.ClearingModeSoftFloor {
+		return
+	}
+
+	winningBids := make(map[string]*pbsOrtbBid)
+	for _, seatBid := range seatBids {
+		if seatBid == nil {
+			continue
+		}
+		for _, bid := range seatBid.bids {
+			if wbid, ok := winningBids[bid.bid.ImpID]; !ok || isNewWinningBid(bid, wbid, preferDeals) {
+				winningBids[bid.bid.ImpID] = bid
+			}
+		}
+	}
+
+	secondPrices := make(map[string]float64, len(winningBids))
+	for _, seatBid := range seatBids {
+		if seatBid == nil {
+			continue
+		}
+		for _, bid := range seatBid.bids {
+			if bid == winningBids[bid.bid.ImpID] {
+				continue
+			}
+			if secondPrice, ok := secondPrices[bid.bid.ImpID]; !ok || bid.bid.Price > secondPrice {
+				secondPrices[bid.bid.ImpID] = bid.bid.Price
+			}
+		}
+	}
+
+	for impID, bid := range winningBids {
+		if len(bid.bid.DealID) == 0 {
+			secondPrice, competed := secondPrices[impID]
+			bid.bid.Price = clearingPrice(cfg, bid.bid.Price, secondPrice, competed)
+		}
+		bid.clearingPrice = bid.bid.Price
+	}
+}
+
+// clearingPrice is the price a winning bid clears the auction at. A bid which didn't compete with any other
+// clears the second price auction at its own price, and the soft floor auction at the soft floor.
+func clearingPrice(cfg config.AccountAuction, price float64, secondPrice float64, competed bool) float64 {
+	var clearing float64
+	switch cfg.ClearingMode {
+	case config.ClearingModeSecondPrice:
+		if !competed {
+			return price
+		}
+		clearing = secondPrice + cfg.PriceIncrement
+	case config.ClearingModeSoftFloor:
+		if price < cfg.SoftFloor {
+			return price
+		}
+		clearing = cfg.SoftFloor
+		if competed {
+			clearing = math.Max(clearing, secondPrice+cfg.PriceIncrement)
+		}
+	default:
+		return price
+	}
+	// Round away the float error of the increment, so that 1.2 + 0.01 clears at 1.21.
+	return math.Min(price, math.Round(clearing*10000)/10000)
+}
@@ -0,0 +1,136 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyClearingPrices(t *testing.T) {
+	testCases := []struct {
+		description            string
+		auction                config.AccountAuction
+		preferDeals            bool
+		appnexusBids           []*openrtb.Bid
+		rubiconBids            []*openrtb.Bid
+		expectedPrices         map[string]float64
+		expectedClearingPrices map[string]float64
+	}{
+		{
+			description:            "First Price",
+			auction:                config.AccountAuction{ClearingMode: config.ClearingModeFirstPrice},
+			appnexusBids:           []*openrtb.Bid{{ID: "apn", ImpID: "imp1", Price: 2}},
+			rubiconBids:            []*openrtb.Bid{{ID: "rubi", ImpID: "imp1", Price: 1}},
+			expectedPrices:         map[string]float64{"apn": 2, "rubi": 1},
+			expectedClearingPrices: map[string]float64{"apn": 0, "rubi": 0},
+		},
+		{
+			description:            "Second Price",
+			auction:                config.AccountAuction{ClearingMode: config.ClearingModeSecondPrice, PriceIncrement: 0.01},
+			appnexusBids:           []*openrtb.Bid{{ID: "apn", ImpID: "imp1", Price: 2}, {ID: "apn2", ImpID: "imp2", Price: 3}},
+			rubiconBids:            []*openrtb.Bid{{ID: "rubi", ImpID: "imp1", Price: 1.2}},
+			expectedPrices:         map[string]float64{"apn": 1.21, "apn2": 3, "rubi": 1.2},
+			expectedClearingPrices: map[string]float64{"apn": 1.21, "apn2": 3, "rubi": 0},
+		},
+		{
+			description:            "Second Price - Never Above The Bid",
+			auction:                config.AccountAuction{ClearingMode: config.ClearingModeSecondPrice, PriceIncrement: 0.5},
+			appnexusBids:           []*openrtb.Bid{{ID: "apn", ImpID: "imp1", Price: 2}},
+			rubiconBids:            []*openrtb.Bid{{ID: "rubi", ImpID: "imp1", Price: 1.8}},
+			expectedPrices:         map[string]float64{"apn": 2, "rubi": 1.8},
+			expectedClearingPrices: map[string]float64{"apn": 2, "rubi": 0},
+		},
+		{
+			description:            "Second Price - Deal Clears At Its Own Price",
+			auction:                config.AccountAuction{ClearingMode: config.ClearingModeSecondPrice, PriceIncrement: 0.01},
+			preferDeals:            true,
+			appnexusBids:           []*openrtb.Bid{{ID: "apn", ImpID: "imp1", Price: 2}},
+			rubiconBids:            []*openrtb.Bid{{ID: "rubi", ImpID: "imp1", Price: 1.5, DealID: "deal"}},
+			expectedPrices:         map[string]float64{"apn": 2, "rubi": 1.5},
+			expectedClearingPrices: map[string]float64{"apn": 0, "rubi": 1.5},
+		},
+		{
+			description:            "Soft Floor - Above The Soft Floor",
+			auction:                config.AccountAuction{ClearingMode: config.ClearingModeSoftFloor, PriceIncrement: 0.01, SoftFloor: 1.5},
+			appnexusBids:           []*openrtb.Bid{{ID: "apn", ImpID: "imp1", Price: 3}, {ID: "apn2", ImpID: "imp2", Price: 3}},
+			rubiconBids:            []*openrtb.Bid{{ID: "rubi", ImpID: "imp1", Price: 1}, {ID: "rubi2", ImpID: "imp2", Price: 2}},
+			expectedPrices:         map[string]float64{"apn": 1.5, "apn2": 2.01, "rubi": 1, "rubi2": 2},
+			expectedClearingPrices: map[string]float64{"apn": 1.5, "apn2": 2.01, "rubi": 0, "rubi2": 0},
+		},
+		{
+			description:            "Soft Floor - Below The Soft Floor",
+			auction:                config.AccountAuction{ClearingMode: config.ClearingModeSoftFloor, PriceIncrement: 0.01, SoftFloor: 1.5},
+			appnexusBids:           []*openrtb.Bid{{ID: "apn", ImpID: "imp1", Price: 1.2}},
+			rubiconBids:            []*openrtb.Bid{{ID: "rubi", ImpID: "imp1", Price: 0.5}},
+			expectedPrices:         map[string]float64{"apn": 1.2, "rubi": 0.5},
+			expectedClearingPrices: map[string]float64{"apn": 1.2, "rubi": 0},
+		},
+		{
+			description:            "Soft Floor - No Competition",
+			auction:                config.AccountAuction{ClearingMode: config.ClearingModeSoftFloor, SoftFloor: 1.5},
+			appnexusBids:           []*openrtb.Bid{{ID: "apn", ImpID: "imp1", Price: 4}},
+			expectedPrices:         map[string]float64{"apn": 1.5},
+			expectedClearingPrices: map[string]float64{"apn": 1.5},
+		},
+	}
+
+	for _, test := range testCases {
+		bids := make(map[string]*pbsOrtbBid)
+		seatBids := make(map[openrtb_ext.BidderName]*pbsOrtbSeatBid)
+		for bidder, bidderBids := range map[openrtb_ext.BidderName][]*openrtb.Bid{"appnexus": test.appnexusBids, "rubicon": test.rubiconBids} {
+			seatBid := &pbsOrtbSeatBid{currency: "USD"}
+			for _, bid := range bidderBids {
+				bids[bid.ID] = &pbsOrtbBid{bid: bid, bidType: openrtb_ext.BidTypeBanner}
+				seatBid.bids = append(seatBid.bids, bids[bid.ID])
+			}
+			seatBids[bidder] = seatBid
+		}
+
+		applyClearingPrices(test.auction, seatBids, test.preferDeals)
+
+		for id, bid := range bids {
+			assert.Equal(t, test.expectedPrices[id], bid.bid.Price, "%s: price of bid %s", test.description, id)
+			assert.Equal(t, test.expectedClearingPrices[id], bid.clearingPrice, "%s: clearing price of bid %s", test.description, id)
+		}
+	}
+}
+
+func TestApplyClearingPricesAfterCategoryDedupe(t *testing.T) {
+	categoriesFetcher, err := newCategoryFetcher("./test/category-mapping")
+	if err != nil {
+		t.Fatalf("Failed to create a category Fetcher: %v", err)
+	}
+	requestExt := newExtRequest()
+	targData := &targetData{priceGranularity: requestExt.Prebid.Targeting.PriceGranularity, includeWinners: true}
+	auction := config.AccountAuction{ClearingMode: config.ClearingModeSecondPrice, PriceIncrement: 0.01}
+
+	// The winner of imp1 shares its category with the bid for imp2, which bids less, so the dedupe keeps the winner
+	// of imp1. Clearing it first would have bucketed it at 10.01 and deduplicated it instead.
+	apnWinner := openrtb.Bid{ID: "apn-winner", ImpID: "imp1", Price: 20, Cat: []string{"IAB1-3"}}
+	apnRunnerUp := openrtb.Bid{ID: "apn-runner-up", ImpID: "imp1", Price: 10, Cat: []string{"IAB1-4"}}
+	rubiSameCategory := openrtb.Bid{ID: "rubi-same-category", ImpID: "imp2", Price: 15, Cat: []string{"IAB1-3"}}
+	video := &openrtb_ext.ExtBidPrebidVideo{Duration: 30}
+	adapterBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		openrtb_ext.BidderAppnexus: {bids: []*pbsOrtbBid{
+			{bid: &apnWinner, bidType: openrtb_ext.BidTypeVideo, bidVideo: video},
+			{bid: &apnRunnerUp, bidType: openrtb_ext.BidTypeVideo, bidVideo: video},
+		}},
+		openrtb_ext.BidderRubicon: {bids: []*pbsOrtbBid{
+			{bid: &rubiSameCategory, bidType: openrtb_ext.BidTypeVideo, bidVideo: video},
+		}},
+	}
+
+	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData)
+	applyClearingPrices(auction, adapterBids, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bid rejected [bid ID: rubi-same-category] reason: Bid was deduplicated"}, rejections)
+	assert.Equal(t, map[string]string{"apn-winner": "20.00_Electronics_30s", "apn-runner-up": "10.00_Sports_30s"}, bidCategory)
+	assert.Empty(t, adapterBids[openrtb_ext.BidderRubicon].bids)
+	assert.Equal(t, 10.01, apnWinner.Price, "Winning bid price")
+	assert.Equal(t, 10.01, adapterBids[openrtb_ext.BidderAppnexus].bids[0].clearingPrice, "Winning bid clearing price")
+	assert.Equal(t, float64(10), apnRunnerUp.Price, "Runner up bid price")
+}
@@ -23,7 +23,9 @@ import (
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/floors"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/hooks"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
@@ -55,6 +57,9 @@ type exchange struct {
 	me                  metrics.MetricsEngine
 	cache               prebid_cache_client.Client
 	cacheTime           time.Duration
+	tmaxBudget          *tmaxBudget
+	softDeadline        *softDeadline
+	bidValidations      config.Validations
 	gDPR                gdpr.Permissions
 	currencyConverter   *currency.RateConverter
 	UsersyncIfAmbiguous bool
@@ -86,11 +91,15 @@ func NewExchange(adapters map[openrtb_ext.BidderName]adaptedBidder, cache prebid
 		currencyConverter:   currencyConverter,
 		gDPR:                gDPR,
 		me:                  metricsEngine,
+		tmaxBudget:          newTmaxBudget(cfg.TmaxAdjustments, metricsEngine),
+		softDeadline:        newSoftDeadline(cfg.SoftDeadline),
+		bidValidations:      cfg.Validations,
 		UsersyncIfAmbiguous: cfg.GDPR.UsersyncIfAmbiguous,
 		privacyConfig: config.Privacy{
-			CCPA: cfg.CCPA,
-			GDPR: cfg.GDPR,
-			LMT:  cfg.LMT,
+			CCPA:           cfg.CCPA,
+			GDPR:           cfg.GDPR,
+			LMT:            cfg.LMT,
+			USStatePrivacy: cfg.USStatePrivacy,
 		},
 	}
 }
@@ -103,6 +112,8 @@ type AuctionRequest struct {
 	UserSyncs   IdFetcher
 	RequestType metrics.RequestType
 	StartTime   time.Time
+	// HookExecutor runs the hooks of the modules at the stages of the auction. It may be nil.
+	HookExecutor *hooks.Executor
 
 	// LegacyLabels is included here for temporary compatability with cleanOpenRTBRequests
 	// in HoldAuction until we get to factoring it away. Do not use for anything new.
@@ -119,6 +130,11 @@ type BidderRequest struct {
 }
 
 func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *DebugLog) (*openrtb.BidResponse, error) {
+	var rejectErr *hooks.RejectError
+	if r.BidRequest, rejectErr = r.HookExecutor.ExecuteProcessedAuctionRequestStage(ctx, r.BidRequest); rejectErr != nil {
+		return rejectedBidResponse(r.HookExecutor, r.BidRequest, rejectErr)
+	}
+
 	var err error
 	requestExt, err := extractBidRequestExt(r.BidRequest)
 	if err != nil {
@@ -138,6 +154,8 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 
 	bidAdjustmentFactors := getExtBidAdjustmentFactors(requestExt)
 
+	multiBid, multiBidErrs := getExtMultiBid(requestExt)
+
 	recordImpMetrics(r.BidRequest, e.me)
 
 	// Make our best guess if GDPR applies
@@ -145,9 +163,21 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 
 	// Slice of BidRequests, each a copy of the original cleaned to only contain bidder data for the named bidder
 	bidderRequests, privacyLabels, errs := cleanOpenRTBRequests(ctx, r, requestExt, e.gDPR, usersyncIfAmbiguous, e.privacyConfig)
+	errs = append(errs, multiBidErrs...)
 
 	e.me.RecordRequestPrivacy(privacyLabels)
 
+	// Get currency rates conversions for the auction
+	conversions := e.currencyConverter.Rates()
+
+	floorRules, floorErrs := floors.Resolve(r.Account.PriceFloors, requestExt.Prebid.Floors)
+	errs = append(errs, floorErrs...)
+	var floorsReport *openrtb_ext.ExtResponseFloors
+	if floorRules != nil {
+		floorsReport, floorErrs = setBidderRequestFloors(floorRules, bidderRequests, conversions)
+		errs = append(errs, floorErrs...)
+	}
+
 	// List of bidders we have requests for.
 	liveAdapters := listBiddersWithRequests(bidderRequests)
 
@@ -156,10 +186,25 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 	auctionCtx, cancel := e.makeAuctionContext(ctx, cacheInstructions.cacheBids)
 	defer cancel()
 
-	// Get currency rates conversions for the auction
-	conversions := e.currencyConverter.Rates()
+	bidValidations := e.bidValidations.WithAccountOverrides(r.Account.Validations)
+
+	adapterBids, adapterExtra, anyBidsReturned := e.getAllBids(auctionCtx, bidderRequests, bidAdjustmentFactors, conversions, bidValidations, r.HookExecutor, r.Account.ID)
+
+	if anyBidsReturned && floorRules != nil {
+		for _, message := range enforceFloors(floorRules, r.BidRequest, adapterBids, conversions) {
+			errs = append(errs, errors.New(message))
+		}
+		anyBidsReturned = anyBids(adapterBids)
+	}
 
-	adapterBids, adapterExtra, anyBidsReturned := e.getAllBids(auctionCtx, bidderRequests, bidAdjustmentFactors, conversions)
+	if anyBidsReturned && len(multiBid) > 0 {
+		applyMultiBidLimits(adapterBids, multiBid)
+	}
+
+	if anyBidsReturned && r.HookExecutor != nil {
+		executeAllProcessedBidResponsesStage(ctx, r.HookExecutor, adapterBids)
+		anyBidsReturned = anyBids(adapterBids)
+	}
 
 	var auc *auction
 	var cacheErrs []error
@@ -178,13 +223,18 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 			}
 		}
 
+		// The bids are bucketed and deduplicated by category at the price they bid, and only the bids which survive
+		// compete for the clearing price.
+		applyClearingPrices(r.Account.Auction, adapterBids, targData != nil && targData.preferDeals)
+
 		if targData != nil {
 			// A non-nil auction is only needed if targeting is active. (It is used below this block to extract cache keys)
 			auc = newAuction(adapterBids, len(r.BidRequest.Imp), targData.preferDeals)
+			auc.addMultiBids(adapterBids, multiBid)
 			auc.setRoundedPrices(targData.priceGranularity)
 
 			if requestExt.Prebid.SupportDeals {
-				dealErrs := applyDealSupport(r.BidRequest, auc, bidCategory)
+				dealErrs := applyDealSupport(r.BidRequest, auc, bidCategory, r.Account.DealTiers)
 				errs = append(errs, dealErrs...)
 			}
 
@@ -194,10 +244,29 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 			}
 			targData.setTargeting(auc, r.BidRequest.App != nil, bidCategory)
 
+			if requestExt.Prebid.ReturnAllBidStatus {
+				addLostBids(auc, adapterBids, targData.preferDeals)
+			}
 		}
+
+		e.recordWonBids(auc, adapterBids, len(r.BidRequest.Imp), requestExt.Prebid.Aliases)
 	}
 
 	bidResponseExt := e.makeExtBidResponse(adapterBids, adapterExtra, r, debugInfo, errs)
+	if floorsReport != nil {
+		if bidResponseExt.Prebid == nil {
+			bidResponseExt.Prebid = &openrtb_ext.ExtResponsePrebid{}
+		}
+		bidResponseExt.Prebid.Floors = floorsReport
+	}
+	if requestExt.Prebid.ReturnAllBidStatus {
+		if seatNonBids := makeSeatNonBids(adapterBids); len(seatNonBids) > 0 {
+			if bidResponseExt.Prebid == nil {
+				bidResponseExt.Prebid = &openrtb_ext.ExtResponsePrebid{}
+			}
+			bidResponseExt.Prebid.SeatNonBid = seatNonBids
+		}
+	}
 
 	// Ensure caching errors are added in case auc.doCache was called and errors were returned
 	if len(cacheErrs) > 0 {
@@ -222,7 +291,11 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 	}
 
 	// Build the response
-	return e.buildBidResponse(ctx, liveAdapters, adapterBids, r.BidRequest, adapterExtra, auc, bidResponseExt, cacheInstructions.returnCreative, errs)
+	bidResponse, err := e.buildBidResponse(ctx, liveAdapters, adapterBids, r.BidRequest, adapterExtra, auc, bidResponseExt, cacheInstructions.returnCreative, errs)
+	if err != nil {
+		return bidResponse, err
+	}
+	return executeAuctionResponseStage(ctx, r.HookExecutor, bidResponse)
 }
 
 func (e *exchange) parseUsersyncIfAmbiguous(bidRequest *openrtb.BidRequest) bool {
@@ -261,16 +334,21 @@ func recordImpMetrics(bidRequest *openrtb.BidRequest, metricsEngine metrics.Metr
 }
 
 // applyDealSupport updates targeting keys with deal prefixes if minimum deal tier exceeded
-func applyDealSupport(bidRequest *openrtb.BidRequest, auc *auction, bidCategory map[string]string) []error {
+func applyDealSupport(bidRequest *openrtb.BidRequest, auc *auction, bidCategory map[string]string, accountDealTiers openrtb_ext.DealTierBidderMap) []error {
 	errs := []error{}
-	impDealMap := getDealTiers(bidRequest)
+	impDealMap := getDealTiers(bidRequest, accountDealTiers)
 
 	for impID, topBidsPerImp := range auc.winningBidsByBidder {
 		impDeal := impDealMap[impID]
-		for bidder, topBidPerBidder := range topBidsPerImp {
+		for bidderCode, topBidPerBidder := range topBidsPerImp {
+			bidder := auc.seatName(bidderCode)
 			if topBidPerBidder.dealPriority > 0 {
 				if validateDealTier(impDeal[bidder]) {
-					updateHbPbCatDur(topBidPerBidder, impDeal[bidder], bidCategory)
+					if topBidPerBidder.bidType == openrtb_ext.BidTypeVideo {
+						updateHbPbCatDur(topBidPerBidder, impDeal[bidder], bidCategory)
+					} else {
+						updateHbPb(auc, topBidPerBidder, impDeal[bidder])
+					}
 				} else {
 					errs = append(errs, fmt.Errorf("dealTier configuration invalid for bidder '%s', imp ID '%s'", string(bidder), impID))
 				}
@@ -281,15 +359,23 @@ func applyDealSupport(bidRequest *openrtb.BidRequest, auc *auction, bidCategory
 	return errs
 }
 
-// getDealTiers creates map of impression to bidder deal tier configuration
-func getDealTiers(bidRequest *openrtb.BidRequest) map[string]openrtb_ext.DealTierBidderMap {
+// getDealTiers creates map of impression to bidder deal tier configuration.
+// The deal tiers of the imp take precedence over the default ones of the account.
+func getDealTiers(bidRequest *openrtb.BidRequest, accountDealTiers openrtb_ext.DealTierBidderMap) map[string]openrtb_ext.DealTierBidderMap {
 	impDealMap := make(map[string]openrtb_ext.DealTierBidderMap)
 
 	for _, imp := range bidRequest.Imp {
-		dealTierBidderMap, err := openrtb_ext.ReadDealTiersFromImp(imp)
-		if err != nil {
+		impDealTiers, err := openrtb_ext.ReadDealTiersFromImp(imp)
+		if err != nil && len(accountDealTiers) == 0 {
 			continue
 		}
+		dealTierBidderMap := make(openrtb_ext.DealTierBidderMap, len(accountDealTiers)+len(impDealTiers))
+		for bidder, dealTier := range accountDealTiers {
+			dealTierBidderMap[bidder] = dealTier
+		}
+		for bidder, dealTier := range impDealTiers {
+			dealTierBidderMap[bidder] = dealTier
+		}
 		impDealMap[imp.ID] = dealTierBidderMap
 	}
 
@@ -300,6 +386,17 @@ func validateDealTier(dealTier openrtb_ext.DealTier) bool {
 	return len(dealTier.Prefix) > 0 && dealTier.MinDealTier > 0
 }
 
+// updateHbPb replaces the price bucket of a banner or native bid with its deal tier, such as "tier5".
+func updateHbPb(auc *auction, bid *pbsOrtbBid, dealTier openrtb_ext.DealTier) {
+	if bid.dealPriority >= dealTier.MinDealTier {
+		bid.dealTierSatisfied = true
+
+		if _, ok := auc.roundedPrices[bid]; ok {
+			auc.roundedPrices[bid] = fmt.Sprintf("%s%d", dealTier.Prefix, bid.dealPriority)
+		}
+	}
+}
+
 func updateHbPbCatDur(bid *pbsOrtbBid, dealTier openrtb_ext.DealTier, bidCategory map[string]string) {
 	if bid.dealPriority >= dealTier.MinDealTier {
 		prefixTier := fmt.Sprintf("%s%d_", dealTier.Prefix, bid.dealPriority)
@@ -336,7 +433,10 @@ func (e *exchange) getAllBids(
 	ctx context.Context,
 	bidderRequests []BidderRequest,
 	bidAdjustments map[string]float64,
-	conversions currency.Conversions) (
+	conversions currency.Conversions,
+	bidValidations config.Validations,
+	hookExecutor *hooks.Executor,
+	accountID string) (
 	map[openrtb_ext.BidderName]*pbsOrtbSeatBid,
 	map[openrtb_ext.BidderName]*seatResponseExtra, bool) {
 	// Set up pointers to the bid results
@@ -345,6 +445,13 @@ func (e *exchange) getAllBids(
 	chBids := make(chan *bidResponseWrapper, len(bidderRequests))
 	bidsFound := false
 
+	// The bidders still running at the soft deadline are cancelled. The channel is buffered, so that they don't block on it.
+	auctionStart := time.Now()
+	softDeadline, stopSoftDeadline := e.softDeadline.start(ctx)
+	defer stopSoftDeadline()
+	ctx, cancelBidders := context.WithCancel(ctx)
+	defer cancelBidders()
+
 	for _, bidder := range bidderRequests {
 		// Here we actually call the adapters and collect the bids.
 		bidderRunner := e.recoverSafely(bidderRequests, func(bidderRequest BidderRequest, conversions currency.Conversions) {
@@ -367,7 +474,27 @@ func (e *exchange) getAllBids(
 			}
 			var reqInfo adapters.ExtraRequestInfo
 			reqInfo.PbsEntryPoint = bidderRequest.BidderLabels.RType
-			bids, err := e.adapterMap[bidderRequest.BidderCoreName].requestBid(ctx, bidderRequest.BidRequest, bidderRequest.BidderName, adjustmentFactor, conversions, &reqInfo)
+			reqInfo.CurrencyConversions = conversions
+			reqInfo.AccountID = accountID
+
+			bidderCtx, cancel, bidderTmax := e.tmaxBudget.makeBidderContext(ctx, bidderRequest.BidderCoreName)
+			defer cancel()
+			if bidderTmax > 0 {
+				bidderRequest.BidRequest.TMax = bidderTmax
+				reqInfo.BidderTmax = bidderTmax
+			}
+			var bids *pbsOrtbSeatBid
+			var err []error
+			// A bidder skipped by a hook isn't called, and is reported with the rejection as its error.
+			if bidRequest, rejectErr := hookExecutor.ExecuteBidderRequestStage(bidderCtx, bidderRequest.BidderName, bidderRequest.BidRequest); rejectErr != nil {
+				err = append(err, rejectErr)
+			} else {
+				bids, err = e.adapterMap[bidderRequest.BidderCoreName].requestBid(bidderCtx, bidRequest, bidderRequest.BidderName, adjustmentFactor, conversions, &reqInfo)
+				if rejectErr := executeRawBidderResponseStage(bidderCtx, hookExecutor, bidderRequest.BidderName, bids); rejectErr != nil {
+					err = append(err, rejectErr)
+				}
+				err = append(err, applyBidValidations(bidRequest, bidderRequest.BidderCoreName, bids, bidValidations, e.me)...)
+			}
 
 			// Add in time reporting
 			elapsed := time.Since(start)
@@ -400,10 +527,17 @@ func (e *exchange) getAllBids(
 	}
 	// Wait for the bidders to do their thing
 	for i := 0; i < len(bidderRequests); i++ {
-		brw := <-chBids
+		var brw *bidResponseWrapper
+		select {
+		case brw = <-chBids:
+		case <-softDeadline:
+			cancelBidders()
+			e.recordLateBidders(bidderRequests, adapterExtra, time.Since(auctionStart))
+			return adapterBids, adapterExtra, bidsFound
+		}
 
-		//if bidder returned no bids back - remove bidder from further processing
-		if brw.adapterBids != nil && len(brw.adapterBids.bids) != 0 {
+		//if bidder returned no bids back - remove bidder from further processing, unless some of its bids were dropped
+		if brw.adapterBids != nil && (len(brw.adapterBids.bids) != 0 || len(brw.adapterBids.nonBids) != 0) {
 			adapterBids[brw.bidder] = brw.adapterBids
 		}
 		//but we need to add all bidders data to adapterExtra to have metrics and other metadata
@@ -417,6 +551,23 @@ func (e *exchange) getAllBids(
 	return adapterBids, adapterExtra, bidsFound
 }
 
+// recordLateBidders reports the bidders which hadn't responded by the soft deadline, both in the metrics and as a
+// timeout error in the response.
+func (e *exchange) recordLateBidders(bidderRequests []BidderRequest, adapterExtra map[openrtb_ext.BidderName]*seatResponseExtra, elapsed time.Duration) {
+	for _, bidderRequest := range bidderRequests {
+		if _, responded := adapterExtra[bidderRequest.BidderName]; responded {
+			continue
+		}
+		e.me.RecordAdapterLateResponse(bidderRequest.BidderCoreName)
+		adapterExtra[bidderRequest.BidderName] = &seatResponseExtra{
+			ResponseTimeMillis: int(elapsed / time.Millisecond),
+			Errors: errsToBidderErrors([]error{&errortypes.Timeout{
+				Message: fmt.Sprintf("Bidder %s didn't respond by the soft deadline of the auction", bidderRequest.BidderName),
+			}}),
+		}
+	}
+}
+
 func (e *exchange) recoverSafely(bidderRequests []BidderRequest,
 	inner func(BidderRequest, currency.Conversions),
 	chBids chan *bidResponseWrapper) func(BidderRequest, currency.Conversions) {
@@ -448,6 +599,16 @@ func (e *exchange) recoverSafely(bidderRequests []BidderRequest,
 	}
 }
 
+// anyBids returns true if at least one seat still has bids.
+func anyBids(adapterBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid) bool {
+	for _, seatBid := range adapterBids {
+		if seatBid != nil && len(seatBid.bids) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func bidsToMetric(bids *pbsOrtbSeatBid) metrics.AdapterBid {
 	if bids == nil || len(bids.bids) == 0 {
 		return metrics.AdapterBidNone
@@ -590,6 +751,7 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 					//on receiving bids from adapters if no unique IAB category is returned  or if no ad server category is returned discard the bid
 					bidsToRemove = append(bidsToRemove, bidInd)
 					rejections = updateRejections(rejections, bidID, "Bid did not contain a category")
+					seatBid.addNonBid(bid.bid, errortypes.CreativeFilteredNonBidCode)
 					continue
 				}
 				if translateCategories {
@@ -601,6 +763,7 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 						bidsToRemove = append(bidsToRemove, bidInd)
 						reason := fmt.Sprintf("Category mapping file for primary ad server: '%s', publisher: '%s' not found", primaryAdServer, publisher)
 						rejections = updateRejections(rejections, bidID, reason)
+						seatBid.addNonBid(bid.bid, errortypes.CreativeFilteredNonBidCode)
 						continue
 					}
 				} else {
@@ -621,6 +784,7 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 				if duration > durationRange[len(durationRange)-1] {
 					bidsToRemove = append(bidsToRemove, bidInd)
 					rejections = updateRejections(rejections, bidID, "Bid duration exceeds maximum allowed")
+					seatBid.addNonBid(bid.bid, errortypes.CreativeFilteredNonBidCode)
 					continue
 				}
 				for _, dur := range durationRange {
@@ -668,9 +832,11 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 						// An older bid from the current bidder
 						bidsToRemove = append(bidsToRemove, dupe.bidIndex)
 						rejections = updateRejections(rejections, dupe.bidID, "Bid was deduplicated")
+						seatBid.addNonBid(seatBid.bids[dupe.bidIndex].bid, errortypes.CategoryExclusionNonBidCode)
 					} else {
 						// An older bid from a different seatBid we've already finished with
 						oldSeatBid := (seatBids)[dupe.bidderName]
+						oldSeatBid.addNonBid(oldSeatBid.bids[dupe.bidIndex].bid, errortypes.CategoryExclusionNonBidCode)
 						if len(oldSeatBid.bids) == 1 {
 							seatBidsToRemove = append(seatBidsToRemove, dupe.bidderName)
 							rejections = updateRejections(rejections, dupe.bidID, "Bid was deduplicated")
@@ -683,6 +849,7 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 					// Remove this bid
 					bidsToRemove = append(bidsToRemove, bidInd)
 					rejections = updateRejections(rejections, bidID, "Bid was deduplicated")
+					seatBid.addNonBid(bid.bid, errortypes.CategoryExclusionNonBidCode)
 					continue
 				}
 			}
@@ -744,7 +911,7 @@ func (e *exchange) makeExtBidResponse(adapterBids map[openrtb_ext.BidderName]*pb
 		}
 	}
 	if !r.StartTime.IsZero() {
-		// auctiontimestamp is the only response.ext.prebid attribute we may emit
+		// auctiontimestamp is always emitted, other response.ext.prebid attributes are optional
 		bidResponseExt.Prebid = &openrtb_ext.ExtResponsePrebid{
 			AuctionTimestamp: r.StartTime.UnixNano() / 1e+6,
 		}
@@ -815,6 +982,8 @@ func (e *exchange) makeBid(Bids []*pbsOrtbBid, auc *auction, returnCreative bool
 				Video:             thisBid.bidVideo,
 				DealPriority:      thisBid.dealPriority,
 				DealTierSatisfied: thisBid.dealTierSatisfied,
+				ClearingPrice:     thisBid.clearingPrice,
+				Reused:            thisBid.reused,
 			},
 		}
 		if cacheInfo, found := e.getBidCacheInfo(thisBid, auc); found {
@@ -53,7 +53,7 @@ func TestNewExchange(t *testing.T) {
 	}
 
 	biddersInfo := adapters.ParseBidderInfos(cfg.Adapters, "../static/bidder-info", knownAdapters)
-	adapters, adaptersErr := BuildAdapters(server.Client(), cfg, biddersInfo, &metricsConf.DummyMetricsEngine{})
+	adapters, adaptersErr := BuildAdapters(server.Client(), cfg, biddersInfo, &metricsConf.DummyMetricsEngine{}, nil)
 	if adaptersErr != nil {
 		t.Fatalf("Error intializing adapters: %v", adaptersErr)
 	}
@@ -97,7 +97,7 @@ func TestCharacterEscape(t *testing.T) {
 	defer server.Close()
 
 	biddersInfo := adapters.ParseBidderInfos(cfg.Adapters, "../static/bidder-info", openrtb_ext.CoreBidderNames())
-	adapters, adaptersErr := BuildAdapters(server.Client(), cfg, biddersInfo, &metricsConf.DummyMetricsEngine{})
+	adapters, adaptersErr := BuildAdapters(server.Client(), cfg, biddersInfo, &metricsConf.DummyMetricsEngine{}, nil)
 	if adaptersErr != nil {
 		t.Fatalf("Error intializing adapters: %v", adaptersErr)
 	}
@@ -523,7 +523,7 @@ func TestGetBidCacheInfoEndToEnd(t *testing.T) {
 	defer server.Close()
 
 	biddersInfo := adapters.ParseBidderInfos(cfg.Adapters, "../static/bidder-info", openrtb_ext.CoreBidderNames())
-	adapters, adaptersErr := BuildAdapters(server.Client(), cfg, biddersInfo, &metricsConf.DummyMetricsEngine{})
+	adapters, adaptersErr := BuildAdapters(server.Client(), cfg, biddersInfo, &metricsConf.DummyMetricsEngine{}, nil)
 	if adaptersErr != nil {
 		t.Fatalf("Error intializing adapters: %v", adaptersErr)
 	}
@@ -872,7 +872,7 @@ func TestBidResponseCurrency(t *testing.T) {
 	defer server.Close()
 
 	biddersInfo := adapters.ParseBidderInfos(cfg.Adapters, "../static/bidder-info", openrtb_ext.CoreBidderNames())
-	adapters, adaptersErr := BuildAdapters(server.Client(), cfg, biddersInfo, &metricsConf.DummyMetricsEngine{})
+	adapters, adaptersErr := BuildAdapters(server.Client(), cfg, biddersInfo, &metricsConf.DummyMetricsEngine{}, nil)
 	if adaptersErr != nil {
 		t.Fatalf("Error intializing adapters: %v", adaptersErr)
 	}
@@ -1046,7 +1046,7 @@ func TestRaceIntegration(t *testing.T) {
 	}
 
 	biddersInfo := adapters.ParseBidderInfos(cfg.Adapters, "../static/bidder-info", openrtb_ext.CoreBidderNames())
-	adapters, adaptersErr := BuildAdapters(server.Client(), cfg, biddersInfo, &metricsConf.DummyMetricsEngine{})
+	adapters, adaptersErr := BuildAdapters(server.Client(), cfg, biddersInfo, &metricsConf.DummyMetricsEngine{}, nil)
 	if adaptersErr != nil {
 		t.Fatalf("Error intializing adapters: %v", adaptersErr)
 	}
@@ -1143,7 +1143,7 @@ func TestPanicRecovery(t *testing.T) {
 	}
 
 	biddersInfo := adapters.ParseBidderInfos(cfg.Adapters, "../static/bidder-info", openrtb_ext.CoreBidderNames())
-	adapters, adaptersErr := BuildAdapters(&http.Client{}, cfg, biddersInfo, &metricsConf.DummyMetricsEngine{})
+	adapters, adaptersErr := BuildAdapters(&http.Client{}, cfg, biddersInfo, &metricsConf.DummyMetricsEngine{}, nil)
 	if adaptersErr != nil {
 		t.Fatalf("Error intializing adapters: %v", adaptersErr)
 	}
@@ -1229,7 +1229,7 @@ func TestPanicRecoveryHighLevel(t *testing.T) {
 	cfg.Adapters["audiencenetwork"] = config.Adapter{Disabled: true}
 
 	biddersInfo := adapters.ParseBidderInfos(cfg.Adapters, "../static/bidder-info", openrtb_ext.CoreBidderNames())
-	adapters, adaptersErr := BuildAdapters(server.Client(), cfg, biddersInfo, &metricsConf.DummyMetricsEngine{})
+	adapters, adaptersErr := BuildAdapters(server.Client(), cfg, biddersInfo, &metricsConf.DummyMetricsEngine{}, nil)
 	if adaptersErr != nil {
 		t.Fatalf("Error intializing adapters: %v", adaptersErr)
 	}
@@ -1611,10 +1611,10 @@ func TestCategoryMapping(t *testing.T) {
 	bid3 := openrtb.Bid{ID: "bid_id3", ImpID: "imp_id3", Price: 30.0000, Cat: cats3, W: 1, H: 1}
 	bid4 := openrtb.Bid{ID: "bid_id4", ImpID: "imp_id4", Price: 40.0000, Cat: cats4, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
-	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, 0, false}
-	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30, PrimaryCategory: "AdapterOverride"}, 0, false}
-	bid1_4 := pbsOrtbBid{&bid4, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
+	bid1_1 := pbsOrtbBid{bid: &bid1, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
+	bid1_2 := pbsOrtbBid{bid: &bid2, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 40}}
+	bid1_3 := pbsOrtbBid{bid: &bid3, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30, PrimaryCategory: "AdapterOverride"}}
+	bid1_4 := pbsOrtbBid{bid: &bid4, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
 
 	innerBids := []*pbsOrtbBid{
 		&bid1_1,
@@ -1623,7 +1623,7 @@ func TestCategoryMapping(t *testing.T) {
 		&bid1_4,
 	}
 
-	seatBid := pbsOrtbSeatBid{innerBids, "USD", nil, nil}
+	seatBid := pbsOrtbSeatBid{innerBids, "USD", nil, nil, nil}
 	bidderName1 := openrtb_ext.BidderName("appnexus")
 
 	adapterBids[bidderName1] = &seatBid
@@ -1666,10 +1666,10 @@ func TestCategoryMappingNoIncludeBrandCategory(t *testing.T) {
 	bid3 := openrtb.Bid{ID: "bid_id3", ImpID: "imp_id3", Price: 30.0000, Cat: cats3, W: 1, H: 1}
 	bid4 := openrtb.Bid{ID: "bid_id4", ImpID: "imp_id4", Price: 40.0000, Cat: cats4, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
-	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, 0, false}
-	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30, PrimaryCategory: "AdapterOverride"}, 0, false}
-	bid1_4 := pbsOrtbBid{&bid4, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 50}, 0, false}
+	bid1_1 := pbsOrtbBid{bid: &bid1, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
+	bid1_2 := pbsOrtbBid{bid: &bid2, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 40}}
+	bid1_3 := pbsOrtbBid{bid: &bid3, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30, PrimaryCategory: "AdapterOverride"}}
+	bid1_4 := pbsOrtbBid{bid: &bid4, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 50}}
 
 	innerBids := []*pbsOrtbBid{
 		&bid1_1,
@@ -1678,7 +1678,7 @@ func TestCategoryMappingNoIncludeBrandCategory(t *testing.T) {
 		&bid1_4,
 	}
 
-	seatBid := pbsOrtbSeatBid{innerBids, "USD", nil, nil}
+	seatBid := pbsOrtbSeatBid{innerBids, "USD", nil, nil, nil}
 	bidderName1 := openrtb_ext.BidderName("appnexus")
 
 	adapterBids[bidderName1] = &seatBid
@@ -1720,9 +1720,9 @@ func TestCategoryMappingTranslateCategoriesNil(t *testing.T) {
 	bid2 := openrtb.Bid{ID: "bid_id2", ImpID: "imp_id2", Price: 20.0000, Cat: cats2, W: 1, H: 1}
 	bid3 := openrtb.Bid{ID: "bid_id3", ImpID: "imp_id3", Price: 30.0000, Cat: cats3, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
-	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, 0, false}
-	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
+	bid1_1 := pbsOrtbBid{bid: &bid1, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
+	bid1_2 := pbsOrtbBid{bid: &bid2, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 40}}
+	bid1_3 := pbsOrtbBid{bid: &bid3, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
 
 	innerBids := []*pbsOrtbBid{
 		&bid1_1,
@@ -1730,7 +1730,7 @@ func TestCategoryMappingTranslateCategoriesNil(t *testing.T) {
 		&bid1_3,
 	}
 
-	seatBid := pbsOrtbSeatBid{innerBids, "USD", nil, nil}
+	seatBid := pbsOrtbSeatBid{innerBids, "USD", nil, nil, nil}
 	bidderName1 := openrtb_ext.BidderName("appnexus")
 
 	adapterBids[bidderName1] = &seatBid
@@ -1802,9 +1802,9 @@ func TestCategoryMappingTranslateCategoriesFalse(t *testing.T) {
 	bid2 := openrtb.Bid{ID: "bid_id2", ImpID: "imp_id2", Price: 20.0000, Cat: cats2, W: 1, H: 1}
 	bid3 := openrtb.Bid{ID: "bid_id3", ImpID: "imp_id3", Price: 30.0000, Cat: cats3, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
-	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, 0, false}
-	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
+	bid1_1 := pbsOrtbBid{bid: &bid1, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
+	bid1_2 := pbsOrtbBid{bid: &bid2, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 40}}
+	bid1_3 := pbsOrtbBid{bid: &bid3, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
 
 	innerBids := []*pbsOrtbBid{
 		&bid1_1,
@@ -1812,7 +1812,7 @@ func TestCategoryMappingTranslateCategoriesFalse(t *testing.T) {
 		&bid1_3,
 	}
 
-	seatBid := pbsOrtbSeatBid{innerBids, "USD", nil, nil}
+	seatBid := pbsOrtbSeatBid{innerBids, "USD", nil, nil, nil}
 	bidderName1 := openrtb_ext.BidderName("appnexus")
 
 	adapterBids[bidderName1] = &seatBid
@@ -1854,11 +1854,11 @@ func TestCategoryDedupe(t *testing.T) {
 	bid4 := openrtb.Bid{ID: "bid_id4", ImpID: "imp_id4", Price: 20.0000, Cat: cats4, W: 1, H: 1}
 	bid5 := openrtb.Bid{ID: "bid_id5", ImpID: "imp_id5", Price: 20.0000, Cat: cats1, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
-	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 50}, 0, false}
-	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
-	bid1_4 := pbsOrtbBid{&bid4, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
-	bid1_5 := pbsOrtbBid{&bid5, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
+	bid1_1 := pbsOrtbBid{bid: &bid1, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
+	bid1_2 := pbsOrtbBid{bid: &bid2, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 50}}
+	bid1_3 := pbsOrtbBid{bid: &bid3, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
+	bid1_4 := pbsOrtbBid{bid: &bid4, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
+	bid1_5 := pbsOrtbBid{bid: &bid5, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
 
 	selectedBids := make(map[string]int)
 	expectedCategories := map[string]string{
@@ -1882,7 +1882,7 @@ func TestCategoryDedupe(t *testing.T) {
 			&bid1_5,
 		}
 
-		seatBid := pbsOrtbSeatBid{innerBids, "USD", nil, nil}
+		seatBid := pbsOrtbSeatBid{innerBids, "USD", nil, nil, nil}
 		bidderName1 := openrtb_ext.BidderName("appnexus")
 
 		adapterBids[bidderName1] = &seatBid
@@ -1933,11 +1933,11 @@ func TestNoCategoryDedupe(t *testing.T) {
 	bid4 := openrtb.Bid{ID: "bid_id4", ImpID: "imp_id4", Price: 20.0000, Cat: cats4, W: 1, H: 1}
 	bid5 := openrtb.Bid{ID: "bid_id5", ImpID: "imp_id5", Price: 10.0000, Cat: cats1, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
-	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
-	bid1_3 := pbsOrtbBid{&bid3, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
-	bid1_4 := pbsOrtbBid{&bid4, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
-	bid1_5 := pbsOrtbBid{&bid5, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
+	bid1_1 := pbsOrtbBid{bid: &bid1, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
+	bid1_2 := pbsOrtbBid{bid: &bid2, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
+	bid1_3 := pbsOrtbBid{bid: &bid3, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
+	bid1_4 := pbsOrtbBid{bid: &bid4, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
+	bid1_5 := pbsOrtbBid{bid: &bid5, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
 
 	selectedBids := make(map[string]int)
 	expectedCategories := map[string]string{
@@ -1962,7 +1962,7 @@ func TestNoCategoryDedupe(t *testing.T) {
 			&bid1_5,
 		}
 
-		seatBid := pbsOrtbSeatBid{innerBids, "USD", nil, nil}
+		seatBid := pbsOrtbSeatBid{innerBids, "USD", nil, nil, nil}
 		bidderName1 := openrtb_ext.BidderName("appnexus")
 
 		adapterBids[bidderName1] = &seatBid
@@ -2013,8 +2013,8 @@ func TestCategoryMappingBidderName(t *testing.T) {
 	bid1 := openrtb.Bid{ID: "bid_id1", ImpID: "imp_id1", Price: 10.0000, Cat: cats1, W: 1, H: 1}
 	bid2 := openrtb.Bid{ID: "bid_id2", ImpID: "imp_id2", Price: 10.0000, Cat: cats2, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
-	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
+	bid1_1 := pbsOrtbBid{bid: &bid1, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
+	bid1_2 := pbsOrtbBid{bid: &bid2, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
 
 	innerBids1 := []*pbsOrtbBid{
 		&bid1_1,
@@ -2023,10 +2023,10 @@ func TestCategoryMappingBidderName(t *testing.T) {
 		&bid1_2,
 	}
 
-	seatBid1 := pbsOrtbSeatBid{innerBids1, "USD", nil, nil}
+	seatBid1 := pbsOrtbSeatBid{innerBids1, "USD", nil, nil, nil}
 	bidderName1 := openrtb_ext.BidderName("bidder1")
 
-	seatBid2 := pbsOrtbSeatBid{innerBids2, "USD", nil, nil}
+	seatBid2 := pbsOrtbSeatBid{innerBids2, "USD", nil, nil, nil}
 	bidderName2 := openrtb_ext.BidderName("bidder2")
 
 	adapterBids[bidderName1] = &seatBid1
@@ -2067,8 +2067,8 @@ func TestCategoryMappingBidderNameNoCategories(t *testing.T) {
 	bid1 := openrtb.Bid{ID: "bid_id1", ImpID: "imp_id1", Price: 10.0000, Cat: cats1, W: 1, H: 1}
 	bid2 := openrtb.Bid{ID: "bid_id2", ImpID: "imp_id2", Price: 12.0000, Cat: cats2, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
-	bid1_2 := pbsOrtbBid{&bid2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
+	bid1_1 := pbsOrtbBid{bid: &bid1, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
+	bid1_2 := pbsOrtbBid{bid: &bid2, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
 
 	innerBids1 := []*pbsOrtbBid{
 		&bid1_1,
@@ -2077,10 +2077,10 @@ func TestCategoryMappingBidderNameNoCategories(t *testing.T) {
 		&bid1_2,
 	}
 
-	seatBid1 := pbsOrtbSeatBid{innerBids1, "USD", nil, nil}
+	seatBid1 := pbsOrtbSeatBid{innerBids1, "USD", nil, nil, nil}
 	bidderName1 := openrtb_ext.BidderName("bidder1")
 
-	seatBid2 := pbsOrtbSeatBid{innerBids2, "USD", nil, nil}
+	seatBid2 := pbsOrtbSeatBid{innerBids2, "USD", nil, nil, nil}
 	bidderName2 := openrtb_ext.BidderName("bidder2")
 
 	adapterBids[bidderName1] = &seatBid1
@@ -2179,12 +2179,12 @@ func TestBidRejectionErrors(t *testing.T) {
 		innerBids := []*pbsOrtbBid{}
 		for _, bid := range test.bids {
 			currentBid := pbsOrtbBid{
-				bid, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: test.duration}, 0, false,
+				bid, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: test.duration}, 0, false, 0, false,
 			}
 			innerBids = append(innerBids, &currentBid)
 		}
 
-		seatBid := pbsOrtbSeatBid{innerBids, "USD", nil, nil}
+		seatBid := pbsOrtbSeatBid{innerBids, "USD", nil, nil, nil}
 
 		adapterBids[bidderName] = &seatBid
 
@@ -2228,8 +2228,8 @@ func TestCategoryMappingTwoBiddersOneBidEachNoCategorySamePrice(t *testing.T) {
 	bidApn1 := openrtb.Bid{ID: "bid_idApn1", ImpID: "imp_idApn1", Price: 10.0000, Cat: cats1, W: 1, H: 1}
 	bidApn2 := openrtb.Bid{ID: "bid_idApn2", ImpID: "imp_idApn2", Price: 10.0000, Cat: cats2, W: 1, H: 1}
 
-	bid1_Apn1 := pbsOrtbBid{&bidApn1, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
-	bid1_Apn2 := pbsOrtbBid{&bidApn2, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, 0, false}
+	bid1_Apn1 := pbsOrtbBid{bid: &bidApn1, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
+	bid1_Apn2 := pbsOrtbBid{bid: &bidApn2, bidType: "video", bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: 30}}
 
 	innerBidsApn1 := []*pbsOrtbBid{
 		&bid1_Apn1,
@@ -2242,10 +2242,10 @@ func TestCategoryMappingTwoBiddersOneBidEachNoCategorySamePrice(t *testing.T) {
 	for i := 1; i < 10; i++ {
 		adapterBids := make(map[openrtb_ext.BidderName]*pbsOrtbSeatBid)
 
-		seatBidApn1 := pbsOrtbSeatBid{innerBidsApn1, "USD", nil, nil}
+		seatBidApn1 := pbsOrtbSeatBid{innerBidsApn1, "USD", nil, nil, nil}
 		bidderNameApn1 := openrtb_ext.BidderName("appnexus1")
 
-		seatBidApn2 := pbsOrtbSeatBid{innerBidsApn2, "USD", nil, nil}
+		seatBidApn2 := pbsOrtbSeatBid{innerBidsApn2, "USD", nil, nil, nil}
 		bidderNameApn2 := openrtb_ext.BidderName("appnexus2")
 
 		adapterBids[bidderNameApn1] = &seatBidApn1
@@ -2356,7 +2356,7 @@ func TestApplyDealSupport(t *testing.T) {
 			},
 		}
 
-		bid := pbsOrtbBid{&openrtb.Bid{ID: "123456"}, "video", map[string]string{}, &openrtb_ext.ExtBidPrebidVideo{}, test.dealPriority, false}
+		bid := pbsOrtbBid{bid: &openrtb.Bid{ID: "123456"}, bidType: "video", bidTargets: map[string]string{}, bidVideo: &openrtb_ext.ExtBidPrebidVideo{}, dealPriority: test.dealPriority}
 		bidCategory := map[string]string{
 			bid.bid.ID: test.targ["hb_pb_cat_dur"],
 		}
@@ -2369,7 +2369,7 @@ func TestApplyDealSupport(t *testing.T) {
 			},
 		}
 
-		dealErrs := applyDealSupport(bidRequest, auc, bidCategory)
+		dealErrs := applyDealSupport(bidRequest, auc, bidCategory, nil)
 
 		assert.Equal(t, test.expectedHbPbCatDur, bidCategory[auc.winningBidsByBidder["imp_id1"][bidderName].bid.ID], test.description)
 		assert.Equal(t, test.expectedDealTierSatisfied, auc.winningBidsByBidder["imp_id1"][bidderName].dealTierSatisfied, "expectedDealTierSatisfied=%v when %v", test.expectedDealTierSatisfied, test.description)
@@ -2379,11 +2379,67 @@ func TestApplyDealSupport(t *testing.T) {
 	}
 }
 
+func TestApplyDealSupportBanner(t *testing.T) {
+	testCases := []struct {
+		description               string
+		dealPriority              int
+		accountDealTiers          openrtb_ext.DealTierBidderMap
+		impExt                    json.RawMessage
+		expectedHbPb              string
+		expectedDealTierSatisfied bool
+	}{
+		{
+			description:               "hb_pb should be replaced by the tier",
+			dealPriority:              5,
+			impExt:                    json.RawMessage(`{"appnexus": {"dealTier": {"minDealTier": 5, "prefix": "tier"}, "placementId": 10433394}}`),
+			expectedHbPb:              "tier5",
+			expectedDealTierSatisfied: true,
+		},
+		{
+			description:               "hb_pb should be replaced by the tier of the account",
+			dealPriority:              7,
+			accountDealTiers:          openrtb_ext.DealTierBidderMap{openrtb_ext.BidderAppnexus: {Prefix: "deal", MinDealTier: 3}},
+			impExt:                    json.RawMessage(`{"appnexus": {"placementId": 10433394}}`),
+			expectedHbPb:              "deal7",
+			expectedDealTierSatisfied: true,
+		},
+		{
+			description:               "hb_pb should not be modified due to priority not exceeding min",
+			dealPriority:              4,
+			impExt:                    json.RawMessage(`{"appnexus": {"dealTier": {"minDealTier": 5, "prefix": "tier"}, "placementId": 10433394}}`),
+			expectedHbPb:              "12.00",
+			expectedDealTierSatisfied: false,
+		},
+	}
+
+	bidderName := openrtb_ext.BidderName("appnexus")
+	for _, test := range testCases {
+		bidRequest := &openrtb.BidRequest{
+			ID:  "some-request-id",
+			Imp: []openrtb.Imp{{ID: "imp_id1", Ext: test.impExt}},
+		}
+		bid := &pbsOrtbBid{bid: &openrtb.Bid{ID: "123456", ImpID: "imp_id1", Price: 12}, bidType: openrtb_ext.BidTypeBanner, dealPriority: test.dealPriority}
+		auc := &auction{
+			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				"imp_id1": {bidderName: bid},
+			},
+			roundedPrices: map[*pbsOrtbBid]string{bid: "12.00"},
+		}
+
+		dealErrs := applyDealSupport(bidRequest, auc, map[string]string{}, test.accountDealTiers)
+
+		assert.Empty(t, dealErrs, test.description)
+		assert.Equal(t, test.expectedHbPb, auc.roundedPrices[bid], test.description)
+		assert.Equal(t, test.expectedDealTierSatisfied, bid.dealTierSatisfied, test.description)
+	}
+}
+
 func TestGetDealTiers(t *testing.T) {
 	testCases := []struct {
-		description string
-		request     openrtb.BidRequest
-		expected    map[string]openrtb_ext.DealTierBidderMap
+		description      string
+		request          openrtb.BidRequest
+		accountDealTiers openrtb_ext.DealTierBidderMap
+		expected         map[string]openrtb_ext.DealTierBidderMap
 	}{
 		{
 			description: "None",
@@ -2428,10 +2484,33 @@ func TestGetDealTiers(t *testing.T) {
 				"imp1": {openrtb_ext.BidderAppnexus: {Prefix: "tier1", MinDealTier: 5}},
 			},
 		},
+		{
+			description: "Account Defaults - Imp Takes Precedence",
+			request: openrtb.BidRequest{
+				Imp: []openrtb.Imp{
+					{ID: "imp1", Ext: json.RawMessage(`{"appnexus": {"dealTier": {"minDealTier": 5, "prefix": "imp"}}}`)},
+					{ID: "imp2", Ext: json.RawMessage(`{"appnexus": {"dealTier": "wrong type"}}`)},
+				},
+			},
+			accountDealTiers: openrtb_ext.DealTierBidderMap{
+				openrtb_ext.BidderAppnexus: {Prefix: "account", MinDealTier: 1},
+				openrtb_ext.BidderRubicon:  {Prefix: "account", MinDealTier: 2},
+			},
+			expected: map[string]openrtb_ext.DealTierBidderMap{
+				"imp1": {
+					openrtb_ext.BidderAppnexus: {Prefix: "imp", MinDealTier: 5},
+					openrtb_ext.BidderRubicon:  {Prefix: "account", MinDealTier: 2},
+				},
+				"imp2": {
+					openrtb_ext.BidderAppnexus: {Prefix: "account", MinDealTier: 1},
+					openrtb_ext.BidderRubicon:  {Prefix: "account", MinDealTier: 2},
+				},
+			},
+		},
 	}
 
 	for _, test := range testCases {
-		result := getDealTiers(&test.request)
+		result := getDealTiers(&test.request, test.accountDealTiers)
 		assert.Equal(t, test.expected, result, test.description)
 	}
 }
@@ -2523,7 +2602,7 @@ func TestUpdateHbPbCatDur(t *testing.T) {
 	}
 
 	for _, test := range testCases {
-		bid := pbsOrtbBid{&openrtb.Bid{ID: "123456"}, "video", map[string]string{}, &openrtb_ext.ExtBidPrebidVideo{}, test.dealPriority, false}
+		bid := pbsOrtbBid{bid: &openrtb.Bid{ID: "123456"}, bidType: "video", bidTargets: map[string]string{}, bidVideo: &openrtb_ext.ExtBidPrebidVideo{}, dealPriority: test.dealPriority}
 		bidCategory := map[string]string{
 			bid.bid.ID: test.targ["hb_pb_cat_dur"],
 		}
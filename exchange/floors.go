@@ -0,0 +1,103 @@
+package exchange
+
+import (
+	"fmt"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/floors"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// setBidderRequestFloors writes the resolved floor into the imp.bidfloor of every bidder's copy of the request,
+// so that adapters can pass it on to their endpoints. The floors are also collected into the report
+// returned in bidresponse.ext.prebid.floors.
+func setBidderRequestFloors(rules *floors.Rules, bidderRequests []BidderRequest, conversions currency.Conversions) (*openrtb_ext.ExtResponseFloors, []error) {
+	var errs []error
+	report := &openrtb_ext.ExtResponseFloors{
+		Enforced: rules.Enforce,
+		Imps:     make(map[string][]openrtb_ext.ExtResponseFloor),
+	}
+
+	for _, bidderRequest := range bidderRequests {
+		request := bidderRequest.BidRequest
+		for i := range request.Imp {
+			imp := &request.Imp[i]
+			fields := floors.FieldsFromImp(request, imp, bidderRequest.BidderName)
+			floor, err := rules.Lookup(imp, fields, conversions, floorCurrency(rules, imp))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("Unable to resolve floor for bidder '%s', imp ID '%s': %v", bidderRequest.BidderName, imp.ID, err))
+				continue
+			}
+			if floor.Value <= 0 {
+				continue
+			}
+			imp.BidFloor = floor.Value
+			imp.BidFloorCur = floor.Currency
+			report.Imps[imp.ID] = append(report.Imps[imp.ID], openrtb_ext.ExtResponseFloor{
+				Bidder:   bidderRequest.BidderName,
+				Floor:    floor.Value,
+				Currency: floor.Currency,
+				Rule:     floor.Rule,
+				Location: floor.Location,
+			})
+		}
+	}
+	return report, errs
+}
+
+// floorCurrency is the currency in which the floor is sent to the bidders.
+// The rule set currency wins over the one the publisher used for imp.bidfloor.
+func floorCurrency(rules *floors.Rules, imp *openrtb.Imp) string {
+	if rules.Data != nil && rules.Data.Currency != "" {
+		return rules.Data.Currency
+	}
+	return imp.BidFloorCur
+}
+
+// enforceFloors removes the bids which are priced below the floor of their imp. Prices are compared after bid
+// adjustment and currency conversion, so the floor is converted into the currency of each seat.
+// It returns a rejection message for every removed bid.
+func enforceFloors(rules *floors.Rules, bidRequest *openrtb.BidRequest, seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, conversions currency.Conversions) []string {
+	var rejections []string
+	if !rules.Enforce && len(rules.Bidders) == 0 {
+		return rejections
+	}
+
+	imps := make(map[string]*openrtb.Imp, len(bidRequest.Imp))
+	for i := range bidRequest.Imp {
+		imps[bidRequest.Imp[i].ID] = &bidRequest.Imp[i]
+	}
+
+	for bidderName, seatBid := range seatBids {
+		if seatBid == nil || !rules.EnforceForBidder(bidderName.String()) {
+			continue
+		}
+		validBids := make([]*pbsOrtbBid, 0, len(seatBid.bids))
+		for _, bid := range seatBid.bids {
+			imp, ok := imps[bid.bid.ImpID]
+			if !ok || (bid.bid.DealID != "" && !rules.EnforceDeals) {
+				validBids = append(validBids, bid)
+				continue
+			}
+			fields := floors.FieldsFromBid(bidRequest, imp, bidderName, bid.bid, bid.bidType)
+			floor, err := rules.Lookup(imp, fields, conversions, seatBid.currency)
+			if err != nil {
+				// A floor we cannot convert is not enforced. The bid is kept, but the publisher is told why.
+				rejections = append(rejections, fmt.Sprintf("floor not enforced [bid ID: %s] reason: %v", bid.bid.ID, err))
+				validBids = append(validBids, bid)
+				continue
+			}
+			if bid.bid.Price < floor.Value {
+				reason := fmt.Sprintf("bid price %.4f %s is below the floor %.4f %s for imp ID '%s' and bidder '%s'", bid.bid.Price, floor.Currency, floor.Value, floor.Currency, imp.ID, bidderName)
+				rejections = updateRejections(rejections, bid.bid.ID, reason)
+				seatBid.addNonBid(bid.bid, errortypes.BelowFloorNonBidCode)
+				continue
+			}
+			validBids = append(validBids, bid)
+		}
+		seatBid.bids = validBids
+	}
+	return rejections
+}
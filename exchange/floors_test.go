@@ -0,0 +1,165 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/floors"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetBidderRequestFloors(t *testing.T) {
+	rules := &floors.Rules{
+		Enforce:  true,
+		Location: floors.LocationRequest,
+		Data: &openrtb_ext.PriceFloorData{
+			Currency: "EUR",
+			Schema:   openrtb_ext.PriceFloorSchema{Fields: []string{"bidder"}},
+			Values:   map[string]float64{"appnexus": 2, "*": 1},
+		},
+	}
+	bidderRequests := []BidderRequest{
+		{
+			BidderName: "appnexus",
+			BidRequest: &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "imp1"}}},
+		},
+		{
+			BidderName: "rubicon",
+			BidRequest: &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "imp1"}}},
+		},
+	}
+
+	report, errs := setBidderRequestFloors(rules, bidderRequests, currency.NewConstantRates())
+
+	assert.Empty(t, errs)
+	assert.Equal(t, 2.0, bidderRequests[0].BidRequest.Imp[0].BidFloor)
+	assert.Equal(t, "EUR", bidderRequests[0].BidRequest.Imp[0].BidFloorCur)
+	assert.Equal(t, 1.0, bidderRequests[1].BidRequest.Imp[0].BidFloor)
+	assert.Equal(t, "EUR", bidderRequests[1].BidRequest.Imp[0].BidFloorCur)
+	assert.Equal(t, &openrtb_ext.ExtResponseFloors{
+		Enforced: true,
+		Imps: map[string][]openrtb_ext.ExtResponseFloor{
+			"imp1": {
+				{Bidder: "appnexus", Floor: 2, Currency: "EUR", Rule: "appnexus", Location: floors.LocationRequest},
+				{Bidder: "rubicon", Floor: 1, Currency: "EUR", Rule: "*", Location: floors.LocationRequest},
+			},
+		},
+	}, report)
+}
+
+func TestEnforceFloors(t *testing.T) {
+	falseValue := false
+	conversions := currency.NewRates(time.Now(), map[string]map[string]float64{
+		"USD": {"EUR": 0.5},
+	})
+	bidRequest := &openrtb.BidRequest{
+		Imp: []openrtb.Imp{
+			{ID: "imp1", Banner: &openrtb.Banner{}},
+		},
+	}
+	data := &openrtb_ext.PriceFloorData{
+		Currency: "USD",
+		Schema:   openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType", "size"}},
+		Values:   map[string]float64{"banner|300x250": 2, "banner|*": 1},
+	}
+
+	testCases := []struct {
+		description        string
+		rules              *floors.Rules
+		currency           string
+		bids               []*openrtb.Bid
+		expectedBids       []string
+		expectedRejections int
+	}{
+		{
+			description: "Bids Below Floor Rejected",
+			rules:       &floors.Rules{Enforce: true, Data: data},
+			currency:    "USD",
+			bids: []*openrtb.Bid{
+				{ID: "above", ImpID: "imp1", Price: 2.5, W: 300, H: 250},
+				{ID: "below", ImpID: "imp1", Price: 1.5, W: 300, H: 250},
+				{ID: "other-size", ImpID: "imp1", Price: 1.5, W: 728, H: 90},
+			},
+			expectedBids:       []string{"above", "other-size"},
+			expectedRejections: 1,
+		},
+		{
+			description: "Floor Converted To Seat Currency",
+			rules:       &floors.Rules{Enforce: true, Data: data},
+			currency:    "EUR",
+			bids: []*openrtb.Bid{
+				{ID: "above", ImpID: "imp1", Price: 1.1, W: 300, H: 250},
+				{ID: "below", ImpID: "imp1", Price: 0.9, W: 300, H: 250},
+			},
+			expectedBids:       []string{"above"},
+			expectedRejections: 1,
+		},
+		{
+			description: "Deal Bids Not Enforced",
+			rules:       &floors.Rules{Enforce: true, Data: data},
+			currency:    "USD",
+			bids: []*openrtb.Bid{
+				{ID: "deal", ImpID: "imp1", Price: 0.5, DealID: "d1"},
+			},
+			expectedBids: []string{"deal"},
+		},
+		{
+			description: "Deal Bids Enforced",
+			rules:       &floors.Rules{Enforce: true, EnforceDeals: true, Data: data},
+			currency:    "USD",
+			bids: []*openrtb.Bid{
+				{ID: "deal", ImpID: "imp1", Price: 0.5, DealID: "d1"},
+			},
+			expectedBids:       []string{},
+			expectedRejections: 1,
+		},
+		{
+			description: "Enforcement Disabled",
+			rules:       &floors.Rules{Enforce: false, Data: data},
+			currency:    "USD",
+			bids: []*openrtb.Bid{
+				{ID: "below", ImpID: "imp1", Price: 0.5},
+			},
+			expectedBids: []string{"below"},
+		},
+		{
+			description: "Bidder Exempt From Enforcement",
+			rules:       &floors.Rules{Enforce: true, Data: data, Bidders: map[string]*openrtb_ext.BidderFloor{"appnexus": {EnforcePBS: &falseValue}}},
+			currency:    "USD",
+			bids: []*openrtb.Bid{
+				{ID: "below", ImpID: "imp1", Price: 0.5},
+			},
+			expectedBids: []string{"below"},
+		},
+		{
+			description: "Floor Not Convertible",
+			rules:       &floors.Rules{Enforce: true, Data: data},
+			currency:    "GBP",
+			bids: []*openrtb.Bid{
+				{ID: "kept", ImpID: "imp1", Price: 0.5},
+			},
+			expectedBids:       []string{"kept"},
+			expectedRejections: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		seatBid := &pbsOrtbSeatBid{currency: test.currency}
+		for _, bid := range test.bids {
+			seatBid.bids = append(seatBid.bids, &pbsOrtbBid{bid: bid, bidType: openrtb_ext.BidTypeBanner})
+		}
+		seatBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{"appnexus": seatBid}
+
+		rejections := enforceFloors(test.rules, bidRequest, seatBids, conversions)
+
+		bidIDs := make([]string, 0, len(seatBid.bids))
+		for _, bid := range seatBid.bids {
+			bidIDs = append(bidIDs, bid.bid.ID)
+		}
+		assert.Equal(t, test.expectedBids, bidIDs, test.description)
+		assert.Len(t, rejections, test.expectedRejections, test.description)
+	}
+}
@@ -0,0 +1,112 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/buger/jsonparser"
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// executeRawBidderResponseStage runs the raw bidder response hooks on the bids of a seat, and keeps the bids they return.
+// All the bids of the seat are dropped if a hook rejects them.
+func executeRawBidderResponseStage(ctx context.Context, executor *hooks.Executor, bidder openrtb_ext.BidderName, seatBid *pbsOrtbSeatBid) *hooks.RejectError {
+	if executor == nil || seatBid == nil {
+		return nil
+	}
+
+	knownBids := make(map[*openrtb.Bid]*pbsOrtbBid, len(seatBid.bids))
+	hookBids, rejectErr := executor.ExecuteRawBidderResponseStage(ctx, bidder, toHookBids(seatBid.bids, knownBids))
+	if rejectErr != nil {
+		seatBid.bids = nil
+		return rejectErr
+	}
+	seatBid.bids = fromHookBids(hookBids, knownBids)
+	return nil
+}
+
+// executeAllProcessedBidResponsesStage runs the all processed bid responses hooks on the bids of every seat, and keeps the bids they return.
+// The hooks can't add seats, as the exchange doesn't know their currency.
+func executeAllProcessedBidResponsesStage(ctx context.Context, executor *hooks.Executor, adapterBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid) {
+	if executor == nil {
+		return
+	}
+
+	knownBids := make(map[*openrtb.Bid]*pbsOrtbBid)
+	hookBids := make(map[openrtb_ext.BidderName][]*hooks.Bid, len(adapterBids))
+	for bidder, seatBid := range adapterBids {
+		hookBids[bidder] = toHookBids(seatBid.bids, knownBids)
+	}
+
+	hookBids = executor.ExecuteAllProcessedBidResponsesStage(ctx, hookBids)
+	for bidder, seatBid := range adapterBids {
+		seatBid.bids = fromHookBids(hookBids[bidder], knownBids)
+		if len(seatBid.bids) == 0 && len(seatBid.nonBids) == 0 {
+			delete(adapterBids, bidder)
+		}
+	}
+}
+
+// executeAuctionResponseStage runs the auction response hooks, and reports the errors and warnings of all the hooks
+// which ran for the request in bidresponse.ext.prebid.modules.
+func executeAuctionResponseStage(ctx context.Context, executor *hooks.Executor, bidResponse *openrtb.BidResponse) (*openrtb.BidResponse, error) {
+	if executor == nil {
+		return bidResponse, nil
+	}
+
+	bidResponse = executor.ExecuteAuctionResponseStage(ctx, bidResponse)
+	return bidResponse, addExtModules(executor, bidResponse)
+}
+
+// rejectedBidResponse is the response to a request rejected by a hook.
+func rejectedBidResponse(executor *hooks.Executor, bidRequest *openrtb.BidRequest, rejectErr *hooks.RejectError) (*openrtb.BidResponse, error) {
+	bidResponse := &openrtb.BidResponse{
+		ID:  bidRequest.ID,
+		NBR: openrtb.NoBidReasonCode(rejectErr.NBR).Ptr(),
+	}
+	return bidResponse, addExtModules(executor, bidResponse)
+}
+
+func addExtModules(executor *hooks.Executor, bidResponse *openrtb.BidResponse) error {
+	extModules := executor.ExtModules()
+	if extModules == nil {
+		return nil
+	}
+	modulesJSON, err := json.Marshal(extModules)
+	if err != nil {
+		return err
+	}
+	if len(bidResponse.Ext) == 0 {
+		bidResponse.Ext = json.RawMessage(`{}`)
+	}
+	bidResponse.Ext, err = jsonparser.Set(bidResponse.Ext, modulesJSON, "prebid", "modules")
+	return err
+}
+
+func toHookBids(bids []*pbsOrtbBid, knownBids map[*openrtb.Bid]*pbsOrtbBid) []*hooks.Bid {
+	hookBids := make([]*hooks.Bid, 0, len(bids))
+	for _, bid := range bids {
+		knownBids[bid.bid] = bid
+		hookBids = append(hookBids, &hooks.Bid{Bid: bid.bid, BidType: bid.bidType})
+	}
+	return hookBids
+}
+
+// fromHookBids maps the bids the hooks returned back to the bids they came from. The bids added by a hook have no deal or video data.
+func fromHookBids(hookBids []*hooks.Bid, knownBids map[*openrtb.Bid]*pbsOrtbBid) []*pbsOrtbBid {
+	bids := make([]*pbsOrtbBid, 0, len(hookBids))
+	for _, hookBid := range hookBids {
+		if hookBid == nil || hookBid.Bid == nil {
+			continue
+		}
+		bid, ok := knownBids[hookBid.Bid]
+		if !ok {
+			bid = &pbsOrtbBid{bid: hookBid.Bid}
+		}
+		bid.bidType = hookBid.BidType
+		bids = append(bids, bid)
+	}
+	return bids
+}
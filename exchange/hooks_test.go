@@ -0,0 +1,148 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+// bidFilterModule drops the bids of the blocked ad domain at the bid response stages, or rejects them all.
+type bidFilterModule struct {
+	blockedDomain string
+	reject        bool
+}
+
+func (m bidFilterModule) HandleRawBidderResponseHook(ctx context.Context, invocation hooks.InvocationContext, payload hooks.RawBidderResponsePayload) (hooks.HookResult, error) {
+	if m.reject {
+		return hooks.HookResult{Reject: true, Message: "all bids blocked"}, nil
+	}
+	return hooks.HookResult{Mutations: []hooks.Mutation{func(payload interface{}) error {
+		bidderPayload := payload.(*hooks.RawBidderResponsePayload)
+		bidderPayload.Bids = m.filter(bidderPayload.Bids)
+		return nil
+	}}}, nil
+}
+
+func (m bidFilterModule) HandleAllProcessedBidResponsesHook(ctx context.Context, invocation hooks.InvocationContext, payload hooks.AllProcessedBidResponsesPayload) (hooks.HookResult, error) {
+	return hooks.HookResult{
+		Warnings: []string{"filtered"},
+		Mutations: []hooks.Mutation{func(payload interface{}) error {
+			allPayload := payload.(*hooks.AllProcessedBidResponsesPayload)
+			filtered := make(map[openrtb_ext.BidderName][]*hooks.Bid, len(allPayload.Bids))
+			for bidder, bids := range allPayload.Bids {
+				filtered[bidder] = m.filter(bids)
+			}
+			allPayload.Bids = filtered
+			return nil
+		}},
+	}, nil
+}
+
+func (m bidFilterModule) filter(bids []*hooks.Bid) []*hooks.Bid {
+	kept := make([]*hooks.Bid, 0, len(bids))
+	for _, bid := range bids {
+		if len(bid.Bid.ADomain) == 0 || bid.Bid.ADomain[0] != m.blockedDomain {
+			kept = append(kept, bid)
+		}
+	}
+	return kept
+}
+
+func newTestHookExecutor(t *testing.T, stage hooks.Stage, module interface{}) *hooks.Executor {
+	builder, err := hooks.NewPlanBuilder(config.Hooks{
+		Enabled: true,
+		HostExecutionPlan: config.HookExecutionPlan{
+			Endpoints: map[string]config.EndpointExecutionPlan{
+				hooks.EndpointAuction: {
+					Stages: map[string]config.StageExecutionPlan{
+						string(stage): {Groups: []config.HookExecutionGroup{
+							{TimeoutMillis: 100, HookSequence: []config.HookID{{ModuleCode: "vendor.filter", HookImplCode: "filter"}}},
+						}},
+					},
+				},
+			},
+		},
+	}, hooks.HookRepository{"vendor.filter": module})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return builder.NewExecutor(hooks.EndpointAuction)
+}
+
+func TestExecuteRawBidderResponseStage(t *testing.T) {
+	blockedBid := &pbsOrtbBid{bid: &openrtb.Bid{ID: "blocked", ADomain: []string{"blocked.com"}}, bidType: openrtb_ext.BidTypeBanner}
+	keptBid := &pbsOrtbBid{bid: &openrtb.Bid{ID: "kept", ADomain: []string{"ok.com"}}, bidType: openrtb_ext.BidTypeVideo, dealPriority: 5}
+
+	testCases := []struct {
+		description    string
+		executor       *hooks.Executor
+		expectedBids   []*pbsOrtbBid
+		expectedReject bool
+	}{
+		{
+			description:  "No executor",
+			executor:     nil,
+			expectedBids: []*pbsOrtbBid{blockedBid, keptBid},
+		},
+		{
+			description:  "Bids are filtered and keep their exchange data",
+			executor:     newTestHookExecutor(t, hooks.StageRawBidderResponse, bidFilterModule{blockedDomain: "blocked.com"}),
+			expectedBids: []*pbsOrtbBid{keptBid},
+		},
+		{
+			description:    "Rejection drops all the bids",
+			executor:       newTestHookExecutor(t, hooks.StageRawBidderResponse, bidFilterModule{reject: true}),
+			expectedBids:   nil,
+			expectedReject: true,
+		},
+	}
+
+	for _, test := range testCases {
+		seatBid := &pbsOrtbSeatBid{bids: []*pbsOrtbBid{blockedBid, keptBid}, currency: "USD"}
+
+		rejectErr := executeRawBidderResponseStage(context.Background(), test.executor, openrtb_ext.BidderAppnexus, seatBid)
+
+		assert.Equal(t, test.expectedReject, rejectErr != nil, test.description)
+		assert.Equal(t, test.expectedBids, seatBid.bids, test.description)
+	}
+}
+
+func TestExecuteAllProcessedBidResponsesStage(t *testing.T) {
+	executor := newTestHookExecutor(t, hooks.StageAllProcessedBidResponses, bidFilterModule{blockedDomain: "blocked.com"})
+	keptBid := &pbsOrtbBid{bid: &openrtb.Bid{ID: "kept", ADomain: []string{"ok.com"}}, bidType: openrtb_ext.BidTypeBanner}
+	adapterBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		openrtb_ext.BidderAppnexus: {bids: []*pbsOrtbBid{
+			{bid: &openrtb.Bid{ID: "blocked", ADomain: []string{"blocked.com"}}, bidType: openrtb_ext.BidTypeBanner},
+			keptBid,
+		}},
+		openrtb_ext.BidderRubicon: {bids: []*pbsOrtbBid{
+			{bid: &openrtb.Bid{ID: "blocked", ADomain: []string{"blocked.com"}}, bidType: openrtb_ext.BidTypeBanner},
+		}},
+	}
+
+	executeAllProcessedBidResponsesStage(context.Background(), executor, adapterBids)
+
+	assert.Equal(t, map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		openrtb_ext.BidderAppnexus: {bids: []*pbsOrtbBid{keptBid}},
+	}, adapterBids, "Seats left without bids should be removed")
+
+	bidResponse, err := executeAuctionResponseStage(context.Background(), executor, &openrtb.BidResponse{ID: "some-id", Ext: json.RawMessage(`{"prebid":{"auctiontimestamp":1}}`)})
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{"prebid":{"auctiontimestamp":1,"modules":{"warnings":{"vendor.filter":{"filter":["filtered"]}}}}}`, string(bidResponse.Ext))
+	}
+}
+
+func TestRejectedBidResponse(t *testing.T) {
+	executor := newTestHookExecutor(t, hooks.StageRawBidderResponse, bidFilterModule{reject: true})
+
+	bidResponse, err := rejectedBidResponse(executor, &openrtb.BidRequest{ID: "some-id"}, &hooks.RejectError{NBR: 8})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &openrtb.BidResponse{ID: "some-id", NBR: openrtb.NoBidReasonCode(8).Ptr()}, bidResponse)
+}
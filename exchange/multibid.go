@@ -0,0 +1,135 @@
+package exchange
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// multiBidConfig is the validated ext.prebid.multibid configuration of a single bidder.
+type multiBidConfig struct {
+	maxBids                int
+	targetBidderCodePrefix string
+}
+
+// getExtMultiBid validates ext.prebid.multibid and returns the configuration of every listed bidder.
+// Invalid entries are fixed up or ignored; each one produces a warning for the publisher.
+func getExtMultiBid(requestExt *openrtb_ext.ExtRequest) (map[openrtb_ext.BidderName]multiBidConfig, []error) {
+	if requestExt == nil || len(requestExt.Prebid.MultiBid) == 0 {
+		return nil, nil
+	}
+
+	var errs []error
+	multiBid := make(map[openrtb_ext.BidderName]multiBidConfig)
+	for i, entry := range requestExt.Prebid.MultiBid {
+		if entry == nil {
+			continue
+		}
+		if entry.MaxBids == nil {
+			errs = append(errs, fmt.Errorf("ext.prebid.multibid[%d] ignored: maxbids is required", i))
+			continue
+		}
+
+		config := multiBidConfig{maxBids: *entry.MaxBids}
+		if config.maxBids < 1 {
+			errs = append(errs, fmt.Errorf("ext.prebid.multibid[%d].maxbids %d raised to 1", i, config.maxBids))
+			config.maxBids = 1
+		} else if config.maxBids > openrtb_ext.MaxBidsPerBidder {
+			errs = append(errs, fmt.Errorf("ext.prebid.multibid[%d].maxbids %d lowered to %d", i, config.maxBids, openrtb_ext.MaxBidsPerBidder))
+			config.maxBids = openrtb_ext.MaxBidsPerBidder
+		}
+
+		bidders := entry.Bidders
+		if entry.Bidder != "" {
+			if len(entry.Bidders) > 0 {
+				errs = append(errs, fmt.Errorf("ext.prebid.multibid[%d] defines both bidder and bidders; bidders ignored", i))
+			}
+			bidders = []string{entry.Bidder}
+			config.targetBidderCodePrefix = entry.TargetBidderCodePrefix
+		} else if entry.TargetBidderCodePrefix != "" {
+			errs = append(errs, fmt.Errorf("ext.prebid.multibid[%d].targetbiddercodeprefix ignored: it may only be used with bidder", i))
+		}
+
+		for _, bidder := range bidders {
+			if _, exists := multiBid[openrtb_ext.BidderName(bidder)]; exists {
+				errs = append(errs, fmt.Errorf("ext.prebid.multibid[%d] ignored for bidder '%s': it is already configured", i, bidder))
+				continue
+			}
+			multiBid[openrtb_ext.BidderName(bidder)] = config
+		}
+	}
+	return multiBid, errs
+}
+
+// applyMultiBidLimits ranks the bids of every configured bidder on each imp by price and drops the ones
+// ranked below maxbids. Bidders which are not configured keep all of their bids.
+func applyMultiBidLimits(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, multiBid map[openrtb_ext.BidderName]multiBidConfig) {
+	for bidderName, seatBid := range seatBids {
+		config, ok := multiBid[bidderName]
+		if !ok || seatBid == nil {
+			continue
+		}
+		sortBidsByPrice(seatBid.bids)
+		bidsPerImp := make(map[string]int)
+		bids := make([]*pbsOrtbBid, 0, len(seatBid.bids))
+		for _, bid := range seatBid.bids {
+			if bidsPerImp[bid.bid.ImpID] < config.maxBids {
+				bidsPerImp[bid.bid.ImpID]++
+				bids = append(bids, bid)
+			} else {
+				seatBid.addNonBid(bid.bid, errortypes.LostToHigherBidNonBidCode)
+			}
+		}
+		seatBid.bids = bids
+	}
+}
+
+// addMultiBids adds the runner-up bids of every bidder with a targeting bidder code prefix to winningBidsByBidder.
+// They are keyed by the bidder code {prefix}{rank}, so they get their own targeting keys and cache entries.
+func (a *auction) addMultiBids(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, multiBid map[openrtb_ext.BidderName]multiBidConfig) {
+	for bidderName, seatBid := range seatBids {
+		config, ok := multiBid[bidderName]
+		if !ok || config.targetBidderCodePrefix == "" || seatBid == nil {
+			continue
+		}
+		bids := make([]*pbsOrtbBid, len(seatBid.bids))
+		copy(bids, seatBid.bids)
+		sortBidsByPrice(bids)
+
+		rankPerImp := make(map[string]int)
+		for _, bid := range bids {
+			impID := bid.bid.ImpID
+			rankPerImp[impID]++
+			if rankPerImp[impID] == 1 || rankPerImp[impID] > config.maxBids {
+				// The top bid is already tracked under the bidder's own name
+				continue
+			}
+			bidderCode := openrtb_ext.BidderName(config.targetBidderCodePrefix + strconv.Itoa(rankPerImp[impID]))
+			if _, ok := a.winningBidsByBidder[impID]; !ok {
+				a.winningBidsByBidder[impID] = make(map[openrtb_ext.BidderName]*pbsOrtbBid)
+			}
+			a.winningBidsByBidder[impID][bidderCode] = bid
+			if a.multiBidSeats == nil {
+				a.multiBidSeats = make(map[openrtb_ext.BidderName]openrtb_ext.BidderName)
+			}
+			a.multiBidSeats[bidderCode] = bidderName
+		}
+	}
+}
+
+// seatName returns the name of the bidder which made the bids tracked under bidderCode in winningBidsByBidder.
+func (a *auction) seatName(bidderCode openrtb_ext.BidderName) openrtb_ext.BidderName {
+	if seat, ok := a.multiBidSeats[bidderCode]; ok {
+		return seat
+	}
+	return bidderCode
+}
+
+func sortBidsByPrice(bids []*pbsOrtbBid) {
+	sort.SliceStable(bids, func(i, j int) bool {
+		return bids[i].bid.Price > bids[j].bid.Price
+	})
+}
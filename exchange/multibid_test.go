@@ -0,0 +1,171 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExtMultiBid(t *testing.T) {
+	zero, two, three, ten := 0, 2, 3, 10
+
+	testCases := []struct {
+		description      string
+		multiBid         []*openrtb_ext.ExtMultiBid
+		expectedMultiBid map[openrtb_ext.BidderName]multiBidConfig
+		expectedErrs     int
+	}{
+		{
+			description:      "Not Configured",
+			multiBid:         nil,
+			expectedMultiBid: nil,
+		},
+		{
+			description: "Single Bidder With Prefix",
+			multiBid: []*openrtb_ext.ExtMultiBid{
+				{Bidder: "appnexus", MaxBids: &three, TargetBidderCodePrefix: "apn"},
+			},
+			expectedMultiBid: map[openrtb_ext.BidderName]multiBidConfig{
+				"appnexus": {maxBids: 3, targetBidderCodePrefix: "apn"},
+			},
+		},
+		{
+			description: "Multiple Bidders",
+			multiBid: []*openrtb_ext.ExtMultiBid{
+				{Bidders: []string{"appnexus", "rubicon"}, MaxBids: &two},
+			},
+			expectedMultiBid: map[openrtb_ext.BidderName]multiBidConfig{
+				"appnexus": {maxBids: 2},
+				"rubicon":  {maxBids: 2},
+			},
+		},
+		{
+			description: "Prefix Ignored With Bidders",
+			multiBid: []*openrtb_ext.ExtMultiBid{
+				{Bidders: []string{"appnexus"}, MaxBids: &two, TargetBidderCodePrefix: "apn"},
+			},
+			expectedMultiBid: map[openrtb_ext.BidderName]multiBidConfig{
+				"appnexus": {maxBids: 2},
+			},
+			expectedErrs: 1,
+		},
+		{
+			description: "Bidders Ignored With Bidder",
+			multiBid: []*openrtb_ext.ExtMultiBid{
+				{Bidder: "appnexus", Bidders: []string{"rubicon"}, MaxBids: &two},
+			},
+			expectedMultiBid: map[openrtb_ext.BidderName]multiBidConfig{
+				"appnexus": {maxBids: 2},
+			},
+			expectedErrs: 1,
+		},
+		{
+			description: "MaxBids Missing",
+			multiBid: []*openrtb_ext.ExtMultiBid{
+				{Bidder: "appnexus"},
+			},
+			expectedMultiBid: map[openrtb_ext.BidderName]multiBidConfig{},
+			expectedErrs:     1,
+		},
+		{
+			description: "MaxBids Out Of Range",
+			multiBid: []*openrtb_ext.ExtMultiBid{
+				{Bidder: "appnexus", MaxBids: &zero},
+				{Bidder: "rubicon", MaxBids: &ten},
+			},
+			expectedMultiBid: map[openrtb_ext.BidderName]multiBidConfig{
+				"appnexus": {maxBids: 1},
+				"rubicon":  {maxBids: openrtb_ext.MaxBidsPerBidder},
+			},
+			expectedErrs: 2,
+		},
+		{
+			description: "Duplicate Bidder",
+			multiBid: []*openrtb_ext.ExtMultiBid{
+				{Bidder: "appnexus", MaxBids: &two},
+				{Bidders: []string{"appnexus", "rubicon"}, MaxBids: &three},
+			},
+			expectedMultiBid: map[openrtb_ext.BidderName]multiBidConfig{
+				"appnexus": {maxBids: 2},
+				"rubicon":  {maxBids: 3},
+			},
+			expectedErrs: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		requestExt := &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{MultiBid: test.multiBid}}
+		multiBid, errs := getExtMultiBid(requestExt)
+		assert.Equal(t, test.expectedMultiBid, multiBid, test.description)
+		assert.Len(t, errs, test.expectedErrs, test.description)
+	}
+}
+
+func TestApplyMultiBidLimits(t *testing.T) {
+	seatBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		"appnexus": {
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb.Bid{ID: "a1", ImpID: "imp1", Price: 1}},
+				{bid: &openrtb.Bid{ID: "a2", ImpID: "imp1", Price: 3}},
+				{bid: &openrtb.Bid{ID: "a3", ImpID: "imp1", Price: 2}},
+				{bid: &openrtb.Bid{ID: "a4", ImpID: "imp2", Price: 1}},
+			},
+		},
+		"rubicon": {
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb.Bid{ID: "r1", ImpID: "imp1", Price: 1}},
+				{bid: &openrtb.Bid{ID: "r2", ImpID: "imp1", Price: 2}},
+			},
+		},
+	}
+	multiBid := map[openrtb_ext.BidderName]multiBidConfig{
+		"appnexus": {maxBids: 2},
+	}
+
+	applyMultiBidLimits(seatBids, multiBid)
+
+	assert.Equal(t, []string{"a2", "a3", "a4"}, bidIDs(seatBids["appnexus"]), "Configured bidder trimmed to maxbids per imp")
+	assert.Equal(t, []string{"r1", "r2"}, bidIDs(seatBids["rubicon"]), "Unconfigured bidder untouched")
+	if assert.Len(t, seatBids["appnexus"].nonBids, 1, "Dropped bid reported") {
+		assert.Equal(t, nonBid{bid: &openrtb.Bid{ID: "a1", ImpID: "imp1", Price: 1}, statusCode: errortypes.LostToHigherBidNonBidCode}, seatBids["appnexus"].nonBids[0])
+	}
+}
+
+func TestAddMultiBids(t *testing.T) {
+	bid1 := &pbsOrtbBid{bid: &openrtb.Bid{ID: "a1", ImpID: "imp1", Price: 3}}
+	bid2 := &pbsOrtbBid{bid: &openrtb.Bid{ID: "a2", ImpID: "imp1", Price: 2}}
+	bid3 := &pbsOrtbBid{bid: &openrtb.Bid{ID: "a3", ImpID: "imp1", Price: 1}}
+	rubiconBid := &pbsOrtbBid{bid: &openrtb.Bid{ID: "r1", ImpID: "imp1", Price: 1}}
+	seatBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		"appnexus": {bids: []*pbsOrtbBid{bid3, bid1, bid2}},
+		"rubicon":  {bids: []*pbsOrtbBid{rubiconBid}},
+	}
+	multiBid := map[openrtb_ext.BidderName]multiBidConfig{
+		"appnexus": {maxBids: 3, targetBidderCodePrefix: "apn"},
+		"rubicon":  {maxBids: 3},
+	}
+
+	auc := newAuction(seatBids, 1, false)
+	auc.addMultiBids(seatBids, multiBid)
+
+	assert.Equal(t, map[openrtb_ext.BidderName]*pbsOrtbBid{
+		"appnexus": bid1,
+		"apn2":     bid2,
+		"apn3":     bid3,
+		"rubicon":  rubiconBid,
+	}, auc.winningBidsByBidder["imp1"])
+	assert.Equal(t, openrtb_ext.BidderName("appnexus"), auc.seatName("apn2"))
+	assert.Equal(t, openrtb_ext.BidderName("rubicon"), auc.seatName("rubicon"))
+	assert.Equal(t, bid1, auc.winningBids["imp1"], "Runner-up bids do not change the winner")
+}
+
+func bidIDs(seatBid *pbsOrtbSeatBid) []string {
+	ids := make([]string, 0, len(seatBid.bids))
+	for _, bid := range seatBid.bids {
+		ids = append(ids, bid.bid.ID)
+	}
+	return ids
+}
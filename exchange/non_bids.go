@@ -0,0 +1,67 @@
+package exchange
+
+import (
+	"sort"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// nonBid is a bid which was dropped, or which lost the auction, along with the errortypes non bid code of the reason.
+type nonBid struct {
+	bid        *openrtb.Bid
+	statusCode int
+}
+
+func (seatBid *pbsOrtbSeatBid) addNonBid(bid *openrtb.Bid, statusCode int) {
+	if bid == nil {
+		return
+	}
+	seatBid.nonBids = append(seatBid.nonBids, nonBid{bid: bid, statusCode: statusCode})
+}
+
+// addLostBids records the bids which didn't win their imp. They stay in the response, as the ad server
+// may still pick them, but are reported with the reason they lost.
+func addLostBids(auc *auction, seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, preferDeals bool) {
+	for _, seatBid := range seatBids {
+		for _, bid := range seatBid.bids {
+			winner, ok := auc.winningBids[bid.bid.ImpID]
+			if !ok || winner == bid {
+				continue
+			}
+			if preferDeals && len(winner.bid.DealID) > 0 && len(bid.bid.DealID) == 0 {
+				seatBid.addNonBid(bid.bid, errortypes.LostToDealBidNonBidCode)
+			} else {
+				seatBid.addNonBid(bid.bid, errortypes.LostToHigherBidNonBidCode)
+			}
+		}
+	}
+}
+
+// makeSeatNonBids builds bidresponse.ext.prebid.seatnonbid, sorted by seat so that the response is stable.
+func makeSeatNonBids(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid) []openrtb_ext.SeatNonBid {
+	seatNonBids := make([]openrtb_ext.SeatNonBid, 0, len(seatBids))
+	for bidderName, seatBid := range seatBids {
+		if seatBid == nil || len(seatBid.nonBids) == 0 {
+			continue
+		}
+		seatNonBid := openrtb_ext.SeatNonBid{
+			Seat:   bidderName.String(),
+			NonBid: make([]openrtb_ext.NonBid, 0, len(seatBid.nonBids)),
+		}
+		for _, nonBid := range seatBid.nonBids {
+			seatNonBid.NonBid = append(seatNonBid.NonBid, openrtb_ext.NonBid{
+				ID:         nonBid.bid.ID,
+				ImpID:      nonBid.bid.ImpID,
+				Price:      nonBid.bid.Price,
+				StatusCode: nonBid.statusCode,
+			})
+		}
+		seatNonBids = append(seatNonBids, seatNonBid)
+	}
+	sort.Slice(seatNonBids, func(i, j int) bool {
+		return seatNonBids[i].Seat < seatNonBids[j].Seat
+	})
+	return seatNonBids
+}
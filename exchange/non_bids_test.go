@@ -0,0 +1,113 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddLostBids(t *testing.T) {
+	testCases := []struct {
+		description      string
+		preferDeals      bool
+		appnexusBids     []*openrtb.Bid
+		rubiconBids      []*openrtb.Bid
+		expectedAppnexus []nonBid
+		expectedRubicon  []nonBid
+	}{
+		{
+			description:  "Lost To Higher Bid",
+			appnexusBids: []*openrtb.Bid{{ID: "apn", ImpID: "imp1", Price: 2}, {ID: "apn2", ImpID: "imp2", Price: 1}},
+			rubiconBids:  []*openrtb.Bid{{ID: "rubi", ImpID: "imp1", Price: 1}},
+			expectedRubicon: []nonBid{
+				{bid: &openrtb.Bid{ID: "rubi", ImpID: "imp1", Price: 1}, statusCode: errortypes.LostToHigherBidNonBidCode},
+			},
+		},
+		{
+			description:  "Lost To Deal Bid",
+			preferDeals:  true,
+			appnexusBids: []*openrtb.Bid{{ID: "apn", ImpID: "imp1", Price: 2}},
+			rubiconBids:  []*openrtb.Bid{{ID: "rubi", ImpID: "imp1", Price: 1, DealID: "deal"}},
+			expectedAppnexus: []nonBid{
+				{bid: &openrtb.Bid{ID: "apn", ImpID: "imp1", Price: 2}, statusCode: errortypes.LostToDealBidNonBidCode},
+			},
+		},
+		{
+			description:  "Deal Lost To Higher Deal",
+			preferDeals:  true,
+			appnexusBids: []*openrtb.Bid{{ID: "apn", ImpID: "imp1", Price: 2, DealID: "deal1"}},
+			rubiconBids:  []*openrtb.Bid{{ID: "rubi", ImpID: "imp1", Price: 1, DealID: "deal2"}},
+			expectedRubicon: []nonBid{
+				{bid: &openrtb.Bid{ID: "rubi", ImpID: "imp1", Price: 1, DealID: "deal2"}, statusCode: errortypes.LostToHigherBidNonBidCode},
+			},
+		},
+		{
+			description:  "Deals Not Preferred",
+			appnexusBids: []*openrtb.Bid{{ID: "apn", ImpID: "imp1", Price: 2}},
+			rubiconBids:  []*openrtb.Bid{{ID: "rubi", ImpID: "imp1", Price: 1, DealID: "deal"}},
+			expectedRubicon: []nonBid{
+				{bid: &openrtb.Bid{ID: "rubi", ImpID: "imp1", Price: 1, DealID: "deal"}, statusCode: errortypes.LostToHigherBidNonBidCode},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		seatBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+			openrtb_ext.BidderAppnexus: {currency: "USD"},
+			openrtb_ext.BidderRubicon:  {currency: "USD"},
+		}
+		for _, bid := range test.appnexusBids {
+			seatBids[openrtb_ext.BidderAppnexus].bids = append(seatBids[openrtb_ext.BidderAppnexus].bids, &pbsOrtbBid{bid: bid, bidType: openrtb_ext.BidTypeBanner})
+		}
+		for _, bid := range test.rubiconBids {
+			seatBids[openrtb_ext.BidderRubicon].bids = append(seatBids[openrtb_ext.BidderRubicon].bids, &pbsOrtbBid{bid: bid, bidType: openrtb_ext.BidTypeBanner})
+		}
+
+		addLostBids(newAuction(seatBids, 2, test.preferDeals), seatBids, test.preferDeals)
+
+		assert.Equal(t, test.expectedAppnexus, seatBids[openrtb_ext.BidderAppnexus].nonBids, "%s: appnexus", test.description)
+		assert.Equal(t, test.expectedRubicon, seatBids[openrtb_ext.BidderRubicon].nonBids, "%s: rubicon", test.description)
+	}
+}
+
+func TestMakeSeatNonBids(t *testing.T) {
+	seatBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		openrtb_ext.BidderRubicon: {
+			nonBids: []nonBid{{bid: &openrtb.Bid{ID: "rubi", ImpID: "imp1", Price: 0.5}, statusCode: errortypes.BelowFloorNonBidCode}},
+		},
+		openrtb_ext.BidderAppnexus: {
+			nonBids: []nonBid{
+				{bid: &openrtb.Bid{ID: "apn1", ImpID: "imp1", Price: 1}, statusCode: errortypes.LostToHigherBidNonBidCode},
+				{bid: &openrtb.Bid{ID: "apn2", ImpID: "imp2", Price: 2}, statusCode: errortypes.CreativeNotSecureNonBidCode},
+			},
+		},
+		openrtb_ext.BidderOpenx: {
+			bids: []*pbsOrtbBid{{bid: &openrtb.Bid{ID: "openx", ImpID: "imp1", Price: 3}}},
+		},
+	}
+
+	expected := []openrtb_ext.SeatNonBid{
+		{
+			Seat: "appnexus",
+			NonBid: []openrtb_ext.NonBid{
+				{ID: "apn1", ImpID: "imp1", Price: 1, StatusCode: errortypes.LostToHigherBidNonBidCode},
+				{ID: "apn2", ImpID: "imp2", Price: 2, StatusCode: errortypes.CreativeNotSecureNonBidCode},
+			},
+		},
+		{
+			Seat:   "rubicon",
+			NonBid: []openrtb_ext.NonBid{{ID: "rubi", ImpID: "imp1", Price: 0.5, StatusCode: errortypes.BelowFloorNonBidCode}},
+		},
+	}
+
+	assert.Equal(t, expected, makeSeatNonBids(seatBids))
+}
+
+func TestAddNonBidIgnoresMissingBids(t *testing.T) {
+	seatBid := &pbsOrtbSeatBid{}
+	seatBid.addNonBid(nil, errortypes.InvalidBidResponseNonBidCode)
+	assert.Empty(t, seatBid.nonBids)
+}
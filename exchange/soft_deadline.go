@@ -0,0 +1,35 @@
+package exchange
+
+import (
+	"context"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+)
+
+// softDeadline finalizes the auction with the bids received so far once a percentage of its time has passed,
+// so that the slowest bidders don't hold up the response until the auction times out.
+type softDeadline struct {
+	percent uint64
+}
+
+func newSoftDeadline(cfg config.SoftDeadline) *softDeadline {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &softDeadline{percent: cfg.Percent}
+}
+
+// start returns a channel which receives once the soft deadline of the auction context has passed, along with
+// the function which releases its timer. The channel never receives if the soft deadline is disabled, or if the
+// auction context has no deadline.
+func (d *softDeadline) start(ctx context.Context) (<-chan time.Time, func()) {
+	deadline, ok := ctx.Deadline()
+	if d == nil || !ok {
+		return nil, func() {}
+	}
+
+	remaining := time.Until(deadline)
+	timer := time.NewTimer(remaining * time.Duration(d.percent) / 100)
+	return timer.C, func() { timer.Stop() }
+}
@@ -0,0 +1,133 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// slowBidder responds once its context is done, with the error of the context.
+type slowBidder struct {
+	cancelled chan error
+}
+
+func (b *slowBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo) (*pbsOrtbSeatBid, []error) {
+	<-ctx.Done()
+	b.cancelled <- ctx.Err()
+	return nil, []error{ctx.Err()}
+}
+
+func TestNewSoftDeadline(t *testing.T) {
+	assert.Nil(t, newSoftDeadline(config.SoftDeadline{Enabled: false, Percent: 80}), "Disabled")
+	assert.Equal(t, &softDeadline{percent: 80}, newSoftDeadline(config.SoftDeadline{Enabled: true, Percent: 80}), "Enabled")
+}
+
+func TestSoftDeadlineStart(t *testing.T) {
+	testCases := []struct {
+		description string
+		deadline    *softDeadline
+		hasDeadline bool
+		expectFire  bool
+	}{
+		{
+			description: "Disabled",
+			deadline:    nil,
+			hasDeadline: true,
+			expectFire:  false,
+		},
+		{
+			description: "No Auction Deadline",
+			deadline:    &softDeadline{percent: 10},
+			hasDeadline: false,
+			expectFire:  false,
+		},
+		{
+			description: "Auction Deadline",
+			deadline:    &softDeadline{percent: 10},
+			hasDeadline: true,
+			expectFire:  true,
+		},
+	}
+
+	for _, test := range testCases {
+		auctionCtx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		ctx := auctionCtx
+		if !test.hasDeadline {
+			ctx = context.Background()
+		}
+
+		fired, stop := test.deadline.start(ctx)
+		select {
+		case <-fired:
+			assert.True(t, test.expectFire, "%s: the soft deadline shouldn't pass", test.description)
+			assert.NoError(t, auctionCtx.Err(), "%s: the soft deadline should pass before the auction deadline", test.description)
+		case <-auctionCtx.Done():
+			assert.False(t, test.expectFire, "%s: the soft deadline should pass before the auction deadline", test.description)
+		}
+		stop()
+		cancel()
+	}
+}
+
+func TestGetAllBidsSoftDeadline(t *testing.T) {
+	slow := &slowBidder{cancelled: make(chan error, 1)}
+	fast := &countingBidder{seatBid: &pbsOrtbSeatBid{
+		bids:     []*pbsOrtbBid{{bid: &openrtb.Bid{ID: "bid1", ImpID: "imp1", Price: 1}, bidType: openrtb_ext.BidTypeBanner}},
+		currency: "USD",
+	}}
+
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterRequest", mock.Anything).Return()
+	metricsMock.On("RecordAdapterTime", mock.Anything, mock.Anything).Return()
+	metricsMock.On("RecordAdapterPrice", mock.Anything, mock.Anything).Return()
+	metricsMock.On("RecordAdapterBidReceived", mock.Anything, mock.Anything, mock.Anything).Return()
+	metricsMock.On("RecordAdapterLateResponse", openrtb_ext.BidderRubicon).Return()
+
+	e := &exchange{
+		adapterMap: map[openrtb_ext.BidderName]adaptedBidder{
+			openrtb_ext.BidderAppnexus: fast,
+			openrtb_ext.BidderRubicon:  slow,
+		},
+		me:           metricsMock,
+		softDeadline: &softDeadline{percent: 10},
+	}
+	bidderRequests := []BidderRequest{
+		{
+			BidderName:     openrtb_ext.BidderAppnexus,
+			BidderCoreName: openrtb_ext.BidderAppnexus,
+			BidderLabels:   metrics.AdapterLabels{Adapter: openrtb_ext.BidderAppnexus},
+			BidRequest:     &openrtb.BidRequest{ID: "req1"},
+		},
+		{
+			BidderName:     openrtb_ext.BidderRubicon,
+			BidderCoreName: openrtb_ext.BidderRubicon,
+			BidderLabels:   metrics.AdapterLabels{Adapter: openrtb_ext.BidderRubicon},
+			BidRequest:     &openrtb.BidRequest{ID: "req1"},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	adapterBids, adapterExtra, bidsFound := e.getAllBids(ctx, bidderRequests, nil, currency.NewConstantRates(), config.Validations{}, nil, "")
+
+	assert.NoError(t, ctx.Err(), "The auction should be finalized before its deadline")
+	assert.True(t, bidsFound)
+	assert.Contains(t, adapterBids, openrtb_ext.BidderAppnexus, "The bids received by the soft deadline should be kept")
+	assert.NotContains(t, adapterBids, openrtb_ext.BidderRubicon)
+	if assert.Contains(t, adapterExtra, openrtb_ext.BidderRubicon, "The late bidder should be reported") && assert.Len(t, adapterExtra[openrtb_ext.BidderRubicon].Errors, 1) {
+		assert.Equal(t, errortypes.TimeoutErrorCode, adapterExtra[openrtb_ext.BidderRubicon].Errors[0].Code)
+	}
+	assert.Equal(t, context.Canceled, <-slow.cancelled, "The late bidder should be cancelled")
+	metricsMock.AssertCalled(t, "RecordAdapterLateResponse", openrtb_ext.BidderRubicon)
+	metricsMock.AssertNotCalled(t, "RecordAdapterLateResponse", openrtb_ext.BidderAppnexus)
+}
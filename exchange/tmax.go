@@ -0,0 +1,59 @@
+package exchange
+
+import (
+	"context"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// tmaxBudget splits the auction deadline into the time budget of each bidder.
+type tmaxBudget struct {
+	config    config.TmaxAdjustments
+	latencies metrics.LatencyReporter
+}
+
+func newTmaxBudget(cfg config.TmaxAdjustments, metricsEngine metrics.MetricsEngine) *tmaxBudget {
+	if !cfg.Enabled {
+		return nil
+	}
+	budget := &tmaxBudget{config: cfg}
+	if cfg.NetworkLatencyPercentile > 0 {
+		budget.latencies, _ = metricsEngine.(metrics.LatencyReporter)
+	}
+	return budget
+}
+
+// makeBidderContext returns the context used to call a bidder, along with the tmax it should be given in milliseconds.
+//
+// The bidder context expires once the safety margin is all that's left of the auction context. The tmax sent to the
+// bidder is the remaining time less the network latency recorded for it, so its response still makes it in time.
+// A zero tmax means that the auction context has no deadline and the bidder shares it.
+func (b *tmaxBudget) makeBidderContext(ctx context.Context, bidder openrtb_ext.BidderName) (context.Context, context.CancelFunc, int64) {
+	deadline, ok := ctx.Deadline()
+	if b == nil || !ok {
+		return ctx, func() {}, 0
+	}
+
+	deadline = deadline.Add(-time.Duration(b.config.SafetyMarginMillis) * time.Millisecond)
+	bidderCtx, cancel := context.WithDeadline(ctx, deadline)
+
+	tmax := time.Until(deadline)
+	if b.latencies != nil {
+		if latency, ok := b.latencies.AdapterConnectionWaitTime(bidder, b.config.NetworkLatencyPercentile); ok {
+			tmax -= latency
+		}
+	}
+
+	tmaxMillis := int64(tmax / time.Millisecond)
+	if minTmax := int64(b.config.MinBidderTmaxMillis); tmaxMillis < minTmax {
+		tmaxMillis = minTmax
+	}
+	if tmaxMillis < 1 {
+		// A zero tmax would tell the bidder that there is no limit
+		tmaxMillis = 1
+	}
+	return bidderCtx, cancel, tmaxMillis
+}
@@ -0,0 +1,123 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	metricsConfig "github.com/prebid/prebid-server/metrics/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeLatencyReporter struct {
+	metricsConfig.DummyMetricsEngine
+	latencies map[openrtb_ext.BidderName]time.Duration
+}
+
+func (r *fakeLatencyReporter) AdapterConnectionWaitTime(adapterName openrtb_ext.BidderName, percentile float64) (time.Duration, bool) {
+	latency, ok := r.latencies[adapterName]
+	return latency, ok
+}
+
+func TestNewTmaxBudget(t *testing.T) {
+	reporter := &fakeLatencyReporter{}
+
+	assert.Nil(t, newTmaxBudget(config.TmaxAdjustments{Enabled: false}, reporter), "Disabled")
+	assert.Nil(t, newTmaxBudget(config.TmaxAdjustments{Enabled: true}, reporter).latencies, "Latencies Ignored")
+	assert.Nil(t, newTmaxBudget(config.TmaxAdjustments{Enabled: true, NetworkLatencyPercentile: 0.9}, &metricsConfig.DummyMetricsEngine{}).latencies, "Engine Without Latencies")
+	assert.Equal(t, reporter, newTmaxBudget(config.TmaxAdjustments{Enabled: true, NetworkLatencyPercentile: 0.9}, reporter).latencies, "Engine With Latencies")
+}
+
+func TestMakeBidderContext(t *testing.T) {
+	reporter := &fakeLatencyReporter{
+		latencies: map[openrtb_ext.BidderName]time.Duration{
+			openrtb_ext.BidderAppnexus: 100 * time.Millisecond,
+			openrtb_ext.BidderRubicon:  time.Second,
+		},
+	}
+
+	testCases := []struct {
+		description      string
+		budget           *tmaxBudget
+		timeout          time.Duration
+		bidder           openrtb_ext.BidderName
+		expectedDeadline time.Duration
+		expectedTmax     int64
+	}{
+		{
+			description:      "Budgeting Disabled",
+			budget:           nil,
+			timeout:          500 * time.Millisecond,
+			bidder:           openrtb_ext.BidderAppnexus,
+			expectedDeadline: 500 * time.Millisecond,
+			expectedTmax:     0,
+		},
+		{
+			description:      "Safety Margin",
+			budget:           &tmaxBudget{config: config.TmaxAdjustments{SafetyMarginMillis: 50}},
+			timeout:          500 * time.Millisecond,
+			bidder:           openrtb_ext.BidderAppnexus,
+			expectedDeadline: 450 * time.Millisecond,
+			expectedTmax:     450,
+		},
+		{
+			description:      "Safety Margin And Network Latency",
+			budget:           &tmaxBudget{config: config.TmaxAdjustments{SafetyMarginMillis: 50}, latencies: reporter},
+			timeout:          500 * time.Millisecond,
+			bidder:           openrtb_ext.BidderAppnexus,
+			expectedDeadline: 450 * time.Millisecond,
+			expectedTmax:     350,
+		},
+		{
+			description:      "No Latency Recorded For Bidder",
+			budget:           &tmaxBudget{config: config.TmaxAdjustments{}, latencies: reporter},
+			timeout:          500 * time.Millisecond,
+			bidder:           openrtb_ext.BidderOpenx,
+			expectedDeadline: 500 * time.Millisecond,
+			expectedTmax:     500,
+		},
+		{
+			description:      "Minimum Tmax",
+			budget:           &tmaxBudget{config: config.TmaxAdjustments{MinBidderTmaxMillis: 200}, latencies: reporter},
+			timeout:          500 * time.Millisecond,
+			bidder:           openrtb_ext.BidderRubicon,
+			expectedDeadline: 500 * time.Millisecond,
+			expectedTmax:     200,
+		},
+		{
+			description:      "Tmax Never Zero",
+			budget:           &tmaxBudget{config: config.TmaxAdjustments{}, latencies: reporter},
+			timeout:          500 * time.Millisecond,
+			bidder:           openrtb_ext.BidderRubicon,
+			expectedDeadline: 500 * time.Millisecond,
+			expectedTmax:     1,
+		},
+	}
+
+	for _, test := range testCases {
+		ctx, cancel := context.WithTimeout(context.Background(), test.timeout)
+		auctionDeadline, _ := ctx.Deadline()
+
+		bidderCtx, bidderCancel, tmax := test.budget.makeBidderContext(ctx, test.bidder)
+
+		bidderDeadline, ok := bidderCtx.Deadline()
+		assert.True(t, ok, test.description)
+		assert.Equal(t, auctionDeadline.Add(test.expectedDeadline-test.timeout), bidderDeadline, test.description)
+		assert.InDelta(t, test.expectedTmax, tmax, 20, test.description)
+		bidderCancel()
+		cancel()
+	}
+}
+
+func TestMakeBidderContextWithoutDeadline(t *testing.T) {
+	budget := &tmaxBudget{config: config.TmaxAdjustments{SafetyMarginMillis: 50}}
+
+	bidderCtx, cancel, tmax := budget.makeBidderContext(context.Background(), openrtb_ext.BidderAppnexus)
+	defer cancel()
+
+	_, ok := bidderCtx.Deadline()
+	assert.False(t, ok)
+	assert.Equal(t, int64(0), tmax)
+}
@@ -11,12 +11,15 @@ import (
 	"github.com/buger/jsonparser"
 	"github.com/mxmCherry/openrtb"
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/firstpartydata"
 	"github.com/prebid/prebid-server/gdpr"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/privacy/ccpa"
+	"github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/prebid/prebid-server/privacy/lmt"
+	"github.com/prebid/prebid-server/privacy/usstate"
 )
 
 var integrationTypeMap = map[metrics.RequestType]config.IntegrationType{
@@ -52,6 +55,8 @@ func BidderToPrebidSChains(req *openrtb_ext.ExtRequest) (map[string]*openrtb_ext
 //   1. BidRequest.Imp[].Ext will only contain the "prebid" field and a "bidder" field which has the params for the intended Bidder.
 //   2. Every BidRequest.Imp[] requested Bids from the Bidder who keys it.
 //   3. BidRequest.User.BuyerUID will be set to that Bidder's ID.
+//   4. BidRequest.Site, BidRequest.App and BidRequest.User will only contain the first party data intended for the Bidder.
+//   5. BidRequest.User.Ext.Eids will only contain the sources which request.ext.prebid.data.eidpermissions allows the Bidder.
 func cleanOpenRTBRequests(ctx context.Context,
 	req AuctionRequest,
 	requestExt *openrtb_ext.ExtRequest,
@@ -71,6 +76,11 @@ func cleanOpenRTBRequests(ctx context.Context,
 
 	bidderRequests, errs = getAuctionBidderRequests(req, requestExt, impsByBidder, aliases)
 
+	// The account may deny calling some of the bidders, or sending them the user data.
+	activityControl := privacy.NewActivityControl(&req.Account.Privacy)
+	activityRequest := privacy.NewActivityRequest(req.BidRequest)
+	bidderRequests = filterFetchBids(bidderRequests, activityControl, activityRequest)
+
 	if len(bidderRequests) == 0 {
 		return
 	}
@@ -84,6 +94,21 @@ func cleanOpenRTBRequests(ctx context.Context,
 		return
 	}
 
+	// An invalid GPP string is ignored, as the other privacy signals of the request still apply.
+	gppEnforcer, gppConsent, gppErr := extractGPP(req.BidRequest, privacyConfig, &req.Account, aliases, integrationTypeMap[req.LegacyLabels.RType])
+	if gppErr != nil {
+		errs = append(errs, gppErr)
+	}
+	if consent == "" {
+		consent = gppConsent
+	}
+
+	// The US state sections of an invalid GPP string can't be read either, which was already reported.
+	usStateEnforcers, err := extractUSStates(req.BidRequest, privacyConfig, &req.Account, aliases, integrationTypeMap[req.LegacyLabels.RType])
+	if err != nil && gppErr == nil {
+		errs = append(errs, err)
+	}
+
 	lmtEnforcer := extractLMT(req.BidRequest, privacyConfig)
 
 	// request level privacy policies
@@ -92,8 +117,8 @@ func cleanOpenRTBRequests(ctx context.Context,
 		LMT:   lmtEnforcer.ShouldEnforce(unknownBidder),
 	}
 
-	privacyLabels.CCPAProvided = ccpaEnforcer.CanEnforce()
-	privacyLabels.CCPAEnforced = ccpaEnforcer.ShouldEnforce(unknownBidder)
+	privacyLabels.CCPAProvided = ccpaEnforcer.CanEnforce() || gppEnforcer.CanEnforce()
+	privacyLabels.CCPAEnforced = ccpaEnforcer.ShouldEnforce(unknownBidder) || gppEnforcer.ShouldEnforce(unknownBidder)
 	privacyLabels.COPPAEnforced = privacyEnforcement.COPPA
 	privacyLabels.LMTEnforced = lmtEnforcer.ShouldEnforce(unknownBidder)
 
@@ -110,13 +135,28 @@ func cleanOpenRTBRequests(ctx context.Context,
 
 	// bidder level privacy policies
 	for _, bidderRequest := range bidderRequests {
-		// CCPA
-		privacyEnforcement.CCPA = ccpaEnforcer.ShouldEnforce(bidderRequest.BidderName.String())
+		// CCPA, along with the US sections of the GPP string
+		privacyEnforcement.CCPA = ccpaEnforcer.ShouldEnforce(bidderRequest.BidderName.String()) ||
+			gppEnforcer.ShouldEnforce(bidderRequest.BidderName.String())
+
+		// US states
+		privacyEnforcement.USState = false
+		for _, usStateEnforcer := range usStateEnforcers {
+			if usStateEnforcer.ShouldEnforce(bidderRequest.BidderName.String()) {
+				privacyEnforcement.USState = true
+				break
+			}
+		}
+
+		// Activity controls
+		bidderComponent := privacy.Component{Type: config.ComponentTypeBidder, Name: bidderRequest.BidderName.String()}
+		privacyEnforcement.UFPD = !activityControl.Allow(privacy.ActivityTransmitUserFPD, bidderComponent, activityRequest)
+		privacyEnforcement.PreciseGeo = !activityControl.Allow(privacy.ActivityTransmitPreciseGeo, bidderComponent, activityRequest)
 
 		// GDPR
 		if gdpr == 1 && gdprEnabled {
 			var publisherID = req.LegacyLabels.PubID
-			_, geo, id, err := gDPR.PersonalInfoAllowed(ctx, bidderRequest.BidderCoreName, publisherID, consent)
+			_, geo, id, err := gDPR.PersonalInfoAllowed(ctx, bidderRequest.BidderCoreName, publisherID, &req.Account.GDPR, consent)
 			privacyEnforcement.GDPRGeo = !geo && err == nil
 			privacyEnforcement.GDPRID = !id && err == nil
 		} else {
@@ -130,6 +170,18 @@ func cleanOpenRTBRequests(ctx context.Context,
 	return
 }
 
+// filterFetchBids removes the requests of the bidders which the account doesn't allow to be called.
+func filterFetchBids(bidderRequests []BidderRequest, activityControl privacy.ActivityControl, activityRequest privacy.ActivityRequest) []BidderRequest {
+	allowedRequests := make([]BidderRequest, 0, len(bidderRequests))
+	for _, bidderRequest := range bidderRequests {
+		bidderComponent := privacy.Component{Type: config.ComponentTypeBidder, Name: bidderRequest.BidderName.String()}
+		if activityControl.Allow(privacy.ActivityFetchBids, bidderComponent, activityRequest) {
+			allowedRequests = append(allowedRequests, bidderRequest)
+		}
+	}
+	return allowedRequests
+}
+
 func gdprEnabled(account *config.Account, privacyConfig config.Privacy, integrationType config.IntegrationType) bool {
 	if accountEnabled := account.GDPR.EnabledForIntegrationType(integrationType); accountEnabled != nil {
 		return *accountEnabled
@@ -163,6 +215,59 @@ func extractCCPA(orig *openrtb.BidRequest, privacyConfig config.Privacy, account
 	return ccpaEnforcer, nil
 }
 
+// extractGPP returns the enforcer of the US sections of the GPP string, which are enabled along with CCPA, and the
+// TCF consent string of its TCF EU v2 section.
+func extractGPP(orig *openrtb.BidRequest, privacyConfig config.Privacy, account *config.Account, aliases map[string]string, requestType config.IntegrationType) (privacy.PolicyEnforcer, string, error) {
+	gppPolicy, err := gpp.ReadFromRequest(orig)
+	if err != nil {
+		return privacy.NilPolicyEnforcer{}, "", err
+	}
+
+	validBidders := GetValidBidders(aliases)
+	gppParsedPolicy, err := gppPolicy.Parse(validBidders)
+	if err != nil {
+		return privacy.NilPolicyEnforcer{}, "", err
+	}
+
+	gppEnforcer := privacy.EnabledPolicyEnforcer{
+		Enabled:        ccpaEnabled(account, privacyConfig, requestType),
+		PolicyEnforcer: gppParsedPolicy,
+	}
+	return gppEnforcer, gppParsedPolicy.TCFEUv2Consent(), nil
+}
+
+func usStateEnabled(account *config.Account, privacyConfig config.Privacy, state usstate.State, requestType config.IntegrationType) bool {
+	if accountState := account.USStatePrivacy.State(string(state)); accountState != nil {
+		if accountEnabled := accountState.EnabledForIntegrationType(requestType); accountEnabled != nil {
+			return *accountEnabled
+		}
+	}
+	return privacyConfig.USStatePrivacy.Enforce
+}
+
+// extractUSStates returns the enforcers of the privacy laws of the US states whose GPP sections apply to the request.
+// An invalid section is ignored, along with the other state sections.
+func extractUSStates(orig *openrtb.BidRequest, privacyConfig config.Privacy, account *config.Account, aliases map[string]string, requestType config.IntegrationType) ([]privacy.PolicyEnforcer, error) {
+	usStatePolicies, err := usstate.ReadFromRequest(orig)
+	if err != nil {
+		return nil, err
+	}
+
+	validBidders := GetValidBidders(aliases)
+	usStateEnforcers := make([]privacy.PolicyEnforcer, 0, len(usStatePolicies))
+	for _, usStatePolicy := range usStatePolicies {
+		usStateParsedPolicy, err := usStatePolicy.Parse(validBidders)
+		if err != nil {
+			return nil, err
+		}
+		usStateEnforcers = append(usStateEnforcers, privacy.EnabledPolicyEnforcer{
+			Enabled:        usStateEnabled(account, privacyConfig, usStatePolicy.State, requestType),
+			PolicyEnforcer: usStateParsedPolicy,
+		})
+	}
+	return usStateEnforcers, nil
+}
+
 func extractLMT(orig *openrtb.BidRequest, privacyConfig config.Privacy) privacy.PolicyEnforcer {
 	return privacy.EnabledPolicyEnforcer{
 		Enabled:        privacyConfig.LMT.Enforce,
@@ -189,6 +294,12 @@ func getAuctionBidderRequests(req AuctionRequest,
 		return nil, []error{err}
 	}
 
+	fpdResolver, err := firstpartydata.NewResolver(req.BidRequest, requestExt)
+	if err != nil {
+		return nil, []error{err}
+	}
+	eidPermissions := getEidPermissions(requestExt)
+
 	reqExt, err := getExtJson(req.BidRequest, requestExt)
 	if err != nil {
 		return nil, []error{err}
@@ -201,6 +312,12 @@ func getAuctionBidderRequests(req AuctionRequest,
 		reqCopy.Imp = imps
 		reqCopy.Ext = reqExt
 		prepareSource(&reqCopy, bidder, sChainsByBidder)
+		if err := fpdResolver.Apply(&reqCopy, bidder); err != nil {
+			return nil, []error{err}
+		}
+		if err := removeUnpermissionedEids(&reqCopy, bidder, eidPermissions); err != nil {
+			return nil, []error{err}
+		}
 
 		bidder := BidderRequest{
 			BidderName:     openrtb_ext.BidderName(bidder),
@@ -233,6 +350,9 @@ func getExtJson(req *openrtb.BidRequest, unpackedExt *openrtb_ext.ExtRequest) (j
 
 	extCopy := *unpackedExt
 	extCopy.Prebid.SChains = nil
+	// The first party data settings would tell the bidders what the others are sent.
+	extCopy.Prebid.Data = nil
+	extCopy.Prebid.BidderConfig = nil
 	return json.Marshal(extCopy)
 }
 
@@ -267,6 +387,81 @@ func prepareSource(req *openrtb.BidRequest, bidder string, sChainsByBidder map[s
 	}
 }
 
+// getEidPermissions maps each user.ext.eids source with a rule in request.ext.prebid.data.eidpermissions to the bidders allowed to receive it.
+func getEidPermissions(requestExt *openrtb_ext.ExtRequest) map[string][]string {
+	if requestExt == nil || requestExt.Prebid.Data == nil || len(requestExt.Prebid.Data.EidPermissions) == 0 {
+		return nil
+	}
+	eidPermissions := make(map[string][]string, len(requestExt.Prebid.Data.EidPermissions))
+	for _, eidPermission := range requestExt.Prebid.Data.EidPermissions {
+		eidPermissions[eidPermission.Source] = eidPermission.Bidders
+	}
+	return eidPermissions
+}
+
+// removeUnpermissionedEids removes the user.ext.eids which the bidder isn't allowed to receive. The user is copied before
+// it is changed, as it is shared with the requests of the other bidders.
+func removeUnpermissionedEids(req *openrtb.BidRequest, bidder string, eidPermissions map[string][]string) error {
+	if len(eidPermissions) == 0 || req.User == nil || len(req.User.Ext) == 0 {
+		return nil
+	}
+
+	var userExt map[string]json.RawMessage
+	if err := json.Unmarshal(req.User.Ext, &userExt); err != nil {
+		return err
+	}
+	eidsJSON, ok := userExt["eids"]
+	if !ok {
+		return nil
+	}
+	var eids []json.RawMessage
+	if err := json.Unmarshal(eidsJSON, &eids); err != nil {
+		return err
+	}
+
+	permittedEids := make([]json.RawMessage, 0, len(eids))
+	for _, eid := range eids {
+		source, _ := jsonparser.GetString(eid, "source")
+		if allowedBidders, hasRule := eidPermissions[source]; !hasRule || isBidderAllowed(bidder, allowedBidders) {
+			permittedEids = append(permittedEids, eid)
+		}
+	}
+	if len(permittedEids) == len(eids) {
+		return nil
+	}
+
+	if len(permittedEids) == 0 {
+		delete(userExt, "eids")
+	} else {
+		permittedEidsJSON, err := json.Marshal(permittedEids)
+		if err != nil {
+			return err
+		}
+		userExt["eids"] = permittedEidsJSON
+	}
+
+	user := *req.User
+	user.Ext = nil
+	if len(userExt) > 0 {
+		userExtJSON, err := json.Marshal(userExt)
+		if err != nil {
+			return err
+		}
+		user.Ext = userExtJSON
+	}
+	req.User = &user
+	return nil
+}
+
+func isBidderAllowed(bidder string, allowedBidders []string) bool {
+	for _, allowedBidder := range allowedBidders {
+		if allowedBidder == bidder || allowedBidder == "*" {
+			return true
+		}
+	}
+	return false
+}
+
 // extractBuyerUIDs parses the values from user.ext.prebid.buyeruids, and then deletes those values from the ext.
 // This prevents a Bidder from using these values to figure out who else is involved in the Auction.
 func extractBuyerUIDs(user *openrtb.User) (map[string]string, error) {
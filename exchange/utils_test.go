@@ -30,7 +30,7 @@ func (p *permissionsMock) BidderSyncAllowed(ctx context.Context, bidder openrtb_
 	return true, nil
 }
 
-func (p *permissionsMock) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, consent string) (bool, bool, bool, error) {
+func (p *permissionsMock) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, account *config.AccountGDPR, consent string) (bool, bool, bool, error) {
 	return p.personalInfoAllowed, p.personalInfoAllowed, p.personalInfoAllowed, nil
 }
 
@@ -306,6 +306,383 @@ func TestCleanOpenRTBRequestsCCPAErrors(t *testing.T) {
 	}
 }
 
+func TestCleanOpenRTBRequestsGPP(t *testing.T) {
+	testCases := []struct {
+		description         string
+		regsExt             string
+		reqExt              string
+		ccpaHostEnabled     bool
+		gdprHostEnabled     bool
+		expectDataScrub     bool
+		expectErrors        []error
+		expectPrivacyLabels metrics.PrivacyLabels
+	}{
+		{
+			description:     "US National Opted Out",
+			regsExt:         `{"gpp":"DBABL~BAAaAA","gpp_sid":[7]}`,
+			ccpaHostEnabled: true,
+			expectDataScrub: true,
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				CCPAProvided: true,
+				CCPAEnforced: true,
+			},
+		},
+		{
+			description:     "US National Not Opted Out",
+			regsExt:         `{"gpp":"DBABL~BAAqAA","gpp_sid":[7]}`,
+			ccpaHostEnabled: true,
+			expectDataScrub: false,
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				CCPAProvided: true,
+				CCPAEnforced: false,
+			},
+		},
+		{
+			description:     "US National Opted Out - No Sale Bidder",
+			regsExt:         `{"gpp":"DBABL~BAAaAA","gpp_sid":[7]}`,
+			reqExt:          `{"prebid":{"nosale":["appnexus"]}}`,
+			ccpaHostEnabled: true,
+			expectDataScrub: false,
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				CCPAProvided: true,
+				CCPAEnforced: true,
+			},
+		},
+		{
+			description:     "US National Opted Out - CCPA Disabled",
+			regsExt:         `{"gpp":"DBABL~BAAaAA","gpp_sid":[7]}`,
+			ccpaHostEnabled: false,
+			expectDataScrub: false,
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				CCPAProvided: true,
+				CCPAEnforced: false,
+			},
+		},
+		{
+			description:     "US National Opted Out - Section Not Applicable",
+			regsExt:         `{"gpp":"DBABL~BAAaAA","gpp_sid":[2]}`,
+			ccpaHostEnabled: true,
+			expectDataScrub: false,
+		},
+		{
+			description:     "TCF EU v2 Section Used As Consent",
+			regsExt:         `{"gdpr":1,"gpp":"DBABM~COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA","gpp_sid":[2]}`,
+			gdprHostEnabled: true,
+			expectDataScrub: true,
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				GDPREnforced:   true,
+				GDPRTCFVersion: metrics.TCFVersionV2,
+			},
+		},
+		{
+			description:     "Invalid GPP String Ignored",
+			regsExt:         `{"gpp":"malformed","gpp_sid":[7]}`,
+			ccpaHostEnabled: true,
+			expectDataScrub: false,
+			expectErrors: []error{
+				&errortypes.InvalidPrivacyConsent{Message: "request.regs.ext.gpp header is invalid: must have type 3. Got 38"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		req := newBidRequest(t)
+		req.Regs = &openrtb.Regs{Ext: json.RawMessage(test.regsExt)}
+		if test.reqExt != "" {
+			req.Ext = json.RawMessage(test.reqExt)
+		}
+
+		privacyConfig := config.Privacy{
+			CCPA: config.CCPA{
+				Enforce: test.ccpaHostEnabled,
+			},
+			GDPR: config.GDPR{
+				Enabled: test.gdprHostEnabled,
+			},
+		}
+
+		auctionReq := AuctionRequest{
+			BidRequest: req,
+			UserSyncs:  &emptyUsersync{},
+		}
+
+		bidderRequests, privacyLabels, errs := cleanOpenRTBRequests(
+			context.Background(),
+			auctionReq,
+			nil,
+			&permissionsMock{personalInfoAllowed: false},
+			true,
+			privacyConfig)
+		result := bidderRequests[0]
+
+		assert.Equal(t, test.expectErrors, errs, test.description)
+		if test.expectDataScrub {
+			assert.Equal(t, result.BidRequest.User.BuyerUID, "", test.description+":User.BuyerUID")
+			assert.Equal(t, result.BidRequest.Device.DIDMD5, "", test.description+":Device.DIDMD5")
+		} else {
+			assert.NotEqual(t, result.BidRequest.User.BuyerUID, "", test.description+":User.BuyerUID")
+			assert.NotEqual(t, result.BidRequest.Device.DIDMD5, "", test.description+":Device.DIDMD5")
+		}
+		assert.Equal(t, test.expectPrivacyLabels, privacyLabels, test.description+":PrivacyLabels")
+	}
+}
+
+func TestCleanOpenRTBRequestsUSStates(t *testing.T) {
+	trueValue, falseValue := true, false
+
+	testCases := []struct {
+		description     string
+		regsExt         string
+		reqExt          string
+		requestType     metrics.RequestType
+		hostEnabled     bool
+		accountConfig   config.AccountUSStatePrivacy
+		expectUserScrub bool
+		expectErrors    []error
+	}{
+		{
+			description:     "Virginia Opted Out Of Sale",
+			regsExt:         `{"gpp":"DBABRg~BAYAAA","gpp_sid":[9]}`,
+			hostEnabled:     true,
+			expectUserScrub: true,
+		},
+		{
+			description:     "Virginia Not Opted Out",
+			regsExt:         `{"gpp":"DBABRg~BAoAAA","gpp_sid":[9]}`,
+			hostEnabled:     true,
+			expectUserScrub: false,
+		},
+		{
+			description:     "Connecticut Opted Out Of Targeted Advertising",
+			regsExt:         `{"gpp":"DBACRj~BAoAAA~BAkAAA","gpp_sid":[9,12]}`,
+			hostEnabled:     true,
+			expectUserScrub: true,
+		},
+		{
+			description:     "Virginia Opted Out - No Sale Bidder",
+			regsExt:         `{"gpp":"DBABRg~BAYAAA","gpp_sid":[9]}`,
+			reqExt:          `{"prebid":{"nosale":["appnexus"]}}`,
+			hostEnabled:     true,
+			expectUserScrub: false,
+		},
+		{
+			description:     "Virginia Opted Out - Other No Sale Bidder",
+			regsExt:         `{"gpp":"DBABRg~BAYAAA","gpp_sid":[9]}`,
+			reqExt:          `{"prebid":{"nosale":["rubicon"]}}`,
+			hostEnabled:     true,
+			expectUserScrub: true,
+		},
+		{
+			description:     "Virginia Opted Out - Section Not Applicable",
+			regsExt:         `{"gpp":"DBABRg~BAYAAA","gpp_sid":[7]}`,
+			hostEnabled:     true,
+			expectUserScrub: false,
+		},
+		{
+			description:     "Virginia Opted Out - Host Disabled",
+			regsExt:         `{"gpp":"DBABRg~BAYAAA","gpp_sid":[9]}`,
+			hostEnabled:     false,
+			expectUserScrub: false,
+		},
+		{
+			description:     "Virginia Opted Out - Account Enabled, Host Disregarded",
+			regsExt:         `{"gpp":"DBABRg~BAYAAA","gpp_sid":[9]}`,
+			hostEnabled:     false,
+			accountConfig:   config.AccountUSStatePrivacy{Virginia: config.AccountUSState{Enabled: &trueValue}},
+			expectUserScrub: true,
+		},
+		{
+			description:     "Virginia Opted Out - Account Disabled, Host Disregarded",
+			regsExt:         `{"gpp":"DBABRg~BAYAAA","gpp_sid":[9]}`,
+			hostEnabled:     true,
+			accountConfig:   config.AccountUSStatePrivacy{Virginia: config.AccountUSState{Enabled: &falseValue}},
+			expectUserScrub: false,
+		},
+		{
+			description:     "Virginia Opted Out - Other State Disabled By Account",
+			regsExt:         `{"gpp":"DBABRg~BAYAAA","gpp_sid":[9]}`,
+			hostEnabled:     true,
+			accountConfig:   config.AccountUSStatePrivacy{Utah: config.AccountUSState{Enabled: &falseValue}},
+			expectUserScrub: true,
+		},
+		{
+			description: "Virginia Opted Out - Account Integration Disabled",
+			regsExt:     `{"gpp":"DBABRg~BAYAAA","gpp_sid":[9]}`,
+			requestType: metrics.ReqTypeORTB2App,
+			hostEnabled: true,
+			accountConfig: config.AccountUSStatePrivacy{Virginia: config.AccountUSState{
+				Enabled:            &trueValue,
+				IntegrationEnabled: config.AccountIntegration{App: &falseValue},
+			}},
+			expectUserScrub: false,
+		},
+		{
+			description:     "Invalid State Section Ignored",
+			regsExt:         `{"gpp":"DBABRg~BA","gpp_sid":[9]}`,
+			hostEnabled:     true,
+			expectUserScrub: false,
+			expectErrors: []error{
+				&errortypes.InvalidPrivacyConsent{Message: "request.regs.ext.gpp section of the US state va is too short"},
+			},
+		},
+		{
+			description:     "Invalid GPP String Reported Once",
+			regsExt:         `{"gpp":"malformed","gpp_sid":[9]}`,
+			hostEnabled:     true,
+			expectUserScrub: false,
+			expectErrors: []error{
+				&errortypes.InvalidPrivacyConsent{Message: "request.regs.ext.gpp header is invalid: must have type 3. Got 38"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		req := newBidRequest(t)
+		req.Regs = &openrtb.Regs{Ext: json.RawMessage(test.regsExt)}
+		if test.reqExt != "" {
+			req.Ext = json.RawMessage(test.reqExt)
+		}
+		req.Device.Geo = &openrtb.Geo{Lat: 123.456, Lon: 678.89}
+
+		privacyConfig := config.Privacy{
+			USStatePrivacy: config.USStatePrivacy{
+				Enforce: test.hostEnabled,
+			},
+		}
+
+		auctionReq := AuctionRequest{
+			BidRequest:   req,
+			UserSyncs:    &emptyUsersync{},
+			Account:      config.Account{USStatePrivacy: test.accountConfig},
+			LegacyLabels: metrics.Labels{RType: test.requestType},
+		}
+
+		bidderRequests, _, errs := cleanOpenRTBRequests(
+			context.Background(),
+			auctionReq,
+			nil,
+			&permissionsMock{personalInfoAllowed: true},
+			true,
+			privacyConfig)
+		result := bidderRequests[0]
+
+		assert.Equal(t, test.expectErrors, errs, test.description)
+		assert.NotEqual(t, "", result.BidRequest.Device.DIDMD5, test.description+":Device.DIDMD5")
+		if test.expectUserScrub {
+			assert.Equal(t, "", result.BidRequest.User.BuyerUID, test.description+":User.BuyerUID")
+			assert.Equal(t, &openrtb.Geo{Lat: 123.46, Lon: 678.89}, result.BidRequest.Device.Geo, test.description+":Device.Geo")
+		} else {
+			assert.NotEqual(t, "", result.BidRequest.User.BuyerUID, test.description+":User.BuyerUID")
+			assert.Equal(t, &openrtb.Geo{Lat: 123.456, Lon: 678.89}, result.BidRequest.Device.Geo, test.description+":Device.Geo")
+		}
+	}
+}
+
+func TestCleanOpenRTBRequestsActivityControls(t *testing.T) {
+	falseValue := false
+	denyBrightroll := config.AccountActivity{Rules: []config.AccountActivityRule{
+		{Condition: config.AccountActivityCondition{ComponentName: []string{"brightroll"}}, Allow: false},
+	}}
+
+	testCases := []struct {
+		description     string
+		privacyConfig   config.AccountPrivacy
+		expectedBidders []openrtb_ext.BidderName
+		expectUserScrub []openrtb_ext.BidderName
+		expectGeoScrub  []openrtb_ext.BidderName
+	}{
+		{
+			description:     "No Rules",
+			privacyConfig:   config.AccountPrivacy{},
+			expectedBidders: []openrtb_ext.BidderName{"appnexus", "brightroll"},
+		},
+		{
+			description: "Fetch Bids Denied For A Bidder",
+			privacyConfig: config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				FetchBids: denyBrightroll,
+			}},
+			expectedBidders: []openrtb_ext.BidderName{"appnexus"},
+		},
+		{
+			description: "Fetch Bids Denied By Default",
+			privacyConfig: config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				FetchBids: config.AccountActivity{Default: &falseValue},
+			}},
+			expectedBidders: []openrtb_ext.BidderName{},
+		},
+		{
+			description: "Transmit User FPD Denied For A Bidder",
+			privacyConfig: config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				TransmitUserFPD: denyBrightroll,
+			}},
+			expectedBidders: []openrtb_ext.BidderName{"appnexus", "brightroll"},
+			expectUserScrub: []openrtb_ext.BidderName{"brightroll"},
+		},
+		{
+			description: "Transmit Precise Geo Denied For The Country",
+			privacyConfig: config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				TransmitPreciseGeo: config.AccountActivity{Rules: []config.AccountActivityRule{
+					{Condition: config.AccountActivityCondition{Geo: []string{"USA"}}, Allow: false},
+				}},
+			}},
+			expectedBidders: []openrtb_ext.BidderName{"appnexus", "brightroll"},
+			expectGeoScrub:  []openrtb_ext.BidderName{"appnexus", "brightroll"},
+		},
+	}
+
+	for _, test := range testCases {
+		req := newAdapterAliasBidRequest(t)
+		req.Regs = nil
+		req.Device.Geo = &openrtb.Geo{Country: "USA", Lat: 123.456, Lon: 678.89}
+
+		auctionReq := AuctionRequest{
+			BidRequest: req,
+			UserSyncs:  &emptyUsersync{},
+			Account:    config.Account{Privacy: test.privacyConfig},
+		}
+
+		bidderRequests, _, errs := cleanOpenRTBRequests(
+			context.Background(),
+			auctionReq,
+			nil,
+			&permissionsMock{personalInfoAllowed: true},
+			true,
+			config.Privacy{})
+
+		assert.Empty(t, errs, test.description)
+		bidders := make([]openrtb_ext.BidderName, 0, len(bidderRequests))
+		for _, bidderRequest := range bidderRequests {
+			bidders = append(bidders, bidderRequest.BidderName)
+
+			if containsBidder(test.expectUserScrub, bidderRequest.BidderName) {
+				assert.Equal(t, "", bidderRequest.BidRequest.User.BuyerUID, "%s:%s:User.BuyerUID", test.description, bidderRequest.BidderName)
+				assert.Equal(t, "", bidderRequest.BidRequest.Device.DIDMD5, "%s:%s:Device.DIDMD5", test.description, bidderRequest.BidderName)
+			} else {
+				assert.NotEqual(t, "", bidderRequest.BidRequest.User.BuyerUID, "%s:%s:User.BuyerUID", test.description, bidderRequest.BidderName)
+				assert.NotEqual(t, "", bidderRequest.BidRequest.Device.DIDMD5, "%s:%s:Device.DIDMD5", test.description, bidderRequest.BidderName)
+			}
+
+			if containsBidder(test.expectGeoScrub, bidderRequest.BidderName) {
+				assert.Equal(t, 123.46, bidderRequest.BidRequest.Device.Geo.Lat, "%s:%s:Device.Geo", test.description, bidderRequest.BidderName)
+				assert.Equal(t, "132.173.230.0", bidderRequest.BidRequest.Device.IP, "%s:%s:Device.IP", test.description, bidderRequest.BidderName)
+			} else {
+				assert.Equal(t, 123.456, bidderRequest.BidRequest.Device.Geo.Lat, "%s:%s:Device.Geo", test.description, bidderRequest.BidderName)
+				assert.Equal(t, "132.173.230.74", bidderRequest.BidRequest.Device.IP, "%s:%s:Device.IP", test.description, bidderRequest.BidderName)
+			}
+		}
+		assert.ElementsMatch(t, test.expectedBidders, bidders, test.description)
+	}
+}
+
+func containsBidder(bidders []openrtb_ext.BidderName, bidder openrtb_ext.BidderName) bool {
+	for _, b := range bidders {
+		if b == bidder {
+			return true
+		}
+	}
+	return false
+}
+
 func TestCleanOpenRTBRequestsCOPPA(t *testing.T) {
 	testCases := []struct {
 		description         string
@@ -460,6 +837,185 @@ func TestCleanOpenRTBRequestsSChain(t *testing.T) {
 	}
 }
 
+func TestCleanOpenRTBRequestsFirstPartyData(t *testing.T) {
+	testCases := []struct {
+		description      string
+		inExt            json.RawMessage
+		expectedSiteExt  map[string]json.RawMessage
+		expectedUserExt  map[string]json.RawMessage
+		expectedSiteName map[string]string
+		expectedExt      json.RawMessage
+		expectError      bool
+	}{
+		{
+			description:     "No First Party Data Settings",
+			inExt:           json.RawMessage(`{"prebid":{}}`),
+			expectedSiteExt: map[string]json.RawMessage{"appnexus": json.RawMessage(`{"data":{"section":"sports"}}`), "rubicon": json.RawMessage(`{"data":{"section":"sports"}}`)},
+			expectedUserExt: map[string]json.RawMessage{"appnexus": json.RawMessage(`{"data":{"interest":"golf"},"consent":"c"}`), "rubicon": json.RawMessage(`{"data":{"interest":"golf"},"consent":"c"}`)},
+			expectedExt:     json.RawMessage(`{"prebid":{}}`),
+		},
+		{
+			description:     "Global Data Restricted To One Bidder",
+			inExt:           json.RawMessage(`{"prebid":{"data":{"bidders":["appnexus"]}}}`),
+			expectedSiteExt: map[string]json.RawMessage{"appnexus": json.RawMessage(`{"data":{"section":"sports"}}`), "rubicon": nil},
+			expectedUserExt: map[string]json.RawMessage{"appnexus": json.RawMessage(`{"data":{"interest":"golf"},"consent":"c"}`), "rubicon": json.RawMessage(`{"consent":"c"}`)},
+			expectedExt:     json.RawMessage(`{"prebid":{}}`),
+		},
+		{
+			description:      "Bidder Config Merged",
+			inExt:            json.RawMessage(`{"prebid":{"data":{"bidders":["*"]},"bidderconfig":[{"bidders":["rubicon"],"config":{"ortb2":{"site":{"name":"rubicon-site","ext":{"data":{"rating":"pg"}}}}}}]}}`),
+			expectedSiteExt:  map[string]json.RawMessage{"appnexus": json.RawMessage(`{"data":{"section":"sports"}}`), "rubicon": json.RawMessage(`{"data":{"rating":"pg","section":"sports"}}`)},
+			expectedUserExt:  map[string]json.RawMessage{"appnexus": json.RawMessage(`{"data":{"interest":"golf"},"consent":"c"}`), "rubicon": json.RawMessage(`{"data":{"interest":"golf"},"consent":"c"}`)},
+			expectedSiteName: map[string]string{"rubicon": "rubicon-site"},
+			expectedExt:      json.RawMessage(`{"prebid":{}}`),
+		},
+		{
+			description: "Duplicate Bidder Config",
+			inExt:       json.RawMessage(`{"prebid":{"bidderconfig":[{"bidders":["rubicon"],"config":{"ortb2":{}}},{"bidders":["rubicon"],"config":{"ortb2":{}}}]}}`),
+			expectError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		req := newBidRequest(t)
+		req.Site.Ext = json.RawMessage(`{"data":{"section":"sports"}}`)
+		req.User.Ext = json.RawMessage(`{"data":{"interest":"golf"},"consent":"c"}`)
+		req.Imp[0].Ext = json.RawMessage(`{"appnexus":{"placementId":1},"rubicon":{}}`)
+		req.Ext = test.inExt
+		extRequest, err := extractBidRequestExt(req)
+		assert.NoError(t, err, test.description)
+
+		auctionReq := AuctionRequest{
+			BidRequest: req,
+			UserSyncs:  &emptyUsersync{},
+		}
+
+		bidderRequests, _, errs := cleanOpenRTBRequests(context.Background(), auctionReq, extRequest, &permissionsMock{}, true, config.Privacy{})
+		if test.expectError {
+			assert.NotEmpty(t, errs, test.description)
+			assert.Empty(t, bidderRequests, test.description)
+			continue
+		}
+
+		assert.Empty(t, errs, test.description)
+		assert.Len(t, bidderRequests, 2, test.description)
+		for _, bidderRequest := range bidderRequests {
+			bidder := bidderRequest.BidderName.String()
+			if test.expectedSiteExt[bidder] == nil {
+				assert.Nil(t, bidderRequest.BidRequest.Site.Ext, "%s: %s site.ext", test.description, bidder)
+			} else {
+				assert.JSONEq(t, string(test.expectedSiteExt[bidder]), string(bidderRequest.BidRequest.Site.Ext), "%s: %s site.ext", test.description, bidder)
+			}
+			assert.JSONEq(t, string(test.expectedUserExt[bidder]), string(bidderRequest.BidRequest.User.Ext), "%s: %s user.ext", test.description, bidder)
+			assert.Equal(t, test.expectedSiteName[bidder], bidderRequest.BidRequest.Site.Name, "%s: %s site.name", test.description, bidder)
+			assert.Equal(t, "domain.com", bidderRequest.BidRequest.Site.Domain, "%s: %s site.domain", test.description, bidder)
+			assert.JSONEq(t, string(test.expectedExt), string(bidderRequest.BidRequest.Ext), "%s: %s ext", test.description, bidder)
+		}
+		assert.JSONEq(t, `{"data":{"section":"sports"}}`, string(req.Site.Ext), "%s: the original site shouldn't change", test.description)
+	}
+}
+
+func TestRemoveUnpermissionedEids(t *testing.T) {
+	eidPermissions := map[string][]string{
+		"source1": {"appnexus"},
+		"source2": {"*"},
+		"source3": {"rubicon"},
+	}
+
+	testCases := []struct {
+		description     string
+		userExt         json.RawMessage
+		bidder          string
+		eidPermissions  map[string][]string
+		expectedUserExt json.RawMessage
+	}{
+		{
+			description:     "No Rules",
+			userExt:         json.RawMessage(`{"eids":[{"source":"source1","id":"id1"}]}`),
+			bidder:          "rubicon",
+			expectedUserExt: json.RawMessage(`{"eids":[{"source":"source1","id":"id1"}]}`),
+		},
+		{
+			description:     "No Eids",
+			userExt:         json.RawMessage(`{"consent":"c"}`),
+			bidder:          "rubicon",
+			eidPermissions:  eidPermissions,
+			expectedUserExt: json.RawMessage(`{"consent":"c"}`),
+		},
+		{
+			description:     "All Eids Permitted",
+			userExt:         json.RawMessage(`{"eids":[{"source":"source1","id":"id1"},{"source":"source2","id":"id2"},{"source":"source4","id":"id4"}]}`),
+			bidder:          "appnexus",
+			eidPermissions:  eidPermissions,
+			expectedUserExt: json.RawMessage(`{"eids":[{"source":"source1","id":"id1"},{"source":"source2","id":"id2"},{"source":"source4","id":"id4"}]}`),
+		},
+		{
+			description:     "Some Eids Permitted",
+			userExt:         json.RawMessage(`{"consent":"c","eids":[{"source":"source1","id":"id1"},{"source":"source2","uids":[{"id":"id2"}]},{"source":"source4","id":"id4"}]}`),
+			bidder:          "rubicon",
+			eidPermissions:  eidPermissions,
+			expectedUserExt: json.RawMessage(`{"consent":"c","eids":[{"source":"source2","uids":[{"id":"id2"}]},{"source":"source4","id":"id4"}]}`),
+		},
+		{
+			description:     "No Eids Permitted",
+			userExt:         json.RawMessage(`{"consent":"c","eids":[{"source":"source1","id":"id1"}]}`),
+			bidder:          "rubicon",
+			eidPermissions:  eidPermissions,
+			expectedUserExt: json.RawMessage(`{"consent":"c"}`),
+		},
+		{
+			description:     "Nothing Left",
+			userExt:         json.RawMessage(`{"eids":[{"source":"source3","id":"id3"}]}`),
+			bidder:          "appnexus",
+			eidPermissions:  eidPermissions,
+			expectedUserExt: nil,
+		},
+	}
+
+	for _, test := range testCases {
+		sharedUser := &openrtb.User{ID: "user", Ext: test.userExt}
+		req := &openrtb.BidRequest{User: sharedUser}
+
+		err := removeUnpermissionedEids(req, test.bidder, test.eidPermissions)
+
+		assert.NoError(t, err, test.description)
+		if test.expectedUserExt == nil {
+			assert.Nil(t, req.User.Ext, test.description)
+		} else {
+			assert.JSONEq(t, string(test.expectedUserExt), string(req.User.Ext), test.description)
+		}
+		assert.Equal(t, "user", req.User.ID, test.description)
+		assert.Equal(t, test.userExt, sharedUser.Ext, "%s: the shared user shouldn't change", test.description)
+	}
+}
+
+func TestCleanOpenRTBRequestsEidPermissions(t *testing.T) {
+	req := newBidRequest(t)
+	req.User.Ext = json.RawMessage(`{"eids":[{"source":"source1","id":"id1"},{"source":"source2","id":"id2"}]}`)
+	req.Imp[0].Ext = json.RawMessage(`{"appnexus":{"placementId":1},"rubicon":{}}`)
+	req.Ext = json.RawMessage(`{"prebid":{"data":{"eidpermissions":[{"source":"source1","bidders":["appnexus"]}]}}}`)
+	extRequest, err := extractBidRequestExt(req)
+	assert.NoError(t, err)
+
+	auctionReq := AuctionRequest{
+		BidRequest: req,
+		UserSyncs:  &emptyUsersync{},
+	}
+	bidderRequests, _, errs := cleanOpenRTBRequests(context.Background(), auctionReq, extRequest, &permissionsMock{}, true, config.Privacy{})
+
+	assert.Empty(t, errs)
+	expectedUserExts := map[openrtb_ext.BidderName]string{
+		"appnexus": `{"eids":[{"source":"source1","id":"id1"},{"source":"source2","id":"id2"}]}`,
+		"rubicon":  `{"eids":[{"source":"source2","id":"id2"}]}`,
+	}
+	if assert.Len(t, bidderRequests, 2) {
+		for _, bidderRequest := range bidderRequests {
+			assert.JSONEq(t, expectedUserExts[bidderRequest.BidderName], string(bidderRequest.BidRequest.User.Ext), bidderRequest.BidderName.String())
+			assert.JSONEq(t, `{"prebid":{}}`, string(bidderRequest.BidRequest.Ext), "The eid permissions shouldn't be sent to %s", bidderRequest.BidderName)
+		}
+	}
+}
+
 func TestExtractBidRequestExt(t *testing.T) {
 	var boolFalse, boolTrue *bool = new(bool), new(bool)
 	*boolFalse = false
@@ -0,0 +1,166 @@
+package firstpartydata
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/buger/jsonparser"
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+const (
+	// dataKey is the ext field of site, app and user which holds their global first party data.
+	dataKey = "data"
+	// allBidders is the entry of ext.prebid.data.bidders and ext.prebid.bidderconfig.bidders which applies to every bidder.
+	allBidders = "*"
+)
+
+// Resolver sets the first party data of each bidder's request, from request.ext.prebid.data and request.ext.prebid.bidderconfig.
+type Resolver struct {
+	allowedBidders map[string]struct{}
+	bidderConfigs  map[string]*openrtb_ext.ORTB2
+}
+
+// NewResolver reads the first party data settings of the request. It returns a nil Resolver, which leaves the
+// requests of the bidders untouched, if the request has none.
+func NewResolver(request *openrtb.BidRequest, requestExt *openrtb_ext.ExtRequest) (*Resolver, error) {
+	if requestExt == nil || (requestExt.Prebid.Data == nil && len(requestExt.Prebid.BidderConfig) == 0) {
+		return nil, nil
+	}
+
+	resolver := &Resolver{}
+	if requestExt.Prebid.Data != nil && len(requestExt.Prebid.Data.Bidders) > 0 {
+		resolver.allowedBidders = make(map[string]struct{}, len(requestExt.Prebid.Data.Bidders))
+		for _, bidder := range requestExt.Prebid.Data.Bidders {
+			resolver.allowedBidders[bidder] = struct{}{}
+		}
+	}
+
+	resolver.bidderConfigs = make(map[string]*openrtb_ext.ORTB2)
+	for i, bidderConfig := range requestExt.Prebid.BidderConfig {
+		if bidderConfig == nil || bidderConfig.Config == nil || bidderConfig.Config.ORTB2 == nil {
+			return nil, fmt.Errorf("request.ext.prebid.bidderconfig[%d].config.ortb2 is required", i)
+		}
+		ortb2 := bidderConfig.Config.ORTB2
+		if len(ortb2.Site) > 0 && request.App != nil {
+			return nil, fmt.Errorf("request.ext.prebid.bidderconfig[%d].config.ortb2.site can't be set on an app request", i)
+		}
+		if len(ortb2.App) > 0 && request.Site != nil {
+			return nil, fmt.Errorf("request.ext.prebid.bidderconfig[%d].config.ortb2.app can't be set on a site request", i)
+		}
+		for _, bidder := range bidderConfig.Bidders {
+			if _, present := resolver.bidderConfigs[bidder]; present {
+				return nil, fmt.Errorf("request.ext.prebid.bidderconfig contains multiple configs for bidder %s; "+
+					"it must contain no more than one per bidder.", bidder)
+			}
+			resolver.bidderConfigs[bidder] = ortb2
+		}
+	}
+	return resolver, nil
+}
+
+// Apply sets the site, app and user of the request sent to the bidder. The global first party data is removed if the
+// bidder isn't allowed to receive it, and its own first party data is merged in. The objects of the request are copied
+// before they are changed, as they are shared with the requests of the other bidders.
+func (r *Resolver) Apply(request *openrtb.BidRequest, bidder string) error {
+	if r == nil {
+		return nil
+	}
+
+	if !r.isAllowed(bidder) {
+		removeGlobalFPD(request)
+	}
+
+	ortb2, ok := r.bidderConfigs[bidder]
+	if !ok {
+		ortb2, ok = r.bidderConfigs[allBidders]
+	}
+	if !ok {
+		return nil
+	}
+
+	if len(ortb2.Site) > 0 {
+		site := &openrtb.Site{}
+		if err := mergeFPD(request.Site, ortb2.Site, site); err != nil {
+			return fmt.Errorf("request.ext.prebid.bidderconfig site of bidder %s could not be merged: %v", bidder, err)
+		}
+		request.Site = site
+	}
+	if len(ortb2.App) > 0 {
+		app := &openrtb.App{}
+		if err := mergeFPD(request.App, ortb2.App, app); err != nil {
+			return fmt.Errorf("request.ext.prebid.bidderconfig app of bidder %s could not be merged: %v", bidder, err)
+		}
+		request.App = app
+	}
+	if len(ortb2.User) > 0 {
+		user := &openrtb.User{}
+		if err := mergeFPD(request.User, ortb2.User, user); err != nil {
+			return fmt.Errorf("request.ext.prebid.bidderconfig user of bidder %s could not be merged: %v", bidder, err)
+		}
+		request.User = user
+	}
+	return nil
+}
+
+func (r *Resolver) isAllowed(bidder string) bool {
+	if r.allowedBidders == nil {
+		return true
+	}
+	_, allowed := r.allowedBidders[bidder]
+	_, allowAll := r.allowedBidders[allBidders]
+	return allowed || allowAll
+}
+
+func removeGlobalFPD(request *openrtb.BidRequest) {
+	if request.Site != nil && hasData(request.Site.Ext) {
+		site := *request.Site
+		site.Ext = removeData(site.Ext)
+		request.Site = &site
+	}
+	if request.App != nil && hasData(request.App.Ext) {
+		app := *request.App
+		app.Ext = removeData(app.Ext)
+		request.App = &app
+	}
+	if request.User != nil && hasData(request.User.Ext) {
+		user := *request.User
+		user.Ext = removeData(user.Ext)
+		request.User = &user
+	}
+}
+
+func hasData(ext json.RawMessage) bool {
+	_, _, _, err := jsonparser.Get(ext, dataKey)
+	return err == nil
+}
+
+// removeData returns the ext without its data field, or nil if nothing else is left.
+func removeData(ext json.RawMessage) json.RawMessage {
+	// jsonparser.Delete works in place, and the ext is shared with the other bidders.
+	ext = jsonparser.Delete(append(json.RawMessage(nil), ext...), dataKey)
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(ext, &fields); err != nil || len(fields) == 0 {
+		return nil
+	}
+	return ext
+}
+
+// mergeFPD merges the bidder's first party data into a copy of the original object, following the JSON Merge Patch
+// rules, so that the fields of the bidder replace the global ones and the objects, such as ext.data, are combined.
+func mergeFPD(original interface{}, fpd json.RawMessage, merged interface{}) error {
+	originalJSON, err := json.Marshal(original)
+	if err != nil {
+		return err
+	}
+	if string(originalJSON) == "null" {
+		originalJSON = []byte("{}")
+	}
+	mergedJSON, err := jsonpatch.MergePatch(originalJSON, fpd)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(mergedJSON, merged)
+}
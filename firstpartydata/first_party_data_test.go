@@ -0,0 +1,236 @@
+package firstpartydata
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewResolver(t *testing.T) {
+	siteRequest := &openrtb.BidRequest{Site: &openrtb.Site{}}
+	appRequest := &openrtb.BidRequest{App: &openrtb.App{}}
+	siteORTB2 := &openrtb_ext.ORTB2{Site: json.RawMessage(`{"name":"site"}`)}
+
+	testCases := []struct {
+		description      string
+		request          *openrtb.BidRequest
+		requestExt       *openrtb_ext.ExtRequest
+		expectedResolver *Resolver
+		expectedError    error
+	}{
+		{
+			description: "No Ext",
+			request:     siteRequest,
+		},
+		{
+			description: "No First Party Data Settings",
+			request:     siteRequest,
+			requestExt:  &openrtb_ext.ExtRequest{},
+		},
+		{
+			description: "Allowed Bidders",
+			request:     siteRequest,
+			requestExt: &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+				Data: &openrtb_ext.ExtRequestPrebidData{Bidders: []string{"appnexus", "rubicon"}},
+			}},
+			expectedResolver: &Resolver{
+				allowedBidders: map[string]struct{}{"appnexus": {}, "rubicon": {}},
+				bidderConfigs:  map[string]*openrtb_ext.ORTB2{},
+			},
+		},
+		{
+			description: "Bidder Configs",
+			request:     siteRequest,
+			requestExt: &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+				Data: &openrtb_ext.ExtRequestPrebidData{},
+				BidderConfig: []*openrtb_ext.ExtRequestPrebidBidderConfig{
+					{Bidders: []string{"appnexus", "rubicon"}, Config: &openrtb_ext.BidderConfig{ORTB2: siteORTB2}},
+				},
+			}},
+			expectedResolver: &Resolver{
+				bidderConfigs: map[string]*openrtb_ext.ORTB2{"appnexus": siteORTB2, "rubicon": siteORTB2},
+			},
+		},
+		{
+			description: "Bidder Config Without ORTB2",
+			request:     siteRequest,
+			requestExt: &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+				BidderConfig: []*openrtb_ext.ExtRequestPrebidBidderConfig{
+					{Bidders: []string{"appnexus"}, Config: &openrtb_ext.BidderConfig{}},
+				},
+			}},
+			expectedError: errors.New("request.ext.prebid.bidderconfig[0].config.ortb2 is required"),
+		},
+		{
+			description: "Multiple Configs For A Bidder",
+			request:     siteRequest,
+			requestExt: &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+				BidderConfig: []*openrtb_ext.ExtRequestPrebidBidderConfig{
+					{Bidders: []string{"appnexus"}, Config: &openrtb_ext.BidderConfig{ORTB2: siteORTB2}},
+					{Bidders: []string{"rubicon", "appnexus"}, Config: &openrtb_ext.BidderConfig{ORTB2: siteORTB2}},
+				},
+			}},
+			expectedError: errors.New("request.ext.prebid.bidderconfig contains multiple configs for bidder appnexus; it must contain no more than one per bidder."),
+		},
+		{
+			description: "Site Config On An App Request",
+			request:     appRequest,
+			requestExt: &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+				BidderConfig: []*openrtb_ext.ExtRequestPrebidBidderConfig{
+					{Bidders: []string{"appnexus"}, Config: &openrtb_ext.BidderConfig{ORTB2: siteORTB2}},
+				},
+			}},
+			expectedError: errors.New("request.ext.prebid.bidderconfig[0].config.ortb2.site can't be set on an app request"),
+		},
+		{
+			description: "App Config On A Site Request",
+			request:     siteRequest,
+			requestExt: &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+				BidderConfig: []*openrtb_ext.ExtRequestPrebidBidderConfig{
+					{Bidders: []string{"appnexus"}, Config: &openrtb_ext.BidderConfig{ORTB2: &openrtb_ext.ORTB2{App: json.RawMessage(`{}`)}}},
+				},
+			}},
+			expectedError: errors.New("request.ext.prebid.bidderconfig[0].config.ortb2.app can't be set on a site request"),
+		},
+	}
+
+	for _, test := range testCases {
+		resolver, err := NewResolver(test.request, test.requestExt)
+		assert.Equal(t, test.expectedResolver, resolver, test.description)
+		assert.Equal(t, test.expectedError, err, test.description)
+	}
+}
+
+func TestApply(t *testing.T) {
+	testCases := []struct {
+		description     string
+		resolver        *Resolver
+		bidder          string
+		request         *openrtb.BidRequest
+		expectedRequest *openrtb.BidRequest
+		expectError     bool
+	}{
+		{
+			description: "Nil Resolver",
+			resolver:    nil,
+			bidder:      "appnexus",
+			request: &openrtb.BidRequest{
+				Site: &openrtb.Site{ID: "site", Ext: json.RawMessage(`{"data":{"section":"sports"}}`)},
+			},
+			expectedRequest: &openrtb.BidRequest{
+				Site: &openrtb.Site{ID: "site", Ext: json.RawMessage(`{"data":{"section":"sports"}}`)},
+			},
+		},
+		{
+			description: "Allowed Bidder",
+			resolver:    &Resolver{allowedBidders: map[string]struct{}{"appnexus": {}}},
+			bidder:      "appnexus",
+			request: &openrtb.BidRequest{
+				Site: &openrtb.Site{ID: "site", Ext: json.RawMessage(`{"data":{"section":"sports"}}`)},
+			},
+			expectedRequest: &openrtb.BidRequest{
+				Site: &openrtb.Site{ID: "site", Ext: json.RawMessage(`{"data":{"section":"sports"}}`)},
+			},
+		},
+		{
+			description: "All Bidders Allowed",
+			resolver:    &Resolver{allowedBidders: map[string]struct{}{"*": {}}},
+			bidder:      "appnexus",
+			request: &openrtb.BidRequest{
+				App: &openrtb.App{ID: "app", Ext: json.RawMessage(`{"data":{"section":"sports"}}`)},
+			},
+			expectedRequest: &openrtb.BidRequest{
+				App: &openrtb.App{ID: "app", Ext: json.RawMessage(`{"data":{"section":"sports"}}`)},
+			},
+		},
+		{
+			description: "Bidder Not Allowed",
+			resolver:    &Resolver{allowedBidders: map[string]struct{}{"rubicon": {}}},
+			bidder:      "appnexus",
+			request: &openrtb.BidRequest{
+				App:  &openrtb.App{ID: "app", Ext: json.RawMessage(`{"data":{"section":"sports"}}`)},
+				User: &openrtb.User{ID: "user", Ext: json.RawMessage(`{"consent":"c","data":{"interest":"golf"}}`)},
+			},
+			expectedRequest: &openrtb.BidRequest{
+				App:  &openrtb.App{ID: "app"},
+				User: &openrtb.User{ID: "user", Ext: json.RawMessage(`{"consent":"c"}`)},
+			},
+		},
+		{
+			description: "Bidder Config",
+			resolver: &Resolver{bidderConfigs: map[string]*openrtb_ext.ORTB2{
+				"appnexus": {Site: json.RawMessage(`{"name":"apn","ext":{"data":{"rating":"pg"}}}`), User: json.RawMessage(`{"keywords":"apn"}`)},
+				"*":        {Site: json.RawMessage(`{"name":"all"}`)},
+			}},
+			bidder: "appnexus",
+			request: &openrtb.BidRequest{
+				Site: &openrtb.Site{ID: "site", Name: "site", Ext: json.RawMessage(`{"data":{"section":"sports"}}`)},
+			},
+			expectedRequest: &openrtb.BidRequest{
+				Site: &openrtb.Site{ID: "site", Name: "apn", Ext: json.RawMessage(`{"data":{"rating":"pg","section":"sports"}}`)},
+				User: &openrtb.User{Keywords: "apn"},
+			},
+		},
+		{
+			description: "Wildcard Bidder Config",
+			resolver: &Resolver{bidderConfigs: map[string]*openrtb_ext.ORTB2{
+				"rubicon": {Site: json.RawMessage(`{"name":"rubicon"}`)},
+				"*":       {Site: json.RawMessage(`{"name":"all"}`)},
+			}},
+			bidder: "appnexus",
+			request: &openrtb.BidRequest{
+				Site: &openrtb.Site{ID: "site", Name: "site"},
+			},
+			expectedRequest: &openrtb.BidRequest{
+				Site: &openrtb.Site{ID: "site", Name: "all"},
+			},
+		},
+		{
+			description: "Bidder Config Not Allowed Global Data",
+			resolver: &Resolver{
+				allowedBidders: map[string]struct{}{"rubicon": {}},
+				bidderConfigs:  map[string]*openrtb_ext.ORTB2{"appnexus": {Site: json.RawMessage(`{"ext":{"data":{"rating":"pg"}}}`)}},
+			},
+			bidder: "appnexus",
+			request: &openrtb.BidRequest{
+				Site: &openrtb.Site{ID: "site", Ext: json.RawMessage(`{"data":{"section":"sports"}}`)},
+			},
+			expectedRequest: &openrtb.BidRequest{
+				Site: &openrtb.Site{ID: "site", Ext: json.RawMessage(`{"data":{"rating":"pg"}}`)},
+			},
+		},
+		{
+			description: "Malformed Bidder Config",
+			resolver: &Resolver{bidderConfigs: map[string]*openrtb_ext.ORTB2{
+				"appnexus": {Site: json.RawMessage(`{"name":1}`)},
+			}},
+			bidder: "appnexus",
+			request: &openrtb.BidRequest{
+				Site: &openrtb.Site{ID: "site"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		original, _ := json.Marshal(test.request)
+		request := *test.request
+
+		err := test.resolver.Apply(&request, test.bidder)
+
+		if test.expectError {
+			assert.Error(t, err, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+			actual, _ := json.Marshal(request)
+			expected, _ := json.Marshal(test.expectedRequest)
+			assert.JSONEq(t, string(expected), string(actual), test.description)
+		}
+		unchanged, _ := json.Marshal(test.request)
+		assert.JSONEq(t, string(original), string(unchanged), "%s: the shared objects shouldn't change", test.description)
+	}
+}
@@ -0,0 +1,338 @@
+package floors
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// Locations a floor may be resolved from. These are reported in bidresponse.ext.prebid.floors.
+const (
+	LocationRequest string = "request"
+	LocationAccount string = "account"
+	LocationImp     string = "imp"
+)
+
+const defaultCurrency string = "USD"
+
+// Rules holds the floor configuration which applies to a single auction.
+type Rules struct {
+	// Enforce is true when bids below the floor should be rejected by Prebid Server.
+	Enforce bool
+	// EnforceDeals is true when deal bids should also be rejected if they are below the floor.
+	EnforceDeals bool
+	// Location is where the rule set was read from.
+	Location    string
+	FloorMin    float64
+	FloorMinCur string
+	Data        *openrtb_ext.PriceFloorData
+	Bidders     map[string]*openrtb_ext.BidderFloor
+
+	floorMinLocation string
+	bidderLocations  map[string]string
+}
+
+// Fields are the attributes of a bid opportunity which floors are looked up by.
+// Any empty field is treated as a wildcard.
+type Fields struct {
+	MediaType  string
+	Size       string
+	Domain     string
+	AdUnitCode string
+	Bidder     string
+}
+
+// Floor is the floor value which applies to a single imp for a single bidder.
+type Floor struct {
+	Value    float64
+	Currency string
+	Rule     string
+	Location string
+}
+
+// Resolve merges the account configuration with the request floors into the Rules used by the auction.
+// Request settings (which already include any stored request) take precedence over the account settings.
+//
+// It returns nil if floors are disabled for this auction. Errors describe rule sets which were
+// discarded because they were malformed.
+func Resolve(account config.AccountPriceFloors, requestFloors *openrtb_ext.PriceFloorRules) (*Rules, []error) {
+	if !account.Enabled || !requestFloors.GetEnabled() {
+		return nil, nil
+	}
+
+	var errs []error
+	rules := &Rules{
+		Enforce:      true,
+		EnforceDeals: account.EnforceDealFloors,
+		Location:     LocationImp,
+		Bidders:      make(map[string]*openrtb_ext.BidderFloor),
+
+		bidderLocations: make(map[string]string),
+	}
+	sources := []struct {
+		floors   *openrtb_ext.PriceFloorRules
+		location string
+	}{
+		{account.Rules, LocationAccount},
+		{requestFloors, LocationRequest},
+	}
+	for _, source := range sources {
+		if source.floors == nil {
+			continue
+		}
+		if enforcement := source.floors.Enforcement; enforcement != nil {
+			if enforcement.EnforcePBS != nil {
+				rules.Enforce = *enforcement.EnforcePBS
+			}
+			if enforcement.FloorDeals != nil {
+				rules.EnforceDeals = *enforcement.FloorDeals
+			}
+		}
+		if source.floors.FloorMin > 0 {
+			rules.FloorMin, rules.FloorMinCur = source.floors.FloorMin, source.floors.FloorMinCur
+			rules.floorMinLocation = source.location
+		}
+		for bidder, bidderFloor := range source.floors.Bidders {
+			rules.Bidders[bidder] = bidderFloor
+			rules.bidderLocations[bidder] = source.location
+		}
+		if source.floors.Data != nil {
+			if err := source.floors.Data.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("%s price floors discarded: %v", source.location, err))
+				continue
+			}
+			rules.Data, rules.Location = source.floors.Data, source.location
+		}
+	}
+	return rules, errs
+}
+
+// FieldsFromImp builds the lookup fields known before the bidders are called.
+// Media type and size are only set if the imp leaves no ambiguity about them.
+func FieldsFromImp(request *openrtb.BidRequest, imp *openrtb.Imp, bidder openrtb_ext.BidderName) Fields {
+	fields := Fields{
+		Domain:     requestDomain(request),
+		AdUnitCode: imp.TagID,
+		Bidder:     bidder.String(),
+	}
+
+	var mediaTypes []openrtb_ext.BidType
+	if imp.Banner != nil {
+		mediaTypes = append(mediaTypes, openrtb_ext.BidTypeBanner)
+	}
+	if imp.Video != nil {
+		mediaTypes = append(mediaTypes, openrtb_ext.BidTypeVideo)
+	}
+	if imp.Audio != nil {
+		mediaTypes = append(mediaTypes, openrtb_ext.BidTypeAudio)
+	}
+	if imp.Native != nil {
+		mediaTypes = append(mediaTypes, openrtb_ext.BidTypeNative)
+	}
+	if len(mediaTypes) == 1 {
+		fields.MediaType = string(mediaTypes[0])
+	}
+
+	if len(mediaTypes) == 1 && imp.Banner != nil {
+		if len(imp.Banner.Format) == 1 {
+			fields.Size = FormatSize(imp.Banner.Format[0].W, imp.Banner.Format[0].H)
+		} else if len(imp.Banner.Format) == 0 && imp.Banner.W != nil && imp.Banner.H != nil {
+			fields.Size = FormatSize(*imp.Banner.W, *imp.Banner.H)
+		}
+	} else if len(mediaTypes) == 1 && imp.Video != nil && imp.Video.W > 0 && imp.Video.H > 0 {
+		fields.Size = FormatSize(imp.Video.W, imp.Video.H)
+	}
+	return fields
+}
+
+// FieldsFromBid builds the lookup fields for a bid which was returned by a bidder.
+func FieldsFromBid(request *openrtb.BidRequest, imp *openrtb.Imp, bidder openrtb_ext.BidderName, bid *openrtb.Bid, bidType openrtb_ext.BidType) Fields {
+	fields := FieldsFromImp(request, imp, bidder)
+	fields.MediaType = string(bidType)
+	if bid.W > 0 && bid.H > 0 {
+		fields.Size = FormatSize(bid.W, bid.H)
+	}
+	return fields
+}
+
+// FormatSize formats a size the same way it is written in the floor rule keys.
+func FormatSize(w, h uint64) string {
+	return fmt.Sprintf("%dx%d", w, h)
+}
+
+func requestDomain(request *openrtb.BidRequest) string {
+	if request.Site != nil {
+		if request.Site.Domain != "" {
+			return request.Site.Domain
+		}
+		if request.Site.Publisher != nil {
+			return request.Site.Publisher.Domain
+		}
+	}
+	if request.App != nil {
+		if request.App.Domain != "" {
+			return request.App.Domain
+		}
+		if request.App.Publisher != nil {
+			return request.App.Publisher.Domain
+		}
+	}
+	return ""
+}
+
+// Lookup finds the floor for an imp and converts it into the target currency.
+//
+// Floors are taken, in order of preference, from the first matching rule, the rule set default and
+// finally imp.bidfloor. The floor is then raised to the floormin of the request and of the bidder, if any.
+// A zero Floor value means that no floor applies.
+func (r *Rules) Lookup(imp *openrtb.Imp, fields Fields, conversions currency.Conversions, targetCur string) (Floor, error) {
+	var floor Floor
+	var value float64
+	var cur string
+
+	if targetCur == "" {
+		targetCur = defaultCurrency
+	}
+
+	if r.Data != nil {
+		cur = r.Data.Currency
+		if rule, ruleValue, found := matchRule(r.Data, fields); found {
+			value, floor.Rule, floor.Location = ruleValue, rule, r.Location
+		} else if r.Data.Default > 0 {
+			value, floor.Location = r.Data.Default, r.Location
+		}
+	}
+	if floor.Location == "" && imp.BidFloor > 0 {
+		value, cur, floor.Location = imp.BidFloor, imp.BidFloorCur, LocationImp
+	}
+
+	floor.Currency = targetCur
+	if value > 0 {
+		converted, err := convert(value, cur, targetCur, conversions)
+		if err != nil {
+			return Floor{}, err
+		}
+		floor.Value = converted
+	}
+
+	floorMin, floorMinCur := r.FloorMin, r.FloorMinCur
+	if floorMinCur == "" && r.Data != nil {
+		floorMinCur = r.Data.Currency
+	}
+	if err := floor.raise(floorMin, floorMinCur, r.floorMinLocation, conversions); err != nil {
+		return Floor{}, err
+	}
+	if bidderFloor, ok := r.Bidders[fields.Bidder]; ok && bidderFloor != nil {
+		if err := floor.raise(bidderFloor.FloorMin, floorMinCur, r.bidderLocations[fields.Bidder], conversions); err != nil {
+			return Floor{}, err
+		}
+	}
+	return floor, nil
+}
+
+// EnforceForBidder returns false if the bidder was exempted from floor enforcement.
+func (r *Rules) EnforceForBidder(bidder string) bool {
+	if bidderFloor, ok := r.Bidders[bidder]; ok && bidderFloor != nil && bidderFloor.EnforcePBS != nil {
+		return *bidderFloor.EnforcePBS
+	}
+	return r.Enforce
+}
+
+func (f *Floor) raise(min float64, minCur string, location string, conversions currency.Conversions) error {
+	if min <= 0 {
+		return nil
+	}
+	converted, err := convert(min, minCur, f.Currency, conversions)
+	if err != nil {
+		return err
+	}
+	if converted > f.Value {
+		f.Value = converted
+		f.Rule = ""
+		f.Location = location
+	}
+	return nil
+}
+
+func convert(value float64, from string, to string, conversions currency.Conversions) (float64, error) {
+	if from == "" {
+		from = defaultCurrency
+	}
+	if to == "" {
+		to = defaultCurrency
+	}
+	rate, err := conversions.GetRate(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return value * rate, nil
+}
+
+// matchRule returns the most specific rule matching the fields. Rules with fewer wildcards are preferred and,
+// amongst those, rules whose wildcards are in the later schema fields.
+func matchRule(data *openrtb_ext.PriceFloorData, fields Fields) (string, float64, bool) {
+	values := make([]string, len(data.Schema.Fields))
+	for i, field := range data.Schema.Fields {
+		values[i] = fieldValue(field, fields)
+	}
+	delimiter := data.GetDelimiter()
+
+	key := make([]string, len(values))
+	for _, mask := range wildcardMasks(len(values)) {
+		for i := range values {
+			if mask&(1<<uint(len(values)-1-i)) != 0 {
+				key[i] = openrtb_ext.FloorWildcard
+			} else {
+				key[i] = values[i]
+			}
+		}
+		rule := strings.Join(key, delimiter)
+		if value, ok := data.Values[rule]; ok {
+			return rule, value, true
+		}
+	}
+	return "", 0, false
+}
+
+// wildcardMasks lists every combination of wildcards over n fields, from the most to the least specific.
+// The bit for the first field is the most significant one, so a lower mask puts its wildcards in later fields.
+func wildcardMasks(n int) []uint {
+	masks := make([]uint, 1<<uint(n))
+	for i := range masks {
+		masks[i] = uint(i)
+	}
+	sort.Slice(masks, func(i, j int) bool {
+		ci, cj := bits.OnesCount(masks[i]), bits.OnesCount(masks[j])
+		if ci != cj {
+			return ci < cj
+		}
+		return masks[i] < masks[j]
+	})
+	return masks
+}
+
+func fieldValue(field string, fields Fields) string {
+	var value string
+	switch field {
+	case openrtb_ext.FloorFieldMediaType:
+		value = fields.MediaType
+	case openrtb_ext.FloorFieldSize:
+		value = fields.Size
+	case openrtb_ext.FloorFieldDomain:
+		value = fields.Domain
+	case openrtb_ext.FloorFieldAdUnitCode:
+		value = fields.AdUnitCode
+	case openrtb_ext.FloorFieldBidder:
+		value = fields.Bidder
+	}
+	if value == "" {
+		return openrtb_ext.FloorWildcard
+	}
+	return strings.ToLower(value)
+}
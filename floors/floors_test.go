@@ -0,0 +1,269 @@
+package floors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	falseValue, trueValue := false, true
+	requestData := &openrtb_ext.PriceFloorData{
+		Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType"}},
+		Values: map[string]float64{"banner": 1},
+	}
+	accountData := &openrtb_ext.PriceFloorData{
+		Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"domain"}},
+		Values: map[string]float64{"*": 2},
+	}
+	invalidData := &openrtb_ext.PriceFloorData{
+		Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"unknown"}},
+	}
+	duplicateFieldsData := &openrtb_ext.PriceFloorData{
+		Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType", "mediaType"}},
+	}
+
+	testCases := []struct {
+		description   string
+		account       config.AccountPriceFloors
+		request       *openrtb_ext.PriceFloorRules
+		expectedRules *Rules
+		expectedErrs  int
+	}{
+		{
+			description:   "Account Disabled",
+			account:       config.AccountPriceFloors{Enabled: false},
+			request:       &openrtb_ext.PriceFloorRules{Data: requestData},
+			expectedRules: nil,
+		},
+		{
+			description:   "Request Disabled",
+			account:       config.AccountPriceFloors{Enabled: true, Rules: &openrtb_ext.PriceFloorRules{Data: accountData}},
+			request:       &openrtb_ext.PriceFloorRules{Enabled: &falseValue},
+			expectedRules: nil,
+		},
+		{
+			description: "No Rule Sets - Imp Floors",
+			account:     config.AccountPriceFloors{Enabled: true, EnforceDealFloors: true},
+			request:     nil,
+			expectedRules: &Rules{
+				Enforce:         true,
+				EnforceDeals:    true,
+				Location:        LocationImp,
+				Bidders:         map[string]*openrtb_ext.BidderFloor{},
+				bidderLocations: map[string]string{},
+			},
+		},
+		{
+			description: "Account Rule Set",
+			account:     config.AccountPriceFloors{Enabled: true, Rules: &openrtb_ext.PriceFloorRules{Data: accountData, FloorMin: 0.5}},
+			request:     &openrtb_ext.PriceFloorRules{Enforcement: &openrtb_ext.PriceFloorEnforcement{EnforcePBS: &falseValue, FloorDeals: &trueValue}},
+			expectedRules: &Rules{
+				Enforce:          false,
+				EnforceDeals:     true,
+				Location:         LocationAccount,
+				FloorMin:         0.5,
+				Data:             accountData,
+				Bidders:          map[string]*openrtb_ext.BidderFloor{},
+				floorMinLocation: LocationAccount,
+				bidderLocations:  map[string]string{},
+			},
+		},
+		{
+			description: "Request Rule Set Overrides Account",
+			account:     config.AccountPriceFloors{Enabled: true, Rules: &openrtb_ext.PriceFloorRules{Data: accountData}},
+			request:     &openrtb_ext.PriceFloorRules{Data: requestData, Bidders: map[string]*openrtb_ext.BidderFloor{"appnexus": {FloorMin: 3}}},
+			expectedRules: &Rules{
+				Enforce:         true,
+				Location:        LocationRequest,
+				Data:            requestData,
+				Bidders:         map[string]*openrtb_ext.BidderFloor{"appnexus": {FloorMin: 3}},
+				bidderLocations: map[string]string{"appnexus": LocationRequest},
+			},
+		},
+		{
+			description: "Invalid Request Rule Set Falls Back To Account",
+			account:     config.AccountPriceFloors{Enabled: true, Rules: &openrtb_ext.PriceFloorRules{Data: accountData}},
+			request:     &openrtb_ext.PriceFloorRules{Data: invalidData},
+			expectedRules: &Rules{
+				Enforce:         true,
+				Location:        LocationAccount,
+				Data:            accountData,
+				Bidders:         map[string]*openrtb_ext.BidderFloor{},
+				bidderLocations: map[string]string{},
+			},
+			expectedErrs: 1,
+		},
+		{
+			description: "Duplicate Schema Fields Fall Back To Account",
+			account:     config.AccountPriceFloors{Enabled: true, Rules: &openrtb_ext.PriceFloorRules{Data: accountData}},
+			request:     &openrtb_ext.PriceFloorRules{Data: duplicateFieldsData},
+			expectedRules: &Rules{
+				Enforce:         true,
+				Location:        LocationAccount,
+				Data:            accountData,
+				Bidders:         map[string]*openrtb_ext.BidderFloor{},
+				bidderLocations: map[string]string{},
+			},
+			expectedErrs: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		rules, errs := Resolve(test.account, test.request)
+		assert.Equal(t, test.expectedRules, rules, test.description)
+		assert.Len(t, errs, test.expectedErrs, test.description)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	conversions := currency.NewRates(time.Now(), map[string]map[string]float64{
+		"USD": {"EUR": 0.5},
+	})
+	data := &openrtb_ext.PriceFloorData{
+		Currency: "USD",
+		Schema:   openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType", "size", "domain"}},
+		Values: map[string]float64{
+			"banner|300x250|www.example.com": 5,
+			"banner|300x250|*":               4,
+			"banner|*|www.example.com":       3,
+			"*|*|*":                          1,
+		},
+		Default: 0.1,
+	}
+
+	testCases := []struct {
+		description   string
+		rules         Rules
+		imp           openrtb.Imp
+		fields        Fields
+		targetCur     string
+		expectedFloor Floor
+	}{
+		{
+			description:   "Exact Match",
+			rules:         Rules{Data: data, Location: LocationRequest},
+			fields:        Fields{MediaType: "banner", Size: "300x250", Domain: "www.example.com"},
+			expectedFloor: Floor{Value: 5, Currency: "USD", Rule: "banner|300x250|www.example.com", Location: LocationRequest},
+		},
+		{
+			description:   "Case Insensitive Match",
+			rules:         Rules{Data: data, Location: LocationRequest},
+			fields:        Fields{MediaType: "Banner", Size: "300x250", Domain: "WWW.Example.com"},
+			expectedFloor: Floor{Value: 5, Currency: "USD", Rule: "banner|300x250|www.example.com", Location: LocationRequest},
+		},
+		{
+			description:   "Wildcard In Later Field Preferred",
+			rules:         Rules{Data: data, Location: LocationRequest},
+			fields:        Fields{MediaType: "banner", Size: "300x250", Domain: "other.com"},
+			expectedFloor: Floor{Value: 4, Currency: "USD", Rule: "banner|300x250|*", Location: LocationRequest},
+		},
+		{
+			description:   "Unknown Size Matches Wildcard",
+			rules:         Rules{Data: data, Location: LocationRequest},
+			fields:        Fields{MediaType: "banner", Domain: "www.example.com"},
+			expectedFloor: Floor{Value: 3, Currency: "USD", Rule: "banner|*|www.example.com", Location: LocationRequest},
+		},
+		{
+			description:   "Catch All Rule Converted",
+			rules:         Rules{Data: data, Location: LocationAccount},
+			fields:        Fields{MediaType: "video"},
+			targetCur:     "EUR",
+			expectedFloor: Floor{Value: 0.5, Currency: "EUR", Rule: "*|*|*", Location: LocationAccount},
+		},
+		{
+			description:   "Imp Floor Without Rule Set",
+			rules:         Rules{Location: LocationImp},
+			imp:           openrtb.Imp{BidFloor: 2, BidFloorCur: "EUR"},
+			expectedFloor: Floor{Value: 4, Currency: "USD", Location: LocationImp},
+		},
+		{
+			description:   "Floor Min Raises Floor",
+			rules:         Rules{Data: data, Location: LocationRequest, FloorMin: 4.5, floorMinLocation: LocationRequest},
+			fields:        Fields{MediaType: "banner", Size: "300x250"},
+			expectedFloor: Floor{Value: 4.5, Currency: "USD", Location: LocationRequest},
+		},
+		{
+			description: "Bidder Floor Min Raises Floor",
+			rules: Rules{
+				Data:            data,
+				Location:        LocationRequest,
+				Bidders:         map[string]*openrtb_ext.BidderFloor{"appnexus": {FloorMin: 10}},
+				bidderLocations: map[string]string{"appnexus": LocationAccount},
+			},
+			fields:        Fields{MediaType: "banner", Bidder: "appnexus"},
+			expectedFloor: Floor{Value: 10, Currency: "USD", Location: LocationAccount},
+		},
+		{
+			description:   "No Floor",
+			rules:         Rules{Location: LocationImp},
+			expectedFloor: Floor{Currency: "USD"},
+		},
+	}
+
+	for _, test := range testCases {
+		floor, err := test.rules.Lookup(&test.imp, test.fields, conversions, test.targetCur)
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expectedFloor, floor, test.description)
+	}
+}
+
+func TestLookupConversionError(t *testing.T) {
+	rules := Rules{Location: LocationImp}
+	imp := openrtb.Imp{BidFloor: 1, BidFloorCur: "EUR"}
+
+	_, err := rules.Lookup(&imp, Fields{}, currency.NewConstantRates(), "USD")
+
+	assert.Error(t, err)
+}
+
+func TestFieldsFromImp(t *testing.T) {
+	w, h := uint64(728), uint64(90)
+	request := &openrtb.BidRequest{Site: &openrtb.Site{Domain: "www.example.com"}}
+
+	testCases := []struct {
+		description    string
+		imp            openrtb.Imp
+		expectedFields Fields
+	}{
+		{
+			description:    "Banner Single Format",
+			imp:            openrtb.Imp{TagID: "slot", Banner: &openrtb.Banner{Format: []openrtb.Format{{W: 300, H: 250}}}},
+			expectedFields: Fields{MediaType: "banner", Size: "300x250", Domain: "www.example.com", AdUnitCode: "slot", Bidder: "appnexus"},
+		},
+		{
+			description:    "Banner Without Format",
+			imp:            openrtb.Imp{Banner: &openrtb.Banner{W: &w, H: &h}},
+			expectedFields: Fields{MediaType: "banner", Size: "728x90", Domain: "www.example.com", Bidder: "appnexus"},
+		},
+		{
+			description:    "Banner Multiple Formats",
+			imp:            openrtb.Imp{Banner: &openrtb.Banner{Format: []openrtb.Format{{W: 300, H: 250}, {W: 300, H: 600}}}},
+			expectedFields: Fields{MediaType: "banner", Domain: "www.example.com", Bidder: "appnexus"},
+		},
+		{
+			description:    "Multi Format",
+			imp:            openrtb.Imp{Banner: &openrtb.Banner{Format: []openrtb.Format{{W: 300, H: 250}}}, Video: &openrtb.Video{W: 640, H: 480}},
+			expectedFields: Fields{Domain: "www.example.com", Bidder: "appnexus"},
+		},
+		{
+			description:    "Video",
+			imp:            openrtb.Imp{Video: &openrtb.Video{W: 640, H: 480}},
+			expectedFields: Fields{MediaType: "video", Size: "640x480", Domain: "www.example.com", Bidder: "appnexus"},
+		},
+	}
+
+	for _, test := range testCases {
+		fields := FieldsFromImp(request, &test.imp, openrtb_ext.BidderAppnexus)
+		assert.Equal(t, test.expectedFields, fields, test.description)
+	}
+}
+
+func TestWildcardMasks(t *testing.T) {
+	assert.Equal(t, []uint{0, 1, 2, 4, 3, 5, 6, 7}, wildcardMasks(3))
+}
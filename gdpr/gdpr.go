@@ -20,10 +20,11 @@ type Permissions interface {
 	// If the consent string was nonsensical, the returned error will be an ErrorMalformedConsent.
 	BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, consent string) (bool, error)
 
-	// Determines whether or not to send PI information to a bidder, or mask it out.
+	// Determines whether or not to send PI information to a bidder, or mask it out. The account may override the
+	// enforcement of the TCF2 purposes set by the host, and may be nil.
 	//
 	// If the consent string was nonsensical, the returned error will be an ErrorMalformedConsent.
-	PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, consent string) (bool, bool, bool, error)
+	PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, account *config.AccountGDPR, consent string) (bool, bool, bool, error)
 }
 
 // Versions of the GDPR TCF technical specification.
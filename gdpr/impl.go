@@ -26,41 +26,26 @@ type permissionsImpl struct {
 }
 
 func (p *permissionsImpl) HostCookiesAllowed(ctx context.Context, consent string) (bool, error) {
-	return p.allowSync(ctx, uint16(p.cfg.HostVendorID), consent)
+	return p.allowSync(ctx, uint16(p.cfg.HostVendorID), "", consent)
 }
 
+// BidderSyncAllowed enforces the TCF2 purposes on the bidders which don't have a GVL ID, or aren't on the GVL, with
+// the basic enforcement, without the vendor signals, when gdpr.tcf2.non_gvl_vendors_basic_enforcement or the
+// basic_enforcement_vendors of the purpose allow it. They're denied otherwise, as they are by the TCF1 consent strings.
 func (p *permissionsImpl) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, consent string) (bool, error) {
-	id, ok := p.vendorIDs[bidder]
-	if ok {
-		return p.allowSync(ctx, id, consent)
-	}
-
-	if consent == "" {
-		return p.cfg.UsersyncIfAmbiguous, nil
-	}
-
-	return false, nil
+	return p.allowSync(ctx, p.vendorIDs[bidder], bidder, consent)
 }
 
-func (p *permissionsImpl) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, consent string) (bool, bool, bool, error) {
+func (p *permissionsImpl) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, account *config.AccountGDPR, consent string) (bool, bool, bool, error) {
 	_, ok := p.cfg.NonStandardPublisherMap[PublisherID]
 	if ok {
 		return true, true, true, nil
 	}
 
-	id, ok := p.vendorIDs[bidder]
-	if ok {
-		return p.allowPI(ctx, id, consent)
-	}
-
-	if consent == "" {
-		return p.cfg.UsersyncIfAmbiguous, p.cfg.UsersyncIfAmbiguous, p.cfg.UsersyncIfAmbiguous, nil
-	}
-
-	return false, false, false, nil
+	return p.allowPI(ctx, p.vendorIDs[bidder], bidder, account, consent)
 }
 
-func (p *permissionsImpl) allowSync(ctx context.Context, vendorID uint16, consent string) (bool, error) {
+func (p *permissionsImpl) allowSync(ctx context.Context, vendorID uint16, bidder openrtb_ext.BidderName, consent string) (bool, error) {
 	// If we're not given a consent string, respect the preferences in the app config.
 	if consent == "" {
 		return p.cfg.UsersyncIfAmbiguous, nil
@@ -71,22 +56,17 @@ func (p *permissionsImpl) allowSync(ctx context.Context, vendorID uint16, consen
 		return false, err
 	}
 
-	if vendor == nil {
-		return false, nil
-	}
-
 	// InfoStorageAccess is the same across TCF 1 and TCF 2
 	if parsedConsent.Version() == 2 {
-		if !p.cfg.TCF2.Purpose1.Enabled {
-			// We are not enforcing purpose 1
-			return true, nil
-		}
 		consent, ok := parsedConsent.(tcf2.ConsentMetadata)
 		if !ok {
 			err := fmt.Errorf("Unable to access TCF2 parsed consent")
 			return false, err
 		}
-		return p.checkPurpose(consent, vendor, vendorID, consentconstants.InfoStorageAccess), nil
+		return p.checkPurpose(consent, vendor, vendorID, bidder, consentconstants.InfoStorageAccess, p.cfg.TCF2.Purpose1), nil
+	}
+	if vendor == nil {
+		return false, nil
 	}
 	if vendor.Purpose(consentconstants.InfoStorageAccess) && parsedConsent.PurposeAllowed(consentconstants.InfoStorageAccess) && parsedConsent.VendorConsent(vendorID) {
 		return true, nil
@@ -94,7 +74,7 @@ func (p *permissionsImpl) allowSync(ctx context.Context, vendorID uint16, consen
 	return false, nil
 }
 
-func (p *permissionsImpl) allowPI(ctx context.Context, vendorID uint16, consent string) (bool, bool, bool, error) {
+func (p *permissionsImpl) allowPI(ctx context.Context, vendorID uint16, bidder openrtb_ext.BidderName, account *config.AccountGDPR, consent string) (bool, bool, bool, error) {
 	// If we're not given a consent string, respect the preferences in the app config.
 	if consent == "" {
 		return p.cfg.UsersyncIfAmbiguous, p.cfg.UsersyncIfAmbiguous, p.cfg.UsersyncIfAmbiguous, nil
@@ -105,14 +85,15 @@ func (p *permissionsImpl) allowPI(ctx context.Context, vendorID uint16, consent
 		return false, false, false, err
 	}
 
+	if parsedConsent.Version() == 2 && p.cfg.TCF2.Enabled {
+		return p.allowPITCF2(parsedConsent, vendor, vendorID, bidder, account)
+	}
+
 	if vendor == nil {
 		return false, false, false, nil
 	}
 
 	if parsedConsent.Version() == 2 {
-		if p.cfg.TCF2.Enabled {
-			return p.allowPITCF2(parsedConsent, vendor, vendorID)
-		}
 		if (vendor.Purpose(consentconstants.InfoStorageAccess) || vendor.LegitimateInterest(consentconstants.InfoStorageAccess)) && parsedConsent.PurposeAllowed(consentconstants.InfoStorageAccess) && (vendor.Purpose(consentconstants.PersonalizationProfile) || vendor.LegitimateInterest(consentconstants.PersonalizationProfile)) && parsedConsent.PurposeAllowed(consentconstants.PersonalizationProfile) && parsedConsent.VendorConsent(vendorID) {
 			return true, true, true, nil
 		}
@@ -124,7 +105,7 @@ func (p *permissionsImpl) allowPI(ctx context.Context, vendorID uint16, consent
 	return false, false, false, nil
 }
 
-func (p *permissionsImpl) allowPITCF2(parsedConsent api.VendorConsents, vendor api.Vendor, vendorID uint16) (allowPI bool, allowGeo bool, allowID bool, err error) {
+func (p *permissionsImpl) allowPITCF2(parsedConsent api.VendorConsents, vendor api.Vendor, vendorID uint16, bidder openrtb_ext.BidderName, account *config.AccountGDPR) (allowPI bool, allowGeo bool, allowID bool, err error) {
 	consent, ok := parsedConsent.(tcf2.ConsentMetadata)
 	err = nil
 	allowPI = false
@@ -134,55 +115,120 @@ func (p *permissionsImpl) allowPITCF2(parsedConsent api.VendorConsents, vendor a
 		err = fmt.Errorf("Unable to access TCF2 parsed consent")
 		return
 	}
-	if p.cfg.TCF2.SpecialPurpose1.Enabled {
-		allowGeo = consent.SpecialFeatureOptIn(1) && vendor.SpecialPurpose(1)
-	} else {
-		allowGeo = true
-	}
-	for i := 2; i <= 10; i++ {
-		if p.checkPurpose(consent, vendor, vendorID, tcf1constants.Purpose(i)) {
+	allowGeo = p.checkSpecialFeatureOne(consent, vendor, bidder)
+	for i := 2; i <= config.TCF2PurposeCount; i++ {
+		purpose := tcf1constants.Purpose(i)
+		if p.checkPurpose(consent, vendor, vendorID, bidder, purpose, legalBasisConfig(p.purposeConfig(purpose, account))) {
 			allowID = true
 			break
 		}
 	}
-	// Set to true so any purpose check can flip it to false
-	allowPI = true
-	if p.cfg.TCF2.Purpose1.Enabled {
-		allowPI = allowPI && p.checkPurpose(consent, vendor, vendorID, consentconstants.InfoStorageAccess)
+	allowPI = p.checkPurpose(consent, vendor, vendorID, bidder, consentconstants.InfoStorageAccess, p.purposeConfig(consentconstants.InfoStorageAccess, account)) &&
+		p.checkPurpose(consent, vendor, vendorID, bidder, consentconstants.BasicAdserving, p.purposeConfig(consentconstants.BasicAdserving, account)) &&
+		p.checkPurpose(consent, vendor, vendorID, bidder, consentconstants.AdPerformance, p.purposeConfig(consentconstants.AdPerformance, account))
+	return
+}
+
+// purposeConfig returns the enforcement of the purpose set by the host, with the overrides of the account if there's one.
+func (p *permissionsImpl) purposeConfig(purpose tcf1constants.Purpose, account *config.AccountGDPR) config.PurposeDetail {
+	if account == nil {
+		return p.cfg.TCF2.PurposeConfig(int(purpose))
+	}
+	return account.PurposeConfig(&p.cfg.TCF2, int(purpose))
+}
+
+// legalBasisConfig returns the enforcement of a purpose for the user IDs, which are only sent to the vendors with a
+// legal basis for one of the purposes 2 to 10. The purposes which aren't enabled, or aren't enforced, are fully
+// enforced here, as they're only relaxed for the other personal info.
+func legalBasisConfig(purposeConfig config.PurposeDetail) config.PurposeDetail {
+	if purposeConfig.Enforcement() == config.TCF2NoEnforcement {
+		purposeConfig.Enabled = true
+		purposeConfig.EnforcePurpose = config.TCF2FullEnforcement
+		purposeConfig.EnforceVendors = true
+	}
+	return purposeConfig
+}
+
+// checkSpecialFeatureOne determines whether the vendor may use the precise geolocation of the user.
+func (p *permissionsImpl) checkSpecialFeatureOne(consent tcf2.ConsentMetadata, vendor api.Vendor, bidder openrtb_ext.BidderName) bool {
+	featureConfig := p.cfg.TCF2.SpecialPurpose1
+	if vendor == nil && !p.nonGVLVendorAllowed(bidder, featureConfig) {
+		return false
 	}
-	if p.cfg.TCF2.Purpose2.Enabled {
-		allowPI = allowPI && p.checkPurpose(consent, vendor, vendorID, consentconstants.BasicAdserving)
+	enforcement := featureConfig.Enforcement()
+	if enforcement == config.TCF2NoEnforcement || featureConfig.IsVendorException(bidder) {
+		return true
 	}
-	if p.cfg.TCF2.Purpose7.Enabled {
-		allowPI = allowPI && p.checkPurpose(consent, vendor, vendorID, consentconstants.AdPerformance)
+	if enforcement == config.TCF2BasicEnforcement || vendor == nil || featureConfig.IsBasicEnforcementVendor(bidder) {
+		return consent.SpecialFeatureOptIn(1)
 	}
-	return
+	return consent.SpecialFeatureOptIn(1) && vendor.SpecialPurpose(1)
+}
+
+// nonGVLVendorAllowed returns whether the bidder, which isn't on the GVL, is given the basic enforcement of the purpose
+// rather than being denied. The host never is, so that its cookie is still denied without a gdpr.host_vendor_id.
+func (p *permissionsImpl) nonGVLVendorAllowed(bidder openrtb_ext.BidderName, purposeConfig config.PurposeDetail) bool {
+	if bidder == "" {
+		return false
+	}
+	return p.cfg.TCF2.NonGVLVendorsBasicEnforcement || purposeConfig.IsBasicEnforcementVendor(bidder)
 }
 
 const pubRestrictNotAllowed = 0
 const pubRestrictRequireConsent = 1
 const pubRestrictRequireLegitInterest = 2
 
-func (p *permissionsImpl) checkPurpose(consent tcf2.ConsentMetadata, vendor api.Vendor, vendorID uint16, purpose tcf1constants.Purpose) bool {
+// checkPurpose determines whether the vendor has a legal basis for the purpose, under the enforcement of the purpose.
+// The full enforcement only accepts the consent, or the legitimate interest, which the vendor declares in the GVL.
+func (p *permissionsImpl) checkPurpose(consent tcf2.ConsentMetadata, vendor api.Vendor, vendorID uint16, bidder openrtb_ext.BidderName, purpose tcf1constants.Purpose, purposeConfig config.PurposeDetail) bool {
+	if vendor == nil && !p.nonGVLVendorAllowed(bidder, purposeConfig) {
+		return false
+	}
+	enforcement := purposeConfig.Enforcement()
+	if enforcement == config.TCF2NoEnforcement || purposeConfig.IsVendorException(bidder) {
+		return true
+	}
 	if purpose == consentconstants.InfoStorageAccess && p.cfg.TCF2.PurposeOneTreatment.Enabled && consent.PurposeOneTreatment() {
 		return p.cfg.TCF2.PurposeOneTreatment.AccessAllowed
 	}
+
+	if vendor == nil || purposeConfig.IsBasicEnforcementVendor(bidder) {
+		return checkPurposeBasic(consent, vendorID, purpose, false)
+	}
+	if enforcement == config.TCF2BasicEnforcement {
+		return checkPurposeBasic(consent, vendorID, purpose, purposeConfig.EnforceVendors)
+	}
+
+	vendorConsent := !purposeConfig.EnforceVendors || consent.VendorConsent(vendorID)
+	vendorLegitInterest := !purposeConfig.EnforceVendors || consent.VendorLegitInterest(vendorID)
+
 	if consent.CheckPubRestriction(uint8(purpose), pubRestrictNotAllowed, vendorID) {
 		return false
 	}
 	if consent.CheckPubRestriction(uint8(purpose), pubRestrictRequireConsent, vendorID) {
-		return vendor.PurposeStrict(purpose) && consent.PurposeAllowed(purpose) && consent.VendorConsent(vendorID)
+		return vendor.PurposeStrict(purpose) && consent.PurposeAllowed(purpose) && vendorConsent
 	}
 	if consent.CheckPubRestriction(uint8(purpose), pubRestrictRequireLegitInterest, vendorID) {
 		// Need LITransparency here
-		return vendor.LegitimateInterestStrict(purpose) && consent.PurposeLITransparency(purpose) && consent.VendorLegitInterest(vendorID)
+		return vendor.LegitimateInterestStrict(purpose) && consent.PurposeLITransparency(purpose) && vendorLegitInterest
 	}
-	purposeAllowed := vendor.Purpose(purpose) && consent.PurposeAllowed(purpose) && consent.VendorConsent(vendorID)
-	legitInterest := vendor.LegitimateInterest(purpose) && consent.PurposeLITransparency(purpose) && consent.VendorLegitInterest(vendorID)
+	purposeAllowed := vendor.Purpose(purpose) && consent.PurposeAllowed(purpose) && vendorConsent
+	legitInterest := vendor.LegitimateInterest(purpose) && consent.PurposeLITransparency(purpose) && vendorLegitInterest
 
 	return purposeAllowed || legitInterest
 }
 
+// checkPurposeBasic determines whether the consent string gives a legal basis for the purpose, without the GVL.
+func checkPurposeBasic(consent tcf2.ConsentMetadata, vendorID uint16, purpose tcf1constants.Purpose, enforceVendors bool) bool {
+	purposeAllowed := consent.PurposeAllowed(purpose) && (!enforceVendors || consent.VendorConsent(vendorID))
+	// Storing and accessing information on the device is only allowed with the consent of the user.
+	if purpose == consentconstants.InfoStorageAccess {
+		return purposeAllowed
+	}
+	legitInterest := consent.PurposeLITransparency(purpose) && (!enforceVendors || consent.VendorLegitInterest(vendorID))
+	return purposeAllowed || legitInterest
+}
+
 func (p *permissionsImpl) parseVendor(ctx context.Context, vendorID uint16, consent string) (parsedConsent api.VendorConsents, vendor api.Vendor, err error) {
 	parsedConsent, err = vendorconsent.ParseString(consent)
 	if err != nil {
@@ -217,7 +263,7 @@ func (a AlwaysAllow) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.B
 	return true, nil
 }
 
-func (a AlwaysAllow) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, consent string) (bool, bool, bool, error) {
+func (a AlwaysAllow) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, account *config.AccountGDPR, consent string) (bool, bool, bool, error) {
 	return true, true, true, nil
 }
 
@@ -232,6 +278,6 @@ func (a AlwaysFail) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.Bi
 	return false, nil
 }
 
-func (a AlwaysFail) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, consent string) (bool, bool, bool, error) {
+func (a AlwaysFail) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, account *config.AccountGDPR, consent string) (bool, bool, bool, error) {
 	return false, false, false, nil
 }
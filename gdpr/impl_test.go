@@ -203,17 +203,17 @@ func TestAllowPersonalInfo(t *testing.T) {
 	}
 
 	// PI needs both purposes to succeed
-	allowPI, _, _, err := perms.PersonalInfoAllowed(context.Background(), openrtb_ext.BidderAppnexus, "", "BOS2bx5OS2bx5ABABBAAABoAAAABBwAA")
+	allowPI, _, _, err := perms.PersonalInfoAllowed(context.Background(), openrtb_ext.BidderAppnexus, "", nil, "BOS2bx5OS2bx5ABABBAAABoAAAABBwAA")
 	assertNilErr(t, err)
 	assertBoolsEqual(t, false, allowPI)
 
-	allowPI, _, _, err = perms.PersonalInfoAllowed(context.Background(), openrtb_ext.BidderPubmatic, "", "BOS2bx5OS2bx5ABABBAAABoAAAABBwAA")
+	allowPI, _, _, err = perms.PersonalInfoAllowed(context.Background(), openrtb_ext.BidderPubmatic, "", nil, "BOS2bx5OS2bx5ABABBAAABoAAAABBwAA")
 	assertNilErr(t, err)
 	assertBoolsEqual(t, true, allowPI)
 
 	// Assert that an item that otherwise would not be allowed PI access, gets approved because it is found in the GDPR.NonStandardPublishers array
 	perms.cfg.NonStandardPublisherMap = map[string]struct{}{"appNexusAppID": {}}
-	allowPI, _, _, err = perms.PersonalInfoAllowed(context.Background(), openrtb_ext.BidderAppnexus, "appNexusAppID", "BOS2bx5OS2bx5ABABBAAABoAAAABBwAA")
+	allowPI, _, _, err = perms.PersonalInfoAllowed(context.Background(), openrtb_ext.BidderAppnexus, "appNexusAppID", nil, "BOS2bx5OS2bx5ABABBAAABoAAAABBwAA")
 	assertNilErr(t, err)
 	assertBoolsEqual(t, true, allowPI)
 }
@@ -255,9 +255,16 @@ var tcf2Config = config.GDPR{
 	HostVendorID: 2,
 	TCF2: config.TCF2{
 		Enabled:         true,
-		Purpose1:        config.PurposeDetail{Enabled: true},
-		Purpose2:        config.PurposeDetail{Enabled: true},
-		Purpose7:        config.PurposeDetail{Enabled: true},
+		Purpose1:        config.PurposeDetail{Enabled: true, EnforceVendors: true},
+		Purpose2:        config.PurposeDetail{Enabled: true, EnforceVendors: true},
+		Purpose3:        config.PurposeDetail{Enabled: true, EnforceVendors: true},
+		Purpose4:        config.PurposeDetail{Enabled: true, EnforceVendors: true},
+		Purpose5:        config.PurposeDetail{Enabled: true, EnforceVendors: true},
+		Purpose6:        config.PurposeDetail{Enabled: true, EnforceVendors: true},
+		Purpose7:        config.PurposeDetail{Enabled: true, EnforceVendors: true},
+		Purpose8:        config.PurposeDetail{Enabled: true, EnforceVendors: true},
+		Purpose9:        config.PurposeDetail{Enabled: true, EnforceVendors: true},
+		Purpose10:       config.PurposeDetail{Enabled: true, EnforceVendors: true},
 		SpecialPurpose1: config.PurposeDetail{Enabled: true},
 	},
 }
@@ -318,7 +325,7 @@ func TestAllowPersonalInfoTCF2(t *testing.T) {
 	}
 
 	for _, td := range testDefs {
-		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), td.bidder, "", td.consent)
+		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), td.bidder, "", nil, td.consent)
 		assert.NoErrorf(t, err, "Error processing PersonalInfoAllowed for %s", td.description)
 		assert.EqualValuesf(t, td.allowPI, allowPI, "AllowPI failure on %s", td.description)
 		assert.EqualValuesf(t, td.allowGeo, allowGeo, "AllowGeo failure on %s", td.description)
@@ -344,7 +351,7 @@ func TestAllowPersonalInfoWhitelistTCF2(t *testing.T) {
 	}
 	// Assert that an item that otherwise would not be allowed PI access, gets approved because it is found in the GDPR.NonStandardPublishers array
 	perms.cfg.NonStandardPublisherMap = map[string]struct{}{"appNexusAppID": {}}
-	allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), openrtb_ext.BidderAppnexus, "appNexusAppID", "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA")
+	allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), openrtb_ext.BidderAppnexus, "appNexusAppID", nil, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA")
 	assert.NoErrorf(t, err, "Error processing PersonalInfoAllowed")
 	assert.EqualValuesf(t, true, allowPI, "AllowPI failure")
 	assert.EqualValuesf(t, true, allowGeo, "AllowGeo failure")
@@ -398,7 +405,7 @@ func TestAllowPersonalInfoTCF2PubRestrict(t *testing.T) {
 	}
 
 	for _, td := range testDefs {
-		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), td.bidder, "", td.consent)
+		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), td.bidder, "", nil, td.consent)
 		assert.NoErrorf(t, err, "Error processing PersonalInfoAllowed for %s", td.description)
 		assert.EqualValuesf(t, td.allowPI, allowPI, "AllowPI failure on %s", td.description)
 		assert.EqualValuesf(t, td.allowGeo, allowGeo, "AllowGeo failure on %s", td.description)
@@ -454,7 +461,7 @@ func TestAllowPersonalInfoTCF2PurposeOneTrue(t *testing.T) {
 	}
 
 	for _, td := range testDefs {
-		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), td.bidder, "", td.consent)
+		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), td.bidder, "", nil, td.consent)
 		assert.NoErrorf(t, err, "Error processing PersonalInfoAllowed for %s", td.description)
 		assert.EqualValuesf(t, td.allowPI, allowPI, "AllowPI failure on %s", td.description)
 		assert.EqualValuesf(t, td.allowGeo, allowGeo, "AllowGeo failure on %s", td.description)
@@ -511,7 +518,7 @@ func TestAllowPersonalInfoTCF2PurposeOneFalse(t *testing.T) {
 	}
 
 	for _, td := range testDefs {
-		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), td.bidder, "", td.consent)
+		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), td.bidder, "", nil, td.consent)
 		assert.NoErrorf(t, err, "Error processing PersonalInfoAllowed for %s", td.description)
 		assert.EqualValuesf(t, td.allowPI, allowPI, "AllowPI failure on %s", td.description)
 		assert.EqualValuesf(t, td.allowGeo, allowGeo, "AllowGeo failure on %s", td.description)
@@ -519,6 +526,397 @@ func TestAllowPersonalInfoTCF2PurposeOneFalse(t *testing.T) {
 	}
 }
 
+// newTCF2Config enables TCF2 with the same config for each purpose.
+func newTCF2Config(purposeConfig config.PurposeDetail, specialPurposeConfig config.PurposeDetail) config.TCF2 {
+	return config.TCF2{
+		Enabled:         true,
+		Purpose1:        purposeConfig,
+		Purpose2:        purposeConfig,
+		Purpose3:        purposeConfig,
+		Purpose4:        purposeConfig,
+		Purpose5:        purposeConfig,
+		Purpose6:        purposeConfig,
+		Purpose7:        purposeConfig,
+		Purpose8:        purposeConfig,
+		Purpose9:        purposeConfig,
+		Purpose10:       purposeConfig,
+		SpecialPurpose1: specialPurposeConfig,
+	}
+}
+
+func TestAllowPersonalInfoTCF2Enforcement(t *testing.T) {
+	fullEnforcement := config.PurposeDetail{Enabled: true, EnforcePurpose: config.TCF2FullEnforcement, EnforceVendors: true}
+	basicEnforcement := config.PurposeDetail{Enabled: true, EnforcePurpose: config.TCF2BasicEnforcement, EnforceVendors: true}
+	noEnforcement := config.PurposeDetail{Enabled: true, EnforcePurpose: config.TCF2NoEnforcement}
+	noVendorEnforcement := false
+
+	testCases := []struct {
+		description      string
+		bidder           openrtb_ext.BidderName
+		tcf2             config.TCF2
+		account          *config.AccountGDPR
+		expectedAllowPI  bool
+		expectedAllowGeo bool
+		expectedAllowID  bool
+	}{
+		{
+			description: "Full Enforcement - Purposes Not Declared In The GVL",
+			bidder:      openrtb_ext.BidderAppnexus,
+			tcf2:        newTCF2Config(fullEnforcement, fullEnforcement),
+		},
+		{
+			description:      "Basic Enforcement - Purposes Not Declared In The GVL",
+			bidder:           openrtb_ext.BidderAppnexus,
+			tcf2:             newTCF2Config(basicEnforcement, basicEnforcement),
+			expectedAllowPI:  true,
+			expectedAllowGeo: true,
+			expectedAllowID:  true,
+		},
+		{
+			description:      "No Enforcement",
+			bidder:           openrtb_ext.BidderAppnexus,
+			tcf2:             newTCF2Config(noEnforcement, noEnforcement),
+			expectedAllowPI:  true,
+			expectedAllowGeo: true,
+			// The user IDs still need a legal basis for one of the purposes 2 to 10, which the vendor doesn't declare.
+			expectedAllowID: false,
+		},
+		{
+			description: "Purpose 2 Disabled - Purposes Not Declared In The GVL",
+			bidder:      openrtb_ext.BidderAppnexus,
+			tcf2: func() config.TCF2 {
+				tcf2 := newTCF2Config(fullEnforcement, fullEnforcement)
+				tcf2.Purpose2 = config.PurposeDetail{Enabled: false}
+				return tcf2
+			}(),
+			expectedAllowID: false,
+		},
+		{
+			description: "Purpose 2 Disabled - Vendor Exception",
+			bidder:      openrtb_ext.BidderAppnexus,
+			tcf2: func() config.TCF2 {
+				tcf2 := newTCF2Config(fullEnforcement, fullEnforcement)
+				tcf2.Purpose2 = config.PurposeDetail{Enabled: false, VendorExceptions: []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}}
+				return tcf2
+			}(),
+			expectedAllowID: true,
+		},
+		{
+			description: "Basic Enforcement - Vendor Without Consent",
+			bidder:      openrtb_ext.BidderOpenx,
+			tcf2:        newTCF2Config(basicEnforcement, basicEnforcement),
+			// The special feature opt in doesn't depend on the vendor.
+			expectedAllowGeo: true,
+		},
+		{
+			description: "Basic Enforcement - Vendor Without Consent Not Enforced",
+			bidder:      openrtb_ext.BidderOpenx,
+			tcf2: newTCF2Config(
+				config.PurposeDetail{Enabled: true, EnforcePurpose: config.TCF2BasicEnforcement, EnforceVendors: false},
+				basicEnforcement),
+			expectedAllowPI:  true,
+			expectedAllowGeo: true,
+			expectedAllowID:  true,
+		},
+		{
+			description: "Full Enforcement - Vendor Without Consent Not Enforced",
+			bidder:      openrtb_ext.BidderOpenx,
+			tcf2: newTCF2Config(
+				config.PurposeDetail{Enabled: true, EnforcePurpose: config.TCF2FullEnforcement, EnforceVendors: false},
+				fullEnforcement),
+			// Purpose 1 isn't declared in the GVL by the vendor.
+			expectedAllowPI:  false,
+			expectedAllowGeo: true,
+			expectedAllowID:  true,
+		},
+		{
+			description: "Full Enforcement - Vendor Exceptions",
+			bidder:      openrtb_ext.BidderAppnexus,
+			tcf2: newTCF2Config(
+				config.PurposeDetail{Enabled: true, EnforcePurpose: config.TCF2FullEnforcement, EnforceVendors: true, VendorExceptions: []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}},
+				config.PurposeDetail{Enabled: true, EnforcePurpose: config.TCF2FullEnforcement, VendorExceptions: []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}}),
+			expectedAllowPI:  true,
+			expectedAllowGeo: true,
+			expectedAllowID:  true,
+		},
+		{
+			description: "Full Enforcement - Other Vendor Exceptions",
+			bidder:      openrtb_ext.BidderAppnexus,
+			tcf2: newTCF2Config(
+				config.PurposeDetail{Enabled: true, EnforcePurpose: config.TCF2FullEnforcement, EnforceVendors: true, VendorExceptions: []openrtb_ext.BidderName{openrtb_ext.BidderRubicon}},
+				fullEnforcement),
+		},
+		{
+			description: "Account Overrides Purposes 2 and 7 With Basic Enforcement",
+			bidder:      openrtb_ext.BidderAppnexus,
+			tcf2:        newTCF2Config(fullEnforcement, fullEnforcement),
+			account: &config.AccountGDPR{
+				Purpose2: config.AccountGDPRPurpose{EnforcePurpose: config.TCF2BasicEnforcement},
+				Purpose7: config.AccountGDPRPurpose{EnforcePurpose: config.TCF2BasicEnforcement},
+			},
+			expectedAllowPI: true,
+			expectedAllowID: true,
+		},
+		{
+			description: "Account Overrides Purpose 1 With Vendor Exceptions",
+			bidder:      openrtb_ext.BidderOpenx,
+			tcf2:        newTCF2Config(fullEnforcement, fullEnforcement),
+			account: &config.AccountGDPR{
+				Purpose1: config.AccountGDPRPurpose{VendorExceptions: []openrtb_ext.BidderName{openrtb_ext.BidderOpenx}},
+				Purpose2: config.AccountGDPRPurpose{EnforceVendors: &noVendorEnforcement},
+				Purpose7: config.AccountGDPRPurpose{EnforceVendors: &noVendorEnforcement},
+			},
+			expectedAllowPI:  true,
+			expectedAllowGeo: true,
+			expectedAllowID:  true,
+		},
+		{
+			description: "Account Overrides Host Basic Enforcement With Full Enforcement",
+			bidder:      openrtb_ext.BidderAppnexus,
+			tcf2:        newTCF2Config(basicEnforcement, basicEnforcement),
+			account: &config.AccountGDPR{
+				Purpose1: config.AccountGDPRPurpose{EnforcePurpose: config.TCF2FullEnforcement},
+				Purpose2: config.AccountGDPRPurpose{EnforcePurpose: config.TCF2FullEnforcement},
+			},
+			expectedAllowPI:  false,
+			expectedAllowGeo: true,
+			expectedAllowID:  true,
+		},
+	}
+
+	// COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA : TCF2 with full consents to purposes and vendors 2, 6, 8
+	vendorListData := tcf2MarshalVendorList(buildTCF2VendorList34())
+	for _, test := range testCases {
+		perms := permissionsImpl{
+			cfg: config.GDPR{HostVendorID: 2, TCF2: test.tcf2},
+			vendorIDs: map[openrtb_ext.BidderName]uint16{
+				openrtb_ext.BidderAppnexus: 2,
+				openrtb_ext.BidderOpenx:    10,
+			},
+			fetchVendorList: map[uint8]func(ctx context.Context, id uint16) (vendorlist.VendorList, error){
+				tcf1SpecVersion: nil,
+				tcf2SpecVersion: listFetcher(map[uint16]vendorlist.VendorList{
+					34: parseVendorListDataV2(t, vendorListData),
+				}),
+			},
+		}
+
+		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), test.bidder, "", test.account, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA")
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expectedAllowPI, allowPI, "%s: allowPI", test.description)
+		assert.Equal(t, test.expectedAllowGeo, allowGeo, "%s: allowGeo", test.description)
+		assert.Equal(t, test.expectedAllowID, allowID, "%s: allowID", test.description)
+	}
+}
+
+func TestAllowSyncTCF2Enforcement(t *testing.T) {
+	testCases := []struct {
+		description       string
+		bidder            openrtb_ext.BidderName
+		purpose1          config.PurposeDetail
+		expectedAllowSync bool
+	}{
+		{
+			description:       "Full Enforcement - Purpose 1 Not Declared In The GVL",
+			bidder:            openrtb_ext.BidderOpenx,
+			purpose1:          config.PurposeDetail{Enabled: true, EnforcePurpose: config.TCF2FullEnforcement, EnforceVendors: true},
+			expectedAllowSync: false,
+		},
+		{
+			description:       "Basic Enforcement - Vendor Without Consent",
+			bidder:            openrtb_ext.BidderOpenx,
+			purpose1:          config.PurposeDetail{Enabled: true, EnforcePurpose: config.TCF2BasicEnforcement, EnforceVendors: true},
+			expectedAllowSync: false,
+		},
+		{
+			description:       "Basic Enforcement - Vendor Without Consent Not Enforced",
+			bidder:            openrtb_ext.BidderOpenx,
+			purpose1:          config.PurposeDetail{Enabled: true, EnforcePurpose: config.TCF2BasicEnforcement, EnforceVendors: false},
+			expectedAllowSync: true,
+		},
+		{
+			description:       "Vendor Exception",
+			bidder:            openrtb_ext.BidderOpenx,
+			purpose1:          config.PurposeDetail{Enabled: true, EnforcePurpose: config.TCF2FullEnforcement, EnforceVendors: true, VendorExceptions: []openrtb_ext.BidderName{openrtb_ext.BidderOpenx}},
+			expectedAllowSync: true,
+		},
+		{
+			description:       "Disabled",
+			bidder:            openrtb_ext.BidderOpenx,
+			purpose1:          config.PurposeDetail{Enabled: false, EnforcePurpose: config.TCF2FullEnforcement, EnforceVendors: true},
+			expectedAllowSync: true,
+		},
+	}
+
+	// COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA : TCF2 with full consents to purposes and vendors 2, 6, 8
+	vendorListData := tcf2MarshalVendorList(buildTCF2VendorList34())
+	for _, test := range testCases {
+		perms := permissionsImpl{
+			cfg: config.GDPR{HostVendorID: 2, TCF2: config.TCF2{Enabled: true, Purpose1: test.purpose1}},
+			vendorIDs: map[openrtb_ext.BidderName]uint16{
+				openrtb_ext.BidderOpenx: 10,
+			},
+			fetchVendorList: map[uint8]func(ctx context.Context, id uint16) (vendorlist.VendorList, error){
+				tcf1SpecVersion: nil,
+				tcf2SpecVersion: listFetcher(map[uint16]vendorlist.VendorList{
+					34: parseVendorListDataV2(t, vendorListData),
+				}),
+			},
+		}
+
+		allowSync, err := perms.BidderSyncAllowed(context.Background(), test.bidder, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA")
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expectedAllowSync, allowSync, test.description)
+	}
+}
+
+func TestBasicEnforcementVendors(t *testing.T) {
+	nonGVLVendorsBasicEnforcement := func(tcf2 config.TCF2) config.TCF2 {
+		tcf2.NonGVLVendorsBasicEnforcement = true
+		return tcf2
+	}
+	basicEnforcementVendors := func(bidder openrtb_ext.BidderName) config.TCF2 {
+		return newTCF2Config(
+			config.PurposeDetail{Enabled: true, EnforceVendors: true, BasicEnforcementVendors: []openrtb_ext.BidderName{bidder}},
+			config.PurposeDetail{Enabled: true, BasicEnforcementVendors: []openrtb_ext.BidderName{bidder}})
+	}
+
+	testCases := []struct {
+		description       string
+		bidder            openrtb_ext.BidderName
+		consent           string
+		tcf2              config.TCF2
+		expectedAllowSync bool
+		expectedAllowPI   bool
+		expectedAllowGeo  bool
+		expectedAllowID   bool
+	}{
+		{
+			description:       "No GVL ID",
+			bidder:            openrtb_ext.BidderRubicon,
+			consent:           "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA",
+			tcf2:              nonGVLVendorsBasicEnforcement(tcf2Config.TCF2),
+			expectedAllowSync: true,
+			expectedAllowPI:   true,
+			expectedAllowGeo:  true,
+			expectedAllowID:   true,
+		},
+		{
+			description:       "Zero GVL ID",
+			bidder:            openrtb_ext.BidderAdform,
+			consent:           "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA",
+			tcf2:              nonGVLVendorsBasicEnforcement(tcf2Config.TCF2),
+			expectedAllowSync: true,
+			expectedAllowPI:   true,
+			expectedAllowGeo:  true,
+			expectedAllowID:   true,
+		},
+		{
+			description:       "Not On The GVL",
+			bidder:            openrtb_ext.BidderSovrn,
+			consent:           "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA",
+			tcf2:              nonGVLVendorsBasicEnforcement(tcf2Config.TCF2),
+			expectedAllowSync: true,
+			expectedAllowPI:   true,
+			expectedAllowGeo:  true,
+			expectedAllowID:   true,
+		},
+		{
+			description: "No GVL ID - Non GVL Vendors Not Allowed",
+			bidder:      openrtb_ext.BidderRubicon,
+			consent:     "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA",
+			tcf2:        tcf2Config.TCF2,
+		},
+		{
+			description: "Not On The GVL - Non GVL Vendors Not Allowed",
+			bidder:      openrtb_ext.BidderSovrn,
+			consent:     "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA",
+			tcf2:        tcf2Config.TCF2,
+		},
+		{
+			description:       "Not On The GVL - Basic Enforcement Vendor",
+			bidder:            openrtb_ext.BidderSovrn,
+			consent:           "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA",
+			tcf2:              basicEnforcementVendors(openrtb_ext.BidderSovrn),
+			expectedAllowSync: true,
+			expectedAllowPI:   true,
+			expectedAllowGeo:  true,
+			expectedAllowID:   true,
+		},
+		{
+			description: "On The GVL Without Vendor Consent",
+			bidder:      openrtb_ext.BidderOpenx,
+			consent:     "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA",
+			tcf2:        tcf2Config.TCF2,
+			// The vendor declares the special purpose on the GVL, and the consent string opts in to the special feature.
+			expectedAllowGeo: true,
+		},
+		{
+			description:       "On The GVL Without Vendor Consent - Basic Enforcement Vendor",
+			bidder:            openrtb_ext.BidderOpenx,
+			consent:           "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA",
+			tcf2:              basicEnforcementVendors(openrtb_ext.BidderOpenx),
+			expectedAllowSync: true,
+			expectedAllowPI:   true,
+			expectedAllowGeo:  true,
+			expectedAllowID:   true,
+		},
+		{
+			description: "No GVL ID - Purposes Not Enforced",
+			bidder:      openrtb_ext.BidderRubicon,
+			consent:     "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA",
+			tcf2: nonGVLVendorsBasicEnforcement(newTCF2Config(
+				config.PurposeDetail{Enabled: true, EnforcePurpose: config.TCF2NoEnforcement},
+				config.PurposeDetail{Enabled: false})),
+			expectedAllowSync: true,
+			expectedAllowPI:   true,
+			expectedAllowGeo:  true,
+			expectedAllowID:   true,
+		},
+		{
+			description: "No GVL ID - TCF1",
+			bidder:      openrtb_ext.BidderRubicon,
+			consent:     "BOS2bx5OS2bx5ABABBAAABoAAAABBwAA",
+			tcf2:        nonGVLVendorsBasicEnforcement(tcf2Config.TCF2),
+		},
+	}
+
+	// COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA : TCF2 with full consents to purposes and vendors 2, 6, 8
+	tcf1VendorListData := tcf1MarshalVendorList(tcf1VendorList{
+		VendorListVersion: 1,
+		Vendors:           []tcf1Vendor{{ID: 2, Purposes: []int{1}}},
+	})
+	tcf2VendorListData := tcf2MarshalVendorList(buildTCF2VendorList34())
+	for _, test := range testCases {
+		perms := permissionsImpl{
+			cfg: config.GDPR{HostVendorID: 2, TCF2: test.tcf2},
+			vendorIDs: map[openrtb_ext.BidderName]uint16{
+				openrtb_ext.BidderAdform: 0,
+				openrtb_ext.BidderOpenx:  10,
+				openrtb_ext.BidderSovrn:  99,
+			},
+			fetchVendorList: map[uint8]func(ctx context.Context, id uint16) (vendorlist.VendorList, error){
+				tcf1SpecVersion: listFetcher(map[uint16]vendorlist.VendorList{
+					1: parseVendorListData(t, tcf1VendorListData),
+				}),
+				tcf2SpecVersion: listFetcher(map[uint16]vendorlist.VendorList{
+					34: parseVendorListDataV2(t, tcf2VendorListData),
+				}),
+			},
+		}
+
+		allowSync, err := perms.BidderSyncAllowed(context.Background(), test.bidder, test.consent)
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expectedAllowSync, allowSync, "%s: allowSync", test.description)
+
+		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), test.bidder, "", nil, test.consent)
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expectedAllowPI, allowPI, "%s: allowPI", test.description)
+		assert.Equal(t, test.expectedAllowGeo, allowGeo, "%s: allowGeo", test.description)
+		assert.Equal(t, test.expectedAllowID, allowID, "%s: allowID", test.description)
+	}
+}
+
 func TestAllowSyncTCF2(t *testing.T) {
 	vendorListData := tcf2MarshalVendorList(buildTCF2VendorList34())
 	perms := permissionsImpl{
@@ -546,6 +944,26 @@ func TestAllowSyncTCF2(t *testing.T) {
 	assert.EqualValuesf(t, true, allowSync, "BidderSyncAllowed failure")
 }
 
+func TestHostCookiesWithoutHostVendorIDTCF2(t *testing.T) {
+	vendorListData := tcf2MarshalVendorList(buildTCF2VendorList34())
+	perms := permissionsImpl{
+		cfg: tcf2Config,
+		fetchVendorList: map[uint8]func(ctx context.Context, id uint16) (vendorlist.VendorList, error){
+			tcf1SpecVersion: nil,
+			tcf2SpecVersion: listFetcher(map[uint16]vendorlist.VendorList{
+				34: parseVendorListDataV2(t, vendorListData),
+			}),
+		},
+	}
+	perms.cfg.HostVendorID = 0
+	perms.cfg.TCF2.NonGVLVendorsBasicEnforcement = true
+
+	// COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA : TCF2 with full consents to purposes and vendors 2, 6, 8
+	allowSync, err := perms.HostCookiesAllowed(context.Background(), "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA")
+	assert.NoErrorf(t, err, "Error processing HostCookiesAllowed")
+	assert.EqualValuesf(t, false, allowSync, "HostCookiesAllowed failure")
+}
+
 func TestProhibitedPurposeSyncTCF2(t *testing.T) {
 	tcf2VendorList34 := buildTCF2VendorList34()
 	tcf2VendorList34.Vendors["8"].Purposes = []int{7}
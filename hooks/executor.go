@@ -0,0 +1,414 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// HookStatus is the outcome of running a hook
+type HookStatus string
+
+const (
+	HookStatusSuccess          HookStatus = "success"
+	HookStatusRejected         HookStatus = "rejected"
+	HookStatusTimeout          HookStatus = "timeout"
+	HookStatusExecutionFailure HookStatus = "execution_failure"
+)
+
+// HookOutcome reports how a hook ran at a stage of the request
+type HookOutcome struct {
+	Stage Stage
+	// Bidder is set at the stages which run for each bidder.
+	Bidder        openrtb_ext.BidderName
+	ID            config.HookID
+	Status        HookStatus
+	ExecutionTime time.Duration
+	Message       string
+	Errors        []string
+	Warnings      []string
+}
+
+// RejectError is returned when a hook rejects the request, or the bidder at the stages which run for each bidder.
+type RejectError struct {
+	NBR        int
+	Stage      Stage
+	ModuleCode string
+	Message    string
+}
+
+func (err *RejectError) Error() string {
+	return fmt.Sprintf("Module %s rejected the %s stage: %s", err.ModuleCode, err.Stage, err.Message)
+}
+
+// Executor runs the hooks of a single request. It is safe to use from the goroutines of each bidder.
+//
+// A nil Executor runs no hooks, and returns its payloads unchanged.
+type Executor struct {
+	planBuilder *PlanBuilder
+	endpoint    string
+
+	mutex          sync.Mutex
+	account        *config.Account
+	moduleContexts map[string]ModuleContext
+	outcomes       []HookOutcome
+}
+
+// SetAccount sets the account of the request once it is known. Until then, only the host execution plan runs.
+func (e *Executor) SetAccount(account *config.Account) {
+	if e == nil {
+		return
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.account = account
+}
+
+// ExecuteEntrypointStage runs the entrypoint hooks on the HTTP request and its body, and returns the body to process.
+func (e *Executor) ExecuteEntrypointStage(r *http.Request, body []byte) ([]byte, *RejectError) {
+	payload := &EntrypointPayload{Request: r, Body: body}
+	reject := e.executeStage(r.Context(), StageEntrypoint, "", func() (interface{}, error) {
+		return EntrypointPayload{Request: payload.Request.Clone(payload.Request.Context()), Body: copyBytes(payload.Body)}, nil
+	}, payload, invokeEntrypoint)
+	return payload.Body, reject
+}
+
+// ExecuteRawAuctionRequestStage runs the raw auction request hooks on the request body, and returns the body to parse.
+func (e *Executor) ExecuteRawAuctionRequestStage(ctx context.Context, body []byte) ([]byte, *RejectError) {
+	payload := &RawAuctionRequestPayload{Body: body}
+	reject := e.executeStage(ctx, StageRawAuctionRequest, "", func() (interface{}, error) {
+		return RawAuctionRequestPayload{Body: copyBytes(payload.Body)}, nil
+	}, payload, invokeRawAuctionRequest)
+	return payload.Body, reject
+}
+
+// ExecuteProcessedAuctionRequestStage runs the processed auction request hooks, and returns the request to run the auction for.
+func (e *Executor) ExecuteProcessedAuctionRequestStage(ctx context.Context, request *openrtb.BidRequest) (*openrtb.BidRequest, *RejectError) {
+	payload := &ProcessedAuctionRequestPayload{BidRequest: request}
+	reject := e.executeStage(ctx, StageProcessedAuctionRequest, "", func() (interface{}, error) {
+		request, err := copyBidRequest(payload.BidRequest)
+		return ProcessedAuctionRequestPayload{BidRequest: request}, err
+	}, payload, invokeProcessedAuctionRequest)
+	return payload.BidRequest, reject
+}
+
+// ExecuteBidderRequestStage runs the bidder request hooks, and returns the request to send to the bidder.
+// The bidder must not be called if the stage is rejected.
+func (e *Executor) ExecuteBidderRequestStage(ctx context.Context, bidder openrtb_ext.BidderName, request *openrtb.BidRequest) (*openrtb.BidRequest, *RejectError) {
+	payload := &BidderRequestPayload{Bidder: bidder, BidRequest: request}
+	reject := e.executeStage(ctx, StageBidderRequest, bidder, func() (interface{}, error) {
+		request, err := copyBidRequest(payload.BidRequest)
+		return BidderRequestPayload{Bidder: payload.Bidder, BidRequest: request}, err
+	}, payload, invokeBidderRequest)
+	return payload.BidRequest, reject
+}
+
+// ExecuteRawBidderResponseStage runs the raw bidder response hooks on the bids of a bidder, and returns the bids to keep.
+// All the bids of the bidder must be dropped if the stage is rejected.
+func (e *Executor) ExecuteRawBidderResponseStage(ctx context.Context, bidder openrtb_ext.BidderName, bids []*Bid) ([]*Bid, *RejectError) {
+	payload := &RawBidderResponsePayload{Bidder: bidder, Bids: bids}
+	reject := e.executeStage(ctx, StageRawBidderResponse, bidder, func() (interface{}, error) {
+		bids, err := copyBids(payload.Bids)
+		return RawBidderResponsePayload{Bidder: payload.Bidder, Bids: bids}, err
+	}, payload, invokeRawBidderResponse)
+	return payload.Bids, reject
+}
+
+// ExecuteAllProcessedBidResponsesStage runs the all processed bid responses hooks, and returns the bids to run the auction with.
+func (e *Executor) ExecuteAllProcessedBidResponsesStage(ctx context.Context, bids map[openrtb_ext.BidderName][]*Bid) map[openrtb_ext.BidderName][]*Bid {
+	payload := &AllProcessedBidResponsesPayload{Bids: bids}
+	e.executeStage(ctx, StageAllProcessedBidResponses, "", func() (interface{}, error) {
+		bids := make(map[openrtb_ext.BidderName][]*Bid, len(payload.Bids))
+		for bidder, bidderBids := range payload.Bids {
+			copied, err := copyBids(bidderBids)
+			if err != nil {
+				return nil, err
+			}
+			bids[bidder] = copied
+		}
+		return AllProcessedBidResponsesPayload{Bids: bids}, nil
+	}, payload, invokeAllProcessedBidResponses)
+	return payload.Bids
+}
+
+// ExecuteAuctionResponseStage runs the auction response hooks, and returns the response to send.
+func (e *Executor) ExecuteAuctionResponseStage(ctx context.Context, response *openrtb.BidResponse) *openrtb.BidResponse {
+	payload := &AuctionResponsePayload{BidResponse: response}
+	e.executeStage(ctx, StageAuctionResponse, "", func() (interface{}, error) {
+		var response *openrtb.BidResponse
+		if payload.BidResponse != nil {
+			response = &openrtb.BidResponse{}
+			if err := copyJSON(payload.BidResponse, response); err != nil {
+				return nil, err
+			}
+		}
+		return AuctionResponsePayload{BidResponse: response}, nil
+	}, payload, invokeAuctionResponse)
+	return payload.BidResponse
+}
+
+// Outcomes returns the outcomes of the hooks which ran so far, in the order they were applied.
+func (e *Executor) Outcomes() []HookOutcome {
+	if e == nil {
+		return nil
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	outcomes := make([]HookOutcome, len(e.outcomes))
+	copy(outcomes, e.outcomes)
+	return outcomes
+}
+
+// ExtModules returns the errors and warnings of the hooks which ran so far, for bidresponse.ext.prebid.modules.
+// It returns nil if there aren't any.
+func (e *Executor) ExtModules() *openrtb_ext.ExtModules {
+	var ext *openrtb_ext.ExtModules
+	for _, outcome := range e.Outcomes() {
+		if len(outcome.Errors) == 0 && len(outcome.Warnings) == 0 {
+			continue
+		}
+		if ext == nil {
+			ext = &openrtb_ext.ExtModules{}
+		}
+		ext.Errors = addModuleMessages(ext.Errors, outcome.ID, outcome.Errors)
+		ext.Warnings = addModuleMessages(ext.Warnings, outcome.ID, outcome.Warnings)
+	}
+	return ext
+}
+
+func addModuleMessages(messages map[string]map[string][]string, id config.HookID, newMessages []string) map[string]map[string][]string {
+	if len(newMessages) == 0 {
+		return messages
+	}
+	if messages == nil {
+		messages = make(map[string]map[string][]string)
+	}
+	if messages[id.ModuleCode] == nil {
+		messages[id.ModuleCode] = make(map[string][]string)
+	}
+	messages[id.ModuleCode][id.HookImplCode] = append(messages[id.ModuleCode][id.HookImplCode], newMessages...)
+	return messages
+}
+
+type hookInvoker func(ctx context.Context, module interface{}, invocation InvocationContext, payload interface{}) (HookResult, error)
+
+type hookResponse struct {
+	index         int
+	received      bool
+	result        HookResult
+	err           error
+	executionTime time.Duration
+}
+
+// payloadCopier returns a deep copy of the payload of a stage, which a single hook may read without racing with
+// the mutations applied to the payload, even after the hook timed out.
+type payloadCopier func() (interface{}, error)
+
+// executeStage runs the groups of hooks planned for the stage one after another. Each hook of a group is given its own
+// copy of the payload, and the mutations they return are applied to the payload once the whole group has run.
+func (e *Executor) executeStage(ctx context.Context, stage Stage, bidder openrtb_ext.BidderName, copyPayload payloadCopier, payload interface{}, invoke hookInvoker) *RejectError {
+	if e == nil {
+		return nil
+	}
+
+	groups, failures := e.planBuilder.plan(e.endpoint, stage, e.getAccount())
+	for _, failure := range failures {
+		failure.Bidder = bidder
+		e.addOutcome(failure)
+	}
+
+	for _, group := range groups {
+		responses := e.runGroup(ctx, group, copyPayload, invoke)
+
+		for i, response := range responses {
+			id := group.hooks[i].id
+			outcome := HookOutcome{Stage: stage, Bidder: bidder, ID: id, ExecutionTime: response.executionTime}
+
+			switch {
+			case !response.received:
+				outcome.Status = HookStatusTimeout
+				outcome.Errors = []string{fmt.Sprintf("hook did not return within %s", group.timeout)}
+			case response.err != nil:
+				outcome.Status = HookStatusExecutionFailure
+				outcome.Errors = []string{response.err.Error()}
+			case response.result.Reject && stage.canReject():
+				outcome.Status = HookStatusRejected
+				outcome.Message = response.result.Message
+				outcome.Errors = response.result.Errors
+				outcome.Warnings = response.result.Warnings
+				e.addOutcome(outcome)
+				return &RejectError{NBR: response.result.NbrCode, Stage: stage, ModuleCode: id.ModuleCode, Message: response.result.Message}
+			default:
+				outcome.Status = HookStatusSuccess
+				outcome.Message = response.result.Message
+				outcome.Errors = response.result.Errors
+				outcome.Warnings = response.result.Warnings
+				if response.result.Reject {
+					outcome.Warnings = append(outcome.Warnings, fmt.Sprintf("rejection is not supported at the %s stage", stage))
+				}
+				for _, mutation := range response.result.Mutations {
+					if err := mutation(payload); err != nil {
+						outcome.Errors = append(outcome.Errors, fmt.Sprintf("failed to apply mutation: %v", err))
+					}
+				}
+				e.mergeModuleContext(id.ModuleCode, response.result.ModuleContext)
+			}
+			e.addOutcome(outcome)
+		}
+	}
+	return nil
+}
+
+// runGroup runs the hooks of the group in parallel, and returns their responses in the order of the group.
+// The hooks which haven't returned once the group has timed out are left unreceived, and their results are ignored.
+// They may keep running on their copy of the payload.
+func (e *Executor) runGroup(ctx context.Context, group hookGroup, copyPayload payloadCopier, invoke hookInvoker) []hookResponse {
+	groupCtx, cancel := context.WithTimeout(ctx, group.timeout)
+	defer cancel()
+
+	responses := make([]hookResponse, len(group.hooks))
+	done := make(chan hookResponse, len(group.hooks))
+	for i, hook := range group.hooks {
+		payload, err := copyPayload()
+		if err != nil {
+			done <- hookResponse{index: i, received: true, err: fmt.Errorf("failed to copy the payload: %v", err)}
+			continue
+		}
+		go func(index int, hook hookEntry, invocation InvocationContext, payload interface{}) {
+			start := time.Now()
+			defer func() {
+				if r := recover(); r != nil {
+					done <- hookResponse{index: index, received: true, err: fmt.Errorf("hook panicked: %v", r), executionTime: time.Since(start)}
+				}
+			}()
+			result, err := invoke(groupCtx, hook.module, invocation, payload)
+			done <- hookResponse{index: index, received: true, result: result, err: err, executionTime: time.Since(start)}
+		}(i, hook, e.invocationContext(hook.id.ModuleCode), payload)
+	}
+
+	for received := 0; received < len(group.hooks); received++ {
+		select {
+		case response := <-done:
+			responses[response.index] = response
+		case <-groupCtx.Done():
+			return responses
+		}
+	}
+	return responses
+}
+
+func copyBytes(value []byte) []byte {
+	if value == nil {
+		return nil
+	}
+	return append([]byte{}, value...)
+}
+
+func copyBidRequest(request *openrtb.BidRequest) (*openrtb.BidRequest, error) {
+	if request == nil {
+		return nil, nil
+	}
+	copied := &openrtb.BidRequest{}
+	return copied, copyJSON(request, copied)
+}
+
+func copyBids(bids []*Bid) ([]*Bid, error) {
+	if bids == nil {
+		return nil, nil
+	}
+	copied := make([]*Bid, 0, len(bids))
+	for _, bid := range bids {
+		copiedBid := &Bid{BidType: bid.BidType}
+		if bid.Bid != nil {
+			copiedBid.Bid = &openrtb.Bid{}
+			if err := copyJSON(bid.Bid, copiedBid.Bid); err != nil {
+				return nil, err
+			}
+		}
+		copied = append(copied, copiedBid)
+	}
+	return copied, nil
+}
+
+// copyJSON deep copies an OpenRTB object through its JSON form.
+func copyJSON(value interface{}, copied interface{}) error {
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(valueJSON, copied)
+}
+
+func (e *Executor) invocationContext(moduleCode string) InvocationContext {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	invocation := InvocationContext{
+		Endpoint:      e.endpoint,
+		ModuleContext: make(ModuleContext, len(e.moduleContexts[moduleCode])),
+	}
+	for key, value := range e.moduleContexts[moduleCode] {
+		invocation.ModuleContext[key] = value
+	}
+	if e.account != nil {
+		invocation.AccountID = e.account.ID
+		invocation.AccountConfig = accountModuleConfig(e.account, moduleCode)
+	}
+	return invocation
+}
+
+// accountModuleConfig returns the configuration of the "{vendor}.{module}" module in the account as JSON.
+func accountModuleConfig(account *config.Account, moduleCode string) json.RawMessage {
+	vendor, module := moduleCode, ""
+	if i := strings.Index(moduleCode, "."); i >= 0 {
+		vendor, module = moduleCode[:i], moduleCode[i+1:]
+	}
+	moduleConfig, ok := account.Hooks.Modules[vendor][module]
+	if !ok {
+		return nil
+	}
+	configJSON, err := json.Marshal(moduleConfig)
+	if err != nil {
+		return nil
+	}
+	return configJSON
+}
+
+func (e *Executor) mergeModuleContext(moduleCode string, moduleContext ModuleContext) {
+	if len(moduleContext) == 0 {
+		return
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.moduleContexts[moduleCode] == nil {
+		e.moduleContexts[moduleCode] = make(ModuleContext, len(moduleContext))
+	}
+	for key, value := range moduleContext {
+		e.moduleContexts[moduleCode][key] = value
+	}
+}
+
+func (e *Executor) getAccount() *config.Account {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.account
+}
+
+func (e *Executor) addOutcome(outcome HookOutcome) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.outcomes = append(e.outcomes, outcome)
+}
@@ -0,0 +1,214 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+// bidderRequestModule runs its handler at StageBidderRequest, and records the invocations it was given.
+type bidderRequestModule struct {
+	handle func(ctx context.Context, invocation InvocationContext, payload BidderRequestPayload) (HookResult, error)
+
+	mutex       sync.Mutex
+	invocations []InvocationContext
+}
+
+func (m *bidderRequestModule) HandleBidderRequestHook(ctx context.Context, invocation InvocationContext, payload BidderRequestPayload) (HookResult, error) {
+	m.mutex.Lock()
+	m.invocations = append(m.invocations, invocation)
+	m.mutex.Unlock()
+	return m.handle(ctx, invocation, payload)
+}
+
+type auctionResponseModule struct {
+	result HookResult
+}
+
+func (m auctionResponseModule) HandleAuctionResponseHook(ctx context.Context, invocation InvocationContext, payload AuctionResponsePayload) (HookResult, error) {
+	return m.result, nil
+}
+
+func setTMax(tmax int64) Mutation {
+	return func(payload interface{}) error {
+		bidderPayload := payload.(*BidderRequestPayload)
+		request := *bidderPayload.BidRequest
+		request.TMax = tmax
+		bidderPayload.BidRequest = &request
+		return nil
+	}
+}
+
+func newTestExecutor(t *testing.T, repository HookRepository, hostPlan config.HookExecutionPlan) *Executor {
+	builder, err := NewPlanBuilder(config.Hooks{Enabled: true, HostExecutionPlan: hostPlan}, repository)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return builder.NewExecutor(EndpointAuction)
+}
+
+func TestExecuteBidderRequestStage(t *testing.T) {
+	testCases := []struct {
+		description      string
+		first            func(ctx context.Context, invocation InvocationContext, payload BidderRequestPayload) (HookResult, error)
+		second           func(ctx context.Context, invocation InvocationContext, payload BidderRequestPayload) (HookResult, error)
+		expectedTMax     int64
+		expectedReject   *RejectError
+		expectedStatuses []HookStatus
+	}{
+		{
+			description: "Mutations are applied in the order of the sequence",
+			first: func(ctx context.Context, invocation InvocationContext, payload BidderRequestPayload) (HookResult, error) {
+				return HookResult{Mutations: []Mutation{setTMax(100)}}, nil
+			},
+			second: func(ctx context.Context, invocation InvocationContext, payload BidderRequestPayload) (HookResult, error) {
+				return HookResult{Mutations: []Mutation{setTMax(200)}}, nil
+			},
+			expectedTMax:     200,
+			expectedStatuses: []HookStatus{HookStatusSuccess, HookStatusSuccess},
+		},
+		{
+			description: "Reject stops the stage",
+			first: func(ctx context.Context, invocation InvocationContext, payload BidderRequestPayload) (HookResult, error) {
+				return HookResult{Reject: true, NbrCode: 123, Message: "blocked", Mutations: []Mutation{setTMax(100)}}, nil
+			},
+			second: func(ctx context.Context, invocation InvocationContext, payload BidderRequestPayload) (HookResult, error) {
+				return HookResult{Mutations: []Mutation{setTMax(200)}}, nil
+			},
+			expectedTMax:     50,
+			expectedReject:   &RejectError{NBR: 123, Stage: StageBidderRequest, ModuleCode: "vendor.first", Message: "blocked"},
+			expectedStatuses: []HookStatus{HookStatusRejected},
+		},
+		{
+			description: "Failed and timed out hooks are ignored",
+			first: func(ctx context.Context, invocation InvocationContext, payload BidderRequestPayload) (HookResult, error) {
+				return HookResult{Mutations: []Mutation{setTMax(100)}}, errors.New("failed")
+			},
+			second: func(ctx context.Context, invocation InvocationContext, payload BidderRequestPayload) (HookResult, error) {
+				<-ctx.Done()
+				time.Sleep(10 * time.Millisecond)
+				return HookResult{Mutations: []Mutation{setTMax(200)}}, nil
+			},
+			expectedTMax:     50,
+			expectedStatuses: []HookStatus{HookStatusExecutionFailure, HookStatusTimeout},
+		},
+		{
+			description: "Panics are recovered",
+			first: func(ctx context.Context, invocation InvocationContext, payload BidderRequestPayload) (HookResult, error) {
+				panic("oops")
+			},
+			second: func(ctx context.Context, invocation InvocationContext, payload BidderRequestPayload) (HookResult, error) {
+				return HookResult{Mutations: []Mutation{setTMax(200)}}, nil
+			},
+			expectedTMax:     200,
+			expectedStatuses: []HookStatus{HookStatusExecutionFailure, HookStatusSuccess},
+		},
+	}
+
+	for _, test := range testCases {
+		repository := HookRepository{
+			"vendor.first":  &bidderRequestModule{handle: test.first},
+			"vendor.second": &bidderRequestModule{handle: test.second},
+		}
+		executor := newTestExecutor(t, repository, planWith(StageBidderRequest, groupOf(20, "vendor.first", "vendor.second")))
+		request := &openrtb.BidRequest{ID: "some-request-id", TMax: 50}
+
+		newRequest, reject := executor.ExecuteBidderRequestStage(context.Background(), openrtb_ext.BidderAppnexus, request)
+
+		assert.Equal(t, test.expectedTMax, newRequest.TMax, test.description)
+		assert.Equal(t, int64(50), request.TMax, test.description+": the original request must not change")
+		assert.Equal(t, test.expectedReject, reject, test.description)
+		statuses := []HookStatus{}
+		for _, outcome := range executor.Outcomes() {
+			assert.Equal(t, openrtb_ext.BidderAppnexus, outcome.Bidder, test.description)
+			statuses = append(statuses, outcome.Status)
+		}
+		assert.Equal(t, test.expectedStatuses, statuses, test.description)
+	}
+}
+
+func TestExecuteBidderRequestStageTimedOutHookPayload(t *testing.T) {
+	readAfterTimeout := make(chan string, 1)
+	repository := HookRepository{
+		"vendor.first": &bidderRequestModule{handle: func(ctx context.Context, invocation InvocationContext, payload BidderRequestPayload) (HookResult, error) {
+			return HookResult{Mutations: []Mutation{func(payload interface{}) error {
+				// The mutation changes the request in place, while the timed out hook still reads its payload.
+				payload.(*BidderRequestPayload).BidRequest.Imp[0].BidFloor = 2
+				return nil
+			}}}, nil
+		}},
+		"vendor.second": &bidderRequestModule{handle: func(ctx context.Context, invocation InvocationContext, payload BidderRequestPayload) (HookResult, error) {
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond)
+			readAfterTimeout <- fmt.Sprintf("%.1f", payload.BidRequest.Imp[0].BidFloor)
+			return HookResult{}, nil
+		}},
+	}
+	executor := newTestExecutor(t, repository, planWith(StageBidderRequest, groupOf(20, "vendor.first", "vendor.second")))
+	request := &openrtb.BidRequest{ID: "some-request-id", Imp: []openrtb.Imp{{ID: "some-imp", BidFloor: 1}}}
+
+	newRequest, _ := executor.ExecuteBidderRequestStage(context.Background(), openrtb_ext.BidderAppnexus, request)
+
+	assert.Equal(t, 2.0, newRequest.Imp[0].BidFloor)
+	assert.Equal(t, "1.0", <-readAfterTimeout, "The timed out hook should keep reading its own copy of the payload")
+}
+
+func TestExecutorModuleContextAndAccountConfig(t *testing.T) {
+	module := &bidderRequestModule{
+		handle: func(ctx context.Context, invocation InvocationContext, payload BidderRequestPayload) (HookResult, error) {
+			return HookResult{ModuleContext: ModuleContext{string(payload.Bidder): true}}, nil
+		},
+	}
+	executor := newTestExecutor(t, HookRepository{"vendor.module": module}, planWith(StageBidderRequest, groupOf(20, "vendor.module")))
+	executor.SetAccount(&config.Account{
+		ID: "some-account",
+		Hooks: config.AccountHooks{
+			Modules: map[string]map[string]interface{}{"vendor": {"module": map[string]interface{}{"enabled": true}}},
+		},
+	})
+
+	executor.ExecuteBidderRequestStage(context.Background(), openrtb_ext.BidderAppnexus, &openrtb.BidRequest{})
+	executor.ExecuteBidderRequestStage(context.Background(), openrtb_ext.BidderRubicon, &openrtb.BidRequest{})
+
+	if assert.Len(t, module.invocations, 2) {
+		assert.Equal(t, InvocationContext{
+			Endpoint:      EndpointAuction,
+			AccountID:     "some-account",
+			AccountConfig: json.RawMessage(`{"enabled":true}`),
+			ModuleContext: ModuleContext{},
+		}, module.invocations[0])
+		assert.Equal(t, ModuleContext{"appnexus": true}, module.invocations[1].ModuleContext, "The module context of the previous invocation should be passed")
+	}
+}
+
+func TestExecuteAuctionResponseStage(t *testing.T) {
+	repository := HookRepository{
+		"vendor.module": auctionResponseModule{result: HookResult{
+			Reject:   true,
+			Errors:   []string{"some error"},
+			Warnings: []string{"some warning"},
+			Mutations: []Mutation{func(payload interface{}) error {
+				payload.(*AuctionResponsePayload).BidResponse = &openrtb.BidResponse{ID: "new-response"}
+				return nil
+			}},
+		}},
+	}
+	executor := newTestExecutor(t, repository, planWith(StageAuctionResponse, groupOf(20, "vendor.module")))
+
+	response := executor.ExecuteAuctionResponseStage(context.Background(), &openrtb.BidResponse{ID: "some-response"})
+
+	assert.Equal(t, "new-response", response.ID, "The stage can't reject, so the mutations should still be applied")
+	assert.Equal(t, &openrtb_ext.ExtModules{
+		Errors:   map[string]map[string][]string{"vendor.module": {"vendor.module-hook": {"some error"}}},
+		Warnings: map[string]map[string][]string{"vendor.module": {"vendor.module-hook": {"some warning", "rejection is not supported at the auction_response stage"}}},
+	}, executor.ExtModules())
+}
@@ -0,0 +1,87 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/buger/jsonparser"
+	"github.com/golang/glog"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mxmCherry/openrtb"
+)
+
+// The endpoints which run the hooks, as named in the execution plans
+const (
+	EndpointAuction = "/openrtb2/auction"
+	EndpointAmp     = "/openrtb2/amp"
+)
+
+type contextKey string
+
+const executorContextKey = contextKey("hookExecutor")
+
+// ContextWithExecutor returns a copy of the context which carries the executor of the request.
+func ContextWithExecutor(ctx context.Context, executor *Executor) context.Context {
+	return context.WithValue(ctx, executorContextKey, executor)
+}
+
+// ExecutorFromContext returns the executor of the request, or nil if hooks don't run for it.
+func ExecutorFromContext(ctx context.Context) *Executor {
+	executor, _ := ctx.Value(executorContextKey).(*Executor)
+	return executor
+}
+
+// WrapHandler runs the entrypoint and raw auction request hooks before the endpoint handles the request.
+// The account isn't known yet at these stages, so only the hooks of the host execution plan run.
+//
+// The endpoint reads the body the hooks returned, and finds the executor of the request with ExecutorFromContext.
+// A nil builder returns the handler unchanged.
+func WrapHandler(builder *PlanBuilder, endpoint string, maxRequestSize int64, next httprouter.Handle) httprouter.Handle {
+	if builder == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		executor := builder.NewExecutor(endpoint)
+
+		var body []byte
+		if r.Body != nil {
+			var err error
+			// Read one more byte than allowed, so that the endpoint still rejects the requests which are too large.
+			if body, err = ioutil.ReadAll(io.LimitReader(r.Body, maxRequestSize+1)); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(err.Error()))
+				return
+			}
+		}
+
+		body, rejectErr := executor.ExecuteEntrypointStage(r, body)
+		if rejectErr == nil && len(body) > 0 {
+			body, rejectErr = executor.ExecuteRawAuctionRequestStage(r.Context(), body)
+		}
+		if rejectErr != nil {
+			writeRejectedResponse(w, body, rejectErr)
+			return
+		}
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		next(w, r.WithContext(ContextWithExecutor(r.Context(), executor)), p)
+	}
+}
+
+func writeRejectedResponse(w http.ResponseWriter, body []byte, rejectErr *RejectError) {
+	id, _ := jsonparser.GetString(body, "id")
+	response := openrtb.BidResponse{
+		ID:  id,
+		NBR: openrtb.NoBidReasonCode(rejectErr.NBR).Ptr(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		glog.Errorf("Failed to write the response to a request rejected by module %s: %v", rejectErr.ModuleCode, err)
+	}
+}
@@ -0,0 +1,93 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type rawAuctionRequestModule struct {
+	result HookResult
+}
+
+func (m rawAuctionRequestModule) HandleRawAuctionRequestHook(ctx context.Context, invocation InvocationContext, payload RawAuctionRequestPayload) (HookResult, error) {
+	return m.result, nil
+}
+
+func replaceBody(body string) Mutation {
+	return func(payload interface{}) error {
+		payload.(*RawAuctionRequestPayload).Body = []byte(body)
+		return nil
+	}
+}
+
+func TestWrapHandler(t *testing.T) {
+	testCases := []struct {
+		description    string
+		result         HookResult
+		expectedCalled bool
+		expectedBody   string
+		expectedNBR    *openrtb.NoBidReasonCode
+	}{
+		{
+			description:    "Endpoint reads the mutated body",
+			result:         HookResult{Mutations: []Mutation{replaceBody(`{"id":"new-id"}`)}},
+			expectedCalled: true,
+			expectedBody:   `{"id":"new-id"}`,
+		},
+		{
+			description:    "Rejected request",
+			result:         HookResult{Reject: true, NbrCode: 2},
+			expectedCalled: false,
+			expectedNBR:    openrtb.NoBidReasonCode(2).Ptr(),
+		},
+	}
+
+	for _, test := range testCases {
+		builder, _ := NewPlanBuilder(config.Hooks{Enabled: true, HostExecutionPlan: planWith(StageRawAuctionRequest, groupOf(20, "vendor.module"))},
+			HookRepository{"vendor.module": rawAuctionRequestModule{result: test.result}})
+
+		called := false
+		handler := WrapHandler(builder, EndpointAuction, 1000, func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+			called = true
+			assert.NotNil(t, ExecutorFromContext(r.Context()), test.description)
+			body, _ := ioutil.ReadAll(r.Body)
+			w.Write(body)
+		})
+
+		recorder := httptest.NewRecorder()
+		handler(recorder, httptest.NewRequest("POST", EndpointAuction, strings.NewReader(`{"id":"some-id"}`)), nil)
+
+		assert.Equal(t, test.expectedCalled, called, test.description)
+		if test.expectedNBR == nil {
+			assert.Equal(t, test.expectedBody, recorder.Body.String(), test.description)
+		} else {
+			var response openrtb.BidResponse
+			if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response), test.description) {
+				assert.Equal(t, "some-id", response.ID, test.description)
+				assert.Equal(t, test.expectedNBR, response.NBR, test.description)
+			}
+		}
+	}
+}
+
+func TestWrapHandlerDisabled(t *testing.T) {
+	called := false
+	handler := WrapHandler(nil, EndpointAuction, 1000, func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		called = true
+		assert.Nil(t, ExecutorFromContext(r.Context()))
+	})
+
+	handler(httptest.NewRecorder(), httptest.NewRequest("POST", EndpointAuction, strings.NewReader(`{}`)), nil)
+
+	assert.True(t, called)
+}
@@ -0,0 +1,164 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// Stage is a point of the auction where the hooks of the modules run
+type Stage string
+
+const (
+	// StageEntrypoint runs as soon as an HTTP request reaches an endpoint. Its hooks may reject the request.
+	StageEntrypoint Stage = "entrypoint"
+	// StageRawAuctionRequest runs on the body of the request before it is parsed. Its hooks may reject the request.
+	StageRawAuctionRequest Stage = "raw_auction_request"
+	// StageProcessedAuctionRequest runs on the validated request before the auction starts. Its hooks may reject the request.
+	StageProcessedAuctionRequest Stage = "processed_auction_request"
+	// StageBidderRequest runs on the request of each bidder before it is called. Its hooks may skip the bidder.
+	StageBidderRequest Stage = "bidder_request"
+	// StageRawBidderResponse runs on the bids of each bidder as soon as they are returned. Its hooks may drop all the bids of the bidder.
+	StageRawBidderResponse Stage = "raw_bidder_response"
+	// StageAllProcessedBidResponses runs on the bids of all the bidders before the auction picks the winners.
+	StageAllProcessedBidResponses Stage = "all_processed_bid_responses"
+	// StageAuctionResponse runs on the response before it is returned.
+	StageAuctionResponse Stage = "auction_response"
+)
+
+// Stages returns all the stages in the order they run
+func Stages() []Stage {
+	return []Stage{
+		StageEntrypoint,
+		StageRawAuctionRequest,
+		StageProcessedAuctionRequest,
+		StageBidderRequest,
+		StageRawBidderResponse,
+		StageAllProcessedBidResponses,
+		StageAuctionResponse,
+	}
+}
+
+// canReject returns true if the hooks of the stage may reject the request, or skip a bidder.
+func (s Stage) canReject() bool {
+	return s != StageAllProcessedBidResponses && s != StageAuctionResponse
+}
+
+// A module is any value implementing at least one of the hook interfaces below. Its hooks run in parallel with
+// the other hooks of their group, each on its own copy of the payload, so the changes they make to it are lost.
+// They return the changes they want to make as Mutations instead, which are applied once the whole group has run.
+
+// EntrypointHook is implemented by the modules which run at StageEntrypoint
+type EntrypointHook interface {
+	HandleEntrypointHook(ctx context.Context, invocation InvocationContext, payload EntrypointPayload) (HookResult, error)
+}
+
+// RawAuctionRequestHook is implemented by the modules which run at StageRawAuctionRequest
+type RawAuctionRequestHook interface {
+	HandleRawAuctionRequestHook(ctx context.Context, invocation InvocationContext, payload RawAuctionRequestPayload) (HookResult, error)
+}
+
+// ProcessedAuctionRequestHook is implemented by the modules which run at StageProcessedAuctionRequest
+type ProcessedAuctionRequestHook interface {
+	HandleProcessedAuctionRequestHook(ctx context.Context, invocation InvocationContext, payload ProcessedAuctionRequestPayload) (HookResult, error)
+}
+
+// BidderRequestHook is implemented by the modules which run at StageBidderRequest
+type BidderRequestHook interface {
+	HandleBidderRequestHook(ctx context.Context, invocation InvocationContext, payload BidderRequestPayload) (HookResult, error)
+}
+
+// RawBidderResponseHook is implemented by the modules which run at StageRawBidderResponse
+type RawBidderResponseHook interface {
+	HandleRawBidderResponseHook(ctx context.Context, invocation InvocationContext, payload RawBidderResponsePayload) (HookResult, error)
+}
+
+// AllProcessedBidResponsesHook is implemented by the modules which run at StageAllProcessedBidResponses
+type AllProcessedBidResponsesHook interface {
+	HandleAllProcessedBidResponsesHook(ctx context.Context, invocation InvocationContext, payload AllProcessedBidResponsesPayload) (HookResult, error)
+}
+
+// AuctionResponseHook is implemented by the modules which run at StageAuctionResponse
+type AuctionResponseHook interface {
+	HandleAuctionResponseHook(ctx context.Context, invocation InvocationContext, payload AuctionResponsePayload) (HookResult, error)
+}
+
+// EntrypointPayload is the payload of StageEntrypoint
+type EntrypointPayload struct {
+	Request *http.Request
+	Body    []byte
+}
+
+// RawAuctionRequestPayload is the payload of StageRawAuctionRequest
+type RawAuctionRequestPayload struct {
+	Body []byte
+}
+
+// ProcessedAuctionRequestPayload is the payload of StageProcessedAuctionRequest
+type ProcessedAuctionRequestPayload struct {
+	BidRequest *openrtb.BidRequest
+}
+
+// BidderRequestPayload is the payload of StageBidderRequest
+type BidderRequestPayload struct {
+	Bidder     openrtb_ext.BidderName
+	BidRequest *openrtb.BidRequest
+}
+
+// Bid is a bid returned by a bidder, along with its type
+type Bid struct {
+	Bid     *openrtb.Bid
+	BidType openrtb_ext.BidType
+}
+
+// RawBidderResponsePayload is the payload of StageRawBidderResponse
+type RawBidderResponsePayload struct {
+	Bidder openrtb_ext.BidderName
+	Bids   []*Bid
+}
+
+// AllProcessedBidResponsesPayload is the payload of StageAllProcessedBidResponses
+type AllProcessedBidResponsesPayload struct {
+	Bids map[openrtb_ext.BidderName][]*Bid
+}
+
+// AuctionResponsePayload is the payload of StageAuctionResponse
+type AuctionResponsePayload struct {
+	BidResponse *openrtb.BidResponse
+}
+
+// InvocationContext is what a hook knows about the request it runs for
+type InvocationContext struct {
+	Endpoint  string
+	AccountID string
+	// AccountConfig is the account configuration of the module, or nil if the account doesn't configure it.
+	AccountConfig json.RawMessage
+	// ModuleContext holds the data the hooks of the module returned at the previous stages of the same request.
+	ModuleContext ModuleContext
+}
+
+// ModuleContext is the data a module keeps from one stage to the next within a request
+type ModuleContext map[string]interface{}
+
+// Mutation changes the payload of the stage the hook ran at. It receives a pointer to the payload,
+// for instance a *BidderRequestPayload at StageBidderRequest.
+type Mutation func(payload interface{}) error
+
+// HookResult is what a hook returns
+type HookResult struct {
+	// Reject stops the request, or skips the bidder at StageBidderRequest and drops its bids at StageRawBidderResponse.
+	// It is ignored at the stages which can't reject.
+	Reject bool
+	// NbrCode is the OpenRTB no-bid reason returned when the request is rejected.
+	NbrCode int
+	// Message explains the rejection.
+	Message  string
+	Errors   []string
+	Warnings []string
+	// ModuleContext is merged into the context of the module for the next stages.
+	ModuleContext ModuleContext
+	Mutations     []Mutation
+}
@@ -0,0 +1,197 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+)
+
+// HookRepository holds the modules built at startup, keyed by module code
+type HookRepository map[string]interface{}
+
+// NewHookRepository makes sure that every module implements at least one hook.
+func NewHookRepository(modules map[string]interface{}) (HookRepository, error) {
+	for code, module := range modules {
+		implemented := false
+		for _, stage := range Stages() {
+			if implementsStage(module, stage) {
+				implemented = true
+				break
+			}
+		}
+		if !implemented {
+			return nil, fmt.Errorf("module %s does not implement any hook", code)
+		}
+	}
+	return HookRepository(modules), nil
+}
+
+func implementsStage(module interface{}, stage Stage) bool {
+	var ok bool
+	switch stage {
+	case StageEntrypoint:
+		_, ok = module.(EntrypointHook)
+	case StageRawAuctionRequest:
+		_, ok = module.(RawAuctionRequestHook)
+	case StageProcessedAuctionRequest:
+		_, ok = module.(ProcessedAuctionRequestHook)
+	case StageBidderRequest:
+		_, ok = module.(BidderRequestHook)
+	case StageRawBidderResponse:
+		_, ok = module.(RawBidderResponseHook)
+	case StageAllProcessedBidResponses:
+		_, ok = module.(AllProcessedBidResponsesHook)
+	case StageAuctionResponse:
+		_, ok = module.(AuctionResponseHook)
+	}
+	return ok
+}
+
+// PlanBuilder resolves the hooks to run at each stage of a request from the host and account execution plans.
+type PlanBuilder struct {
+	repository HookRepository
+	hostPlan   config.HookExecutionPlan
+}
+
+// NewPlanBuilder returns nil if hooks are disabled. Otherwise the host execution plan must only reference
+// the stages of this package, and the modules of the repository which implement them.
+func NewPlanBuilder(cfg config.Hooks, repository HookRepository) (*PlanBuilder, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	builder := &PlanBuilder{
+		repository: repository,
+		hostPlan:   cfg.HostExecutionPlan,
+	}
+	for endpoint, endpointPlan := range cfg.HostExecutionPlan.Endpoints {
+		for stageName, stagePlan := range endpointPlan.Stages {
+			if !isStage(stageName) {
+				return nil, fmt.Errorf("hooks.host_execution_plan.endpoints.%s: unknown stage %s", endpoint, stageName)
+			}
+			for _, group := range stagePlan.Groups {
+				for _, id := range group.HookSequence {
+					if _, err := builder.resolve(Stage(stageName), id); err != nil {
+						return nil, fmt.Errorf("hooks.host_execution_plan.endpoints.%s.stages.%s: %v", endpoint, stageName, err)
+					}
+				}
+			}
+		}
+	}
+	return builder, nil
+}
+
+// NewExecutor returns the executor of the hooks of a request to the endpoint. A nil builder returns a nil executor, which runs no hooks.
+func (b *PlanBuilder) NewExecutor(endpoint string) *Executor {
+	if b == nil {
+		return nil
+	}
+	return &Executor{
+		planBuilder:    b,
+		endpoint:       endpoint,
+		moduleContexts: make(map[string]ModuleContext),
+	}
+}
+
+type hookEntry struct {
+	id     config.HookID
+	module interface{}
+}
+
+type hookGroup struct {
+	timeout time.Duration
+	hooks   []hookEntry
+}
+
+// plan returns the groups of hooks to run at a stage of the endpoint: the host groups first, and then the account ones.
+// The account hooks which can't be resolved are skipped, and reported as failed outcomes.
+func (b *PlanBuilder) plan(endpoint string, stage Stage, account *config.Account) ([]hookGroup, []HookOutcome) {
+	groups := b.groups(b.hostPlan, endpoint, stage, nil)
+	if account == nil {
+		return groups, nil
+	}
+
+	var failures []HookOutcome
+	groups = append(groups, b.groups(account.Hooks.ExecutionPlan, endpoint, stage, &failures)...)
+	return groups, failures
+}
+
+func (b *PlanBuilder) groups(plan config.HookExecutionPlan, endpoint string, stage Stage, failures *[]HookOutcome) []hookGroup {
+	stagePlan := plan.Endpoints[endpoint].Stages[string(stage)]
+
+	groups := make([]hookGroup, 0, len(stagePlan.Groups))
+	for _, group := range stagePlan.Groups {
+		resolved := hookGroup{
+			timeout: time.Duration(group.TimeoutMillis) * time.Millisecond,
+			hooks:   make([]hookEntry, 0, len(group.HookSequence)),
+		}
+		for _, id := range group.HookSequence {
+			entry, err := b.resolve(stage, id)
+			if err == nil && group.TimeoutMillis <= 0 {
+				err = fmt.Errorf("the timeout of the group must be positive. Got %d", group.TimeoutMillis)
+			}
+			if err != nil {
+				if failures != nil {
+					*failures = append(*failures, HookOutcome{Stage: stage, ID: id, Status: HookStatusExecutionFailure, Errors: []string{err.Error()}})
+				}
+				continue
+			}
+			resolved.hooks = append(resolved.hooks, entry)
+		}
+		if len(resolved.hooks) > 0 {
+			groups = append(groups, resolved)
+		}
+	}
+	return groups
+}
+
+func (b *PlanBuilder) resolve(stage Stage, id config.HookID) (hookEntry, error) {
+	module, ok := b.repository[id.ModuleCode]
+	if !ok {
+		return hookEntry{}, fmt.Errorf("unknown module %s", id.ModuleCode)
+	}
+	if !implementsStage(module, stage) {
+		return hookEntry{}, fmt.Errorf("module %s does not implement the %s stage", id.ModuleCode, stage)
+	}
+	return hookEntry{id: id, module: module}, nil
+}
+
+func isStage(name string) bool {
+	for _, stage := range Stages() {
+		if string(stage) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// The functions below call the hook of a module at each stage. They are only given modules which implement the stage.
+
+func invokeEntrypoint(ctx context.Context, module interface{}, invocation InvocationContext, payload interface{}) (HookResult, error) {
+	return module.(EntrypointHook).HandleEntrypointHook(ctx, invocation, payload.(EntrypointPayload))
+}
+
+func invokeRawAuctionRequest(ctx context.Context, module interface{}, invocation InvocationContext, payload interface{}) (HookResult, error) {
+	return module.(RawAuctionRequestHook).HandleRawAuctionRequestHook(ctx, invocation, payload.(RawAuctionRequestPayload))
+}
+
+func invokeProcessedAuctionRequest(ctx context.Context, module interface{}, invocation InvocationContext, payload interface{}) (HookResult, error) {
+	return module.(ProcessedAuctionRequestHook).HandleProcessedAuctionRequestHook(ctx, invocation, payload.(ProcessedAuctionRequestPayload))
+}
+
+func invokeBidderRequest(ctx context.Context, module interface{}, invocation InvocationContext, payload interface{}) (HookResult, error) {
+	return module.(BidderRequestHook).HandleBidderRequestHook(ctx, invocation, payload.(BidderRequestPayload))
+}
+
+func invokeRawBidderResponse(ctx context.Context, module interface{}, invocation InvocationContext, payload interface{}) (HookResult, error) {
+	return module.(RawBidderResponseHook).HandleRawBidderResponseHook(ctx, invocation, payload.(RawBidderResponsePayload))
+}
+
+func invokeAllProcessedBidResponses(ctx context.Context, module interface{}, invocation InvocationContext, payload interface{}) (HookResult, error) {
+	return module.(AllProcessedBidResponsesHook).HandleAllProcessedBidResponsesHook(ctx, invocation, payload.(AllProcessedBidResponsesPayload))
+}
+
+func invokeAuctionResponse(ctx context.Context, module interface{}, invocation InvocationContext, payload interface{}) (HookResult, error) {
+	return module.(AuctionResponseHook).HandleAuctionResponseHook(ctx, invocation, payload.(AuctionResponsePayload))
+}
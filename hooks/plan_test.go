@@ -0,0 +1,175 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type entrypointModule struct{}
+
+func (m entrypointModule) HandleEntrypointHook(ctx context.Context, invocation InvocationContext, payload EntrypointPayload) (HookResult, error) {
+	return HookResult{}, nil
+}
+
+func planWith(stage Stage, groups ...config.HookExecutionGroup) config.HookExecutionPlan {
+	return config.HookExecutionPlan{
+		Endpoints: map[string]config.EndpointExecutionPlan{
+			EndpointAuction: {
+				Stages: map[string]config.StageExecutionPlan{
+					string(stage): {Groups: groups},
+				},
+			},
+		},
+	}
+}
+
+func groupOf(timeoutMillis int, moduleCodes ...string) config.HookExecutionGroup {
+	group := config.HookExecutionGroup{TimeoutMillis: timeoutMillis}
+	for _, code := range moduleCodes {
+		group.HookSequence = append(group.HookSequence, config.HookID{ModuleCode: code, HookImplCode: code + "-hook"})
+	}
+	return group
+}
+
+func TestNewHookRepository(t *testing.T) {
+	testCases := []struct {
+		description   string
+		modules       map[string]interface{}
+		expectedError error
+	}{
+		{
+			description: "Every module implements a hook",
+			modules:     map[string]interface{}{"vendor.entrypoint": entrypointModule{}},
+		},
+		{
+			description:   "Module implementing no hook",
+			modules:       map[string]interface{}{"vendor.nothing": struct{}{}},
+			expectedError: errors.New("module vendor.nothing does not implement any hook"),
+		},
+	}
+
+	for _, test := range testCases {
+		_, err := NewHookRepository(test.modules)
+		assert.Equal(t, test.expectedError, err, test.description)
+	}
+}
+
+func TestNewPlanBuilder(t *testing.T) {
+	repository := HookRepository{"vendor.entrypoint": entrypointModule{}}
+
+	testCases := []struct {
+		description     string
+		cfg             config.Hooks
+		expectedBuilder bool
+		expectedError   error
+	}{
+		{
+			description: "Hooks disabled",
+			cfg:         config.Hooks{Enabled: false, HostExecutionPlan: planWith(StageEntrypoint, groupOf(10, "vendor.unknown"))},
+		},
+		{
+			description:     "Valid host plan",
+			cfg:             config.Hooks{Enabled: true, HostExecutionPlan: planWith(StageEntrypoint, groupOf(10, "vendor.entrypoint"))},
+			expectedBuilder: true,
+		},
+		{
+			description:   "Unknown stage",
+			cfg:           config.Hooks{Enabled: true, HostExecutionPlan: planWith(Stage("unknown"), groupOf(10, "vendor.entrypoint"))},
+			expectedError: errors.New("hooks.host_execution_plan.endpoints./openrtb2/auction: unknown stage unknown"),
+		},
+		{
+			description:   "Unknown module",
+			cfg:           config.Hooks{Enabled: true, HostExecutionPlan: planWith(StageEntrypoint, groupOf(10, "vendor.unknown"))},
+			expectedError: errors.New("hooks.host_execution_plan.endpoints./openrtb2/auction.stages.entrypoint: unknown module vendor.unknown"),
+		},
+		{
+			description:   "Module not implementing the stage",
+			cfg:           config.Hooks{Enabled: true, HostExecutionPlan: planWith(StageAuctionResponse, groupOf(10, "vendor.entrypoint"))},
+			expectedError: errors.New("hooks.host_execution_plan.endpoints./openrtb2/auction.stages.auction_response: module vendor.entrypoint does not implement the auction_response stage"),
+		},
+	}
+
+	for _, test := range testCases {
+		builder, err := NewPlanBuilder(test.cfg, repository)
+		assert.Equal(t, test.expectedError, err, test.description)
+		assert.Equal(t, test.expectedBuilder, builder != nil, test.description)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	repository := HookRepository{"vendor.host": entrypointModule{}, "vendor.account": entrypointModule{}}
+	builder, err := NewPlanBuilder(config.Hooks{Enabled: true, HostExecutionPlan: planWith(StageEntrypoint, groupOf(10, "vendor.host"))}, repository)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	testCases := []struct {
+		description      string
+		account          *config.Account
+		expectedGroups   []hookGroup
+		expectedFailures []HookOutcome
+	}{
+		{
+			description: "No account",
+			expectedGroups: []hookGroup{
+				{timeout: 10 * time.Millisecond, hooks: []hookEntry{{id: config.HookID{ModuleCode: "vendor.host", HookImplCode: "vendor.host-hook"}, module: entrypointModule{}}}},
+			},
+		},
+		{
+			description: "Account groups run after the host ones",
+			account:     &config.Account{Hooks: config.AccountHooks{ExecutionPlan: planWith(StageEntrypoint, groupOf(20, "vendor.account"))}},
+			expectedGroups: []hookGroup{
+				{timeout: 10 * time.Millisecond, hooks: []hookEntry{{id: config.HookID{ModuleCode: "vendor.host", HookImplCode: "vendor.host-hook"}, module: entrypointModule{}}}},
+				{timeout: 20 * time.Millisecond, hooks: []hookEntry{{id: config.HookID{ModuleCode: "vendor.account", HookImplCode: "vendor.account-hook"}, module: entrypointModule{}}}},
+			},
+		},
+		{
+			description: "Invalid account hooks are reported as failures",
+			account: &config.Account{Hooks: config.AccountHooks{ExecutionPlan: planWith(StageEntrypoint,
+				groupOf(20, "vendor.unknown", "vendor.account"),
+				groupOf(0, "vendor.account"),
+			)}},
+			expectedGroups: []hookGroup{
+				{timeout: 10 * time.Millisecond, hooks: []hookEntry{{id: config.HookID{ModuleCode: "vendor.host", HookImplCode: "vendor.host-hook"}, module: entrypointModule{}}}},
+				{timeout: 20 * time.Millisecond, hooks: []hookEntry{{id: config.HookID{ModuleCode: "vendor.account", HookImplCode: "vendor.account-hook"}, module: entrypointModule{}}}},
+			},
+			expectedFailures: []HookOutcome{
+				{
+					Stage:  StageEntrypoint,
+					ID:     config.HookID{ModuleCode: "vendor.unknown", HookImplCode: "vendor.unknown-hook"},
+					Status: HookStatusExecutionFailure,
+					Errors: []string{"unknown module vendor.unknown"},
+				},
+				{
+					Stage:  StageEntrypoint,
+					ID:     config.HookID{ModuleCode: "vendor.account", HookImplCode: "vendor.account-hook"},
+					Status: HookStatusExecutionFailure,
+					Errors: []string{"the timeout of the group must be positive. Got 0"},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		groups, failures := builder.plan(EndpointAuction, StageEntrypoint, test.account)
+		assert.Equal(t, test.expectedGroups, groups, test.description)
+		assert.Equal(t, test.expectedFailures, failures, test.description)
+	}
+}
+
+func TestNilPlanBuilder(t *testing.T) {
+	var builder *PlanBuilder
+	executor := builder.NewExecutor(EndpointAuction)
+
+	assert.Nil(t, executor)
+	body, reject := executor.ExecuteRawAuctionRequestStage(context.Background(), []byte(`{}`))
+	assert.Equal(t, []byte(`{}`), body)
+	assert.Nil(t, reject)
+	assert.Nil(t, executor.Outcomes())
+	assert.Nil(t, executor.ExtModules())
+}
@@ -60,6 +60,15 @@ type DetailedMetricsEngine struct {
 	PrometheusMetrics *prometheusmetrics.Metrics
 }
 
+// AdapterConnectionWaitTime implements the metrics.LatencyReporter interface. Only go-metrics keeps
+// the recorded values around, so nothing is reported when it isn't configured.
+func (me *DetailedMetricsEngine) AdapterConnectionWaitTime(adapterName openrtb_ext.BidderName, percentile float64) (time.Duration, bool) {
+	if me.GoMetrics == nil {
+		return 0, false
+	}
+	return me.GoMetrics.AdapterConnectionWaitTime(adapterName, percentile)
+}
+
 // MultiMetricsEngine logs metrics to multiple metrics databases The can be useful in transitioning
 // an instance from one engine to another, you can run both in parallel to verify stats match up.
 type MultiMetricsEngine []metrics.MetricsEngine
@@ -125,6 +134,34 @@ func (me *MultiMetricsEngine) RecordAdapterPanic(labels metrics.AdapterLabels) {
 	}
 }
 
+// RecordAdapterCircuitBreakerState across all engines
+func (me *MultiMetricsEngine) RecordAdapterCircuitBreakerState(adapterName openrtb_ext.BidderName, state metrics.CircuitBreakerState) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterCircuitBreakerState(adapterName, state)
+	}
+}
+
+// RecordAdapterBidValidationError across all engines
+func (me *MultiMetricsEngine) RecordAdapterBidValidationError(adapterName openrtb_ext.BidderName, validation metrics.BidValidation) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterBidValidationError(adapterName, validation)
+	}
+}
+
+// RecordAdapterLateResponse across all engines
+func (me *MultiMetricsEngine) RecordAdapterLateResponse(adapterName openrtb_ext.BidderName) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterLateResponse(adapterName)
+	}
+}
+
+// RecordAdapterGZIPBytesSaved across all engines
+func (me *MultiMetricsEngine) RecordAdapterGZIPBytesSaved(adapterName openrtb_ext.BidderName, direction metrics.CompressionDirection, bytesSaved int64) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterGZIPBytesSaved(adapterName, direction, bytesSaved)
+	}
+}
+
 // RecordAdapterRequest across all engines
 func (me *MultiMetricsEngine) RecordAdapterRequest(labels metrics.AdapterLabels) {
 	for _, thisME := range *me {
@@ -283,6 +320,22 @@ func (me *DummyMetricsEngine) RecordStoredDataError(labels metrics.StoredDataLab
 func (me *DummyMetricsEngine) RecordAdapterPanic(labels metrics.AdapterLabels) {
 }
 
+// RecordAdapterCircuitBreakerState as a noop
+func (me *DummyMetricsEngine) RecordAdapterCircuitBreakerState(adapterName openrtb_ext.BidderName, state metrics.CircuitBreakerState) {
+}
+
+// RecordAdapterBidValidationError as a noop
+func (me *DummyMetricsEngine) RecordAdapterBidValidationError(adapterName openrtb_ext.BidderName, validation metrics.BidValidation) {
+}
+
+// RecordAdapterLateResponse as a noop
+func (me *DummyMetricsEngine) RecordAdapterLateResponse(adapterName openrtb_ext.BidderName) {
+}
+
+// RecordAdapterGZIPBytesSaved as a noop
+func (me *DummyMetricsEngine) RecordAdapterGZIPBytesSaved(adapterName openrtb_ext.BidderName, direction metrics.CompressionDirection, bytesSaved int64) {
+}
+
 // RecordAdapterRequest as a noop
 func (me *DummyMetricsEngine) RecordAdapterRequest(labels metrics.AdapterLabels) {
 }
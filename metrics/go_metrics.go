@@ -87,6 +87,14 @@ type AdapterMetrics struct {
 	ConnCreated       metrics.Counter
 	ConnReused        metrics.Counter
 	ConnWaitTime      metrics.Timer
+	// CircuitBreakerMeters count the transitions of the adapter circuit breaker into each state
+	CircuitBreakerMeters map[CircuitBreakerState]metrics.Meter
+	// BidValidationMeters count the bids of the adapter which failed each optional validation
+	BidValidationMeters map[BidValidation]metrics.Meter
+	// LateResponseMeter counts the requests to the adapter which were still running when the auction was finalized at its soft deadline
+	LateResponseMeter metrics.Meter
+	// GZIPBytesSavedMeters count the bytes saved by gzipping the request and response bodies exchanged with the adapter
+	GZIPBytesSavedMeters map[CompressionDirection]metrics.Meter
 }
 
 type MarkupDeliveryMetrics struct {
@@ -300,6 +308,11 @@ func makeBlankAdapterMetrics(disabledMetrics config.DisabledMetrics) *AdapterMet
 		BidsReceivedMeter: blankMeter,
 		PanicMeter:        blankMeter,
 		MarkupMetrics:     makeBlankBidMarkupMetrics(),
+
+		CircuitBreakerMeters: make(map[CircuitBreakerState]metrics.Meter),
+		BidValidationMeters:  make(map[BidValidation]metrics.Meter),
+		LateResponseMeter:    blankMeter,
+		GZIPBytesSavedMeters: make(map[CompressionDirection]metrics.Meter),
 	}
 	if !disabledMetrics.AdapterConnectionMetrics {
 		newAdapter.ConnCreated = metrics.NilCounter{}
@@ -309,6 +322,15 @@ func makeBlankAdapterMetrics(disabledMetrics config.DisabledMetrics) *AdapterMet
 	for _, err := range AdapterErrors() {
 		newAdapter.ErrorMeters[err] = blankMeter
 	}
+	for _, state := range CircuitBreakerStates() {
+		newAdapter.CircuitBreakerMeters[state] = blankMeter
+	}
+	for _, validation := range BidValidations() {
+		newAdapter.BidValidationMeters[validation] = blankMeter
+	}
+	for _, direction := range CompressionDirections() {
+		newAdapter.GZIPBytesSavedMeters[direction] = blankMeter
+	}
 	return newAdapter
 }
 
@@ -346,6 +368,18 @@ func registerAdapterMetrics(registry metrics.Registry, adapterOrAccount string,
 	for err := range am.ErrorMeters {
 		am.ErrorMeters[err] = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.requests.%s", adapterOrAccount, exchange, err), registry)
 	}
+	if adapterOrAccount == "adapter" {
+		for state := range am.CircuitBreakerMeters {
+			am.CircuitBreakerMeters[state] = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.circuit_breaker.%s", adapterOrAccount, exchange, state), registry)
+		}
+		for validation := range am.BidValidationMeters {
+			am.BidValidationMeters[validation] = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.bid_validation.%s", adapterOrAccount, exchange, validation), registry)
+		}
+		am.LateResponseMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.late_responses", adapterOrAccount, exchange), registry)
+		for direction := range am.GZIPBytesSavedMeters {
+			am.GZIPBytesSavedMeters[direction] = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.gzip_bytes_saved.%s", adapterOrAccount, exchange, direction), registry)
+		}
+	}
 	if adapterOrAccount != "adapter" {
 		am.BidsReceivedMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.bids_received", adapterOrAccount, exchange), registry)
 	}
@@ -541,6 +575,15 @@ func (me *Metrics) RecordAdapterConnections(adapterName openrtb_ext.BidderName,
 	am.ConnWaitTime.Update(connWaitTime)
 }
 
+// AdapterConnectionWaitTime implements the LatencyReporter interface
+func (me *Metrics) AdapterConnectionWaitTime(adapterName openrtb_ext.BidderName, percentile float64) (time.Duration, bool) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok || am.ConnWaitTime.Count() == 0 {
+		return 0, false
+	}
+	return time.Duration(am.ConnWaitTime.Percentile(percentile)), true
+}
+
 func (me *Metrics) RecordDNSTime(dnsLookupTime time.Duration) {
 	me.DNSLookupTimer.Update(dnsLookupTime)
 }
@@ -607,6 +650,52 @@ func (me *Metrics) RecordAdapterTime(labels AdapterLabels, length time.Duration)
 	}
 }
 
+// RecordAdapterCircuitBreakerState implements a part of the MetricsEngine interface. Records a transition of the adapter circuit breaker
+func (me *Metrics) RecordAdapterCircuitBreakerState(adapterName openrtb_ext.BidderName, state CircuitBreakerState) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to run adapter circuit breaker metrics on %s: adapter metrics not found", string(adapterName))
+		return
+	}
+	if meter, ok := am.CircuitBreakerMeters[state]; ok {
+		meter.Mark(1)
+	}
+}
+
+// RecordAdapterBidValidationError implements a part of the MetricsEngine interface. Records a bid which failed an optional validation
+func (me *Metrics) RecordAdapterBidValidationError(adapterName openrtb_ext.BidderName, validation BidValidation) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to run adapter bid validation metrics on %s: adapter metrics not found", string(adapterName))
+		return
+	}
+	if meter, ok := am.BidValidationMeters[validation]; ok {
+		meter.Mark(1)
+	}
+}
+
+// RecordAdapterLateResponse implements a part of the MetricsEngine interface. Records a request to the adapter which missed the soft deadline
+func (me *Metrics) RecordAdapterLateResponse(adapterName openrtb_ext.BidderName) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to run adapter late response metrics on %s: adapter metrics not found", string(adapterName))
+		return
+	}
+	am.LateResponseMeter.Mark(1)
+}
+
+// RecordAdapterGZIPBytesSaved implements a part of the MetricsEngine interface. Records the bytes saved by gzipping a body exchanged with the adapter
+func (me *Metrics) RecordAdapterGZIPBytesSaved(adapterName openrtb_ext.BidderName, direction CompressionDirection, bytesSaved int64) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to run adapter gzip metrics on %s: adapter metrics not found", string(adapterName))
+		return
+	}
+	if meter, ok := am.GZIPBytesSavedMeters[direction]; ok {
+		meter.Mark(bytesSaved)
+	}
+}
+
 // RecordCookieSync implements a part of the MetricsEngine interface. Records a cookie sync request
 func (me *Metrics) RecordCookieSync() {
 	me.CookieSyncMeter.Mark(1)
@@ -343,6 +343,78 @@ func TestRecordAdapterConnections(t *testing.T) {
 	}
 }
 
+func TestAdapterConnectionWaitTime(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus, openrtb_ext.BidderRubicon}, config.DisabledMetrics{})
+	for i := 1; i <= 10; i++ {
+		m.RecordAdapterConnections(openrtb_ext.BidderAppnexus, false, time.Duration(i)*time.Millisecond)
+	}
+
+	waitTime, ok := m.AdapterConnectionWaitTime(openrtb_ext.BidderAppnexus, 0.5)
+	assert.True(t, ok, "Recorded adapter should report a wait time")
+	assert.Equal(t, 5500*time.Microsecond, waitTime, "Recorded adapter median wait time")
+
+	_, ok = m.AdapterConnectionWaitTime(openrtb_ext.BidderRubicon, 0.5)
+	assert.False(t, ok, "Adapter without recorded connections should not report a wait time")
+
+	_, ok = m.AdapterConnectionWaitTime("unknown", 0.5)
+	assert.False(t, ok, "Unknown adapter should not report a wait time")
+}
+
+func TestRecordAdapterCircuitBreakerState(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordAdapterCircuitBreakerState(openrtb_ext.BidderAppnexus, CircuitBreakerOpen)
+	m.RecordAdapterCircuitBreakerState(openrtb_ext.BidderAppnexus, CircuitBreakerHalfOpen)
+	m.RecordAdapterCircuitBreakerState(openrtb_ext.BidderAppnexus, CircuitBreakerOpen)
+	m.RecordAdapterCircuitBreakerState("unknown", CircuitBreakerOpen)
+
+	assert.Equal(t, int64(2), m.AdapterMetrics[openrtb_ext.BidderAppnexus].CircuitBreakerMeters[CircuitBreakerOpen].Count())
+	assert.Equal(t, int64(1), m.AdapterMetrics[openrtb_ext.BidderAppnexus].CircuitBreakerMeters[CircuitBreakerHalfOpen].Count())
+	assert.Equal(t, int64(0), m.AdapterMetrics[openrtb_ext.BidderAppnexus].CircuitBreakerMeters[CircuitBreakerClosed].Count())
+	assert.NotNil(t, registry.Get("adapter.appnexus.circuit_breaker.open"), "circuit breaker meter should be registered")
+}
+
+func TestRecordAdapterBidValidationError(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordAdapterBidValidationError(openrtb_ext.BidderAppnexus, BidValidationBannerCreativeSize)
+	m.RecordAdapterBidValidationError("unknown", BidValidationBannerCreativeSize)
+
+	assert.Equal(t, int64(1), m.AdapterMetrics[openrtb_ext.BidderAppnexus].BidValidationMeters[BidValidationBannerCreativeSize].Count())
+	assert.Equal(t, int64(0), m.AdapterMetrics[openrtb_ext.BidderAppnexus].BidValidationMeters[BidValidationSecureMarkup].Count())
+	assert.NotNil(t, registry.Get("adapter.appnexus.bid_validation.banner_creative_size"), "bid validation meter should be registered")
+}
+
+func TestRecordAdapterLateResponse(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordAdapterLateResponse(openrtb_ext.BidderAppnexus)
+	m.RecordAdapterLateResponse(openrtb_ext.BidderAppnexus)
+	m.RecordAdapterLateResponse("unknown")
+
+	assert.Equal(t, int64(2), m.AdapterMetrics[openrtb_ext.BidderAppnexus].LateResponseMeter.Count())
+	assert.NotNil(t, registry.Get("adapter.appnexus.late_responses"), "late response meter should be registered")
+}
+
+func TestRecordAdapterGZIPBytesSaved(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordAdapterGZIPBytesSaved(openrtb_ext.BidderAppnexus, CompressionRequest, 100)
+	m.RecordAdapterGZIPBytesSaved(openrtb_ext.BidderAppnexus, CompressionRequest, 50)
+	m.RecordAdapterGZIPBytesSaved(openrtb_ext.BidderAppnexus, CompressionResponse, 20)
+	m.RecordAdapterGZIPBytesSaved("unknown", CompressionRequest, 100)
+
+	assert.Equal(t, int64(150), m.AdapterMetrics[openrtb_ext.BidderAppnexus].GZIPBytesSavedMeters[CompressionRequest].Count())
+	assert.Equal(t, int64(20), m.AdapterMetrics[openrtb_ext.BidderAppnexus].GZIPBytesSavedMeters[CompressionResponse].Count())
+	assert.NotNil(t, registry.Get("adapter.appnexus.gzip_bytes_saved.request"), "gzip request meter should be registered")
+	assert.NotNil(t, registry.Get("adapter.appnexus.gzip_bytes_saved.response"), "gzip response meter should be registered")
+}
+
 func TestNewMetricsWithDisabledConfig(t *testing.T) {
 	registry := metrics.NewRegistry()
 	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus, openrtb_ext.BidderRubicon}, config.DisabledMetrics{AccountAdapterDetails: true})
@@ -294,6 +294,56 @@ func RequestActions() []RequestAction {
 	}
 }
 
+// CircuitBreakerState : The state of the circuit breaker of an adapter
+type CircuitBreakerState string
+
+const (
+	CircuitBreakerClosed   CircuitBreakerState = "closed"
+	CircuitBreakerOpen     CircuitBreakerState = "open"
+	CircuitBreakerHalfOpen CircuitBreakerState = "half_open"
+)
+
+// CircuitBreakerStates returns the possible states of an adapter circuit breaker
+func CircuitBreakerStates() []CircuitBreakerState {
+	return []CircuitBreakerState{
+		CircuitBreakerClosed,
+		CircuitBreakerOpen,
+		CircuitBreakerHalfOpen,
+	}
+}
+
+// BidValidation : The optional validations run on the bids of an adapter
+type BidValidation string
+
+const (
+	BidValidationBannerCreativeSize BidValidation = "banner_creative_size"
+	BidValidationSecureMarkup       BidValidation = "secure_markup"
+)
+
+// BidValidations returns the possible optional bid validations
+func BidValidations() []BidValidation {
+	return []BidValidation{
+		BidValidationBannerCreativeSize,
+		BidValidationSecureMarkup,
+	}
+}
+
+// CompressionDirection : The direction of the gzipped HTTP bodies exchanged with an adapter
+type CompressionDirection string
+
+const (
+	CompressionRequest  CompressionDirection = "request"
+	CompressionResponse CompressionDirection = "response"
+)
+
+// CompressionDirections returns the possible directions of the gzipped HTTP bodies
+func CompressionDirections() []CompressionDirection {
+	return []CompressionDirection{
+		CompressionRequest,
+		CompressionResponse,
+	}
+}
+
 // TCFVersionValue : The possible values for TCF versions
 type TCFVersionValue string
 
@@ -346,6 +396,14 @@ type MetricsEngine interface {
 	RecordAdapterBidReceived(labels AdapterLabels, bidType openrtb_ext.BidType, hasAdm bool)
 	RecordAdapterPrice(labels AdapterLabels, cpm float64)
 	RecordAdapterTime(labels AdapterLabels, length time.Duration)
+	// RecordAdapterCircuitBreakerState records a transition of the adapter circuit breaker into the given state.
+	RecordAdapterCircuitBreakerState(adapterName openrtb_ext.BidderName, state CircuitBreakerState)
+	// RecordAdapterBidValidationError records a bid of the adapter which failed an optional validation, whether it was rejected or only warned about.
+	RecordAdapterBidValidationError(adapterName openrtb_ext.BidderName, validation BidValidation)
+	// RecordAdapterLateResponse records an adapter which hadn't responded when the auction was finalized at its soft deadline.
+	RecordAdapterLateResponse(adapterName openrtb_ext.BidderName)
+	// RecordAdapterGZIPBytesSaved records the bytes saved by gzipping a request body sent to the adapter, or a response body received from it.
+	RecordAdapterGZIPBytesSaved(adapterName openrtb_ext.BidderName, direction CompressionDirection, bytesSaved int64)
 	RecordCookieSync()
 	RecordAdapterCookieSync(adapter openrtb_ext.BidderName, gdprBlocked bool)
 	RecordUserIDSet(userLabels UserLabels) // Function should verify bidder values
@@ -359,3 +417,10 @@ type MetricsEngine interface {
 	RecordTimeoutNotice(sucess bool)
 	RecordRequestPrivacy(privacy PrivacyLabels)
 }
+
+// LatencyReporter is implemented by the metrics engines which can report back the network latencies they recorded.
+type LatencyReporter interface {
+	// AdapterConnectionWaitTime returns the given percentile of the connection wait times recorded for the adapter.
+	// The boolean is false if no connection wait times were recorded.
+	AdapterConnectionWaitTime(adapterName openrtb_ext.BidderName, percentile float64) (time.Duration, bool)
+}
@@ -57,6 +57,26 @@ func (me *MetricsEngineMock) RecordAdapterPanic(labels AdapterLabels) {
 	me.Called(labels)
 }
 
+// RecordAdapterBidValidationError mock
+func (me *MetricsEngineMock) RecordAdapterBidValidationError(adapterName openrtb_ext.BidderName, validation BidValidation) {
+	me.Called(adapterName, validation)
+}
+
+// RecordAdapterLateResponse mock
+func (me *MetricsEngineMock) RecordAdapterLateResponse(adapterName openrtb_ext.BidderName) {
+	me.Called(adapterName)
+}
+
+// RecordAdapterGZIPBytesSaved mock
+func (me *MetricsEngineMock) RecordAdapterGZIPBytesSaved(adapterName openrtb_ext.BidderName, direction CompressionDirection, bytesSaved int64) {
+	me.Called(adapterName, direction, bytesSaved)
+}
+
+// RecordAdapterCircuitBreakerState mock
+func (me *MetricsEngineMock) RecordAdapterCircuitBreakerState(adapterName openrtb_ext.BidderName, state CircuitBreakerState) {
+	me.Called(adapterName, state)
+}
+
 // RecordAdapterRequest mock
 func (me *MetricsEngineMock) RecordAdapterRequest(labels AdapterLabels) {
 	me.Called(labels)
@@ -59,6 +59,10 @@ type Metrics struct {
 	adapterReusedConnections  *prometheus.CounterVec
 	adapterCreatedConnections *prometheus.CounterVec
 	adapterConnectionWaitTime *prometheus.HistogramVec
+	adapterCircuitBreaker     *prometheus.CounterVec
+	adapterBidValidation      *prometheus.CounterVec
+	adapterLateResponses      *prometheus.CounterVec
+	adapterGZIPBytesSaved     *prometheus.CounterVec
 
 	// Account Metrics
 	accountRequests *prometheus.CounterVec
@@ -73,6 +77,7 @@ const (
 	adapterLabel         = "adapter"
 	bidTypeLabel         = "bid_type"
 	cacheResultLabel     = "cache_result"
+	circuitBreakerLabel  = "circuit_breaker"
 	connectionErrorLabel = "connection_error"
 	cookieLabel          = "cookie"
 	hasBidsLabel         = "has_bids"
@@ -86,6 +91,8 @@ const (
 	requestStatusLabel   = "request_status"
 	requestTypeLabel     = "request_type"
 	successLabel         = "success"
+	validationLabel      = "validation"
+	directionLabel       = "direction"
 	versionLabel         = "version"
 )
 
@@ -303,6 +310,26 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"Count of panics labeled by adapter.",
 		[]string{adapterLabel})
 
+	metrics.adapterCircuitBreaker = newCounter(cfg, metrics.Registry,
+		"adapter_circuit_breaker",
+		"Count of adapter circuit breaker transitions labeled by adapter and the state transitioned into.",
+		[]string{adapterLabel, circuitBreakerLabel})
+
+	metrics.adapterBidValidation = newCounter(cfg, metrics.Registry,
+		"adapter_bid_validation_errors",
+		"Count of bids which failed an optional validation labeled by adapter and validation.",
+		[]string{adapterLabel, validationLabel})
+
+	metrics.adapterLateResponses = newCounter(cfg, metrics.Registry,
+		"adapter_late_responses",
+		"Count of adapter requests still running when the auction was finalized at its soft deadline labeled by adapter.",
+		[]string{adapterLabel})
+
+	metrics.adapterGZIPBytesSaved = newCounter(cfg, metrics.Registry,
+		"adapter_gzip_bytes_saved",
+		"Count of bytes saved by gzipping the bodies exchanged with the adapters labeled by adapter and direction.",
+		[]string{adapterLabel, directionLabel})
+
 	metrics.adapterPrices = newHistogramVec(cfg, metrics.Registry,
 		"adapter_prices",
 		"Monetary value of the bids labeled by adapter.",
@@ -570,6 +597,33 @@ func (m *Metrics) RecordAdapterPanic(labels metrics.AdapterLabels) {
 	}).Inc()
 }
 
+func (m *Metrics) RecordAdapterCircuitBreakerState(adapterName openrtb_ext.BidderName, state metrics.CircuitBreakerState) {
+	m.adapterCircuitBreaker.With(prometheus.Labels{
+		adapterLabel:        string(adapterName),
+		circuitBreakerLabel: string(state),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterBidValidationError(adapterName openrtb_ext.BidderName, validation metrics.BidValidation) {
+	m.adapterBidValidation.With(prometheus.Labels{
+		adapterLabel:    string(adapterName),
+		validationLabel: string(validation),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterLateResponse(adapterName openrtb_ext.BidderName) {
+	m.adapterLateResponses.With(prometheus.Labels{
+		adapterLabel: string(adapterName),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterGZIPBytesSaved(adapterName openrtb_ext.BidderName, direction metrics.CompressionDirection, bytesSaved int64) {
+	m.adapterGZIPBytesSaved.With(prometheus.Labels{
+		adapterLabel:   string(adapterName),
+		directionLabel: string(direction),
+	}).Add(float64(bytesSaved))
+}
+
 func (m *Metrics) RecordAdapterBidReceived(labels metrics.AdapterLabels, bidType openrtb_ext.BidType, hasAdm bool) {
 	markupDelivery := markupDeliveryNurl
 	if hasAdm {
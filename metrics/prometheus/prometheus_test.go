@@ -990,6 +990,77 @@ func TestAdapterPanicMetric(t *testing.T) {
 		})
 }
 
+func TestAdapterCircuitBreakerMetric(t *testing.T) {
+	m := createMetricsForTesting()
+	adapterName := "anyName"
+
+	m.RecordAdapterCircuitBreakerState(openrtb_ext.BidderName(adapterName), metrics.CircuitBreakerOpen)
+	m.RecordAdapterCircuitBreakerState(openrtb_ext.BidderName(adapterName), metrics.CircuitBreakerOpen)
+	m.RecordAdapterCircuitBreakerState(openrtb_ext.BidderName(adapterName), metrics.CircuitBreakerHalfOpen)
+
+	assertCounterVecValue(t, "", "adapterCircuitBreaker:open", m.adapterCircuitBreaker,
+		float64(2),
+		prometheus.Labels{
+			adapterLabel:        adapterName,
+			circuitBreakerLabel: string(metrics.CircuitBreakerOpen),
+		})
+	assertCounterVecValue(t, "", "adapterCircuitBreaker:half_open", m.adapterCircuitBreaker,
+		float64(1),
+		prometheus.Labels{
+			adapterLabel:        adapterName,
+			circuitBreakerLabel: string(metrics.CircuitBreakerHalfOpen),
+		})
+}
+
+func TestAdapterLateResponseMetric(t *testing.T) {
+	m := createMetricsForTesting()
+	adapterName := "anyName"
+
+	m.RecordAdapterLateResponse(openrtb_ext.BidderName(adapterName))
+	m.RecordAdapterLateResponse(openrtb_ext.BidderName(adapterName))
+
+	assertCounterVecValue(t, "", "adapterLateResponses", m.adapterLateResponses,
+		float64(2),
+		prometheus.Labels{
+			adapterLabel: adapterName,
+		})
+}
+
+func TestAdapterGZIPBytesSavedMetric(t *testing.T) {
+	m := createMetricsForTesting()
+	adapterName := "anyName"
+
+	m.RecordAdapterGZIPBytesSaved(openrtb_ext.BidderName(adapterName), metrics.CompressionRequest, 100)
+	m.RecordAdapterGZIPBytesSaved(openrtb_ext.BidderName(adapterName), metrics.CompressionRequest, 50)
+
+	assertCounterVecValue(t, "", "adapterGZIPBytesSaved:request", m.adapterGZIPBytesSaved,
+		float64(150),
+		prometheus.Labels{
+			adapterLabel:   adapterName,
+			directionLabel: string(metrics.CompressionRequest),
+		})
+}
+
+func TestAdapterBidValidationMetric(t *testing.T) {
+	m := createMetricsForTesting()
+	adapterName := "anyName"
+
+	m.RecordAdapterBidValidationError(openrtb_ext.BidderName(adapterName), metrics.BidValidationSecureMarkup)
+
+	assertCounterVecValue(t, "", "adapterBidValidation:secure_markup", m.adapterBidValidation,
+		float64(1),
+		prometheus.Labels{
+			adapterLabel:    adapterName,
+			validationLabel: string(metrics.BidValidationSecureMarkup),
+		})
+	assertCounterVecValue(t, "", "adapterBidValidation:banner_creative_size", m.adapterBidValidation,
+		float64(0),
+		prometheus.Labels{
+			adapterLabel:    adapterName,
+			validationLabel: string(metrics.BidValidationBannerCreativeSize),
+		})
+}
+
 func TestStoredReqCacheResultMetric(t *testing.T) {
 	m := createMetricsForTesting()
 
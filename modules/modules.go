@@ -0,0 +1,46 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/hooks"
+)
+
+// ModuleBuilder builds a module from its host configuration. The module must implement at least one of the hook interfaces.
+type ModuleBuilder func(cfg json.RawMessage) (interface{}, error)
+
+// builders returns the builders of the modules shipped with Prebid Server, keyed by vendor and then by module name.
+// New modules are registered here.
+func builders() map[string]map[string]ModuleBuilder {
+	return map[string]map[string]ModuleBuilder{}
+}
+
+// Build builds the modules which have a host configuration in hooks.modules, and returns them keyed by module code.
+func Build(cfg config.Hooks) (hooks.HookRepository, error) {
+	return build(builders(), cfg)
+}
+
+func build(builders map[string]map[string]ModuleBuilder, cfg config.Hooks) (hooks.HookRepository, error) {
+	modules := make(map[string]interface{})
+	for vendor, vendorModules := range cfg.Modules {
+		for name, moduleCfg := range vendorModules {
+			code := fmt.Sprintf("%s.%s", vendor, name)
+			builder, ok := builders[vendor][name]
+			if !ok {
+				return nil, fmt.Errorf("hooks.modules: unknown module %s", code)
+			}
+			cfgJSON, err := json.Marshal(moduleCfg)
+			if err != nil {
+				return nil, fmt.Errorf("hooks.modules: failed to encode the configuration of module %s: %v", code, err)
+			}
+			module, err := builder(cfgJSON)
+			if err != nil {
+				return nil, fmt.Errorf("hooks.modules: failed to build module %s: %v", code, err)
+			}
+			modules[code] = module
+		}
+	}
+	return hooks.NewHookRepository(modules)
+}
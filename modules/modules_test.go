@@ -0,0 +1,74 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/stretchr/testify/assert"
+)
+
+type testModule struct {
+	cfg json.RawMessage
+}
+
+func (m testModule) HandleEntrypointHook(ctx context.Context, invocation hooks.InvocationContext, payload hooks.EntrypointPayload) (hooks.HookResult, error) {
+	return hooks.HookResult{}, nil
+}
+
+func TestBuild(t *testing.T) {
+	testBuilders := map[string]map[string]ModuleBuilder{
+		"vendor": {
+			"module": func(cfg json.RawMessage) (interface{}, error) {
+				return testModule{cfg: cfg}, nil
+			},
+			"broken": func(cfg json.RawMessage) (interface{}, error) {
+				return nil, errors.New("bad config")
+			},
+			"nohooks": func(cfg json.RawMessage) (interface{}, error) {
+				return struct{}{}, nil
+			},
+		},
+	}
+
+	testCases := []struct {
+		description        string
+		modules            map[string]map[string]interface{}
+		expectedRepository hooks.HookRepository
+		expectedError      error
+	}{
+		{
+			description:        "No configured modules",
+			expectedRepository: hooks.HookRepository{},
+		},
+		{
+			description:        "Configured module",
+			modules:            map[string]map[string]interface{}{"vendor": {"module": map[string]interface{}{"key": "value"}}},
+			expectedRepository: hooks.HookRepository{"vendor.module": testModule{cfg: json.RawMessage(`{"key":"value"}`)}},
+		},
+		{
+			description:   "Unknown module",
+			modules:       map[string]map[string]interface{}{"vendor": {"unknown": nil}},
+			expectedError: errors.New("hooks.modules: unknown module vendor.unknown"),
+		},
+		{
+			description:   "Builder error",
+			modules:       map[string]map[string]interface{}{"vendor": {"broken": nil}},
+			expectedError: errors.New("hooks.modules: failed to build module vendor.broken: bad config"),
+		},
+		{
+			description:   "Module without hooks",
+			modules:       map[string]map[string]interface{}{"vendor": {"nohooks": nil}},
+			expectedError: errors.New("module vendor.nohooks does not implement any hook"),
+		},
+	}
+
+	for _, test := range testCases {
+		repository, err := build(testBuilders, config.Hooks{Enabled: true, Modules: test.modules})
+		assert.Equal(t, test.expectedError, err, test.description)
+		assert.Equal(t, test.expectedRepository, repository, test.description)
+	}
+}
@@ -16,9 +16,11 @@ type ExtBid struct {
 // DealTierSatisfied true represents corresponding bid has satisfied the deal tier
 type ExtBidPrebid struct {
 	Cache             *ExtBidPrebidCache `json:"cache,omitempty"`
+	ClearingPrice     float64            `json:"clearingprice,omitempty"`
 	DealPriority      int                `json:"dealpriority,omitempty"`
 	DealTierSatisfied bool               `json:"dealtiersatisfied,omitempty"`
 	Meta              *ExtBidPrebidMeta  `json:"meta,omitempty"`
+	Reused            bool               `json:"reused,omitempty"`
 	Targeting         map[string]string  `json:"targeting,omitempty"`
 	Type              BidType            `json:"type"`
 	Video             *ExtBidPrebidVideo `json:"video,omitempty"`
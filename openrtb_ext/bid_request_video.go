@@ -169,6 +169,13 @@ type PodConfig struct {
 	//  Flag indicating exact ad duration requirement. Default is false.
 	RequireExactDuration bool `json:"requireexactduration,omitempty"`
 
+	// Attribute:
+	//   exclusion
+	// Type:
+	//   object; optional
+	//  Competitive separation rules applied when filling each pod.
+	Exclusion *PodExclusion `json:"exclusion,omitempty"`
+
 	// Attribute:
 	//   pods
 	// Type:
@@ -177,6 +184,22 @@ type PodConfig struct {
 	Pods []Pod `json:"pods"`
 }
 
+type PodExclusion struct {
+	// Attribute:
+	//   advertiserdomain
+	// Type:
+	//   boolean, optional
+	//  Flag indicating that two ads of the same advertiser domain can't be in the same pod. Default is false.
+	AdvertiserDomain bool `json:"advertiserdomain,omitempty"`
+
+	// Attribute:
+	//   iabcategory
+	// Type:
+	//   boolean, optional
+	//  Flag indicating that two ads of the same primary category can't be in the same pod. Default is false.
+	IABCategory bool `json:"iabcategory,omitempty"`
+}
+
 type Pod struct {
 	// Attribute:
 	//   podid
@@ -79,6 +79,7 @@ const (
 	BidderEPlanning        BidderName = "eplanning"
 	BidderGamma            BidderName = "gamma"
 	BidderGamoshi          BidderName = "gamoshi"
+	BidderGeneric          BidderName = "generic"
 	BidderGrid             BidderName = "grid"
 	BidderGumGum           BidderName = "gumgum"
 	BidderImprovedigital   BidderName = "improvedigital"
@@ -134,9 +135,9 @@ const (
 	BidderZeroClickFraud   BidderName = "zeroclickfraud"
 )
 
-// CoreBidderNames returns a slice of all core bidders.
+// CoreBidderNames returns a slice of all core bidders, followed by the aliases defined in the host configuration.
 func CoreBidderNames() []BidderName {
-	return []BidderName{
+	return append([]BidderName{
 		Bidder33Across,
 		BidderAcuityAds,
 		BidderAdform,
@@ -176,6 +177,7 @@ func CoreBidderNames() []BidderName {
 		BidderEPlanning,
 		BidderGamma,
 		BidderGamoshi,
+		BidderGeneric,
 		BidderGrid,
 		BidderGumGum,
 		BidderImprovedigital,
@@ -229,7 +231,44 @@ func CoreBidderNames() []BidderName {
 		BidderYieldmo,
 		BidderYieldone,
 		BidderZeroClickFraud,
+	}, aliasBidderNames...)
+}
+
+// aliasBidderNames holds the aliases defined in the host configuration, in the order they were set.
+var aliasBidderNames []BidderName
+
+// aliasBidderToParent maps the aliases defined in the host configuration to the core bidders whose adapters they use.
+var aliasBidderToParent = map[BidderName]BidderName{}
+
+// SetAliasBidderName registers an alias defined in the host configuration, so that it's treated as a core bidder
+// which uses the adapter and the bidder params of its parent. It must be called on startup, before the bidders,
+// the metrics and the bidder params validator are built.
+func SetAliasBidderName(aliasBidderName string, parentBidderName BidderName) error {
+	alias := BidderName(strings.ToLower(aliasBidderName))
+	parent, parentExists := NormalizeBidderName(string(parentBidderName))
+	if !parentExists {
+		return fmt.Errorf("alias %s refers to unknown bidder: %s", aliasBidderName, parentBidderName)
+	}
+	if registeredParent, isAlias := aliasBidderToParent[alias]; isAlias && registeredParent == parent {
+		return nil
+	}
+	if _, exists := NormalizeBidderName(aliasBidderName); exists {
+		return fmt.Errorf("alias %s is already a bidder name", aliasBidderName)
+	}
+	if _, isAlias := aliasBidderToParent[parent]; isAlias {
+		return fmt.Errorf("alias %s refers to the alias %s instead of a core bidder", aliasBidderName, parent)
 	}
+
+	aliasBidderNames = append(aliasBidderNames, alias)
+	aliasBidderToParent[alias] = parent
+	bidderNameLookup[string(alias)] = alias
+	return nil
+}
+
+// AliasBidderParent returns the core bidder whose adapter is used by an alias defined in the host configuration.
+func AliasBidderParent(name BidderName) (BidderName, bool) {
+	parent, isAlias := aliasBidderToParent[name]
+	return parent, isAlias
 }
 
 // BuildBidderMap builds a map of string to BidderName, to remain compatbile with the
@@ -311,6 +350,14 @@ func NewBidderParamsValidator(schemaDirectory string) (BidderParamValidator, err
 		schemaContents[BidderName(bidderName)] = string(fileBytes)
 	}
 
+	// The aliases defined in the host configuration take the bidder params of their parent.
+	for alias, parent := range aliasBidderToParent {
+		if schema, ok := schemas[parent]; ok {
+			schemas[alias] = schema
+			schemaContents[alias] = schemaContents[parent]
+		}
+	}
+
 	return &bidderParamValidator{
 		schemaContents: schemaContents,
 		parsedSchemas:  schemas,
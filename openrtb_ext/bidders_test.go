@@ -113,3 +113,94 @@ func uniqueForPrefixLength(b []string, prefixLength int) bool {
 
 	return true
 }
+
+func TestSetAliasBidderName(t *testing.T) {
+	defer resetAliasBidderNames()
+
+	testCases := []struct {
+		description   string
+		alias         string
+		parent        BidderName
+		expectedError string
+	}{
+		{
+			description: "Alias Of A Core Bidder",
+			alias:       "MyBrand",
+			parent:      BidderAppnexus,
+		},
+		{
+			description: "Same Alias Set Again",
+			alias:       "mybrand",
+			parent:      BidderAppnexus,
+		},
+		{
+			description:   "Alias Set To Another Bidder",
+			alias:         "mybrand",
+			parent:        BidderRubicon,
+			expectedError: "alias mybrand is already a bidder name",
+		},
+		{
+			description:   "Core Bidder Name",
+			alias:         "rubicon",
+			parent:        BidderAppnexus,
+			expectedError: "alias rubicon is already a bidder name",
+		},
+		{
+			description:   "Alias Of An Alias",
+			alias:         "otherbrand",
+			parent:        BidderName("mybrand"),
+			expectedError: "alias otherbrand refers to the alias mybrand instead of a core bidder",
+		},
+		{
+			description:   "Alias Of An Unknown Bidder",
+			alias:         "otherbrand",
+			parent:        BidderName("unknown"),
+			expectedError: "alias otherbrand refers to unknown bidder: unknown",
+		},
+	}
+
+	for _, test := range testCases {
+		err := SetAliasBidderName(test.alias, test.parent)
+		if test.expectedError == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		}
+	}
+
+	parent, isAlias := AliasBidderParent(BidderName("mybrand"))
+	assert.True(t, isAlias)
+	assert.Equal(t, BidderAppnexus, parent)
+	_, isAlias = AliasBidderParent(BidderAppnexus)
+	assert.False(t, isAlias, "A core bidder isn't an alias")
+
+	assert.Contains(t, CoreBidderNames(), BidderName("mybrand"))
+	assert.Equal(t, BidderName("mybrand"), BuildBidderMap()["mybrand"])
+	normalized, found := NormalizeBidderName("MYBRAND")
+	assert.True(t, found)
+	assert.Equal(t, BidderName("mybrand"), normalized)
+}
+
+func TestBidderParamsValidatorAliases(t *testing.T) {
+	defer resetAliasBidderNames()
+	if !assert.NoError(t, SetAliasBidderName("mybrand", BidderAppnexus)) {
+		return
+	}
+
+	aliasValidator, err := NewBidderParamsValidator("../static/bidder-params")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, validator.Schema(BidderAppnexus), aliasValidator.Schema(BidderName("mybrand")), "An alias should take the schema of its parent")
+	assert.NoError(t, aliasValidator.Validate(BidderName("mybrand"), json.RawMessage(`{"placementId":123}`)))
+	assert.Error(t, aliasValidator.Validate(BidderName("mybrand"), json.RawMessage(`{}`)))
+}
+
+func resetAliasBidderNames() {
+	for _, alias := range aliasBidderNames {
+		delete(bidderNameLookup, string(alias))
+	}
+	aliasBidderNames = nil
+	aliasBidderToParent = map[BidderName]BidderName{}
+}
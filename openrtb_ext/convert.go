@@ -0,0 +1,143 @@
+package openrtb_ext
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/buger/jsonparser"
+)
+
+// ortb26Field is a field which OpenRTB 2.6 defines in an object of the request, and which the 2.5 structs hold in an ext.
+type ortb26Field struct {
+	path    []string
+	extPath []string
+}
+
+// requestFields26 are the 2.6 fields of the request, along with their 2.5 placement.
+var requestFields26 = []ortb26Field{
+	{path: []string{"regs", "gdpr"}, extPath: []string{"regs", "ext", "gdpr"}},
+	{path: []string{"regs", "us_privacy"}, extPath: []string{"regs", "ext", "us_privacy"}},
+	{path: []string{"regs", "gpp"}, extPath: []string{"regs", "ext", "gpp"}},
+	{path: []string{"regs", "gpp_sid"}, extPath: []string{"regs", "ext", "gpp_sid"}},
+	{path: []string{"user", "consent"}, extPath: []string{"user", "ext", "consent"}},
+	{path: []string{"user", "eids"}, extPath: []string{"user", "ext", "eids"}},
+	{path: []string{"source", "schain"}, extPath: []string{"source", "ext", "schain"}},
+}
+
+// impFields26 are the 2.6 fields of each imp, along with their 2.5 placement.
+var impFields26 = []ortb26Field{
+	{path: []string{"rwdd"}, extPath: []string{"ext", "prebid", "is_rewarded_inventory"}},
+	{path: []string{"video", "podid"}, extPath: []string{"video", "ext", "podid"}},
+	{path: []string{"video", "podseq"}, extPath: []string{"video", "ext", "podseq"}},
+	{path: []string{"video", "slotinpod"}, extPath: []string{"video", "ext", "slotinpod"}},
+	{path: []string{"video", "mincpmpersec"}, extPath: []string{"video", "ext", "mincpmpersec"}},
+	{path: []string{"video", "maxseq"}, extPath: []string{"video", "ext", "maxseq"}},
+	{path: []string{"video", "poddur"}, extPath: []string{"video", "ext", "poddur"}},
+	{path: []string{"video", "rqddurs"}, extPath: []string{"video", "ext", "rqddurs"}},
+}
+
+// ConvertDownTo25 moves the OpenRTB 2.6 fields of the request, which the 2.5 structs used by Prebid Server can't hold,
+// to their placement in the exts. The 2.6 fields replace the ext fields set by the request.
+func ConvertDownTo25(request json.RawMessage) (json.RawMessage, error) {
+	return convertRequest(request, true)
+}
+
+// ConvertUpTo26 moves the ext fields of the request which OpenRTB 2.6 defines elsewhere to their 2.6 placement,
+// for the bidders which have migrated to it.
+func ConvertUpTo26(request json.RawMessage) (json.RawMessage, error) {
+	return convertRequest(request, false)
+}
+
+func convertRequest(request json.RawMessage, down bool) (json.RawMessage, error) {
+	// jsonparser.Delete works in place, and the request may be shared.
+	converted, err := moveFields(append(json.RawMessage(nil), request...), requestFields26, down)
+	if err != nil {
+		return nil, err
+	}
+
+	imps, dataType, _, _ := jsonparser.Get(converted, "imp")
+	if dataType != jsonparser.Array {
+		return converted, nil
+	}
+	var convertedImps [][]byte
+	var impErr error
+	malformedImp := false
+	jsonparser.ArrayEach(imps, func(imp []byte, dataType jsonparser.ValueType, _ int, _ error) {
+		if impErr != nil || malformedImp {
+			return
+		}
+		if dataType != jsonparser.Object {
+			malformedImp = true
+			return
+		}
+		var convertedImp []byte
+		convertedImp, impErr = moveFields(append([]byte(nil), imp...), impFields26, down)
+		convertedImps = append(convertedImps, convertedImp)
+	})
+	if impErr != nil {
+		return nil, impErr
+	}
+	if malformedImp {
+		// The imps are left as they are, for the validation of the request to reject them.
+		return converted, nil
+	}
+
+	convertedImpsJSON := append(append([]byte("["), bytes.Join(convertedImps, []byte(","))...), ']')
+	return jsonparser.Set(converted, convertedImpsJSON, "imp")
+}
+
+// moveFields moves the fields of the object from their 2.6 placement to their 2.5 placement if down is true, or the
+// other way round if it's false. The fields which aren't set, or are null, are left alone.
+func moveFields(object []byte, fields []ortb26Field, down bool) ([]byte, error) {
+	for _, field := range fields {
+		from, to := field.extPath, field.path
+		if down {
+			from, to = field.path, field.extPath
+		}
+
+		value, dataType, _, err := jsonparser.Get(object, from...)
+		if dataType == jsonparser.NotExist || dataType == jsonparser.Null {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// The value points into the object, which is changed in place by jsonparser.Delete.
+		if dataType == jsonparser.String {
+			value = append(append([]byte(`"`), value...), '"')
+		} else {
+			value = append([]byte(nil), value...)
+		}
+
+		object = removeField(object, from)
+		if object, err = jsonparser.Set(object, value, to...); err != nil {
+			return nil, err
+		}
+	}
+	return object, nil
+}
+
+// removeField deletes the field from the object, along with the exts which have nothing else left.
+func removeField(object []byte, path []string) []byte {
+	object = jsonparser.Delete(object, path...)
+	for i := len(path) - 1; i > 0; i-- {
+		if !containsExt(path[:i]) {
+			break
+		}
+		parent, dataType, _, _ := jsonparser.Get(object, path[:i]...)
+		if dataType != jsonparser.Object || len(bytes.TrimSpace(parent[1:len(parent)-1])) > 0 {
+			break
+		}
+		object = jsonparser.Delete(object, path[:i]...)
+	}
+	return object
+}
+
+func containsExt(path []string) bool {
+	for _, key := range path {
+		if key == "ext" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package openrtb_ext
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertDownTo25(t *testing.T) {
+	testCases := []struct {
+		description     string
+		request         string
+		expectedRequest string
+	}{
+		{
+			description:     "No 2.6 Fields",
+			request:         `{"id":"req1","regs":{"ext":{"gdpr":1}},"imp":[{"id":"imp1"}]}`,
+			expectedRequest: `{"id":"req1","regs":{"ext":{"gdpr":1}},"imp":[{"id":"imp1"}]}`,
+		},
+		{
+			description: "Regs",
+			request:     `{"id":"req1","regs":{"coppa":1,"gdpr":1,"us_privacy":"1YNN","gpp":"DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA","gpp_sid":[2]}}`,
+			expectedRequest: `{"id":"req1","regs":{"coppa":1,"ext":{"gdpr":1,"us_privacy":"1YNN",` +
+				`"gpp":"DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA","gpp_sid":[2]}}}`,
+		},
+		{
+			description:     "User",
+			request:         `{"id":"req1","user":{"id":"user1","consent":"BONciguONcjGKADACHENAOLS1rAHDAFAAEAASABQAMwAeACEAFw","eids":[{"source":"adserver.org","uids":[{"id":"tdid"}]}]}}`,
+			expectedRequest: `{"id":"req1","user":{"id":"user1","ext":{"consent":"BONciguONcjGKADACHENAOLS1rAHDAFAAEAASABQAMwAeACEAFw","eids":[{"source":"adserver.org","uids":[{"id":"tdid"}]}]}}}`,
+		},
+		{
+			description:     "Source",
+			request:         `{"id":"req1","source":{"tid":"tid1","schain":{"complete":1,"nodes":[{"asi":"example.com","sid":"1","hp":1}],"ver":"1.0"}}}`,
+			expectedRequest: `{"id":"req1","source":{"tid":"tid1","ext":{"schain":{"complete":1,"nodes":[{"asi":"example.com","sid":"1","hp":1}],"ver":"1.0"}}}}`,
+		},
+		{
+			description:     "Imps",
+			request:         `{"id":"req1","imp":[{"id":"imp1","rwdd":1,"video":{"mimes":["video/mp4"],"podid":"pod1","podseq":1,"slotinpod":2,"rqddurs":[15,30]}},{"id":"imp2","banner":{}}]}`,
+			expectedRequest: `{"id":"req1","imp":[{"id":"imp1","ext":{"prebid":{"is_rewarded_inventory":1}},"video":{"mimes":["video/mp4"],"ext":{"podid":"pod1","podseq":1,"slotinpod":2,"rqddurs":[15,30]}}},{"id":"imp2","banner":{}}]}`,
+		},
+		{
+			description:     "2.6 Fields Replace Ext Fields",
+			request:         `{"id":"req1","regs":{"gdpr":0,"ext":{"gdpr":1,"us_privacy":"1YNN"}},"imp":[{"id":"imp1","rwdd":1,"ext":{"prebid":{"is_rewarded_inventory":0},"bidder":{}}}]}`,
+			expectedRequest: `{"id":"req1","regs":{"ext":{"gdpr":0,"us_privacy":"1YNN"}},"imp":[{"id":"imp1","ext":{"prebid":{"is_rewarded_inventory":1},"bidder":{}}}]}`,
+		},
+		{
+			description:     "Null Fields",
+			request:         `{"id":"req1","regs":{"gdpr":null},"user":null}`,
+			expectedRequest: `{"id":"req1","regs":{"gdpr":null},"user":null}`,
+		},
+		{
+			description:     "Escaped String",
+			request:         `{"id":"req1","user":{"consent":"a\"b"}}`,
+			expectedRequest: `{"id":"req1","user":{"ext":{"consent":"a\"b"}}}`,
+		},
+		{
+			description:     "Malformed Imps",
+			request:         `{"id":"req1","regs":{"gdpr":1},"imp":["imp1"]}`,
+			expectedRequest: `{"id":"req1","regs":{"ext":{"gdpr":1}},"imp":["imp1"]}`,
+		},
+	}
+
+	for _, test := range testCases {
+		request := json.RawMessage(test.request)
+
+		converted, err := ConvertDownTo25(request)
+
+		assert.NoError(t, err, test.description)
+		assert.JSONEq(t, test.expectedRequest, string(converted), test.description)
+		assert.Equal(t, test.request, string(request), "%s: the request shouldn't change", test.description)
+	}
+}
+
+func TestConvertUpTo26(t *testing.T) {
+	testCases := []struct {
+		description     string
+		request         string
+		expectedRequest string
+	}{
+		{
+			description:     "No Ext Fields",
+			request:         `{"id":"req1","regs":{"coppa":1},"imp":[{"id":"imp1"}]}`,
+			expectedRequest: `{"id":"req1","regs":{"coppa":1},"imp":[{"id":"imp1"}]}`,
+		},
+		{
+			description:     "Regs",
+			request:         `{"id":"req1","regs":{"ext":{"gdpr":1,"us_privacy":"1YNN"}}}`,
+			expectedRequest: `{"id":"req1","regs":{"gdpr":1,"us_privacy":"1YNN"}}`,
+		},
+		{
+			description:     "User Keeps Other Ext Fields",
+			request:         `{"id":"req1","user":{"ext":{"consent":"BONciguONcjGKADACHENAOLS1rAHDAFAAEAASABQAMwAeACEAFw","digitrust":{"id":"1"}}}}`,
+			expectedRequest: `{"id":"req1","user":{"consent":"BONciguONcjGKADACHENAOLS1rAHDAFAAEAASABQAMwAeACEAFw","ext":{"digitrust":{"id":"1"}}}}`,
+		},
+		{
+			description:     "Source",
+			request:         `{"id":"req1","source":{"ext":{"schain":{"complete":1,"nodes":[],"ver":"1.0"}}}}`,
+			expectedRequest: `{"id":"req1","source":{"schain":{"complete":1,"nodes":[],"ver":"1.0"}}}`,
+		},
+		{
+			description:     "Imps",
+			request:         `{"id":"req1","imp":[{"id":"imp1","ext":{"prebid":{"is_rewarded_inventory":1}},"video":{"ext":{"podid":"pod1","maxseq":3}}},{"id":"imp2","ext":{"bidder":{}}}]}`,
+			expectedRequest: `{"id":"req1","imp":[{"id":"imp1","rwdd":1,"video":{"podid":"pod1","maxseq":3}},{"id":"imp2","ext":{"bidder":{}}}]}`,
+		},
+	}
+
+	for _, test := range testCases {
+		converted, err := ConvertUpTo26(json.RawMessage(test.request))
+
+		assert.NoError(t, err, test.description)
+		assert.JSONEq(t, test.expectedRequest, string(converted), test.description)
+	}
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	request := `{"id":"req1","regs":{"gdpr":1},"user":{"eids":[{"source":"adserver.org","uids":[{"id":"tdid"}]}]},"imp":[{"id":"imp1","rwdd":1}]}`
+
+	converted, err := ConvertDownTo25(json.RawMessage(request))
+	assert.NoError(t, err)
+	converted, err = ConvertUpTo26(converted)
+	assert.NoError(t, err)
+
+	assert.JSONEq(t, request, string(converted))
+}
@@ -0,0 +1,153 @@
+package openrtb_ext
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Floor schema fields supported in bidrequest.ext.prebid.floors.data.schema.fields
+const (
+	FloorFieldMediaType  string = "mediaType"
+	FloorFieldSize       string = "size"
+	FloorFieldDomain     string = "domain"
+	FloorFieldAdUnitCode string = "adUnitCode"
+	FloorFieldBidder     string = "bidder"
+)
+
+// maxFloorSchemaFields is the number of supported schema fields, which may each be used once.
+const maxFloorSchemaFields = 5
+
+// FloorWildcard matches any value of a schema field.
+const FloorWildcard string = "*"
+
+// DefaultFloorDelimiter separates the schema field values in the keys of a floor rule set.
+const DefaultFloorDelimiter string = "|"
+
+// PriceFloorRules defines the contract for bidrequest.ext.prebid.floors
+type PriceFloorRules struct {
+	Enabled     *bool                   `json:"enabled,omitempty"`
+	FloorMin    float64                 `json:"floormin,omitempty"`
+	FloorMinCur string                  `json:"floormincur,omitempty"`
+	Enforcement *PriceFloorEnforcement  `json:"enforcement,omitempty"`
+	Data        *PriceFloorData         `json:"data,omitempty"`
+	Bidders     map[string]*BidderFloor `json:"bidders,omitempty"`
+}
+
+// PriceFloorEnforcement defines the contract for bidrequest.ext.prebid.floors.enforcement
+type PriceFloorEnforcement struct {
+	EnforcePBS *bool `json:"enforcepbs,omitempty"`
+	FloorDeals *bool `json:"floordeals,omitempty"`
+}
+
+// PriceFloorData defines the contract for bidrequest.ext.prebid.floors.data
+type PriceFloorData struct {
+	Currency string             `json:"currency,omitempty"`
+	Schema   PriceFloorSchema   `json:"schema"`
+	Values   map[string]float64 `json:"values"`
+	Default  float64            `json:"default,omitempty"`
+}
+
+// PriceFloorSchema defines the contract for bidrequest.ext.prebid.floors.data.schema
+type PriceFloorSchema struct {
+	Fields    []string `json:"fields"`
+	Delimiter string   `json:"delimiter,omitempty"`
+}
+
+// BidderFloor defines the contract for bidrequest.ext.prebid.floors.bidders.{bidder}
+//
+// It allows a publisher to raise the floor of a single bidder or to exempt it from enforcement.
+type BidderFloor struct {
+	FloorMin   float64 `json:"floormin,omitempty"`
+	EnforcePBS *bool   `json:"enforcepbs,omitempty"`
+}
+
+// GetEnabled returns whether floors were requested, defaulting to true when not specified.
+func (f *PriceFloorRules) GetEnabled() bool {
+	if f != nil && f.Enabled != nil {
+		return *f.Enabled
+	}
+	return true
+}
+
+// Validate checks the floor rule set for structural problems that would make it unusable.
+func (d *PriceFloorData) Validate() error {
+	if d == nil {
+		return nil
+	}
+	if len(d.Schema.Fields) == 0 {
+		return errors.New("floors.data.schema.fields must contain at least one field")
+	}
+	if len(d.Schema.Fields) > maxFloorSchemaFields {
+		return fmt.Errorf("floors.data.schema.fields must contain at most %d fields", maxFloorSchemaFields)
+	}
+	seen := make(map[string]struct{}, len(d.Schema.Fields))
+	for _, field := range d.Schema.Fields {
+		switch field {
+		case FloorFieldMediaType, FloorFieldSize, FloorFieldDomain, FloorFieldAdUnitCode, FloorFieldBidder:
+		default:
+			return fmt.Errorf("floors.data.schema.fields contains unsupported field '%s'", field)
+		}
+		if _, ok := seen[field]; ok {
+			return fmt.Errorf("floors.data.schema.fields contains duplicate field '%s'", field)
+		}
+		seen[field] = struct{}{}
+	}
+	delimiter := d.GetDelimiter()
+	for key, value := range d.Values {
+		if value < 0 {
+			return fmt.Errorf("floors.data.values[%s] must be non-negative", key)
+		}
+		if len(strings.Split(key, delimiter)) != len(d.Schema.Fields) {
+			return fmt.Errorf("floors.data.values[%s] does not match the %d schema fields", key, len(d.Schema.Fields))
+		}
+	}
+	if d.Default < 0 {
+		return errors.New("floors.data.default must be non-negative")
+	}
+	return nil
+}
+
+// GetDelimiter returns the delimiter used in the rule keys, falling back to the default delimiter.
+func (d *PriceFloorData) GetDelimiter() string {
+	if d.Schema.Delimiter != "" {
+		return d.Schema.Delimiter
+	}
+	return DefaultFloorDelimiter
+}
+
+// UnmarshalJSON lowercases the rule keys so that matching is case insensitive.
+func (d *PriceFloorData) UnmarshalJSON(b []byte) error {
+	type priceFloorDataAlias PriceFloorData // Prevents infinite UnmarshalJSON loops
+	var proxy priceFloorDataAlias
+	if err := json.Unmarshal(b, &proxy); err != nil {
+		return err
+	}
+	if len(proxy.Values) > 0 {
+		values := make(map[string]float64, len(proxy.Values))
+		for key, value := range proxy.Values {
+			values[strings.ToLower(key)] = value
+		}
+		proxy.Values = values
+	}
+	*d = PriceFloorData(proxy)
+	return nil
+}
+
+// ExtResponseFloors defines the contract for bidresponse.ext.prebid.floors
+type ExtResponseFloors struct {
+	Enforced bool                          `json:"enforced"`
+	Imps     map[string][]ExtResponseFloor `json:"imps,omitempty"`
+}
+
+// ExtResponseFloor defines the contract for bidresponse.ext.prebid.floors.imps.{impID}[i]
+//
+// Location reports where the floor was resolved from: "request", "account" or "imp".
+type ExtResponseFloor struct {
+	Bidder   BidderName `json:"bidder"`
+	Floor    float64    `json:"floor"`
+	Currency string     `json:"currency"`
+	Rule     string     `json:"rule,omitempty"`
+	Location string     `json:"location"`
+}
@@ -0,0 +1,86 @@
+package openrtb_ext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceFloorDataValidate(t *testing.T) {
+	testCases := []struct {
+		description   string
+		data          *PriceFloorData
+		expectedError string
+	}{
+		{
+			description: "Nil",
+			data:        nil,
+		},
+		{
+			description: "Valid",
+			data: &PriceFloorData{
+				Schema: PriceFloorSchema{Fields: []string{"mediaType", "size", "domain", "adUnitCode", "bidder"}},
+				Values: map[string]float64{"banner|300x250|*|*|appnexus": 1},
+			},
+		},
+		{
+			description:   "No Fields",
+			data:          &PriceFloorData{},
+			expectedError: "floors.data.schema.fields must contain at least one field",
+		},
+		{
+			description:   "Unsupported Field",
+			data:          &PriceFloorData{Schema: PriceFloorSchema{Fields: []string{"unknown"}}},
+			expectedError: "floors.data.schema.fields contains unsupported field 'unknown'",
+		},
+		{
+			description:   "Duplicate Field",
+			data:          &PriceFloorData{Schema: PriceFloorSchema{Fields: []string{"mediaType", "size", "mediaType"}}},
+			expectedError: "floors.data.schema.fields contains duplicate field 'mediaType'",
+		},
+		{
+			description: "Too Many Fields",
+			data: &PriceFloorData{Schema: PriceFloorSchema{Fields: []string{
+				"mediaType", "mediaType", "mediaType", "mediaType", "mediaType", "mediaType", "mediaType", "mediaType",
+				"mediaType", "mediaType", "mediaType", "mediaType", "mediaType", "mediaType", "mediaType", "mediaType",
+				"mediaType", "mediaType", "mediaType", "mediaType", "mediaType", "mediaType", "mediaType", "mediaType",
+				"mediaType", "mediaType", "mediaType", "mediaType", "mediaType", "mediaType", "mediaType", "mediaType",
+				"mediaType", "mediaType", "mediaType", "mediaType", "mediaType", "mediaType", "mediaType", "mediaType",
+			}}},
+			expectedError: "floors.data.schema.fields must contain at most 5 fields",
+		},
+		{
+			description: "Key Doesn't Match The Fields",
+			data: &PriceFloorData{
+				Schema: PriceFloorSchema{Fields: []string{"mediaType", "size"}},
+				Values: map[string]float64{"banner": 1},
+			},
+			expectedError: "floors.data.values[banner] does not match the 2 schema fields",
+		},
+		{
+			description: "Negative Value",
+			data: &PriceFloorData{
+				Schema: PriceFloorSchema{Fields: []string{"mediaType"}},
+				Values: map[string]float64{"banner": -1},
+			},
+			expectedError: "floors.data.values[banner] must be non-negative",
+		},
+		{
+			description: "Negative Default",
+			data: &PriceFloorData{
+				Schema:  PriceFloorSchema{Fields: []string{"mediaType"}},
+				Default: -1,
+			},
+			expectedError: "floors.data.default must be non-negative",
+		},
+	}
+
+	for _, test := range testCases {
+		err := test.data.Validate()
+		if test.expectedError == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		}
+	}
+}
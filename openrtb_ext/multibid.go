@@ -0,0 +1,16 @@
+package openrtb_ext
+
+// MaxBidsPerBidder is the largest number of bids per imp a single bidder may be configured to return.
+const MaxBidsPerBidder int = 9
+
+// ExtMultiBid defines the contract for bidrequest.ext.prebid.multibid[i]
+//
+// Either Bidder or Bidders should be set. TargetBidderCodePrefix is only allowed together with Bidder.
+// The runner-up bids of the bidder get targeting keys as if they came from a bidder named
+// {prefix}{rank}, e.g. hb_pb_apn2 for the second best bid.
+type ExtMultiBid struct {
+	Bidder                 string   `json:"bidder,omitempty"`
+	Bidders                []string `json:"bidders,omitempty"`
+	MaxBids                *int     `json:"maxbids,omitempty"`
+	TargetBidderCodePrefix string   `json:"targetbiddercodeprefix,omitempty"`
+}
@@ -25,6 +25,13 @@ type ExtRequestPrebid struct {
 	Targeting            *ExtRequestTargeting      `json:"targeting,omitempty"`
 	SupportDeals         bool                      `json:"supportdeals,omitempty"`
 	Debug                bool                      `json:"debug,omitempty"`
+	Floors               *PriceFloorRules          `json:"floors,omitempty"`
+	MultiBid             []*ExtMultiBid            `json:"multibid,omitempty"`
+	TrafficShaping       *ExtRequestTrafficShaping `json:"trafficshaping,omitempty"`
+	// ReturnAllBidStatus adds the bids which were dropped or lost the auction to bidresponse.ext.prebid.seatnonbid
+	ReturnAllBidStatus bool                            `json:"returnallbidstatus,omitempty"`
+	Data               *ExtRequestPrebidData           `json:"data,omitempty"`
+	BidderConfig       []*ExtRequestPrebidBidderConfig `json:"bidderconfig,omitempty"`
 
 	// NoSale specifies bidders with whom the publisher has a legal relationship where the
 	// passing of personally identifiable information doesn't constitute a sale per CCPA law.
@@ -32,6 +39,47 @@ type ExtRequestPrebid struct {
 	NoSale []string `json:"nosale,omitempty"`
 }
 
+// ExtRequestTrafficShaping defines the contract for bidrequest.ext.prebid.trafficshaping
+type ExtRequestTrafficShaping struct {
+	// Debug bypasses traffic shaping for this request. The bidders which would have been skipped
+	// are called anyway and reported with a warning.
+	Debug bool `json:"debug,omitempty"`
+}
+
+// ExtRequestPrebidData defines the contract for bidrequest.ext.prebid.data
+type ExtRequestPrebidData struct {
+	// Bidders are the only bidders which receive the first party data in site.ext.data, app.ext.data and user.ext.data.
+	// All the bidders receive it if the list is empty. The "*" entry allows every bidder.
+	Bidders []string `json:"bidders,omitempty"`
+	// EidPermissions restrict the user.ext.eids sources to some bidders. The sources without a rule are sent to all of them.
+	EidPermissions []ExtRequestPrebidDataEidPermission `json:"eidpermissions,omitempty"`
+}
+
+// ExtRequestPrebidDataEidPermission defines the contract for bidrequest.ext.prebid.data.eidpermissions[i]
+type ExtRequestPrebidDataEidPermission struct {
+	Source string `json:"source"`
+	// Bidders are the bidders allowed to receive the user IDs of the source. The "*" entry allows every bidder.
+	Bidders []string `json:"bidders"`
+}
+
+// ExtRequestPrebidBidderConfig defines the contract for bidrequest.ext.prebid.bidderconfig
+type ExtRequestPrebidBidderConfig struct {
+	Bidders []string      `json:"bidders"`
+	Config  *BidderConfig `json:"config"`
+}
+
+// BidderConfig defines the contract for bidrequest.ext.prebid.bidderconfig.config
+type BidderConfig struct {
+	ORTB2 *ORTB2 `json:"ortb2,omitempty"`
+}
+
+// ORTB2 holds the bidder specific first party data, which is merged into the site, app and user of the bidder's request.
+type ORTB2 struct {
+	Site json.RawMessage `json:"site,omitempty"`
+	App  json.RawMessage `json:"app,omitempty"`
+	User json.RawMessage `json:"user,omitempty"`
+}
+
 // ExtRequestPrebid defines the contract for bidrequest.ext.prebid.schains
 type ExtRequestPrebidSChain struct {
 	Bidders []string                     `json:"bidders,omitempty"`
@@ -38,7 +38,31 @@ type ExtResponseSyncData struct {
 
 // ExtResponsePrebid defines the contract for bidresponse.ext.prebid
 type ExtResponsePrebid struct {
-	AuctionTimestamp int64 `json:"auctiontimestamp,omitempty"`
+	AuctionTimestamp int64              `json:"auctiontimestamp,omitempty"`
+	Floors           *ExtResponseFloors `json:"floors,omitempty"`
+	Modules          *ExtModules        `json:"modules,omitempty"`
+	SeatNonBid       []SeatNonBid       `json:"seatnonbid,omitempty"`
+}
+
+// SeatNonBid defines the contract for bidresponse.ext.prebid.seatnonbid
+type SeatNonBid struct {
+	Seat   string   `json:"seat"`
+	NonBid []NonBid `json:"nonbid"`
+}
+
+// NonBid defines the contract for bidresponse.ext.prebid.seatnonbid[i].nonbid[j]. The status code is one of the
+// errortypes non bid codes, and the price is the one the bid had when it was dropped.
+type NonBid struct {
+	ID         string  `json:"id"`
+	ImpID      string  `json:"impid"`
+	Price      float64 `json:"price"`
+	StatusCode int     `json:"statuscode"`
+}
+
+// ExtModules defines the contract for bidresponse.ext.prebid.modules. The messages are keyed by module code and then by hook implementation code.
+type ExtModules struct {
+	Errors   map[string]map[string][]string `json:"errors,omitempty"`
+	Warnings map[string]map[string][]string `json:"warnings,omitempty"`
 }
 
 // ExtUserSync defines the contract for bidresponse.ext.usersync.{bidder}.syncs[i]
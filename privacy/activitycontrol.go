@@ -0,0 +1,189 @@
+package privacy
+
+import (
+	"strings"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/privacy/gpp"
+)
+
+// Activity is an action which a component of Prebid Server takes on the user data.
+type Activity int
+
+const (
+	// ActivitySyncUser syncs the user ID of a bidder.
+	ActivitySyncUser Activity = iota
+	// ActivityFetchBids calls a bidder in the auction.
+	ActivityFetchBids
+	// ActivityTransmitUserFPD sends the user IDs, eids, demographics and device IDs to a bidder.
+	ActivityTransmitUserFPD
+	// ActivityTransmitPreciseGeo sends the precise geo and IP addresses to a bidder.
+	ActivityTransmitPreciseGeo
+)
+
+// Component is the part of Prebid Server, such as a bidder, which takes an activity.
+type Component struct {
+	Type string
+	Name string
+}
+
+// ActivityRequest holds the properties of the request which the conditions of the activity rules match.
+type ActivityRequest struct {
+	GPPSectionIDs []int8
+	// Country is the ISO-3166-1-alpha-3 code of the country of the user.
+	Country string
+	Region  string
+}
+
+// NewActivityRequest returns the properties of the OpenRTB bid request which the activity rules match. An invalid
+// GPP section list is ignored, for the privacy policies to report it.
+func NewActivityRequest(req *openrtb.BidRequest) ActivityRequest {
+	activityRequest := ActivityRequest{}
+	if gppPolicy, err := gpp.ReadFromRequest(req); err == nil {
+		activityRequest.GPPSectionIDs = gppPolicy.SectionIDs
+	}
+	if req != nil && req.Device != nil && req.Device.Geo != nil {
+		activityRequest.Country = req.Device.Geo.Country
+		activityRequest.Region = req.Device.Geo.Region
+	}
+	return activityRequest
+}
+
+// ActivityControl decides whether the components of Prebid Server may take an activity, per the rules configured by
+// the account. The zero value allows all activities.
+type ActivityControl struct {
+	plans map[Activity]activityPlan
+}
+
+type activityPlan struct {
+	defaultAllow bool
+	rules        []activityRule
+}
+
+type activityRule struct {
+	allow          bool
+	componentNames map[string]struct{}
+	componentTypes map[string]struct{}
+	gppSectionIDs  []int8
+	geos           []geoCondition
+}
+
+type geoCondition struct {
+	country string
+	region  string
+}
+
+// NewActivityControl builds the activity control of the account privacy config.
+func NewActivityControl(privacyConfig *config.AccountPrivacy) ActivityControl {
+	if privacyConfig == nil {
+		return ActivityControl{}
+	}
+
+	activities := privacyConfig.AllowActivities
+	return ActivityControl{
+		plans: map[Activity]activityPlan{
+			ActivitySyncUser:           newActivityPlan(activities.SyncUser),
+			ActivityFetchBids:          newActivityPlan(activities.FetchBids),
+			ActivityTransmitUserFPD:    newActivityPlan(activities.TransmitUserFPD),
+			ActivityTransmitPreciseGeo: newActivityPlan(activities.TransmitPreciseGeo),
+		},
+	}
+}
+
+func newActivityPlan(activity config.AccountActivity) activityPlan {
+	plan := activityPlan{
+		defaultAllow: activity.Default == nil || *activity.Default,
+		rules:        make([]activityRule, 0, len(activity.Rules)),
+	}
+	for _, rule := range activity.Rules {
+		plan.rules = append(plan.rules, activityRule{
+			allow:          rule.Allow,
+			componentNames: newLowerCaseSet(rule.Condition.ComponentName),
+			componentTypes: newLowerCaseSet(rule.Condition.ComponentType),
+			gppSectionIDs:  rule.Condition.GPPSID,
+			geos:           newGeoConditions(rule.Condition.Geo),
+		})
+	}
+	return plan
+}
+
+func newLowerCaseSet(values []string) map[string]struct{} {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		set[strings.ToLower(value)] = struct{}{}
+	}
+	return set
+}
+
+func newGeoConditions(geos []string) []geoCondition {
+	conditions := make([]geoCondition, 0, len(geos))
+	for _, geo := range geos {
+		parts := strings.SplitN(geo, ".", 2)
+		condition := geoCondition{country: parts[0]}
+		if len(parts) == 2 {
+			condition.region = parts[1]
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions
+}
+
+// Allow returns true when the component may take the activity on the user data of the request.
+func (c ActivityControl) Allow(activity Activity, component Component, request ActivityRequest) bool {
+	plan, found := c.plans[activity]
+	if !found {
+		return true
+	}
+
+	for _, rule := range plan.rules {
+		if rule.matches(component, request) {
+			return rule.allow
+		}
+	}
+	return plan.defaultAllow
+}
+
+func (r activityRule) matches(component Component, request ActivityRequest) bool {
+	return matchesSet(r.componentNames, component.Name) &&
+		matchesSet(r.componentTypes, component.Type) &&
+		r.matchesGPPSectionIDs(request.GPPSectionIDs) &&
+		r.matchesGeo(request.Country, request.Region)
+}
+
+func matchesSet(set map[string]struct{}, value string) bool {
+	if set == nil {
+		return true
+	}
+	_, found := set[strings.ToLower(value)]
+	return found
+}
+
+func (r activityRule) matchesGPPSectionIDs(sectionIDs []int8) bool {
+	if len(r.gppSectionIDs) == 0 {
+		return true
+	}
+	for _, ruleID := range r.gppSectionIDs {
+		for _, id := range sectionIDs {
+			if ruleID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r activityRule) matchesGeo(country, region string) bool {
+	if len(r.geos) == 0 {
+		return true
+	}
+	for _, geo := range r.geos {
+		if strings.EqualFold(geo.country, country) && (geo.region == "" || strings.EqualFold(geo.region, region)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package privacy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewActivityRequest(t *testing.T) {
+	testCases := []struct {
+		description     string
+		request         *openrtb.BidRequest
+		expectedRequest ActivityRequest
+	}{
+		{
+			description:     "Nil Request",
+			request:         nil,
+			expectedRequest: ActivityRequest{},
+		},
+		{
+			description: "GPP Sections And Geo",
+			request: &openrtb.BidRequest{
+				Regs:   &openrtb.Regs{Ext: json.RawMessage(`{"gpp":"DBABRg~BAYAAA","gpp_sid":[9]}`)},
+				Device: &openrtb.Device{Geo: &openrtb.Geo{Country: "USA", Region: "VA"}},
+			},
+			expectedRequest: ActivityRequest{GPPSectionIDs: []int8{9}, Country: "USA", Region: "VA"},
+		},
+		{
+			description: "Malformed GPP Sections Ignored",
+			request: &openrtb.BidRequest{
+				Regs:   &openrtb.Regs{Ext: json.RawMessage(`{"gpp_sid":"9"}`)},
+				Device: &openrtb.Device{},
+			},
+			expectedRequest: ActivityRequest{},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedRequest, NewActivityRequest(test.request), test.description)
+	}
+}
+
+func TestActivityControlAllow(t *testing.T) {
+	trueValue, falseValue := true, false
+	appnexus := Component{Type: config.ComponentTypeBidder, Name: "appnexus"}
+	rubicon := Component{Type: config.ComponentTypeBidder, Name: "rubicon"}
+	virginia := ActivityRequest{GPPSectionIDs: []int8{9}, Country: "USA", Region: "VA"}
+
+	testCases := []struct {
+		description    string
+		privacyConfig  *config.AccountPrivacy
+		activity       Activity
+		component      Component
+		request        ActivityRequest
+		expectedResult bool
+	}{
+		{
+			description:    "No Config",
+			privacyConfig:  nil,
+			activity:       ActivitySyncUser,
+			component:      appnexus,
+			expectedResult: true,
+		},
+		{
+			description:    "No Rules",
+			privacyConfig:  &config.AccountPrivacy{},
+			activity:       ActivityFetchBids,
+			component:      appnexus,
+			expectedResult: true,
+		},
+		{
+			description: "Default Denies",
+			privacyConfig: &config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				FetchBids: config.AccountActivity{Default: &falseValue},
+			}},
+			activity:       ActivityFetchBids,
+			component:      appnexus,
+			expectedResult: false,
+		},
+		{
+			description: "Default Of Another Activity",
+			privacyConfig: &config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				FetchBids: config.AccountActivity{Default: &falseValue},
+			}},
+			activity:       ActivitySyncUser,
+			component:      appnexus,
+			expectedResult: true,
+		},
+		{
+			description: "Component Name Matches",
+			privacyConfig: &config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				SyncUser: config.AccountActivity{Rules: []config.AccountActivityRule{
+					{Condition: config.AccountActivityCondition{ComponentName: []string{"AppNexus"}}, Allow: false},
+				}},
+			}},
+			activity:       ActivitySyncUser,
+			component:      appnexus,
+			expectedResult: false,
+		},
+		{
+			description: "Component Name Doesn't Match",
+			privacyConfig: &config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				SyncUser: config.AccountActivity{Rules: []config.AccountActivityRule{
+					{Condition: config.AccountActivityCondition{ComponentName: []string{"appnexus"}}, Allow: false},
+				}},
+			}},
+			activity:       ActivitySyncUser,
+			component:      rubicon,
+			expectedResult: true,
+		},
+		{
+			description: "Component Type Matches",
+			privacyConfig: &config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				TransmitUserFPD: config.AccountActivity{Rules: []config.AccountActivityRule{
+					{Condition: config.AccountActivityCondition{ComponentType: []string{"analytics", "bidder"}}, Allow: false},
+				}},
+			}},
+			activity:       ActivityTransmitUserFPD,
+			component:      rubicon,
+			expectedResult: false,
+		},
+		{
+			description: "First Matching Rule Decides",
+			privacyConfig: &config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				FetchBids: config.AccountActivity{Default: &falseValue, Rules: []config.AccountActivityRule{
+					{Condition: config.AccountActivityCondition{ComponentName: []string{"appnexus"}}, Allow: true},
+					{Condition: config.AccountActivityCondition{ComponentType: []string{"bidder"}}, Allow: false},
+				}},
+			}},
+			activity:       ActivityFetchBids,
+			component:      appnexus,
+			expectedResult: true,
+		},
+		{
+			description: "No Matching Rule Falls Back To Default",
+			privacyConfig: &config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				FetchBids: config.AccountActivity{Default: &trueValue, Rules: []config.AccountActivityRule{
+					{Condition: config.AccountActivityCondition{ComponentName: []string{"appnexus"}}, Allow: false},
+				}},
+			}},
+			activity:       ActivityFetchBids,
+			component:      rubicon,
+			expectedResult: true,
+		},
+		{
+			description: "GPP Section Matches",
+			privacyConfig: &config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				TransmitPreciseGeo: config.AccountActivity{Rules: []config.AccountActivityRule{
+					{Condition: config.AccountActivityCondition{GPPSID: []int8{7, 9}}, Allow: false},
+				}},
+			}},
+			activity:       ActivityTransmitPreciseGeo,
+			component:      appnexus,
+			request:        virginia,
+			expectedResult: false,
+		},
+		{
+			description: "GPP Section Doesn't Match",
+			privacyConfig: &config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				TransmitPreciseGeo: config.AccountActivity{Rules: []config.AccountActivityRule{
+					{Condition: config.AccountActivityCondition{GPPSID: []int8{2}}, Allow: false},
+				}},
+			}},
+			activity:       ActivityTransmitPreciseGeo,
+			component:      appnexus,
+			request:        virginia,
+			expectedResult: true,
+		},
+		{
+			description: "Country Matches",
+			privacyConfig: &config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				FetchBids: config.AccountActivity{Rules: []config.AccountActivityRule{
+					{Condition: config.AccountActivityCondition{Geo: []string{"usa"}}, Allow: false},
+				}},
+			}},
+			activity:       ActivityFetchBids,
+			component:      appnexus,
+			request:        virginia,
+			expectedResult: false,
+		},
+		{
+			description: "Region Matches",
+			privacyConfig: &config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				FetchBids: config.AccountActivity{Rules: []config.AccountActivityRule{
+					{Condition: config.AccountActivityCondition{Geo: []string{"USA.CA", "USA.VA"}}, Allow: false},
+				}},
+			}},
+			activity:       ActivityFetchBids,
+			component:      appnexus,
+			request:        virginia,
+			expectedResult: false,
+		},
+		{
+			description: "Region Doesn't Match",
+			privacyConfig: &config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				FetchBids: config.AccountActivity{Rules: []config.AccountActivityRule{
+					{Condition: config.AccountActivityCondition{Geo: []string{"USA.CA"}}, Allow: false},
+				}},
+			}},
+			activity:       ActivityFetchBids,
+			component:      appnexus,
+			request:        virginia,
+			expectedResult: true,
+		},
+		{
+			description: "All Of The Condition Must Match",
+			privacyConfig: &config.AccountPrivacy{AllowActivities: config.AccountActivities{
+				FetchBids: config.AccountActivity{Rules: []config.AccountActivityRule{
+					{Condition: config.AccountActivityCondition{ComponentName: []string{"appnexus"}, Geo: []string{"CAN"}}, Allow: false},
+				}},
+			}},
+			activity:       ActivityFetchBids,
+			component:      appnexus,
+			request:        virginia,
+			expectedResult: true,
+		},
+	}
+
+	for _, test := range testCases {
+		activityControl := NewActivityControl(test.privacyConfig)
+		result := activityControl.Allow(test.activity, test.component, test.request)
+		assert.Equal(t, test.expectedResult, result, test.description)
+	}
+}
+
+func TestActivityControlZeroValue(t *testing.T) {
+	assert.True(t, ActivityControl{}.Allow(ActivitySyncUser, Component{Type: config.ComponentTypeBidder, Name: "appnexus"}, ActivityRequest{}))
+}
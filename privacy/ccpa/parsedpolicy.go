@@ -46,7 +46,7 @@ func (p Policy) Parse(validBidders map[string]struct{}) (ParsedPolicy, error) {
 		return ParsedPolicy{}, &errortypes.InvalidPrivacyConsent{Message: msg}
 	}
 
-	noSaleForAllBidders, noSaleSpecificBidders, err := parseNoSaleBidders(p.NoSaleBidders, validBidders)
+	noSaleForAllBidders, noSaleSpecificBidders, err := ParseNoSaleBidders(p.NoSaleBidders, validBidders)
 	if err != nil {
 		return ParsedPolicy{}, fmt.Errorf("request.ext.prebid.nosale is invalid: %s", err.Error())
 	}
@@ -92,7 +92,9 @@ func parseConsent(consent string) (consentOptOutSale bool, err error) {
 	return consent[indexOptOutSale] == ccpaYes, nil
 }
 
-func parseNoSaleBidders(noSaleBidders []string, validBidders map[string]struct{}) (noSaleForAllBidders bool, noSaleSpecificBidders map[string]struct{}, err error) {
+// ParseNoSaleBidders validates the bidders of request.ext.prebid.nosale, which the opt out of the sale of the user's
+// data doesn't apply to. A single "*" stands for all the bidders.
+func ParseNoSaleBidders(noSaleBidders []string, validBidders map[string]struct{}) (noSaleForAllBidders bool, noSaleSpecificBidders map[string]struct{}, err error) {
 	noSaleSpecificBidders = make(map[string]struct{})
 
 	if len(noSaleBidders) == 1 && noSaleBidders[0] == allBiddersMarker {
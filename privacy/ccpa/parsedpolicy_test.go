@@ -253,7 +253,7 @@ func TestParseNoSaleBidders(t *testing.T) {
 			validBiddersMap[v] = struct{}{}
 		}
 
-		resultNoSaleForAllBidders, resultNoSaleSpecificBidders, err := parseNoSaleBidders(test.noSaleBidders, validBiddersMap)
+		resultNoSaleForAllBidders, resultNoSaleSpecificBidders, err := ParseNoSaleBidders(test.noSaleBidders, validBiddersMap)
 
 		if test.expectedError == "" {
 			assert.NoError(t, err, test.description+":err")
@@ -11,11 +11,19 @@ type Enforcement struct {
 	GDPRGeo bool
 	GDPRID  bool
 	LMT     bool
+	// USState is set when the user opted out of the sale of their data or of targeted advertising under the
+	// privacy law of a US state, which removes the user ids, eids and precise geo.
+	USState bool
+	// UFPD is set when the account denies the transmission of the user first party data, which removes the user
+	// ids, eids, demographics, first party data and device ids.
+	UFPD bool
+	// PreciseGeo is set when the account denies the transmission of the precise geo, which also truncates the IPs.
+	PreciseGeo bool
 }
 
 // Any returns true if at least one privacy policy requires enforcement.
 func (e Enforcement) Any() bool {
-	return e.CCPA || e.COPPA || e.GDPRGeo || e.GDPRID || e.LMT
+	return e.CCPA || e.COPPA || e.GDPRGeo || e.GDPRID || e.LMT || e.USState || e.UFPD || e.PreciseGeo
 }
 
 // Apply cleans personally identifiable information from an OpenRTB bid request.
@@ -31,7 +39,7 @@ func (e Enforcement) apply(bidRequest *openrtb.BidRequest, scrubber Scrubber) {
 }
 
 func (e Enforcement) getDeviceIDScrubStrategy() ScrubStrategyDeviceID {
-	if e.COPPA || e.GDPRID || e.CCPA || e.LMT {
+	if e.COPPA || e.GDPRID || e.CCPA || e.LMT || e.UFPD {
 		return ScrubStrategyDeviceIDAll
 	}
 
@@ -39,7 +47,7 @@ func (e Enforcement) getDeviceIDScrubStrategy() ScrubStrategyDeviceID {
 }
 
 func (e Enforcement) getIPv4ScrubStrategy() ScrubStrategyIPV4 {
-	if e.COPPA || e.GDPRGeo || e.CCPA || e.LMT {
+	if e.COPPA || e.GDPRGeo || e.CCPA || e.LMT || e.USState || e.PreciseGeo {
 		return ScrubStrategyIPV4Lowest8
 	}
 
@@ -51,7 +59,7 @@ func (e Enforcement) getIPv6ScrubStrategy() ScrubStrategyIPV6 {
 		return ScrubStrategyIPV6Lowest32
 	}
 
-	if e.GDPRGeo || e.CCPA || e.LMT {
+	if e.GDPRGeo || e.CCPA || e.LMT || e.USState || e.PreciseGeo {
 		return ScrubStrategyIPV6Lowest16
 	}
 
@@ -63,7 +71,7 @@ func (e Enforcement) getGeoScrubStrategy() ScrubStrategyGeo {
 		return ScrubStrategyGeoFull
 	}
 
-	if e.GDPRGeo || e.CCPA || e.LMT {
+	if e.GDPRGeo || e.CCPA || e.LMT || e.USState || e.PreciseGeo {
 		return ScrubStrategyGeoReducedPrecision
 	}
 
@@ -71,11 +79,15 @@ func (e Enforcement) getGeoScrubStrategy() ScrubStrategyGeo {
 }
 
 func (e Enforcement) getUserScrubStrategy() ScrubStrategyUser {
+	if e.UFPD {
+		return ScrubStrategyUserIDDemographicAndFPD
+	}
+
 	if e.COPPA {
 		return ScrubStrategyUserIDAndDemographic
 	}
 
-	if e.CCPA || e.LMT {
+	if e.CCPA || e.LMT || e.USState {
 		return ScrubStrategyUserID
 	}
 
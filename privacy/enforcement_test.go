@@ -1,6 +1,7 @@
 package privacy
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/mxmCherry/openrtb"
@@ -36,6 +37,21 @@ func TestAny(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			description: "US State Only",
+			enforcement: Enforcement{
+				USState: true,
+			},
+			expected: true,
+		},
+		{
+			description: "Activities Only",
+			enforcement: Enforcement{
+				UFPD:       true,
+				PreciseGeo: true,
+			},
+			expected: true,
+		},
 		{
 			description: "Mixed",
 			enforcement: Enforcement{
@@ -178,6 +194,47 @@ func TestApply(t *testing.T) {
 			expectedUser:       ScrubStrategyUserID,
 			expectedUserGeo:    ScrubStrategyGeoReducedPrecision,
 		},
+		{
+			description: "US State Only",
+			enforcement: Enforcement{
+				CCPA:    false,
+				COPPA:   false,
+				GDPRGeo: false,
+				GDPRID:  false,
+				LMT:     false,
+				USState: true,
+			},
+			expectedDeviceID:   ScrubStrategyDeviceIDNone,
+			expectedDeviceIPv4: ScrubStrategyIPV4Lowest8,
+			expectedDeviceIPv6: ScrubStrategyIPV6Lowest16,
+			expectedDeviceGeo:  ScrubStrategyGeoReducedPrecision,
+			expectedUser:       ScrubStrategyUserID,
+			expectedUserGeo:    ScrubStrategyGeoReducedPrecision,
+		},
+		{
+			description: "UFPD Only",
+			enforcement: Enforcement{
+				UFPD: true,
+			},
+			expectedDeviceID:   ScrubStrategyDeviceIDAll,
+			expectedDeviceIPv4: ScrubStrategyIPV4None,
+			expectedDeviceIPv6: ScrubStrategyIPV6None,
+			expectedDeviceGeo:  ScrubStrategyGeoNone,
+			expectedUser:       ScrubStrategyUserIDDemographicAndFPD,
+			expectedUserGeo:    ScrubStrategyGeoNone,
+		},
+		{
+			description: "Precise Geo Only",
+			enforcement: Enforcement{
+				PreciseGeo: true,
+			},
+			expectedDeviceID:   ScrubStrategyDeviceIDNone,
+			expectedDeviceIPv4: ScrubStrategyIPV4Lowest8,
+			expectedDeviceIPv6: ScrubStrategyIPV6Lowest16,
+			expectedDeviceGeo:  ScrubStrategyGeoReducedPrecision,
+			expectedUser:       ScrubStrategyUserNone,
+			expectedUserGeo:    ScrubStrategyGeoReducedPrecision,
+		},
 		{
 			description: "Interactions: COPPA + GDPR Full",
 			enforcement: Enforcement{
@@ -194,6 +251,19 @@ func TestApply(t *testing.T) {
 			expectedUser:       ScrubStrategyUserIDAndDemographic,
 			expectedUserGeo:    ScrubStrategyGeoFull,
 		},
+		{
+			description: "Interactions: COPPA + UFPD",
+			enforcement: Enforcement{
+				COPPA: true,
+				UFPD:  true,
+			},
+			expectedDeviceID:   ScrubStrategyDeviceIDAll,
+			expectedDeviceIPv4: ScrubStrategyIPV4Lowest8,
+			expectedDeviceIPv6: ScrubStrategyIPV6Lowest32,
+			expectedDeviceGeo:  ScrubStrategyGeoFull,
+			expectedUser:       ScrubStrategyUserIDDemographicAndFPD,
+			expectedUserGeo:    ScrubStrategyGeoFull,
+		},
 	}
 
 	for _, test := range testCases {
@@ -216,6 +286,27 @@ func TestApply(t *testing.T) {
 	}
 }
 
+func TestApplyUFPD(t *testing.T) {
+	req := &openrtb.BidRequest{
+		User: &openrtb.User{
+			ID:       "anyID",
+			BuyerUID: "anyBuyerUID",
+			Yob:      42,
+			Gender:   "anyGender",
+			Keywords: "anyKeywords",
+			Data:     []openrtb.Data{{ID: "anyData"}},
+			Ext:      json.RawMessage(`{"anyExisting":42,"data":{"anyData":42},"eids":[{"source":"anySource"}]}`),
+		},
+	}
+
+	Enforcement{UFPD: true}.Apply(req)
+
+	expectedUser := &openrtb.User{
+		Ext: json.RawMessage(`{"anyExisting":42}`),
+	}
+	assert.Equal(t, expectedUser, req.User)
+}
+
 func TestApplyNoneApplicable(t *testing.T) {
 	req := &openrtb.BidRequest{}
 
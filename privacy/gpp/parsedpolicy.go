@@ -0,0 +1,291 @@
+package gpp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/privacy/ccpa"
+)
+
+const (
+	headerType        = 3
+	sectionSeparator  = "~"
+	segmentSeparator  = "."
+	base64URLAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	maxSectionID      = 127
+	// maxFibonacciBits is the length of the longest Fibonacci code of a section ID, with its terminating 1 bit.
+	maxFibonacciBits = 12
+)
+
+// The opt out of the sale of the USPv1 section, which holds a US Privacy string.
+const (
+	uspIndexOptOutSale = 2
+	uspYes             = 'Y'
+)
+
+// OptedOut is the value of the 2 bit opt out fields of the US sections when the user opted out.
+const OptedOut = 1
+
+// usSectionOptOuts are the bit offsets, in the core segment of the US sections, of the 2 bit fields which opt the
+// user out of the sale of their data, its sharing and targeted advertising. The state sections of Virginia, Colorado,
+// Utah and Connecticut are enforced per state by the usstate package.
+var usSectionOptOuts = map[int8][]uint{
+	SectionUSNat: {18, 20, 22},
+	SectionUSCA:  {12, 14},
+}
+
+// ParsedPolicy represents parsed and validated GPP regulatory information. Use this struct to make enforcement
+// decisions.
+type ParsedPolicy struct {
+	usSectionApplies      bool
+	usOptOut              bool
+	tcfEUv2Consent        string
+	noSaleForAllBidders   bool
+	noSaleSpecificBidders map[string]struct{}
+}
+
+// Parse returns a parsed and validated ParsedPolicy intended for use in enforcement decisions. Only the sections
+// which apply to the request are read. The no sale bidders are validated against the valid bidders, as by CCPA.
+func (p Policy) Parse(validBidders map[string]struct{}) (ParsedPolicy, error) {
+	sections, err := p.Sections()
+	if err != nil {
+		return ParsedPolicy{}, err
+	}
+
+	parsed := ParsedPolicy{}
+	if len(p.NoSaleBidders) > 0 {
+		parsed.noSaleForAllBidders, parsed.noSaleSpecificBidders, err = ccpa.ParseNoSaleBidders(p.NoSaleBidders, validBidders)
+		if err != nil {
+			return ParsedPolicy{}, fmt.Errorf("request.ext.prebid.nosale is invalid: %s", err.Error())
+		}
+	}
+	for id, section := range sections {
+		switch id {
+		case SectionTCFEUv2:
+			parsed.tcfEUv2Consent = section
+		case SectionUSPv1:
+			if section == "" || !ccpa.ValidateConsent(section) {
+				return ParsedPolicy{}, invalidConsent("section %d is not a valid US Privacy string", id)
+			}
+			parsed.usSectionApplies = true
+			parsed.usOptOut = parsed.usOptOut || section[uspIndexOptOutSale] == uspYes
+		case SectionUSNat, SectionUSCA:
+			optOut, err := parseUSSection(id, section)
+			if err != nil {
+				return ParsedPolicy{}, invalidConsent("section %d is invalid: %v", id, err)
+			}
+			parsed.usSectionApplies = true
+			parsed.usOptOut = parsed.usOptOut || optOut
+		}
+	}
+	return parsed, nil
+}
+
+// Sections returns the sections of the GPP string which apply to the request, by their ID.
+func (p Policy) Sections() (map[int8]string, error) {
+	if p.Consent == "" {
+		return nil, nil
+	}
+
+	sections := strings.Split(p.Consent, sectionSeparator)
+	sectionIDs, err := parseHeader(sections[0])
+	if err != nil {
+		return nil, invalidConsent("header is invalid: %v", err)
+	}
+	if len(sectionIDs) != len(sections)-1 {
+		return nil, invalidConsent("has %d sections but its header lists %d", len(sections)-1, len(sectionIDs))
+	}
+
+	applicableIDs := make(map[int8]struct{}, len(p.SectionIDs))
+	for _, id := range p.SectionIDs {
+		applicableIDs[id] = struct{}{}
+	}
+
+	applicableSections := make(map[int8]string, len(p.SectionIDs))
+	for i, id := range sectionIDs {
+		if _, applies := applicableIDs[id]; applies {
+			applicableSections[id] = sections[i+1]
+		}
+	}
+	return applicableSections, nil
+}
+
+func invalidConsent(format string, a ...interface{}) error {
+	return &errortypes.InvalidPrivacyConsent{Message: "request.regs.ext.gpp " + fmt.Sprintf(format, a...)}
+}
+
+// parseHeader returns the IDs of the sections of the GPP string, in their order.
+func parseHeader(header string) ([]int8, error) {
+	reader, err := newBitReader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	if value, err := reader.readInt(6); err != nil {
+		return nil, err
+	} else if value != headerType {
+		return nil, fmt.Errorf("must have type %d. Got %d", headerType, value)
+	}
+	if _, err := reader.readInt(6); err != nil {
+		return nil, err
+	}
+	entries, err := reader.readInt(12)
+	if err != nil {
+		return nil, err
+	}
+
+	// The section IDs are Fibonacci coded, as offsets from the previous ID.
+	var ids []int8
+	previousID := 0
+	for i := 0; i < entries; i++ {
+		isRange, err := reader.readInt(1)
+		if err != nil {
+			return nil, err
+		}
+		start, err := reader.readFibonacci()
+		if err != nil {
+			return nil, err
+		}
+		start += previousID
+		end := start
+		if isRange == 1 {
+			length, err := reader.readFibonacci()
+			if err != nil {
+				return nil, err
+			}
+			end += length
+		}
+		if start < 1 || start > maxSectionID {
+			return nil, fmt.Errorf("has an unknown section ID %d", start)
+		}
+		if end < start || end > maxSectionID {
+			return nil, fmt.Errorf("has an unknown section ID %d", end)
+		}
+		for id := start; id <= end; id++ {
+			ids = append(ids, int8(id))
+		}
+		previousID = end
+	}
+	return ids, nil
+}
+
+// parseUSSection returns whether the user opted out of the sale or sharing of their data, or of targeted advertising.
+func parseUSSection(id int8, section string) (bool, error) {
+	for _, offset := range usSectionOptOuts[id] {
+		value, err := ReadField(section, offset, 2)
+		if err != nil {
+			return false, err
+		}
+		if value == OptedOut {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ReadField returns the value of the field of the given length, in bits, at the given offset of the core segment of
+// the section.
+func ReadField(section string, offset uint, bits int) (int, error) {
+	// The optional segments, such as the Global Privacy Control one, follow the core segment.
+	core := strings.SplitN(section, segmentSeparator, 2)[0]
+	reader, err := newBitReader(core)
+	if err != nil {
+		return 0, err
+	}
+	reader.position = offset
+	return reader.readInt(bits)
+}
+
+// CanEnforce returns true when a US section of the GPP string applies to the request.
+func (p ParsedPolicy) CanEnforce() bool {
+	return p.usSectionApplies
+}
+
+func (p ParsedPolicy) isNoSaleForBidder(bidder string) bool {
+	if p.noSaleForAllBidders {
+		return true
+	}
+
+	_, exists := p.noSaleSpecificBidders[bidder]
+	return exists
+}
+
+// ShouldEnforce returns true when a US section which applies to the request opts the user out, unless the bidder is
+// one of the no sale bidders.
+func (p ParsedPolicy) ShouldEnforce(bidder string) bool {
+	return !p.isNoSaleForBidder(bidder) && p.usOptOut
+}
+
+// TCFEUv2Consent returns the TCF consent string of the GPP string, or an empty string if the TCF EU v2 section
+// doesn't apply to the request.
+func (p ParsedPolicy) TCFEUv2Consent() string {
+	return p.tcfEUv2Consent
+}
+
+var errEndOfSegment = errors.New("is too short")
+
+// bitReader reads the fields of a base64url encoded segment of the GPP string.
+type bitReader struct {
+	values   []byte
+	position uint
+}
+
+func newBitReader(segment string) (*bitReader, error) {
+	values := make([]byte, len(segment))
+	for i := 0; i < len(segment); i++ {
+		value := strings.IndexByte(base64URLAlphabet, segment[i])
+		if value < 0 {
+			return nil, fmt.Errorf("has an invalid character %q", segment[i])
+		}
+		values[i] = byte(value)
+	}
+	return &bitReader{values: values}, nil
+}
+
+func (r *bitReader) readBit() (int, error) {
+	if r.position >= uint(len(r.values))*6 {
+		return 0, errEndOfSegment
+	}
+	bit := r.values[r.position/6] >> (5 - r.position%6) & 1
+	r.position++
+	return int(bit), nil
+}
+
+func (r *bitReader) readInt(bits int) (int, error) {
+	value := 0
+	for i := 0; i < bits; i++ {
+		bit, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+		value = value<<1 | bit
+	}
+	return value, nil
+}
+
+// readFibonacci reads a Fibonacci coded integer, which ends with two consecutive 1 bits. The codes longer than
+// maxFibonacciBits are rejected, as they can't be section IDs, and would overflow.
+func (r *bitReader) readFibonacci() (int, error) {
+	value := 0
+	previousBit := 0
+	current, next := 1, 2
+	for i := 0; ; i++ {
+		if i == maxFibonacciBits {
+			return 0, errors.New("has a Fibonacci coded integer which is too long")
+		}
+		bit, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+		if bit == 1 && previousBit == 1 {
+			return value, nil
+		}
+		if bit == 1 {
+			value += current
+		}
+		previousBit = bit
+		current, next = next, current+next
+	}
+}
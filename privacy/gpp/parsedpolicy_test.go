@@ -0,0 +1,351 @@
+package gpp
+
+import (
+	"testing"
+
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/stretchr/testify/assert"
+)
+
+const tcfEUv2Consent = "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		description    string
+		policy         Policy
+		expectedPolicy ParsedPolicy
+		expectedError  string
+	}{
+		{
+			description:    "Empty",
+			policy:         Policy{},
+			expectedPolicy: ParsedPolicy{},
+		},
+		{
+			description:    "TCF EU v2",
+			policy:         Policy{Consent: "DBABM~" + tcfEUv2Consent, SectionIDs: []int8{2}},
+			expectedPolicy: ParsedPolicy{tcfEUv2Consent: tcfEUv2Consent},
+		},
+		{
+			description:    "TCF EU v2 Not Applicable",
+			policy:         Policy{Consent: "DBABM~" + tcfEUv2Consent, SectionIDs: []int8{6}},
+			expectedPolicy: ParsedPolicy{},
+		},
+		{
+			description:    "TCF EU v2 And USPv1 Opted Out",
+			policy:         Policy{Consent: "DBACNY~" + tcfEUv2Consent + "~1YYN", SectionIDs: []int8{2, 6}},
+			expectedPolicy: ParsedPolicy{usSectionApplies: true, usOptOut: true, tcfEUv2Consent: tcfEUv2Consent},
+		},
+		{
+			description:    "USPv1 Not Opted Out",
+			policy:         Policy{Consent: "DBACNY~" + tcfEUv2Consent + "~1YNN", SectionIDs: []int8{6}},
+			expectedPolicy: ParsedPolicy{usSectionApplies: true},
+		},
+		{
+			description:    "US National Not Opted Out",
+			policy:         Policy{Consent: "DBABL~BAAqAA", SectionIDs: []int8{7}},
+			expectedPolicy: ParsedPolicy{usSectionApplies: true},
+		},
+		{
+			description:    "US National Opted Out Of Sale",
+			policy:         Policy{Consent: "DBABL~BAAaAA", SectionIDs: []int8{7}},
+			expectedPolicy: ParsedPolicy{usSectionApplies: true, usOptOut: true},
+		},
+		{
+			description:    "US National Opted Out Of Targeted Advertising With Optional Segment",
+			policy:         Policy{Consent: "DBABL~BAApAA.QA", SectionIDs: []int8{7}},
+			expectedPolicy: ParsedPolicy{usSectionApplies: true, usOptOut: true},
+		},
+		{
+			description:    "US California Opted Out Of Sharing",
+			policy:         Policy{Consent: "DBABBg~BAkAAA", SectionIDs: []int8{8}},
+			expectedPolicy: ParsedPolicy{usSectionApplies: true, usOptOut: true},
+		},
+		{
+			description:    "US Utah Opted Out Of Targeted Advertising - Enforced Per State",
+			policy:         Policy{Consent: "DBABFg~BAJAAA", SectionIDs: []int8{11}},
+			expectedPolicy: ParsedPolicy{},
+		},
+		{
+			description:    "Opted Out Section Not Applicable",
+			policy:         Policy{Consent: "DBACMM~" + tcfEUv2Consent + "~BAAaAA", SectionIDs: []int8{2}},
+			expectedPolicy: ParsedPolicy{tcfEUv2Consent: tcfEUv2Consent},
+		},
+		{
+			description:   "Header Of Wrong Type",
+			policy:        Policy{Consent: "BBABM~" + tcfEUv2Consent, SectionIDs: []int8{2}},
+			expectedError: "request.regs.ext.gpp header is invalid: must have type 3. Got 1",
+		},
+		{
+			description:   "Header With Invalid Character",
+			policy:        Policy{Consent: "DBA*M~" + tcfEUv2Consent, SectionIDs: []int8{2}},
+			expectedError: "request.regs.ext.gpp header is invalid: has an invalid character '*'",
+		},
+		{
+			description:   "Header Too Short",
+			policy:        Policy{Consent: "DBAB~" + tcfEUv2Consent, SectionIDs: []int8{2}},
+			expectedError: "request.regs.ext.gpp header is invalid: is too short",
+		},
+		{
+			description:   "Missing Section",
+			policy:        Policy{Consent: "DBACNY~" + tcfEUv2Consent, SectionIDs: []int8{2}},
+			expectedError: "request.regs.ext.gpp has 1 sections but its header lists 2",
+		},
+		{
+			description:   "Invalid USPv1 Section",
+			policy:        Policy{Consent: "DBACNY~" + tcfEUv2Consent + "~1YY", SectionIDs: []int8{6}},
+			expectedError: "request.regs.ext.gpp section 6 is not a valid US Privacy string",
+		},
+		{
+			description:   "US Section Too Short",
+			policy:        Policy{Consent: "DBABL~BAA", SectionIDs: []int8{7}},
+			expectedError: "request.regs.ext.gpp section 7 is invalid: is too short",
+		},
+	}
+
+	for _, test := range testCases {
+		result, err := test.policy.Parse(nil)
+
+		if test.expectedError == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.Equal(t, &errortypes.InvalidPrivacyConsent{Message: test.expectedError}, err, test.description)
+		}
+		assert.Equal(t, test.expectedPolicy, result, test.description)
+	}
+}
+
+func TestParseNoSaleBidders(t *testing.T) {
+	validBidders := map[string]struct{}{"a": {}, "b": {}}
+
+	testCases := []struct {
+		description    string
+		policy         Policy
+		expectedPolicy ParsedPolicy
+		expectedError  string
+	}{
+		{
+			description:    "Specific Bidders",
+			policy:         Policy{Consent: "DBABL~BAAaAA", SectionIDs: []int8{7}, NoSaleBidders: []string{"a"}},
+			expectedPolicy: ParsedPolicy{usSectionApplies: true, usOptOut: true, noSaleSpecificBidders: map[string]struct{}{"a": {}}},
+		},
+		{
+			description:    "All Bidders",
+			policy:         Policy{Consent: "DBABL~BAAaAA", SectionIDs: []int8{7}, NoSaleBidders: []string{"*"}},
+			expectedPolicy: ParsedPolicy{usSectionApplies: true, usOptOut: true, noSaleForAllBidders: true, noSaleSpecificBidders: map[string]struct{}{}},
+		},
+		{
+			description:   "Unrecognized Bidder",
+			policy:        Policy{Consent: "DBABL~BAAaAA", SectionIDs: []int8{7}, NoSaleBidders: []string{"c"}},
+			expectedError: "request.ext.prebid.nosale is invalid: unrecognized bidder 'c'",
+		},
+	}
+
+	for _, test := range testCases {
+		result, err := test.policy.Parse(validBidders)
+
+		if test.expectedError == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		}
+		assert.Equal(t, test.expectedPolicy, result, test.description)
+	}
+}
+
+func TestSections(t *testing.T) {
+	testCases := []struct {
+		description      string
+		policy           Policy
+		expectedSections map[int8]string
+		expectedError    string
+	}{
+		{
+			description:      "Empty",
+			policy:           Policy{},
+			expectedSections: nil,
+		},
+		{
+			description:      "Applicable Sections Only",
+			policy:           Policy{Consent: "DBACRj~BAYAAA~BAkAAA", SectionIDs: []int8{12, 2}},
+			expectedSections: map[int8]string{12: "BAkAAA"},
+		},
+		{
+			description:      "No Applicable Sections",
+			policy:           Policy{Consent: "DBACRj~BAYAAA~BAkAAA"},
+			expectedSections: map[int8]string{},
+		},
+		{
+			description:   "Missing Section",
+			policy:        Policy{Consent: "DBACRj~BAYAAA", SectionIDs: []int8{9}},
+			expectedError: "request.regs.ext.gpp has 1 sections but its header lists 2",
+		},
+		{
+			description:   "Header Fibonacci Code Too Long",
+			policy:        Policy{Consent: "DBABgAAAAAAAAAAAAAANCgCopIoVFKJIKhIgY~x", SectionIDs: []int8{2}},
+			expectedError: "request.regs.ext.gpp header is invalid: has a Fibonacci coded integer which is too long",
+		},
+	}
+
+	for _, test := range testCases {
+		result, err := test.policy.Sections()
+
+		if test.expectedError == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.Equal(t, &errortypes.InvalidPrivacyConsent{Message: test.expectedError}, err, test.description)
+		}
+		assert.Equal(t, test.expectedSections, result, test.description)
+	}
+}
+
+func TestReadField(t *testing.T) {
+	testCases := []struct {
+		description   string
+		section       string
+		offset        uint
+		bits          int
+		expectedValue int
+		expectedError string
+	}{
+		{
+			description:   "Version",
+			section:       "BAYAAA",
+			offset:        0,
+			bits:          6,
+			expectedValue: 1,
+		},
+		{
+			description:   "Opt Out Field",
+			section:       "BAYAAA",
+			offset:        12,
+			bits:          2,
+			expectedValue: OptedOut,
+		},
+		{
+			description:   "Optional Segment Ignored",
+			section:       "BAY.QA",
+			offset:        12,
+			bits:          2,
+			expectedValue: OptedOut,
+		},
+		{
+			description:   "Past The Core Segment",
+			section:       "BAY.QA",
+			offset:        18,
+			bits:          2,
+			expectedError: "is too short",
+		},
+		{
+			description:   "Invalid Character",
+			section:       "BA*AAA",
+			offset:        12,
+			bits:          2,
+			expectedError: "has an invalid character '*'",
+		},
+	}
+
+	for _, test := range testCases {
+		result, err := ReadField(test.section, test.offset, test.bits)
+
+		if test.expectedError == "" {
+			assert.NoError(t, err, test.description)
+			assert.Equal(t, test.expectedValue, result, test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		}
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	testCases := []struct {
+		description string
+		header      string
+		expectedIDs []int8
+	}{
+		{
+			description: "No Sections",
+			header:      "DBAA",
+			expectedIDs: nil,
+		},
+		{
+			description: "One Section",
+			header:      "DBABM",
+			expectedIDs: []int8{2},
+		},
+		{
+			description: "One Section With Padding",
+			header:      "DBABMA",
+			expectedIDs: []int8{2},
+		},
+		{
+			description: "Many Sections",
+			header:      "DBACTM",
+			expectedIDs: []int8{6, 8},
+		},
+		{
+			description: "Range",
+			header:      "DBABsw",
+			expectedIDs: []int8{2, 3, 4, 5},
+		},
+	}
+
+	for _, test := range testCases {
+		result, err := parseHeader(test.header)
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expectedIDs, result, test.description)
+	}
+}
+
+func TestParseHeaderErrors(t *testing.T) {
+	testCases := []struct {
+		description   string
+		header        string
+		expectedError string
+	}{
+		{
+			description:   "Wrong Type",
+			header:        "BBAA",
+			expectedError: "must have type 3. Got 1",
+		},
+		{
+			description:   "Too Short",
+			header:        "DBAB",
+			expectedError: "is too short",
+		},
+		{
+			description:   "Fibonacci Code Too Long",
+			header:        "DBABgAAAAAAAAAAAAAANCgCopIoVFKJIKhIgY",
+			expectedError: "has a Fibonacci coded integer which is too long",
+		},
+		{
+			description:   "Section ID Too Large",
+			header:        "DBABCL",
+			expectedError: "has an unknown section ID 128",
+		},
+		{
+			description:   "Range Too Large",
+			header:        "DBABlDJY",
+			expectedError: "has an unknown section ID 150",
+		},
+	}
+
+	for _, test := range testCases {
+		result, err := parseHeader(test.header)
+		assert.EqualError(t, err, test.expectedError, test.description)
+		assert.Nil(t, result, test.description)
+	}
+}
+
+func TestCanEnforce(t *testing.T) {
+	assert.True(t, ParsedPolicy{usSectionApplies: true}.CanEnforce(), "US Section Applies")
+	assert.False(t, ParsedPolicy{tcfEUv2Consent: tcfEUv2Consent}.CanEnforce(), "Only TCF EU v2")
+}
+
+func TestShouldEnforce(t *testing.T) {
+	assert.True(t, ParsedPolicy{usSectionApplies: true, usOptOut: true}.ShouldEnforce("appnexus"), "Opted Out")
+	assert.False(t, ParsedPolicy{usSectionApplies: true}.ShouldEnforce("appnexus"), "Not Opted Out")
+	assert.False(t, ParsedPolicy{usSectionApplies: true, usOptOut: true, noSaleSpecificBidders: map[string]struct{}{"appnexus": {}}}.ShouldEnforce("appnexus"), "Opted Out - No Sale Bidder")
+	assert.True(t, ParsedPolicy{usSectionApplies: true, usOptOut: true, noSaleSpecificBidders: map[string]struct{}{"rubicon": {}}}.ShouldEnforce("appnexus"), "Opted Out - Other No Sale Bidder")
+	assert.False(t, ParsedPolicy{usSectionApplies: true, usOptOut: true, noSaleForAllBidders: true}.ShouldEnforce("appnexus"), "Opted Out - No Sale For All Bidders")
+}
@@ -0,0 +1,81 @@
+package gpp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// IDs of the sections of the GPP string which Prebid Server understands.
+const (
+	SectionTCFEUv2 int8 = 2
+	SectionUSPv1   int8 = 6
+	SectionUSNat   int8 = 7
+	SectionUSCA    int8 = 8
+	SectionUSVA    int8 = 9
+	SectionUSCO    int8 = 10
+	SectionUSUT    int8 = 11
+	SectionUSCT    int8 = 12
+)
+
+// Policy represents the GPP (Global Privacy Platform) regulatory information from an OpenRTB bid request.
+type Policy struct {
+	Consent string
+	// SectionIDs are the sections of the GPP string which apply to the request.
+	SectionIDs []int8
+	// NoSaleBidders are the bidders of request.ext.prebid.nosale, which the opt outs of the US sections don't apply to.
+	NoSaleBidders []string
+}
+
+// ReadFromRequest extracts the GPP regulatory information from an OpenRTB bid request.
+func ReadFromRequest(req *openrtb.BidRequest) (Policy, error) {
+	if req == nil || req.Regs == nil || len(req.Regs.Ext) == 0 {
+		return Policy{}, nil
+	}
+
+	var ext openrtb_ext.ExtRegs
+	if err := json.Unmarshal(req.Regs.Ext, &ext); err != nil {
+		return Policy{}, fmt.Errorf("error reading request.regs.ext: %s", err)
+	}
+	policy := Policy{Consent: ext.GPP, SectionIDs: ext.GPPSID}
+
+	if len(req.Ext) > 0 {
+		var requestExt openrtb_ext.ExtRequest
+		if err := json.Unmarshal(req.Ext, &requestExt); err != nil {
+			return Policy{}, fmt.Errorf("error reading request.ext.prebid: %s", err)
+		}
+		policy.NoSaleBidders = requestExt.Prebid.NoSale
+	}
+	return policy, nil
+}
+
+// ParseSectionIDs parses the comma separated list of section IDs used by the cookie sync requests and the usersync URLs.
+func ParseSectionIDs(sectionIDs string) ([]int8, error) {
+	if sectionIDs == "" {
+		return nil, nil
+	}
+
+	values := strings.Split(sectionIDs, ",")
+	ids := make([]int8, 0, len(values))
+	for _, value := range values {
+		id, err := strconv.ParseInt(strings.TrimSpace(value), 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("gpp_sid must be a comma separated list of section IDs. Got %s", sectionIDs)
+		}
+		ids = append(ids, int8(id))
+	}
+	return ids, nil
+}
+
+// SectionIDsString returns the section IDs as a comma separated list.
+func (p Policy) SectionIDsString() string {
+	values := make([]string, len(p.SectionIDs))
+	for i, id := range p.SectionIDs {
+		values[i] = strconv.Itoa(int(id))
+	}
+	return strings.Join(values, ",")
+}
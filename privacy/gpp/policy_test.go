@@ -0,0 +1,167 @@
+package gpp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadFromRequest(t *testing.T) {
+	testCases := []struct {
+		description    string
+		request        *openrtb.BidRequest
+		expectedPolicy Policy
+		expectedError  bool
+	}{
+		{
+			description: "Success",
+			request: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpp":"DBABM~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA","gpp_sid":[2]}`)},
+			},
+			expectedPolicy: Policy{
+				Consent:    "DBABM~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA",
+				SectionIDs: []int8{2},
+			},
+		},
+		{
+			description: "No Sale Bidders",
+			request: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpp":"DBABL~BAAaAA","gpp_sid":[7]}`)},
+				Ext:  json.RawMessage(`{"prebid":{"nosale":["a","b"]}}`),
+			},
+			expectedPolicy: Policy{
+				Consent:       "DBABL~BAAaAA",
+				SectionIDs:    []int8{7},
+				NoSaleBidders: []string{"a", "b"},
+			},
+		},
+		{
+			description: "Malformed Ext",
+			request: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpp":"DBABL~BAAaAA","gpp_sid":[7]}`)},
+				Ext:  json.RawMessage(`malformed`),
+			},
+			expectedPolicy: Policy{},
+			expectedError:  true,
+		},
+		{
+			description:    "Nil Request",
+			request:        nil,
+			expectedPolicy: Policy{},
+		},
+		{
+			description:    "Nil Regs",
+			request:        &openrtb.BidRequest{},
+			expectedPolicy: Policy{},
+		},
+		{
+			description:    "Nil Regs.Ext",
+			request:        &openrtb.BidRequest{Regs: &openrtb.Regs{}},
+			expectedPolicy: Policy{},
+		},
+		{
+			description:    "Missing GPP Values",
+			request:        &openrtb.BidRequest{Regs: &openrtb.Regs{Ext: json.RawMessage(`{"us_privacy":"1YNN"}`)}},
+			expectedPolicy: Policy{},
+		},
+		{
+			description:    "Malformed Regs.Ext",
+			request:        &openrtb.BidRequest{Regs: &openrtb.Regs{Ext: json.RawMessage(`malformed`)}},
+			expectedPolicy: Policy{},
+			expectedError:  true,
+		},
+		{
+			description:    "Invalid Section IDs Type",
+			request:        &openrtb.BidRequest{Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpp_sid":"2"}`)}},
+			expectedPolicy: Policy{},
+			expectedError:  true,
+		},
+	}
+
+	for _, test := range testCases {
+		result, err := ReadFromRequest(test.request)
+		assertError(t, test.expectedError, err, test.description)
+		assert.Equal(t, test.expectedPolicy, result, test.description)
+	}
+}
+
+func TestParseSectionIDs(t *testing.T) {
+	testCases := []struct {
+		description   string
+		sectionIDs    string
+		expectedIDs   []int8
+		expectedError bool
+	}{
+		{
+			description: "Empty",
+			sectionIDs:  "",
+			expectedIDs: nil,
+		},
+		{
+			description: "One",
+			sectionIDs:  "2",
+			expectedIDs: []int8{2},
+		},
+		{
+			description: "Many With Spaces",
+			sectionIDs:  "2, 6,8",
+			expectedIDs: []int8{2, 6, 8},
+		},
+		{
+			description:   "Not A Number",
+			sectionIDs:    "2,a",
+			expectedError: true,
+		},
+		{
+			description:   "Out Of Range",
+			sectionIDs:    "200",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		result, err := ParseSectionIDs(test.sectionIDs)
+		assertError(t, test.expectedError, err, test.description)
+		assert.Equal(t, test.expectedIDs, result, test.description)
+	}
+}
+
+func TestSectionIDsString(t *testing.T) {
+	testCases := []struct {
+		description    string
+		sectionIDs     []int8
+		expectedString string
+	}{
+		{
+			description:    "None",
+			sectionIDs:     nil,
+			expectedString: "",
+		},
+		{
+			description:    "One",
+			sectionIDs:     []int8{2},
+			expectedString: "2",
+		},
+		{
+			description:    "Many",
+			sectionIDs:     []int8{2, 6, 8},
+			expectedString: "2,6,8",
+		},
+	}
+
+	for _, test := range testCases {
+		result := Policy{SectionIDs: test.sectionIDs}.SectionIDsString()
+		assert.Equal(t, test.expectedString, result, test.description)
+	}
+}
+
+func assertError(t *testing.T, expectError bool, err error, description string) {
+	t.Helper()
+	if expectError {
+		assert.Error(t, err, description)
+	} else {
+		assert.NoError(t, err, description)
+	}
+}
@@ -58,6 +58,10 @@ const (
 
 	// ScrubStrategyUserID removes the user's buyer id.
 	ScrubStrategyUserID
+
+	// ScrubStrategyUserIDDemographicAndFPD removes the user's buyer id, exchange id, year of birth, gender and first
+	// party data: data, keywords and ext.data.
+	ScrubStrategyUserIDDemographicAndFPD
 )
 
 // ScrubStrategyDeviceID defines the approach to remove hardware id and device id data.
@@ -132,6 +136,11 @@ func (scrubber) ScrubUser(user *openrtb.User, strategy ScrubStrategyUser, geo Sc
 	userCopy := *user
 
 	switch strategy {
+	case ScrubStrategyUserIDDemographicAndFPD:
+		userCopy.Data = nil
+		userCopy.Keywords = ""
+		userCopy.Ext = scrubUserExtData(userCopy.Ext)
+		fallthrough
 	case ScrubStrategyUserIDAndDemographic:
 		userCopy.BuyerUID = ""
 		userCopy.ID = ""
@@ -238,3 +247,26 @@ func scrubUserExtIDs(userExt json.RawMessage) json.RawMessage {
 
 	return userExt
 }
+
+func scrubUserExtData(userExt json.RawMessage) json.RawMessage {
+	if len(userExt) == 0 {
+		return userExt
+	}
+
+	var userExtParsed map[string]json.RawMessage
+	err := json.Unmarshal(userExt, &userExtParsed)
+	if err != nil {
+		return userExt
+	}
+
+	if _, hasData := userExtParsed["data"]; hasData {
+		delete(userExtParsed, "data")
+
+		result, err := json.Marshal(userExtParsed)
+		if err == nil {
+			return result
+		}
+	}
+
+	return userExt
+}
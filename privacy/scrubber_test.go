@@ -379,6 +379,46 @@ func TestScrubUser(t *testing.T) {
 	}
 }
 
+func TestScrubUserFPD(t *testing.T) {
+	user := &openrtb.User{
+		ID:       "anyID",
+		BuyerUID: "anyBuyerUID",
+		Yob:      42,
+		Gender:   "anyGender",
+		Keywords: "anyKeywords",
+		Data:     []openrtb.Data{{ID: "anyData"}},
+		Ext:      json.RawMessage(`{"data":{"anyData":42},"digitrust":{"id":"anyId","keyv":4,"pref":8}}`),
+	}
+
+	testCases := []struct {
+		description string
+		expected    *openrtb.User
+		scrubUser   ScrubStrategyUser
+	}{
+		{
+			description: "User ID, Demographic And FPD",
+			expected: &openrtb.User{
+				Ext: json.RawMessage(`{}`),
+			},
+			scrubUser: ScrubStrategyUserIDDemographicAndFPD,
+		},
+		{
+			description: "User ID And Demographic",
+			expected: &openrtb.User{
+				Keywords: "anyKeywords",
+				Data:     []openrtb.Data{{ID: "anyData"}},
+				Ext:      json.RawMessage(`{"data":{"anyData":42}}`),
+			},
+			scrubUser: ScrubStrategyUserIDAndDemographic,
+		},
+	}
+
+	for _, test := range testCases {
+		result := NewScrubber().ScrubUser(user, test.scrubUser, ScrubStrategyGeoNone)
+		assert.Equal(t, test.expected, result, test.description)
+	}
+}
+
 func TestScrubUserNil(t *testing.T) {
 	result := NewScrubber().ScrubUser(nil, ScrubStrategyUserNone, ScrubStrategyGeoNone)
 	assert.Nil(t, result)
@@ -642,3 +682,42 @@ func TestScrubUserExtIDs(t *testing.T) {
 		assert.Equal(t, test.expected, result, test.description)
 	}
 }
+
+func TestScrubUserExtData(t *testing.T) {
+	testCases := []struct {
+		description string
+		userExt     json.RawMessage
+		expected    json.RawMessage
+	}{
+		{
+			description: "Nil",
+			userExt:     nil,
+			expected:    nil,
+		},
+		{
+			description: "Do Nothing When Malformed",
+			userExt:     json.RawMessage(`malformed`),
+			expected:    json.RawMessage(`malformed`),
+		},
+		{
+			description: "Do Nothing When No Data Present",
+			userExt:     json.RawMessage(`{"anyExisting":42}}`),
+			expected:    json.RawMessage(`{"anyExisting":42}}`),
+		},
+		{
+			description: "Remove data",
+			userExt:     json.RawMessage(`{"data":{"anyData":42}}`),
+			expected:    json.RawMessage(`{}`),
+		},
+		{
+			description: "Remove data - With Other Data",
+			userExt:     json.RawMessage(`{"anyExisting":42,"data":{"anyData":42}}`),
+			expected:    json.RawMessage(`{"anyExisting":42}`),
+		},
+	}
+
+	for _, test := range testCases {
+		result := scrubUserExtData(test.userExt)
+		assert.Equal(t, test.expected, result, test.description)
+	}
+}
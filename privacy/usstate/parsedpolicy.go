@@ -0,0 +1,104 @@
+package usstate
+
+import (
+	"fmt"
+
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/privacy/ccpa"
+	"github.com/prebid/prebid-server/privacy/gpp"
+)
+
+// optOutFields are the bit offsets, in the core segment of the section of a state, of the 2 bit fields which opt the
+// user out of the sale of their data and of targeted advertising. These state laws only require a notice for the
+// sharing of the data, which can't be opted out of.
+type optOutFields struct {
+	sale                uint
+	targetedAdvertising uint
+}
+
+var stateOptOutFields = map[State]optOutFields{
+	StateVirginia:    {sale: 12, targetedAdvertising: 14},
+	StateColorado:    {sale: 12, targetedAdvertising: 14},
+	StateConnecticut: {sale: 12, targetedAdvertising: 14},
+	StateUtah:        {sale: 14, targetedAdvertising: 16},
+}
+
+// ParsedPolicy represents parsed and validated privacy signals of a US state. Use this struct to make enforcement
+// decisions.
+type ParsedPolicy struct {
+	consentSpecified          bool
+	optOutSale                bool
+	optOutTargetedAdvertising bool
+	noSaleForAllBidders       bool
+	noSaleSpecificBidders     map[string]struct{}
+}
+
+// Parse returns a parsed and validated ParsedPolicy intended for use in enforcement decisions. The no sale bidders
+// are validated against the valid bidders, as by CCPA.
+func (p Policy) Parse(validBidders map[string]struct{}) (ParsedPolicy, error) {
+	if p.Consent == "" {
+		return ParsedPolicy{}, nil
+	}
+
+	fields, found := stateOptOutFields[p.State]
+	if !found {
+		return ParsedPolicy{}, fmt.Errorf("unknown US state %s", p.State)
+	}
+
+	sale, err := gpp.ReadField(p.Consent, fields.sale, 2)
+	if err != nil {
+		return ParsedPolicy{}, invalidConsent(p.State, err)
+	}
+	targetedAdvertising, err := gpp.ReadField(p.Consent, fields.targetedAdvertising, 2)
+	if err != nil {
+		return ParsedPolicy{}, invalidConsent(p.State, err)
+	}
+
+	parsed := ParsedPolicy{
+		consentSpecified:          true,
+		optOutSale:                sale == gpp.OptedOut,
+		optOutTargetedAdvertising: targetedAdvertising == gpp.OptedOut,
+	}
+	if len(p.NoSaleBidders) > 0 {
+		parsed.noSaleForAllBidders, parsed.noSaleSpecificBidders, err = ccpa.ParseNoSaleBidders(p.NoSaleBidders, validBidders)
+		if err != nil {
+			return ParsedPolicy{}, fmt.Errorf("request.ext.prebid.nosale is invalid: %s", err.Error())
+		}
+	}
+	return parsed, nil
+}
+
+func invalidConsent(state State, err error) error {
+	msg := fmt.Sprintf("request.regs.ext.gpp section of the US state %s %s", state, err.Error())
+	return &errortypes.InvalidPrivacyConsent{Message: msg}
+}
+
+// CanEnforce returns true when the request holds the privacy signals of the state.
+func (p ParsedPolicy) CanEnforce() bool {
+	return p.consentSpecified
+}
+
+func (p ParsedPolicy) isNoSaleForBidder(bidder string) bool {
+	if p.noSaleForAllBidders {
+		return true
+	}
+
+	_, exists := p.noSaleSpecificBidders[bidder]
+	return exists
+}
+
+// ShouldEnforce returns true when the user opted out of the sale of their data or of targeted advertising, unless the
+// bidder is one of the no sale bidders.
+func (p ParsedPolicy) ShouldEnforce(bidder string) bool {
+	return !p.isNoSaleForBidder(bidder) && (p.optOutSale || p.optOutTargetedAdvertising)
+}
+
+// OptOutSale returns true when the user opted out of the sale of their data.
+func (p ParsedPolicy) OptOutSale() bool {
+	return p.optOutSale
+}
+
+// OptOutTargetedAdvertising returns true when the user opted out of targeted advertising.
+func (p ParsedPolicy) OptOutTargetedAdvertising() bool {
+	return p.optOutTargetedAdvertising
+}
@@ -0,0 +1,134 @@
+package usstate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		description    string
+		policy         Policy
+		expectedPolicy ParsedPolicy
+		expectedError  error
+	}{
+		{
+			description:    "Empty",
+			policy:         Policy{State: StateVirginia},
+			expectedPolicy: ParsedPolicy{},
+		},
+		{
+			description:    "Virginia Not Opted Out",
+			policy:         Policy{State: StateVirginia, Consent: "BAoAAA"},
+			expectedPolicy: ParsedPolicy{consentSpecified: true},
+		},
+		{
+			description:    "Virginia Opted Out Of Sale",
+			policy:         Policy{State: StateVirginia, Consent: "BAYAAA"},
+			expectedPolicy: ParsedPolicy{consentSpecified: true, optOutSale: true},
+		},
+		{
+			description:    "Colorado Opted Out Of Targeted Advertising",
+			policy:         Policy{State: StateColorado, Consent: "BAkAAA"},
+			expectedPolicy: ParsedPolicy{consentSpecified: true, optOutTargetedAdvertising: true},
+		},
+		{
+			description:    "Connecticut Opted Out Of Sale With Optional Segment",
+			policy:         Policy{State: StateConnecticut, Consent: "BAYAAA.QA"},
+			expectedPolicy: ParsedPolicy{consentSpecified: true, optOutSale: true},
+		},
+		{
+			description:    "Utah Not Opted Out",
+			policy:         Policy{State: StateUtah, Consent: "BAKAAA"},
+			expectedPolicy: ParsedPolicy{consentSpecified: true},
+		},
+		{
+			description:    "Utah Opted Out Of Targeted Advertising",
+			policy:         Policy{State: StateUtah, Consent: "BAJAAA"},
+			expectedPolicy: ParsedPolicy{consentSpecified: true, optOutTargetedAdvertising: true},
+		},
+		{
+			description: "No Sale Bidders",
+			policy:      Policy{State: StateVirginia, Consent: "BAYAAA", NoSaleBidders: []string{"appnexus"}},
+			expectedPolicy: ParsedPolicy{consentSpecified: true, optOutSale: true,
+				noSaleSpecificBidders: map[string]struct{}{"appnexus": {}}},
+		},
+		{
+			description:    "No Sale For All Bidders",
+			policy:         Policy{State: StateVirginia, Consent: "BAYAAA", NoSaleBidders: []string{"*"}},
+			expectedPolicy: ParsedPolicy{consentSpecified: true, optOutSale: true, noSaleForAllBidders: true, noSaleSpecificBidders: map[string]struct{}{}},
+		},
+		{
+			description:   "Invalid No Sale Bidders",
+			policy:        Policy{State: StateVirginia, Consent: "BAYAAA", NoSaleBidders: []string{"*", "appnexus"}},
+			expectedError: errors.New("request.ext.prebid.nosale is invalid: can only specify all bidders if no other bidders are provided"),
+		},
+		{
+			description:   "Section Too Short",
+			policy:        Policy{State: StateVirginia, Consent: "BA"},
+			expectedError: &errortypes.InvalidPrivacyConsent{Message: "request.regs.ext.gpp section of the US state va is too short"},
+		},
+		{
+			description:   "Unknown State",
+			policy:        Policy{State: "ca", Consent: "BAYAAA"},
+			expectedError: errors.New("unknown US state ca"),
+		},
+	}
+
+	for _, test := range testCases {
+		result, err := test.policy.Parse(map[string]struct{}{"appnexus": {}})
+		assert.Equal(t, test.expectedError, err, test.description)
+		assert.Equal(t, test.expectedPolicy, result, test.description)
+	}
+}
+
+func TestCanEnforce(t *testing.T) {
+	assert.True(t, ParsedPolicy{consentSpecified: true}.CanEnforce(), "Consent Specified")
+	assert.False(t, ParsedPolicy{}.CanEnforce(), "Consent Not Specified")
+}
+
+func TestShouldEnforce(t *testing.T) {
+	testCases := []struct {
+		description    string
+		policy         ParsedPolicy
+		expectedResult bool
+	}{
+		{
+			description:    "Not Opted Out",
+			policy:         ParsedPolicy{consentSpecified: true},
+			expectedResult: false,
+		},
+		{
+			description:    "Opted Out Of Sale",
+			policy:         ParsedPolicy{consentSpecified: true, optOutSale: true},
+			expectedResult: true,
+		},
+		{
+			description:    "Opted Out Of Targeted Advertising",
+			policy:         ParsedPolicy{consentSpecified: true, optOutTargetedAdvertising: true},
+			expectedResult: true,
+		},
+		{
+			description:    "Opted Out - No Sale Bidder",
+			policy:         ParsedPolicy{consentSpecified: true, optOutSale: true, noSaleSpecificBidders: map[string]struct{}{"appnexus": {}}},
+			expectedResult: false,
+		},
+		{
+			description:    "Opted Out - Other No Sale Bidder",
+			policy:         ParsedPolicy{consentSpecified: true, optOutSale: true, noSaleSpecificBidders: map[string]struct{}{"rubicon": {}}},
+			expectedResult: true,
+		},
+		{
+			description:    "Opted Out - No Sale For All Bidders",
+			policy:         ParsedPolicy{consentSpecified: true, optOutTargetedAdvertising: true, noSaleForAllBidders: true},
+			expectedResult: false,
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedResult, test.policy.ShouldEnforce("appnexus"), test.description)
+	}
+}
@@ -0,0 +1,59 @@
+package usstate
+
+import (
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/privacy/gpp"
+)
+
+// State identifies a US state with its own privacy law.
+type State string
+
+// The US states whose privacy laws Prebid Server enforces.
+const (
+	StateVirginia    State = "va"
+	StateColorado    State = "co"
+	StateConnecticut State = "ct"
+	StateUtah        State = "ut"
+)
+
+// States are the US states whose privacy laws Prebid Server enforces, in the order they're read from the request.
+var States = []State{StateVirginia, StateColorado, StateConnecticut, StateUtah}
+
+// stateSections are the IDs of the sections of the GPP string which hold the privacy signals of each state.
+var stateSections = map[State]int8{
+	StateVirginia:    gpp.SectionUSVA,
+	StateColorado:    gpp.SectionUSCO,
+	StateConnecticut: gpp.SectionUSCT,
+	StateUtah:        gpp.SectionUSUT,
+}
+
+// Policy represents the privacy signals of a US state from an OpenRTB bid request.
+type Policy struct {
+	State   State
+	Consent string
+	// NoSaleBidders are the bidders of request.ext.prebid.nosale, which the opt outs of the state don't apply to, as
+	// for the US sections of the GPP string.
+	NoSaleBidders []string
+}
+
+// ReadFromRequest extracts the privacy signals of the US states from the sections of the GPP string which apply to
+// an OpenRTB bid request.
+func ReadFromRequest(req *openrtb.BidRequest) ([]Policy, error) {
+	gppPolicy, err := gpp.ReadFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	sections, err := gppPolicy.Sections()
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []Policy
+	for _, state := range States {
+		if section, found := sections[stateSections[state]]; found {
+			policies = append(policies, Policy{State: state, Consent: section, NoSaleBidders: gppPolicy.NoSaleBidders})
+		}
+	}
+	return policies, nil
+}
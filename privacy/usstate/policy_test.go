@@ -0,0 +1,78 @@
+package usstate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadFromRequest(t *testing.T) {
+	testCases := []struct {
+		description      string
+		request          *openrtb.BidRequest
+		expectedPolicies []Policy
+		expectedError    error
+	}{
+		{
+			description:      "Nil Request",
+			request:          nil,
+			expectedPolicies: nil,
+		},
+		{
+			description:      "No GPP String",
+			request:          &openrtb.BidRequest{Regs: &openrtb.Regs{Ext: json.RawMessage(`{"us_privacy":"1YNN"}`)}},
+			expectedPolicies: nil,
+		},
+		{
+			description: "One State",
+			request:     &openrtb.BidRequest{Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpp":"DBABRg~BAYAAA","gpp_sid":[9]}`)}},
+			expectedPolicies: []Policy{
+				{State: StateVirginia, Consent: "BAYAAA"},
+			},
+		},
+		{
+			description: "Many States",
+			request:     &openrtb.BidRequest{Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpp":"DBACRj~BAYAAA~BAkAAA","gpp_sid":[12,9]}`)}},
+			expectedPolicies: []Policy{
+				{State: StateVirginia, Consent: "BAYAAA"},
+				{State: StateConnecticut, Consent: "BAkAAA"},
+			},
+		},
+		{
+			description: "State Not Applicable",
+			request:     &openrtb.BidRequest{Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpp":"DBACRj~BAYAAA~BAkAAA","gpp_sid":[12]}`)}},
+			expectedPolicies: []Policy{
+				{State: StateConnecticut, Consent: "BAkAAA"},
+			},
+		},
+		{
+			description: "No Sale Bidders",
+			request: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpp":"DBABRg~BAYAAA","gpp_sid":[9]}`)},
+				Ext:  json.RawMessage(`{"prebid":{"nosale":["appnexus"]}}`),
+			},
+			expectedPolicies: []Policy{
+				{State: StateVirginia, Consent: "BAYAAA", NoSaleBidders: []string{"appnexus"}},
+			},
+		},
+		{
+			description:      "Other Sections Only",
+			request:          &openrtb.BidRequest{Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpp":"DBABL~BAAaAA","gpp_sid":[7]}`)}},
+			expectedPolicies: nil,
+		},
+		{
+			description:   "Invalid GPP String",
+			request:       &openrtb.BidRequest{Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpp":"DBACRj~BAYAAA","gpp_sid":[9]}`)}},
+			expectedError: &errortypes.InvalidPrivacyConsent{Message: "request.regs.ext.gpp has 1 sections but its header lists 2"},
+		},
+	}
+
+	for _, test := range testCases {
+		result, err := ReadFromRequest(test.request)
+		assert.Equal(t, test.expectedError, err, test.description)
+		assert.Equal(t, test.expectedPolicies, result, test.description)
+	}
+}
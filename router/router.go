@@ -33,19 +33,23 @@ import (
 	"github.com/prebid/prebid-server/cache/dummycache"
 	"github.com/prebid/prebid-server/cache/filecache"
 	"github.com/prebid/prebid-server/cache/postgrescache"
+	"github.com/prebid/prebid-server/circuitbreaker"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/endpoints"
 	infoEndpoints "github.com/prebid/prebid-server/endpoints/info"
 	"github.com/prebid/prebid-server/endpoints/openrtb2"
 	"github.com/prebid/prebid-server/exchange"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/hooks"
 	metricsConf "github.com/prebid/prebid-server/metrics/config"
+	"github.com/prebid/prebid-server/modules"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/pbs"
 	pbc "github.com/prebid/prebid-server/prebid_cache_client"
 	"github.com/prebid/prebid-server/router/aspects"
 	"github.com/prebid/prebid-server/server/ssl"
 	storedRequestsConf "github.com/prebid/prebid-server/stored_requests/config"
+	"github.com/prebid/prebid-server/usersync"
 	"github.com/prebid/prebid-server/usersync/usersyncers"
 
 	"github.com/golang/glog"
@@ -86,6 +90,13 @@ func NewJsonDirectoryServer(schemaDirectory string, validator openrtb_ext.Bidder
 		data[bidder] = json.RawMessage(validator.Schema(bidderName))
 	}
 
+	// Add in the aliases defined in the host configuration, which take the schema of their parent
+	for _, bidderName := range openrtb_ext.CoreBidderNames() {
+		if _, isAlias := openrtb_ext.AliasBidderParent(bidderName); isAlias {
+			data[string(bidderName)] = json.RawMessage(validator.Schema(bidderName))
+		}
+	}
+
 	// Add in any default aliases
 	for aliasName, bidderName := range aliases {
 		bidderData, ok := data[bidderName]
@@ -182,6 +193,10 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		Router: httprouter.New(),
 	}
 
+	if err := registerBidderAliases(cfg.Adapters); err != nil {
+		return nil, fmt.Errorf("Prebid Server could not register the bidder aliases: %v", err)
+	}
+
 	// For bid processing, we need both the hardcoded certificates and the certificates found in container's
 	// local file system
 	certPool := ssl.GetRootCAPool()
@@ -240,12 +255,13 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 	defaultAliases, defReqJSON := readDefaultRequest(cfg.DefReqConfig)
 
 	syncers := usersyncers.NewSyncerMap(cfg)
-	gdprPerms := gdpr.NewPermissions(context.Background(), cfg.GDPR, adapters.GDPRAwareSyncerIDs(syncers), generalHttpClient)
+	gdprPerms := gdpr.NewPermissions(context.Background(), cfg.GDPR, gdprVendorIDs(cfg.Adapters, syncers), generalHttpClient)
 
 	exchanges = newExchangeMap(cfg)
 	cacheClient := pbc.NewClient(cacheHttpClient, &cfg.CacheURL, &cfg.ExtCacheURL, r.MetricsEngine)
 
-	adapters, adaptersErrs := exchange.BuildAdapters(generalHttpClient, cfg, bidderInfos, r.MetricsEngine)
+	breakers := circuitbreaker.NewBreakers(cfg.Adapters, r.MetricsEngine)
+	adapters, adaptersErrs := exchange.BuildAdapters(generalHttpClient, cfg, bidderInfos, r.MetricsEngine, breakers)
 	if len(adaptersErrs) > 0 {
 		errs := errortypes.NewAggregateErrors("Failed to initialize adapters", adaptersErrs)
 		glog.Fatalf("%v", errs)
@@ -253,6 +269,17 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 
 	theExchange := exchange.NewExchange(adapters, cacheClient, cfg, r.MetricsEngine, gdprPerms, rateConvertor, categoriesFetcher)
 
+	var hookPlanBuilder *hooks.PlanBuilder
+	if cfg.Hooks.Enabled {
+		hookRepository, err := modules.Build(cfg.Hooks)
+		if err != nil {
+			glog.Fatalf("Failed to build the modules. %v", err)
+		}
+		if hookPlanBuilder, err = hooks.NewPlanBuilder(cfg.Hooks, hookRepository); err != nil {
+			glog.Fatalf("Failed to create the hook execution plan. %v", err)
+		}
+	}
+
 	openrtbEndpoint, err := openrtb2.NewEndpoint(theExchange, paramsValidator, fetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders)
 	if err != nil {
 		glog.Fatalf("Failed to create the openrtb endpoint handler. %v", err)
@@ -275,13 +302,13 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 	}
 
 	r.POST("/auction", endpoints.Auction(cfg, syncers, gdprPerms, r.MetricsEngine, dataCache, exchanges))
-	r.POST("/openrtb2/auction", openrtbEndpoint)
+	r.POST("/openrtb2/auction", hooks.WrapHandler(hookPlanBuilder, hooks.EndpointAuction, cfg.MaxRequestSize, openrtbEndpoint))
 	r.POST("/openrtb2/video", videoEndpoint)
-	r.GET("/openrtb2/amp", ampEndpoint)
+	r.GET("/openrtb2/amp", hooks.WrapHandler(hookPlanBuilder, hooks.EndpointAmp, cfg.MaxRequestSize, ampEndpoint))
 	r.GET("/info/bidders", infoEndpoints.NewBiddersEndpoint(defaultAliases))
-	r.GET("/info/bidders/:bidderName", infoEndpoints.NewBidderDetailsEndpoint(bidderInfos, defaultAliases))
+	r.GET("/info/bidders/:bidderName", infoEndpoints.NewBidderDetailsEndpoint(bidderInfos, defaultAliases, breakers))
 	r.GET("/bidders/params", NewJsonDirectoryServer(schemaDirectory, paramsValidator, defaultAliases))
-	r.POST("/cookie_sync", endpoints.NewCookieSyncEndpoint(syncers, cfg, gdprPerms, r.MetricsEngine, pbsAnalytics, activeBidders))
+	r.POST("/cookie_sync", endpoints.NewCookieSyncEndpoint(syncers, cfg, gdprPerms, r.MetricsEngine, pbsAnalytics, activeBidders, accounts))
 	r.GET("/status", endpoints.NewStatusEndpoint(cfg.StatusResponse))
 	r.GET("/", serveIndex)
 	r.ServeFiles("/static/*filepath", http.Dir("static"))
@@ -304,7 +331,7 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		PBSAnalytics:     pbsAnalytics,
 	}
 
-	r.GET("/setuid", endpoints.NewSetUIDEndpoint(cfg.HostCookie, syncers, gdprPerms, pbsAnalytics, r.MetricsEngine))
+	r.GET("/setuid", endpoints.NewSetUIDEndpoint(cfg, syncers, gdprPerms, pbsAnalytics, r.MetricsEngine, accounts))
 	r.GET("/getuids", endpoints.NewGetUIDsEndpoint(cfg.HostCookie))
 	r.POST("/optout", userSyncDeps.OptOut)
 	r.GET("/optout", userSyncDeps.OptOut)
@@ -312,6 +339,32 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 	return r, nil
 }
 
+// registerBidderAliases makes the aliases defined in the host configuration known as bidders. It must run before
+// the bidder params validator, the metrics and the adapters are built from the bidder names.
+func registerBidderAliases(adapterConfig map[string]config.Adapter) error {
+	for bidder, adapter := range adapterConfig {
+		if adapter.AliasOf == "" {
+			continue
+		}
+		if err := openrtb_ext.SetAliasBidderName(bidder, openrtb_ext.BidderName(adapter.AliasOf)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gdprVendorIDs returns the GDPR vendor IDs of the bidders with a syncer, along with the ones of the aliases defined
+// in the host configuration, which are enforced even if they don't sync.
+func gdprVendorIDs(adapterConfig map[string]config.Adapter, syncers map[openrtb_ext.BidderName]usersync.Usersyncer) map[openrtb_ext.BidderName]uint16 {
+	vendorIDs := adapters.GDPRAwareSyncerIDs(syncers)
+	for bidder, adapter := range adapterConfig {
+		if adapter.AliasOf != "" && adapter.GVLVendorID != 0 {
+			vendorIDs[openrtb_ext.BidderName(strings.ToLower(bidder))] = adapter.GVLVendorID
+		}
+	}
+	return vendorIDs
+}
+
 // Fixes #648
 //
 // These CORS options pose a security risk... but it's a calculated one.
@@ -8,8 +8,10 @@ import (
 	"os"
 	"testing"
 
+	"github.com/prebid/prebid-server/adapters"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/usersync"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -182,3 +184,40 @@ func TestLoadDefaultAliasesNoInfo(t *testing.T) {
 	assert.Equal(t, expectedAliases, defAliases)
 
 }
+
+func TestBidderAliases(t *testing.T) {
+	adapterConfig := map[string]config.Adapter{
+		"appnexus":       {Endpoint: "http://appnexus.com"},
+		"routerbrand":    {AliasOf: "appnexus", Endpoint: "http://routerbrand.com", GVLVendorID: 1001},
+		"routernosync":   {AliasOf: "rubicon", Endpoint: "http://routernosync.com"},
+		"routernovendor": {AliasOf: "appnexus", Endpoint: "http://routernovendor.com", UserSyncURL: "http://routernovendor.com/sync"},
+	}
+	if !assert.NoError(t, registerBidderAliases(adapterConfig)) {
+		return
+	}
+
+	parent, isAlias := openrtb_ext.AliasBidderParent(openrtb_ext.BidderName("routerbrand"))
+	assert.True(t, isAlias)
+	assert.Equal(t, openrtb_ext.BidderAppnexus, parent)
+
+	syncers := map[openrtb_ext.BidderName]usersync.Usersyncer{
+		openrtb_ext.BidderAppnexus: adapters.NewSyncer("adnxs", 32, nil, adapters.SyncTypeRedirect),
+	}
+	expectedVendorIDs := map[openrtb_ext.BidderName]uint16{
+		openrtb_ext.BidderAppnexus:            32,
+		openrtb_ext.BidderName("routerbrand"): 1001,
+	}
+	assert.Equal(t, expectedVendorIDs, gdprVendorIDs(adapterConfig, syncers))
+
+	handler := NewJsonDirectoryServer("../static/bidder-params", &testValidator{}, nil)
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/whatever", nil)
+	handler(recorder, request, nil)
+
+	var data map[string]json.RawMessage
+	json.Unmarshal(recorder.Body.Bytes(), &data)
+	ensureHasKey(t, data, "routerbrand")
+	ensureHasKey(t, data, "routernosync")
+
+	assert.Error(t, registerBidderAliases(map[string]config.Adapter{"rubicon": {AliasOf: "appnexus"}}), "A core bidder can't be an alias")
+}
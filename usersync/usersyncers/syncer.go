@@ -5,6 +5,7 @@ import (
 	"text/template"
 
 	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/adapters"
 	ttx "github.com/prebid/prebid-server/adapters/33across"
 	"github.com/prebid/prebid-server/adapters/acuityads"
 	"github.com/prebid/prebid-server/adapters/adform"
@@ -169,6 +170,8 @@ func NewSyncerMap(cfg *config.Configuration) map[openrtb_ext.BidderName]usersync
 	insertIntoMap(cfg, syncers, openrtb_ext.BidderZeroClickFraud, zeroclickfraud.NewZeroClickFraudSyncer)
 	insertIntoMap(cfg, syncers, openrtb_ext.BidderBetween, between.NewBetweenSyncer)
 
+	insertAliasesIntoMap(cfg, syncers)
+
 	return syncers
 }
 
@@ -181,3 +184,28 @@ func insertIntoMap(cfg *config.Configuration, syncers map[openrtb_ext.BidderName
 	}
 	syncers[bidder] = syncerFactory(template.Must(template.New(lowercased + "_usersync_url").Parse(urlString)))
 }
+
+// insertAliasesIntoMap adds the syncers of the aliases defined in the host configuration. They sync the same way as
+// their parent, but with their own cookie family, usersync URL and GDPR vendor ID.
+func insertAliasesIntoMap(cfg *config.Configuration, syncers map[openrtb_ext.BidderName]usersync.Usersyncer) {
+	for bidder, adapter := range cfg.Adapters {
+		if adapter.AliasOf == "" {
+			continue
+		}
+		if adapter.UserSyncURL == "" {
+			glog.Warningf("adapters." + bidder + ".usersync_url was not defined. No usersyncs will be performed with the alias " + bidder)
+			continue
+		}
+
+		syncType := adapters.SyncTypeRedirect
+		if parent, found := openrtb_ext.NormalizeBidderName(adapter.AliasOf); found {
+			if parentSyncer, ok := syncers[parent].(*adapters.Syncer); ok {
+				syncType = parentSyncer.SyncType()
+			}
+		}
+
+		lowercased := strings.ToLower(bidder)
+		urlTemplate := template.Must(template.New(lowercased + "_usersync_url").Parse(adapter.UserSyncURL))
+		syncers[openrtb_ext.BidderName(lowercased)] = adapters.NewSyncer(lowercased, adapter.GVLVendorID, urlTemplate, syncType)
+	}
+}
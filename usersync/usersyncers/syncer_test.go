@@ -4,8 +4,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/prebid/prebid-server/adapters"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/privacy"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestNewSyncerMap(t *testing.T) {
@@ -97,6 +100,7 @@ func TestNewSyncerMap(t *testing.T) {
 		openrtb_ext.BidderAdgeneration: true,
 		openrtb_ext.BidderAdhese:       true,
 		openrtb_ext.BidderAdoppler:     true,
+		openrtb_ext.BidderGeneric:      true,
 		openrtb_ext.BidderApplogy:      true,
 		openrtb_ext.BidderInMobi:       true,
 		openrtb_ext.BidderKidoz:        true,
@@ -126,6 +130,63 @@ func TestNewSyncerMap(t *testing.T) {
 	}
 }
 
+func TestNewSyncerMapAliases(t *testing.T) {
+	cfg := &config.Configuration{
+		Adapters: map[string]config.Adapter{
+			"beachfront":  {UserSyncURL: "http://beachfront.com/sync"},
+			"iframebrand": {AliasOf: "beachfront", UserSyncURL: "http://iframebrand.com/sync?gdpr={{.GDPR}}", GVLVendorID: 1001},
+			"mybrand":     {AliasOf: "appnexus", UserSyncURL: "http://mybrand.com/sync", GVLVendorID: 1002},
+			"nosyncbrand": {AliasOf: "appnexus"},
+		},
+	}
+
+	syncers := NewSyncerMap(cfg)
+
+	testCases := []struct {
+		description      string
+		bidder           openrtb_ext.BidderName
+		expectedFamily   string
+		expectedVendorID uint16
+		expectedURL      string
+		expectedType     adapters.SyncType
+	}{
+		{
+			description:      "Parent With An Iframe Syncer",
+			bidder:           openrtb_ext.BidderName("iframebrand"),
+			expectedFamily:   "iframebrand",
+			expectedVendorID: 1001,
+			expectedURL:      "http://iframebrand.com/sync?gdpr=1",
+			expectedType:     adapters.SyncTypeIframe,
+		},
+		{
+			description:      "Parent Without A Syncer",
+			bidder:           openrtb_ext.BidderName("mybrand"),
+			expectedFamily:   "mybrand",
+			expectedVendorID: 1002,
+			expectedURL:      "http://mybrand.com/sync",
+			expectedType:     adapters.SyncTypeRedirect,
+		},
+	}
+
+	for _, test := range testCases {
+		syncer, ok := syncers[test.bidder]
+		if !assert.True(t, ok, test.description) {
+			continue
+		}
+		assert.Equal(t, test.expectedFamily, syncer.FamilyName(), test.description)
+		assert.Equal(t, test.expectedVendorID, syncer.GDPRVendorID(), test.description)
+
+		policies := privacy.Policies{}
+		policies.GDPR.Signal = "1"
+		syncInfo, err := syncer.GetUsersyncInfo(policies)
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expectedURL, syncInfo.URL, test.description)
+		assert.Equal(t, string(test.expectedType), syncInfo.Type, test.description)
+	}
+
+	assert.NotContains(t, syncers, openrtb_ext.BidderName("nosyncbrand"), "An alias without a usersync URL shouldn't have a syncer")
+}
+
 // Bidders may have an ID on the IAB-maintained global vendor list.
 // This makes sure that we don't have conflicting IDs among Bidders in our project,
 // since that's almost certainly a bug.